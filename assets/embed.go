@@ -0,0 +1,10 @@
+// Package assets embeds the default game assets, used when no asset directory
+// is found on disk.
+package assets
+
+import "embed"
+
+// FS holds the default assets, keyed by their path relative to this directory
+//
+//go:embed manifest.json blocks.json images/*.png locales/*.json levels/* schematics/*
+var FS embed.FS
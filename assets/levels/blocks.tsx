@@ -0,0 +1,34 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" name="blocks" tilewidth="32" tileheight="32" tilecount="400" columns="20">
+ <image source="../images/test.png" width="640" height="640"/>
+ <tile id="1">
+  <properties>
+   <property name="block" value="small_block"/>
+  </properties>
+ </tile>
+ <tile id="2">
+  <properties>
+   <property name="block" value="red_block"/>
+  </properties>
+ </tile>
+ <tile id="3">
+  <properties>
+   <property name="block" value="blue_block"/>
+  </properties>
+ </tile>
+ <tile id="4">
+  <properties>
+   <property name="block" value="green_block"/>
+  </properties>
+ </tile>
+ <tile id="5">
+  <properties>
+   <property name="block" value="dirt"/>
+  </properties>
+ </tile>
+ <tile id="6">
+  <properties>
+   <property name="block" value="wood"/>
+  </properties>
+ </tile>
+</tileset>
@@ -1,12 +1,20 @@
 package main
 
 import (
+	"flag"
 	"log"
+
 	game "github.com/wubinrui111/2d-game/internal/engine"
 )
 
 func main() {
-	if err := game.Run(); err != nil {
+	var opts game.Options
+	flag.StringVar(&opts.RecordPath, "record", "", "start a new world and record its input to this replay file")
+	flag.StringVar(&opts.ReplayPath, "replay", "", "play back a replay file")
+	flag.BoolVar(&opts.Dev, "dev", false, "reload assets when their files change")
+	flag.Parse()
+
+	if err := game.Run(opts); err != nil {
 		log.Fatal(err)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,29 @@
+package assets
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/png"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Image returns the decoded image of the asset key, decoding it on first use
+func (m *Manager) Image(key string) (*ebiten.Image, error) {
+	e, err := m.load(key)
+	if err != nil {
+		return nil, err
+	}
+	if img, ok := e.decoded.(*ebiten.Image); ok {
+		return img, nil
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(e.data))
+	if err != nil {
+		return nil, fmt.Errorf("assets: failed to decode %s: %w", key, err)
+	}
+	img := ebiten.NewImageFromImage(decoded)
+	e.decoded = img
+	return img, nil
+}
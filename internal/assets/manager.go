@@ -0,0 +1,258 @@
+// Package assets finds and caches game assets. An asset is named by a key, its
+// slash-separated path below the assets directory, such as "images/test.png".
+// Keys are looked up in a list of directories and then in the defaults embedded
+// in the binary, so the game runs from any working directory.
+package assets
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	embedded "github.com/wubinrui111/2d-game/assets"
+)
+
+// EnvDir is the environment variable naming an extra asset directory, searched first
+const EnvDir = "GAME_ASSETS"
+
+// DefaultPollInterval is how many Update calls pass between checks for changed files
+// when watching, one second at 60 ticks per second
+const DefaultPollInterval = 60
+
+// Source is a place assets are looked up in
+type Source struct {
+	Name string // Shown in errors, such as the directory path
+	FS   fs.FS
+}
+
+// MissingError is returned for a key that is in none of the sources
+type MissingError struct {
+	Key      string
+	Searched []string // Source names in search order
+}
+
+func (e *MissingError) Error() string {
+	return fmt.Sprintf("assets: %s not found (searched %s)", e.Key, strings.Join(e.Searched, ", "))
+}
+
+// version identifies the file an asset was read from, to notice changes
+type version struct {
+	source  int
+	modTime time.Time
+	size    int64
+}
+
+// matches reports whether the file found in source i is the one the version was read from
+func (v version) matches(source int, info fs.FileInfo) bool {
+	return v.source == source && v.modTime.Equal(info.ModTime()) && v.size == info.Size()
+}
+
+// entry is a cached asset
+type entry struct {
+	data    []byte
+	version version
+	decoded any // Decoded form, such as an image, nil until requested
+}
+
+// Manager resolves keys through its sources and caches what it reads
+type Manager struct {
+	sources []Source
+
+	// Watch makes Update check the files of cached assets for changes and reload them.
+	// It is meant for development, to see edited sprites and block definitions without a restart.
+	Watch bool
+
+	// PollInterval is how many Update calls pass between checks when watching
+	PollInterval int
+
+	cache   map[string]*entry
+	missing map[string]bool
+	ticks   int
+	reloads uint64
+}
+
+// NewManager creates a manager that looks keys up in sources, in order
+func NewManager(sources ...Source) *Manager {
+	return &Manager{
+		sources:      sources,
+		PollInterval: DefaultPollInterval,
+		cache:        make(map[string]*entry),
+		missing:      make(map[string]bool),
+	}
+}
+
+// DefaultSources returns the directory named by $GAME_ASSETS, ./assets, the assets
+// directory next to the executable and the embedded defaults
+func DefaultSources() []Source {
+	var dirs []string
+	if dir := os.Getenv(EnvDir); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	dirs = append(dirs, "./assets")
+	if exe, err := os.Executable(); err == nil {
+		dirs = append(dirs, filepath.Join(filepath.Dir(exe), "assets"))
+	}
+
+	var sources []Source
+	for _, dir := range dirs {
+		sources = append(sources, Source{Name: dir, FS: os.DirFS(dir)})
+	}
+	return append(sources, Source{Name: "embedded defaults", FS: embedded.FS})
+}
+
+var defaultManager = NewManager(DefaultSources()...)
+
+// Default returns the asset manager shared by the game
+func Default() *Manager {
+	return defaultManager
+}
+
+// Sources returns the places keys are looked up in, in order
+func (m *Manager) Sources() []Source {
+	return m.sources
+}
+
+// resolve finds the first source containing key
+func (m *Manager) resolve(key string) (int, fs.FileInfo, error) {
+	if !fs.ValidPath(key) {
+		return 0, nil, fmt.Errorf("assets: invalid key %q", key)
+	}
+	for i, source := range m.sources {
+		info, err := fs.Stat(source.FS, key)
+		if err == nil && !info.IsDir() {
+			return i, info, nil
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return 0, nil, fmt.Errorf("assets: %s in %s: %w", key, source.Name, err)
+		}
+	}
+
+	searched := make([]string, len(m.sources))
+	for i, source := range m.sources {
+		searched[i] = source.Name
+	}
+	return 0, nil, &MissingError{Key: key, Searched: searched}
+}
+
+// Data returns the content of the asset key, reading it on first use
+func (m *Manager) Data(key string) ([]byte, error) {
+	e, err := m.load(key)
+	if err != nil {
+		return nil, err
+	}
+	return e.data, nil
+}
+
+// load returns the cache entry of key, reading it if it is not cached
+func (m *Manager) load(key string) (*entry, error) {
+	if e, exists := m.cache[key]; exists {
+		return e, nil
+	}
+
+	i, info, err := m.resolve(key)
+	if err != nil {
+		var missing *MissingError
+		if errors.As(err, &missing) {
+			m.missing[key] = true
+		}
+		return nil, err
+	}
+	data, err := fs.ReadFile(m.sources[i].FS, key)
+	if err != nil {
+		return nil, fmt.Errorf("assets: failed to read %s from %s: %w", key, m.sources[i].Name, err)
+	}
+
+	delete(m.missing, key)
+	e := &entry{data: data, version: version{source: i, modTime: info.ModTime(), size: info.Size()}}
+	m.cache[key] = e
+	return e, nil
+}
+
+// List returns the keys of the files directly in dir, from all sources.
+// A file in an earlier source hides the file with the same name in later ones.
+func (m *Manager) List(dir string) []string {
+	seen := make(map[string]bool)
+	for _, source := range m.sources {
+		entries, err := fs.ReadDir(source.FS, dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() {
+				seen[path.Join(dir, e.Name())] = true
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Missing returns the keys that were requested but not found, sorted
+func (m *Manager) Missing() []string {
+	keys := make([]string, 0, len(m.missing))
+	for key := range m.missing {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Reloads counts the times changed assets were reloaded. Users of assets compare it
+// with the value they last saw to know when to load their assets again.
+func (m *Manager) Reloads() uint64 {
+	return m.reloads
+}
+
+// Update checks for changed files every PollInterval calls when watching.
+// It is called once per tick.
+func (m *Manager) Update() {
+	if !m.Watch {
+		return
+	}
+	m.ticks++
+	if m.ticks < m.PollInterval {
+		return
+	}
+	m.ticks = 0
+	m.Refresh()
+}
+
+// Refresh drops every cached asset whose file changed, or that is now found in another
+// source, and returns the changed keys, including missing keys that now exist. They are
+// read again on next use.
+func (m *Manager) Refresh() []string {
+	var changed []string
+	for key, e := range m.cache {
+		i, info, err := m.resolve(key)
+		if err == nil && e.version.matches(i, info) {
+			continue
+		}
+		delete(m.cache, key)
+		changed = append(changed, key)
+	}
+
+	// 之前找不到、现在出现的资源也算作变化
+	for key := range m.missing {
+		if _, _, err := m.resolve(key); err == nil {
+			delete(m.missing, key)
+			changed = append(changed, key)
+		}
+	}
+	if len(changed) > 0 {
+		sort.Strings(changed)
+		m.reloads++
+		fmt.Printf("Reloading assets: %s\n", strings.Join(changed, ", "))
+	}
+	return changed
+}
@@ -0,0 +1,135 @@
+package assets
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestDataResolvesInSourceOrder(t *testing.T) {
+	override := fstest.MapFS{"blocks.json": {Data: []byte("override")}}
+	defaults := fstest.MapFS{
+		"blocks.json":   {Data: []byte("default")},
+		"manifest.json": {Data: []byte("manifest")},
+	}
+	m := NewManager(Source{Name: "dir", FS: override}, Source{Name: "embedded", FS: defaults})
+
+	if data, err := m.Data("blocks.json"); err != nil || string(data) != "override" {
+		t.Errorf("Expected the first source to win, got %q, %v", data, err)
+	}
+	if data, err := m.Data("manifest.json"); err != nil || string(data) != "manifest" {
+		t.Errorf("Expected a fallback to later sources, got %q, %v", data, err)
+	}
+}
+
+func TestDataIsCached(t *testing.T) {
+	files := fstest.MapFS{"a.txt": {Data: []byte("one")}}
+	m := NewManager(Source{Name: "dir", FS: files})
+	if _, err := m.Data("a.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 不刷新时仍然返回缓存的内容
+	files["a.txt"] = &fstest.MapFile{Data: []byte("two")}
+	if data, _ := m.Data("a.txt"); string(data) != "one" {
+		t.Errorf("Expected the cached content, got %q", data)
+	}
+}
+
+func TestMissingError(t *testing.T) {
+	m := NewManager(Source{Name: "./assets", FS: fstest.MapFS{}}, Source{Name: "embedded defaults", FS: fstest.MapFS{}})
+	_, err := m.Data("images/none.png")
+
+	var missing *MissingError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Expected a MissingError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "images/none.png") || !strings.Contains(err.Error(), "./assets, embedded defaults") {
+		t.Errorf("Expected the key and searched sources in %q", err)
+	}
+	if got := m.Missing(); !reflect.DeepEqual(got, []string{"images/none.png"}) {
+		t.Errorf("Expected the key to be recorded as missing, got %v", got)
+	}
+
+	if _, err := m.Data("../outside.txt"); err == nil || errors.As(err, &missing) {
+		t.Errorf("Expected an invalid key error, got %v", err)
+	}
+}
+
+func TestList(t *testing.T) {
+	m := NewManager(
+		Source{Name: "dir", FS: fstest.MapFS{"locales/en.json": {}, "locales/fr.json": {}}},
+		Source{Name: "embedded", FS: fstest.MapFS{"locales/en.json": {}, "locales/zh.json": {}, "locales/sub/x.json": {}}},
+	)
+	want := []string{"locales/en.json", "locales/fr.json", "locales/zh.json"}
+	if got := m.List("locales"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got := m.List("fonts"); len(got) != 0 {
+		t.Errorf("Expected no keys in a missing directory, got %v", got)
+	}
+}
+
+func TestRefreshReloadsChangedFiles(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	files := fstest.MapFS{"blocks.json": {Data: []byte("old"), ModTime: start}}
+	m := NewManager(Source{Name: "dir", FS: files})
+	if _, err := m.Data("blocks.json"); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed := m.Refresh(); len(changed) != 0 || m.Reloads() != 0 {
+		t.Errorf("Expected no changes, got %v", changed)
+	}
+
+	files["blocks.json"] = &fstest.MapFile{Data: []byte("new"), ModTime: start.Add(time.Second)}
+	if changed := m.Refresh(); !reflect.DeepEqual(changed, []string{"blocks.json"}) || m.Reloads() != 1 {
+		t.Errorf("Expected blocks.json to change once, got %v after %d reloads", changed, m.Reloads())
+	}
+	if data, _ := m.Data("blocks.json"); string(data) != "new" {
+		t.Errorf("Expected the new content, got %q", data)
+	}
+}
+
+func TestRefreshFindsMissingFiles(t *testing.T) {
+	files := fstest.MapFS{}
+	m := NewManager(Source{Name: "dir", FS: files})
+	if _, err := m.Data("images/new.png"); err == nil {
+		t.Fatal("Expected the asset to be missing")
+	}
+
+	files["images/new.png"] = &fstest.MapFile{Data: []byte("png")}
+	if changed := m.Refresh(); !reflect.DeepEqual(changed, []string{"images/new.png"}) {
+		t.Errorf("Expected the new file to count as changed, got %v", changed)
+	}
+	if len(m.Missing()) != 0 {
+		t.Errorf("Expected nothing missing, got %v", m.Missing())
+	}
+}
+
+func TestUpdatePollsOnlyWhenWatching(t *testing.T) {
+	files := fstest.MapFS{"a.txt": {Data: []byte("one")}}
+	m := NewManager(Source{Name: "dir", FS: files})
+	m.PollInterval = 2
+	m.Data("a.txt")
+	files["a.txt"] = &fstest.MapFile{Data: []byte("changed"), ModTime: time.Unix(1, 0)}
+
+	m.Update()
+	m.Update()
+	if m.Reloads() != 0 {
+		t.Error("Expected no polling without Watch")
+	}
+
+	m.Watch = true
+	m.Update()
+	if m.Reloads() != 0 {
+		t.Error("Expected no polling before the interval")
+	}
+	m.Update()
+	if m.Reloads() != 1 {
+		t.Errorf("Expected a reload after the interval, got %d", m.Reloads())
+	}
+}
@@ -0,0 +1,192 @@
+// Package blocks holds the block definitions: what each kind of block looks like
+// and which item it drops. Definitions are data, loaded from blocks.json.
+package blocks
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/wubinrui111/2d-game/internal/lighting"
+)
+
+// DefaultKey is the asset key of the block definitions
+const DefaultKey = "blocks.json"
+
+// DefaultSprite is the sprite drawn for a block whose sprite is missing from the atlas
+const DefaultSprite = "small_block"
+
+// Definition describes one kind of block. Its ID is also the ID of the item that
+// places it and that it drops.
+type Definition struct {
+	ID     string
+	Name   string     // English display name of the item
+	Color  color.RGBA // Used for the item and when there is no sprite
+	Sprite string     // Sprite name in the atlas
+
+	// Light is the light level the block gives off, 0 to lighting.MaxLevel. Transparent
+	// blocks let light through; all others stop it.
+	Light       int
+	Transparent bool
+
+	// Falls is whether the block falls when the cell below it is empty, like sand
+	Falls bool
+
+	// Tinted is whether the block takes the tint of its biome, like grass and leaves
+	Tinted bool
+
+	// Ticks is how the block changes on block ticks, nil if it never does
+	Ticks *Behavior
+
+	// Aliases are other block names that mean this block, such as older entity names
+	Aliases []string
+}
+
+// Behavior is how a block reacts to block ticks. Random ticks change the block with
+// probability Chance; scheduled ticks, Delay ticks after the block or one next to it
+// changed, always do.
+type Behavior struct {
+	Chance float64 `json:"chance"` // 0 to 1, 0 if random ticks do nothing
+	Delay  int     `json:"delay"`  // 0 if the block has no scheduled ticks
+
+	// MinLight is the light the block needs to spread or grow
+	MinLight int `json:"min_light"`
+
+	// Spread is a block next to this one that it turns into its own kind when the
+	// cell above is lit, like grass onto dirt. Covered is the block it becomes when a
+	// block that is not transparent is on top of it.
+	Spread  string `json:"spread"`
+	Covered string `json:"covered"`
+
+	// Grow is the block it grows into, like the next stage of a crop; Structure is
+	// the schematic it grows into instead, like a sapling into a tree
+	Grow      string `json:"grow"`
+	Structure string `json:"structure"`
+
+	// DecayWithout is a block that must be within DecayRange cells, or the block
+	// decays, like leaves without a log
+	DecayWithout string `json:"decay_without"`
+	DecayRange   int    `json:"decay_range"`
+}
+
+// definitionJSON is a definition as written in blocks.json
+type definitionJSON struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Color   string   `json:"color"` // "#rrggbb" or "#rrggbbaa"
+	Sprite  string   `json:"sprite"`
+	Aliases []string `json:"aliases"`
+
+	Light       int  `json:"light"`
+	Transparent bool `json:"transparent"`
+	Falls       bool `json:"falls"`
+	Tinted      bool `json:"tinted"`
+
+	Ticks *Behavior `json:"ticks"`
+}
+
+// Registry looks up block definitions by ID or alias
+type Registry struct {
+	defs    map[string]*Definition
+	aliases map[string]string
+	ids     []string
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		defs:    make(map[string]*Definition),
+		aliases: make(map[string]string),
+	}
+}
+
+// Parse reads block definitions in the blocks.json format
+func Parse(data []byte) (*Registry, error) {
+	var file struct {
+		Blocks []definitionJSON `json:"blocks"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	r := NewRegistry()
+	for _, raw := range file.Blocks {
+		c, err := ParseColor(raw.Color)
+		if err != nil {
+			return nil, fmt.Errorf("blocks: %s: %w", raw.ID, err)
+		}
+		if raw.Light < 0 || raw.Light > lighting.MaxLevel {
+			return nil, fmt.Errorf("blocks: %s: light %d is not between 0 and %d", raw.ID, raw.Light, lighting.MaxLevel)
+		}
+		def := &Definition{
+			ID: raw.ID, Name: raw.Name, Color: c, Sprite: raw.Sprite, Aliases: raw.Aliases,
+			Light: raw.Light, Transparent: raw.Transparent, Falls: raw.Falls, Tinted: raw.Tinted, Ticks: raw.Ticks,
+		}
+		if b := raw.Ticks; b != nil && (b.Chance < 0 || b.Chance > 1 || b.Delay < 0) {
+			return nil, fmt.Errorf("blocks: %s: tick chance %v must be between 0 and 1 and delay %d not negative", raw.ID, b.Chance, b.Delay)
+		}
+		if err := r.Add(def); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Add registers a definition
+func (r *Registry) Add(def *Definition) error {
+	if def.ID == "" {
+		return fmt.Errorf("blocks: definition without an id")
+	}
+	if _, exists := r.Lookup(def.ID); exists {
+		return fmt.Errorf("blocks: %s is defined twice", def.ID)
+	}
+	for _, alias := range def.Aliases {
+		if _, exists := r.Lookup(alias); exists || alias == def.ID {
+			return fmt.Errorf("blocks: alias %s of %s is already used", alias, def.ID)
+		}
+	}
+
+	r.defs[def.ID] = def
+	for _, alias := range def.Aliases {
+		r.aliases[alias] = def.ID
+	}
+	r.ids = append(r.ids, def.ID)
+	return nil
+}
+
+// Get returns the definition with the given ID
+func (r *Registry) Get(id string) (*Definition, bool) {
+	def, exists := r.defs[id]
+	return def, exists
+}
+
+// Lookup returns the definition for a block name, which is an ID or an alias
+func (r *Registry) Lookup(name string) (*Definition, bool) {
+	if id, exists := r.aliases[name]; exists {
+		name = id
+	}
+	return r.Get(name)
+}
+
+// IDs returns the IDs of all definitions in the order they were added
+func (r *Registry) IDs() []string {
+	return append([]string(nil), r.ids...)
+}
+
+// ParseColor parses "#rrggbb" or "#rrggbbaa"
+func ParseColor(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 && len(hex) != 8 {
+		return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+	return color.RGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
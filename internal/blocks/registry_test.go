@@ -0,0 +1,97 @@
+package blocks
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	data := []byte(`{"blocks": [
+		{"id": "stone", "name": "Stone", "color": "#808080", "sprite": "stone"},
+		{"id": "red_block", "name": "Red Block", "color": "#c8323280", "aliases": ["RedBlock"]},
+		{"id": "torch", "name": "Torch", "color": "#ffd040", "light": 14, "transparent": true},
+		{"id": "sand", "name": "Sand", "color": "#dcc882", "falls": true},
+		{"id": "grass", "name": "Grass", "color": "#4ca03c", "tinted": true, "ticks": {"chance": 0.5, "min_light": 9, "spread": "dirt", "covered": "dirt"}}
+	]}`)
+	r, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stone, exists := r.Get("stone")
+	if !exists || stone.Color != (color.RGBA{128, 128, 128, 255}) || stone.Sprite != "stone" {
+		t.Errorf("Unexpected stone definition %+v", stone)
+	}
+	red, exists := r.Lookup("RedBlock")
+	if !exists || red.ID != "red_block" || red.Color.A != 0x80 {
+		t.Errorf("Expected the alias to find red_block, got %+v", red)
+	}
+	if _, exists := r.Get("RedBlock"); exists {
+		t.Error("Expected Get to ignore aliases")
+	}
+	if torch, _ := r.Get("torch"); torch.Light != 14 || !torch.Transparent || stone.Light != 0 || stone.Transparent {
+		t.Errorf("Unexpected light of torch %+v and stone %+v", torch, stone)
+	}
+	if grass, _ := r.Get("grass"); !grass.Tinted || stone.Tinted {
+		t.Errorf("Expected only grass to be tinted, got %+v and %+v", grass, stone)
+	}
+	if sand, _ := r.Get("sand"); !sand.Falls || stone.Falls {
+		t.Errorf("Expected only sand to fall, got %+v and %+v", sand, stone)
+	}
+	if grass, _ := r.Get("grass"); grass.Ticks == nil || *grass.Ticks != (Behavior{Chance: 0.5, MinLight: 9, Spread: "dirt", Covered: "dirt"}) || stone.Ticks != nil {
+		t.Errorf("Unexpected tick behavior of grass %+v and stone %+v", grass.Ticks, stone.Ticks)
+	}
+	if ids := r.IDs(); len(ids) != 5 || ids[0] != "stone" || ids[1] != "red_block" || ids[2] != "torch" || ids[3] != "sand" || ids[4] != "grass" {
+		t.Errorf("Expected IDs in file order, got %v", ids)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"invalid json":    `{`,
+		"missing id":      `{"blocks": [{"color": "#000000"}]}`,
+		"bad color":       `{"blocks": [{"id": "a", "color": "red"}]}`,
+		"too bright":      `{"blocks": [{"id": "a", "color": "#000000", "light": 16}]}`,
+		"bad tick chance": `{"blocks": [{"id": "a", "color": "#000000", "ticks": {"chance": 2}}]}`,
+		"negative delay":  `{"blocks": [{"id": "a", "color": "#000000", "ticks": {"delay": -1}}]}`,
+		"duplicate id":    `{"blocks": [{"id": "a", "color": "#000000"}, {"id": "a", "color": "#000000"}]}`,
+		"alias is an id":  `{"blocks": [{"id": "a", "color": "#000000"}, {"id": "b", "color": "#000000", "aliases": ["a"]}]}`,
+		"duplicate alias": `{"blocks": [{"id": "a", "color": "#000000", "aliases": ["x"]}, {"id": "b", "color": "#000000", "aliases": ["x"]}]}`,
+	}
+	for name, data := range tests {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestDefaultDefinitions(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "assets", DefaultKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 初始物品栏中的物品都能放置
+	for _, id := range []string{"stone", "dirt", "wood", "small_block", "red_block", "blue_block", "green_block", "torch", "water", "lava", "sand", "gravel", "grass", "sapling", "wheat", "leaves", "tall_grass", "red_flower", "yellow_flower", "snow"} {
+		if _, exists := r.Get(id); !exists {
+			t.Errorf("Expected a definition for %s", id)
+		}
+	}
+	// 方块刻变成的方块都有定义
+	for _, id := range r.IDs() {
+		def, _ := r.Get(id)
+		if def.Ticks == nil {
+			continue
+		}
+		for _, other := range []string{def.Ticks.Spread, def.Ticks.Covered, def.Ticks.Grow, def.Ticks.DecayWithout} {
+			if _, exists := r.Lookup(other); other != "" && !exists {
+				t.Errorf("Expected a definition for %s, which %s ticks into", other, id)
+			}
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Package camera maps the world onto the screen: following a target with a dead-zone
+// and look-ahead, zoom, world bounds and screen shake.
+package camera
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Default camera tuning
+const (
+	DefaultSmoothing      = 0.1  // Fraction of the distance to the goal moved per tick
+	DefaultDeadZoneWidth  = 64.0 // World pixels the target can move horizontally without moving the camera
+	DefaultDeadZoneHeight = 48.0
+	DefaultLookAhead      = 0.3  // Seconds of target velocity the camera looks ahead
+	DefaultMaxLookAheadX  = 96.0 // World pixels
+	DefaultMaxLookAheadY  = 48.0
+	DefaultMinZoom        = 0.5
+	DefaultMaxZoom        = 3.0
+	DefaultMaxShake       = 12.0 // Screen pixels of offset at full shake
+	DefaultShakeDecay     = 1.5  // Shake lost per second
+)
+
+// Rect is an axis-aligned rectangle in world coordinates
+type Rect struct {
+	X, Y, W, H float64
+}
+
+// Camera is the view onto the world. X and Y are the world position shown
+// at the center of the screen.
+type Camera struct {
+	X, Y float64
+
+	// ScreenWidth and ScreenHeight are the size of the view on screen
+	ScreenWidth, ScreenHeight float64
+
+	// Smoothing is the fraction of the distance to the goal moved per tick (0.0 to 1.0)
+	Smoothing float64
+
+	// DeadZoneWidth and DeadZoneHeight are the world size of the area around the center
+	// in which the target moves without moving the camera
+	DeadZoneWidth, DeadZoneHeight float64
+
+	// LookAhead is how many seconds of the target's velocity the camera looks ahead,
+	// limited to MaxLookAheadX and MaxLookAheadY world pixels
+	LookAhead                    float64
+	MaxLookAheadX, MaxLookAheadY float64
+
+	// MinZoom and MaxZoom limit the zoom
+	MinZoom, MaxZoom float64
+
+	// Bounds keeps the view inside the world, nil for an unbounded world
+	Bounds *Rect
+
+	// MaxShake is the offset in screen pixels at full shake, ShakeDecay the shake lost per second
+	MaxShake, ShakeDecay float64
+
+	zoom           float64
+	shake          float64 // 0 to 1, the offset grows with its square
+	shakeX, shakeY float64
+	rng            *rand.Rand
+}
+
+// New creates a camera for a screen of the given size with the default tuning
+func New(screenWidth, screenHeight float64) *Camera {
+	return &Camera{
+		ScreenWidth:    screenWidth,
+		ScreenHeight:   screenHeight,
+		Smoothing:      DefaultSmoothing,
+		DeadZoneWidth:  DefaultDeadZoneWidth,
+		DeadZoneHeight: DefaultDeadZoneHeight,
+		LookAhead:      DefaultLookAhead,
+		MaxLookAheadX:  DefaultMaxLookAheadX,
+		MaxLookAheadY:  DefaultMaxLookAheadY,
+		MinZoom:        DefaultMinZoom,
+		MaxZoom:        DefaultMaxZoom,
+		MaxShake:       DefaultMaxShake,
+		ShakeDecay:     DefaultShakeDecay,
+		zoom:           1,
+		// 震动使用固定种子，回放时画面一致
+		rng: rand.New(rand.NewSource(1)),
+	}
+}
+
+// CenterOn moves the camera to a world position at once
+func (c *Camera) CenterOn(x, y float64) {
+	c.X, c.Y = x, y
+	c.clampToBounds()
+}
+
+// Update follows a target at (targetX, targetY) moving with (velX, velY) and advances
+// the shake by dt seconds. It is called once per tick.
+func (c *Camera) Update(targetX, targetY, velX, velY, dt float64) {
+	// 根据速度向前看
+	focusX := targetX + clamp(velX*c.LookAhead, -c.MaxLookAheadX, c.MaxLookAheadX)
+	focusY := targetY + clamp(velY*c.LookAhead, -c.MaxLookAheadY, c.MaxLookAheadY)
+
+	// 焦点在死区内时摄像机不动，否则移动到焦点刚好在死区边缘的位置
+	goalX := deadZoneGoal(c.X, focusX, c.DeadZoneWidth/2)
+	goalY := deadZoneGoal(c.Y, focusY, c.DeadZoneHeight/2)
+
+	// 平滑跟随
+	c.X += (goalX - c.X) * c.Smoothing
+	c.Y += (goalY - c.Y) * c.Smoothing
+	c.clampToBounds()
+
+	c.updateShake(dt)
+}
+
+// deadZoneGoal returns the camera position that puts focus at the edge of a dead-zone
+// of half size half around center, or center if focus is inside it
+func deadZoneGoal(center, focus, half float64) float64 {
+	switch {
+	case focus < center-half:
+		return focus + half
+	case focus > center+half:
+		return focus - half
+	}
+	return center
+}
+
+// Zoom returns the zoom factor, 1 shows one world pixel per screen pixel
+func (c *Camera) Zoom() float64 {
+	return c.zoom
+}
+
+// SetZoom sets the zoom factor within MinZoom and MaxZoom
+func (c *Camera) SetZoom(zoom float64) {
+	c.zoom = clamp(zoom, c.MinZoom, c.MaxZoom)
+	c.clampToBounds()
+}
+
+// ZoomBy multiplies the zoom factor, for example 1.1 to zoom in a step
+func (c *Camera) ZoomBy(factor float64) {
+	c.SetZoom(c.zoom * factor)
+}
+
+// Shake adds screen shake from 0 (none) to 1 (full). Shakes add up to at most 1
+// and fade out over time.
+func (c *Camera) Shake(amount float64) {
+	c.shake = clamp(c.shake+amount, 0, 1)
+}
+
+// updateShake picks a new random offset for the current shake and lets it fade
+func (c *Camera) updateShake(dt float64) {
+	if c.shake <= 0 {
+		c.shakeX, c.shakeY = 0, 0
+		return
+	}
+
+	offset := c.MaxShake * c.shake * c.shake
+	c.shakeX = offset * (c.rng.Float64()*2 - 1)
+	c.shakeY = offset * (c.rng.Float64()*2 - 1)
+	c.shake = math.Max(0, c.shake-c.ShakeDecay*dt)
+}
+
+// clampToBounds keeps the view inside Bounds, centering it when the bounds are smaller than the view
+func (c *Camera) clampToBounds() {
+	if c.Bounds == nil {
+		return
+	}
+
+	view := c.ViewRect()
+	c.X = clampAxis(c.X, view.W/2, c.Bounds.X, c.Bounds.W)
+	c.Y = clampAxis(c.Y, view.H/2, c.Bounds.Y, c.Bounds.H)
+}
+
+// clampAxis keeps a view of half size half centered at center inside [min, min+size]
+func clampAxis(center, half, min, size float64) float64 {
+	if 2*half >= size {
+		return min + size/2
+	}
+	return clamp(center, min+half, min+size-half)
+}
+
+// ViewRect returns the part of the world that is on screen, ignoring shake
+func (c *Camera) ViewRect() Rect {
+	w := c.ScreenWidth / c.zoom
+	h := c.ScreenHeight / c.zoom
+	return Rect{X: c.X - w/2, Y: c.Y - h/2, W: w, H: h}
+}
+
+// WorldToScreen converts a world position to screen coordinates
+func (c *Camera) WorldToScreen(x, y float64) (float64, float64) {
+	return (x-c.X)*c.zoom + c.ScreenWidth/2 + c.shakeX,
+		(y-c.Y)*c.zoom + c.ScreenHeight/2 + c.shakeY
+}
+
+// ScreenToWorld converts screen coordinates, such as the cursor, to a world position
+func (c *Camera) ScreenToWorld(x, y float64) (float64, float64) {
+	return (x-c.ScreenWidth/2-c.shakeX)/c.zoom + c.X,
+		(y-c.ScreenHeight/2-c.shakeY)/c.zoom + c.Y
+}
+
+// GeoM returns the world-to-screen transform, to be concatenated after an
+// image's world placement
+func (c *Camera) GeoM() ebiten.GeoM {
+	var g ebiten.GeoM
+	g.Translate(-c.X, -c.Y)
+	g.Scale(c.zoom, c.zoom)
+	g.Translate(c.ScreenWidth/2+c.shakeX, c.ScreenHeight/2+c.shakeY)
+	return g
+}
+
+// clamp limits v to [lo, hi]
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
@@ -0,0 +1,144 @@
+package camera
+
+import (
+	"math"
+	"testing"
+)
+
+const dt = 1.0 / 60.0
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestScreenWorldRoundTrip(t *testing.T) {
+	c := New(800, 600)
+	c.CenterOn(100, 50)
+	c.SetZoom(2)
+
+	x, y := c.WorldToScreen(100, 50)
+	if !near(x, 400) || !near(y, 300) {
+		t.Errorf("Expected the camera position at the screen center, got (%v, %v)", x, y)
+	}
+
+	x, y = c.WorldToScreen(110, 60)
+	if !near(x, 420) || !near(y, 320) {
+		t.Errorf("Expected zoom to scale distances, got (%v, %v)", x, y)
+	}
+
+	wx, wy := c.ScreenToWorld(x, y)
+	if !near(wx, 110) || !near(wy, 60) {
+		t.Errorf("Expected ScreenToWorld to invert WorldToScreen, got (%v, %v)", wx, wy)
+	}
+}
+
+func TestGeoMMatchesWorldToScreen(t *testing.T) {
+	c := New(800, 600)
+	c.CenterOn(-40, 300)
+	c.SetZoom(1.5)
+	c.Shake(1)
+	c.Update(-40, 300, 0, 0, dt)
+
+	g := c.GeoM()
+	gx, gy := g.Apply(12, 34)
+	x, y := c.WorldToScreen(12, 34)
+	if !near(gx, x) || !near(gy, y) {
+		t.Errorf("Expected GeoM (%v, %v) to match WorldToScreen (%v, %v)", gx, gy, x, y)
+	}
+}
+
+func TestZoomIsClamped(t *testing.T) {
+	c := New(800, 600)
+	c.SetZoom(100)
+	if c.Zoom() != c.MaxZoom {
+		t.Errorf("Expected zoom %v, got %v", c.MaxZoom, c.Zoom())
+	}
+	for i := 0; i < 100; i++ {
+		c.ZoomBy(0.5)
+	}
+	if c.Zoom() != c.MinZoom {
+		t.Errorf("Expected zoom %v, got %v", c.MinZoom, c.Zoom())
+	}
+}
+
+func TestViewRectShrinksWithZoom(t *testing.T) {
+	c := New(800, 600)
+	c.CenterOn(0, 0)
+	c.SetZoom(2)
+
+	view := c.ViewRect()
+	if view != (Rect{X: -200, Y: -150, W: 400, H: 300}) {
+		t.Errorf("Unexpected view %+v", view)
+	}
+}
+
+func TestDeadZone(t *testing.T) {
+	c := New(800, 600)
+	c.LookAhead = 0
+	c.CenterOn(0, 0)
+
+	// 死区内移动不影响摄像机
+	c.Update(c.DeadZoneWidth/2-1, c.DeadZoneHeight/2-1, 0, 0, dt)
+	if c.X != 0 || c.Y != 0 {
+		t.Errorf("Expected the camera not to move inside the dead-zone, got (%v, %v)", c.X, c.Y)
+	}
+
+	// 离开死区后摄像机跟随，直到目标回到死区边缘
+	for i := 0; i < 600; i++ {
+		c.Update(500, 0, 0, 0, dt)
+	}
+	if !near(c.X, 500-c.DeadZoneWidth/2) {
+		t.Errorf("Expected the target to end at the dead-zone edge, camera at %v", c.X)
+	}
+}
+
+func TestLookAhead(t *testing.T) {
+	c := New(800, 600)
+	c.DeadZoneWidth = 0
+	c.CenterOn(0, 0)
+	for i := 0; i < 600; i++ {
+		c.Update(0, 0, 1000, 0, dt)
+	}
+	if !near(c.X, c.MaxLookAheadX) {
+		t.Errorf("Expected the camera to look %v ahead, got %v", c.MaxLookAheadX, c.X)
+	}
+}
+
+func TestBounds(t *testing.T) {
+	c := New(800, 600)
+	c.Bounds = &Rect{X: 0, Y: 0, W: 2000, H: 1000}
+
+	c.CenterOn(-500, 5000)
+	if c.X != 400 || c.Y != 700 {
+		t.Errorf("Expected the view to stay inside the bounds, got (%v, %v)", c.X, c.Y)
+	}
+
+	// 视野比世界大时居中
+	c.SetZoom(0.5)
+	if c.Y != 500 {
+		t.Errorf("Expected the view to be centered on small bounds, got %v", c.Y)
+	}
+}
+
+func TestShakeFades(t *testing.T) {
+	c := New(800, 600)
+	c.CenterOn(0, 0)
+	c.Shake(1)
+
+	c.Update(0, 0, 0, 0, dt)
+	x, y := c.WorldToScreen(0, 0)
+	if x == 400 && y == 300 {
+		t.Error("Expected shake to offset the view")
+	}
+	if math.Abs(x-400) > c.MaxShake || math.Abs(y-300) > c.MaxShake {
+		t.Errorf("Expected shake within %v pixels, got (%v, %v)", c.MaxShake, x, y)
+	}
+
+	for i := 0; i < 120; i++ {
+		c.Update(0, 0, 0, 0, dt)
+	}
+	x, y = c.WorldToScreen(0, 0)
+	if x != 400 || y != 300 {
+		t.Errorf("Expected shake to fade out, got (%v, %v)", x, y)
+	}
+}
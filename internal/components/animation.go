@@ -0,0 +1,143 @@
+package components
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// DefaultFrameDuration is used for frames without a duration, in seconds
+const DefaultFrameDuration = 0.1
+
+// LoopMode is what a clip does after its last frame
+type LoopMode int
+
+const (
+	LoopForever  LoopMode = iota // Start again from the first frame
+	LoopOnce                     // Stop on the last frame
+	LoopPingPong                 // Play backward to the first frame, then forward again
+)
+
+// AnimationFrame is one image of a clip
+type AnimationFrame struct {
+	Image    *ebiten.Image
+	Duration float64 // Seconds, 0 means DefaultFrameDuration
+}
+
+// AnimationClip is a named sequence of frames, such as "run"
+type AnimationClip struct {
+	Frames []AnimationFrame
+	Loop   LoopMode
+}
+
+// StaticClip returns a clip showing a single image
+func StaticClip(img *ebiten.Image) *AnimationClip {
+	return &AnimationClip{Frames: []AnimationFrame{{Image: img}}}
+}
+
+// frameDuration returns how long frame i is shown
+func (c *AnimationClip) frameDuration(i int) float64 {
+	if d := c.Frames[i].Duration; d > 0 {
+		return d
+	}
+	return DefaultFrameDuration
+}
+
+// Animation plays one clip at a time out of a set of clips
+type Animation struct {
+	Clips map[string]*AnimationClip
+
+	// FlipX draws the frames mirrored horizontally, for sprites facing the other way
+	FlipX bool
+
+	clip     string
+	frame    int
+	elapsed  float64 // Time the current frame has been shown
+	backward bool    // Ping-pong clips: whether playing toward the first frame
+	finished bool    // Once clips: whether the last frame was reached
+}
+
+// NewAnimation creates an animation without clips
+func NewAnimation() *Animation {
+	return &Animation{Clips: make(map[string]*AnimationClip)}
+}
+
+// Play switches to the clip named name from its first frame.
+// Playing the clip that is already playing does not restart it.
+func (a *Animation) Play(name string) {
+	if name == a.clip {
+		return
+	}
+	a.clip = name
+	a.Restart()
+}
+
+// Restart plays the current clip again from its first frame
+func (a *Animation) Restart() {
+	a.frame = 0
+	a.elapsed = 0
+	a.backward = false
+	a.finished = false
+}
+
+// Clip returns the name of the current clip
+func (a *Animation) Clip() string {
+	return a.clip
+}
+
+// FrameIndex returns the index of the current frame in the current clip
+func (a *Animation) FrameIndex() int {
+	return a.frame
+}
+
+// Finished reports whether a clip played once has reached its last frame
+func (a *Animation) Finished() bool {
+	return a.finished
+}
+
+// Frame returns the image to draw, nil if the current clip does not exist or is empty
+func (a *Animation) Frame() *ebiten.Image {
+	c := a.Clips[a.clip]
+	if c == nil || len(c.Frames) == 0 {
+		return nil
+	}
+	return c.Frames[a.frame].Image
+}
+
+// Update advances the current clip by dt seconds
+func (a *Animation) Update(dt float64) {
+	c := a.Clips[a.clip]
+	if c == nil || len(c.Frames) < 2 {
+		return
+	}
+
+	a.elapsed += dt
+	for !a.finished && a.elapsed >= c.frameDuration(a.frame) {
+		a.elapsed -= c.frameDuration(a.frame)
+		a.advance(c)
+	}
+}
+
+// advance moves to the next frame of c according to its loop mode
+func (a *Animation) advance(c *AnimationClip) {
+	last := len(c.Frames) - 1
+	switch c.Loop {
+	case LoopOnce:
+		if a.frame == last {
+			a.finished = true
+			a.elapsed = 0
+			return
+		}
+		a.frame++
+	case LoopPingPong:
+		// 到达两端时改变方向
+		if a.frame == last {
+			a.backward = true
+		} else if a.frame == 0 {
+			a.backward = false
+		}
+		if a.backward {
+			a.frame--
+		} else {
+			a.frame++
+		}
+	default:
+		a.frame = (a.frame + 1) % len(c.Frames)
+	}
+}
@@ -0,0 +1,126 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// testClip returns a clip of n frames of 0.1 seconds
+func testClip(n int, loop LoopMode) *AnimationClip {
+	clip := &AnimationClip{Loop: loop}
+	for i := 0; i < n; i++ {
+		clip.Frames = append(clip.Frames, AnimationFrame{Image: &ebiten.Image{}, Duration: 0.1})
+	}
+	return clip
+}
+
+// frames plays the animation for steps ticks of 0.1 seconds and returns the frame indices
+func frames(a *Animation, steps int) []int {
+	var indices []int
+	for i := 0; i < steps; i++ {
+		a.Update(0.1)
+		indices = append(indices, a.FrameIndex())
+	}
+	return indices
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestAnimationLoopModes(t *testing.T) {
+	tests := []struct {
+		loop LoopMode
+		want []int
+	}{
+		{LoopForever, []int{1, 2, 0, 1, 2, 0}},
+		{LoopOnce, []int{1, 2, 2, 2, 2, 2}},
+		{LoopPingPong, []int{1, 2, 1, 0, 1, 2}},
+	}
+	for _, tt := range tests {
+		a := NewAnimation()
+		a.Clips["clip"] = testClip(3, tt.loop)
+		a.Play("clip")
+		if got := frames(a, 6); !equalInts(got, tt.want) {
+			t.Errorf("Loop mode %d: expected frames %v, got %v", tt.loop, tt.want, got)
+		}
+	}
+}
+
+func TestAnimationOnceFinishes(t *testing.T) {
+	a := NewAnimation()
+	a.Clips["mine"] = testClip(2, LoopOnce)
+	a.Play("mine")
+
+	a.Update(0.15)
+	if a.Finished() {
+		t.Error("Expected the clip not to be finished on its last frame")
+	}
+	a.Update(0.1)
+	if !a.Finished() {
+		t.Error("Expected the clip to be finished after its last frame was shown")
+	}
+
+	a.Restart()
+	if a.Finished() || a.FrameIndex() != 0 {
+		t.Error("Expected Restart to play the clip from the start")
+	}
+}
+
+func TestAnimationPlayKeepsCurrentClip(t *testing.T) {
+	a := NewAnimation()
+	a.Clips["run"] = testClip(4, LoopForever)
+	a.Clips["idle"] = testClip(2, LoopForever)
+
+	a.Play("run")
+	a.Update(0.25)
+	a.Play("run")
+	if a.FrameIndex() != 2 {
+		t.Errorf("Expected playing the same clip to continue at frame 2, got %d", a.FrameIndex())
+	}
+
+	a.Play("idle")
+	if a.Clip() != "idle" || a.FrameIndex() != 0 {
+		t.Errorf("Expected a new clip to start at its first frame, got %s frame %d", a.Clip(), a.FrameIndex())
+	}
+}
+
+func TestAnimationFrameDurations(t *testing.T) {
+	a := NewAnimation()
+	a.Clips["clip"] = &AnimationClip{Frames: []AnimationFrame{
+		{Image: &ebiten.Image{}, Duration: 0.5},
+		{Image: &ebiten.Image{}}, // 默认时长
+	}}
+	a.Play("clip")
+
+	a.Update(0.4)
+	if a.FrameIndex() != 0 {
+		t.Errorf("Expected the long first frame to still show, got frame %d", a.FrameIndex())
+	}
+	a.Update(0.1)
+	if a.FrameIndex() != 1 {
+		t.Errorf("Expected the second frame, got %d", a.FrameIndex())
+	}
+	a.Update(DefaultFrameDuration)
+	if a.FrameIndex() != 0 {
+		t.Errorf("Expected the default duration to apply, got frame %d", a.FrameIndex())
+	}
+}
+
+func TestAnimationMissingClip(t *testing.T) {
+	a := NewAnimation()
+	a.Play("missing")
+	a.Update(1)
+	if a.Frame() != nil {
+		t.Error("Expected no frame for a missing clip")
+	}
+}
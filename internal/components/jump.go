@@ -0,0 +1,17 @@
+package components
+
+// Jump holds the parameters that shape a jump, used together with Acceleration.JumpForce
+type Jump struct {
+	CoyoteTime    float64 // Seconds after walking off a ledge during which a jump is still allowed
+	BufferTime    float64 // Seconds a jump press is remembered before landing
+	CutMultiplier float64 // Upward velocity is multiplied by this when jump is released early (0.0 to 1.0)
+}
+
+// NewJump creates a new jump component with default values
+func NewJump() *Jump {
+	return &Jump{
+		CoyoteTime:    0.1, // About 6 frames
+		BufferTime:    0.1, // About 6 frames
+		CutMultiplier: 0.5, // Releasing early halves the remaining upward speed
+	}
+}
@@ -0,0 +1,67 @@
+// internal/config/config.go
+package config
+
+import (
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is the location of the game configuration relative to the working directory
+const DefaultPath = "./config/config.yaml"
+
+// WindowConfig holds the window settings
+type WindowConfig struct {
+	Width  int    `yaml:"width"`
+	Height int    `yaml:"height"`
+	Title  string `yaml:"title"`
+}
+
+// GameConfig holds the general game settings
+type GameConfig struct {
+	FPS int `yaml:"fps"`
+
+	// Language is the locale used for UI strings, e.g. "zh-CN" or "en-US"
+	Language string `yaml:"language"`
+
+	// RandomTickSpeed is how many random block ticks each chunk gets per game tick
+	RandomTickSpeed int `yaml:"random_tick_speed"`
+}
+
+// Config is the root of config.yaml
+type Config struct {
+	Window WindowConfig `yaml:"window"`
+	Game   GameConfig   `yaml:"game"`
+}
+
+// Default returns the configuration used when no config file is available
+func Default() *Config {
+	return &Config{
+		Window: WindowConfig{
+			Width:  800,
+			Height: 600,
+			Title:  "2D Game Engine",
+		},
+		Game: GameConfig{
+			FPS:             60,
+			Language:        "zh-CN",
+			RandomTickSpeed: 3,
+		},
+	}
+}
+
+// Load reads a config file; fields missing from the file keep their default values
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return Default(), err
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadKeepsDefaultsForMissingFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "window:\n  width: 1024\ngame:\n  language: en-US\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Expected config to load, got %v", err)
+	}
+
+	if cfg.Window.Width != 1024 {
+		t.Errorf("Expected window width to be 1024, got %d", cfg.Window.Width)
+	}
+
+	if cfg.Window.Height != 600 {
+		t.Errorf("Expected default window height 600, got %d", cfg.Window.Height)
+	}
+
+	if cfg.Game.Language != "en-US" {
+		t.Errorf("Expected language to be 'en-US', got '%s'", cfg.Game.Language)
+	}
+
+	if cfg.Game.RandomTickSpeed != 3 {
+		t.Errorf("Expected default random tick speed 3, got %d", cfg.Game.RandomTickSpeed)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err == nil {
+		t.Error("Expected an error for a missing config file")
+	}
+
+	if cfg == nil || cfg.Game.Language != Default().Game.Language {
+		t.Error("Expected default config to be returned for a missing file")
+	}
+}
+
+func TestLoadRepositoryConfig(t *testing.T) {
+	cfg, err := Load("../../config/config.yaml")
+	if err != nil {
+		t.Fatalf("Expected repository config to load, got %v", err)
+	}
+
+	if cfg.Window.Width <= 0 || cfg.Window.Height <= 0 {
+		t.Errorf("Expected positive window size, got %dx%d", cfg.Window.Width, cfg.Window.Height)
+	}
+}
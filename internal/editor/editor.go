@@ -0,0 +1,292 @@
+// Package editor implements the level editor: tools that change many cells of the
+// world at once, a clipboard, schematics (regions saved to files) and an undo/redo
+// history. Every block mutation goes through Editor.Apply, which records it as a
+// command in the history.
+package editor
+
+import (
+	"errors"
+
+	"github.com/wubinrui111/2d-game/internal/grid"
+)
+
+const (
+	// DefaultHistoryBytes caps the memory used by the undo history
+	DefaultHistoryBytes = 8 << 20
+
+	// DefaultMaxFlood is the largest number of cells a flood fill may change
+	// outside of a selection
+	DefaultMaxFlood = 16384
+)
+
+// ErrFloodTooLarge is returned by Flood when the area to fill is larger than
+// MaxFlood cells. Nothing is changed.
+var ErrFloodTooLarge = errors.New("editor: area to flood is too large")
+
+// World is the block grid the editor changes. Cells without a block hold "".
+type World interface {
+	Block(x, y int) string
+	SetBlock(x, y int, id string) // "" removes the block
+}
+
+// EntityWorld is a World that also stores block entity data, such as the contents of
+// a chest. Copies and pastes in such a world carry the data along, and the history
+// restores it on undo and redo.
+type EntityWorld interface {
+	World
+	BlockEntity(x, y int) map[string]string // nil if the cell has no block entity
+	SetBlockEntity(x, y int, data map[string]string)
+}
+
+// Edit sets a cell to a block, "" for no block
+type Edit struct {
+	grid.Cell
+	ID     string
+	Entity map[string]string // Block entity data of the new block, nil for none
+}
+
+// Tool is what the pointer does in the editor
+type Tool int
+
+const (
+	ToolPencil Tool = iota // Places or removes single cells
+	ToolSelect             // Drags a rectangle selection
+	ToolLine               // Drags a line of blocks
+	ToolFlood              // Fills the connected area of the same block
+
+	toolCount
+)
+
+// String returns the tool's name, as used in translation keys
+func (t Tool) String() string {
+	switch t {
+	case ToolPencil:
+		return "pencil"
+	case ToolSelect:
+		return "select"
+	case ToolLine:
+		return "line"
+	case ToolFlood:
+		return "flood"
+	}
+	return "unknown"
+}
+
+// Next returns the tool after t, wrapping around to the first
+func (t Tool) Next() Tool {
+	return (t + 1) % toolCount
+}
+
+// Editor changes a world with tools and records every change for undo
+type Editor struct {
+	World   World
+	History *History
+	Tool    Tool
+
+	// Selection is the selected area, empty when nothing is selected
+	Selection Rect
+
+	// Clipboard holds the last copied or cut region, nil before the first copy
+	Clipboard *Region
+
+	// MaxFlood limits flood fills outside of a selection
+	MaxFlood int
+
+	// group collects the changes of a group started with Begin
+	group *Command
+}
+
+// New creates an editor for world with a history of at most maxBytes
+func New(world World, maxBytes int) *Editor {
+	return &Editor{World: world, History: NewHistory(maxBytes), MaxFlood: DefaultMaxFlood}
+}
+
+// Apply sets cells to blocks and records the changes as one command named name.
+// Later edits of the same cell win; edits that change nothing are left out. It returns
+// the command, nil if nothing changed.
+func (e *Editor) Apply(name string, edits []Edit) *Command {
+	cmd := &Command{Name: name}
+	index := make(map[grid.Cell]int, len(edits))
+	entities, hasEntities := e.World.(EntityWorld)
+	for _, edit := range edits {
+		if i, exists := index[edit.Cell]; exists {
+			cmd.Changes[i].After, cmd.Changes[i].AfterEntity = edit.ID, cloneData(edit.Entity)
+			continue
+		}
+		index[edit.Cell] = len(cmd.Changes)
+		change := Change{Cell: edit.Cell, Before: e.World.Block(edit.X, edit.Y), After: edit.ID, AfterEntity: cloneData(edit.Entity)}
+		if hasEntities {
+			change.BeforeEntity = cloneData(entities.BlockEntity(edit.X, edit.Y))
+		}
+		cmd.Changes = append(cmd.Changes, change)
+	}
+
+	// 去掉没有变化的格子
+	changes := cmd.Changes[:0]
+	for _, c := range cmd.Changes {
+		if c.Before != c.After || !sameData(c.BeforeEntity, c.AfterEntity) {
+			changes = append(changes, c)
+		}
+	}
+	cmd.Changes = changes
+	if len(cmd.Changes) == 0 {
+		return nil
+	}
+
+	cmd.redo(e.World)
+	if e.group != nil {
+		e.group.Changes = append(e.group.Changes, cmd.Changes...)
+	} else {
+		e.History.Push(cmd)
+	}
+	return cmd
+}
+
+// Begin starts a group: until End, applied changes are recorded as one command
+// named name, so a pencil stroke is undone at once
+func (e *Editor) Begin(name string) {
+	e.End()
+	e.group = &Command{Name: name}
+}
+
+// End finishes the group started with Begin, if any
+func (e *Editor) End() {
+	if e.group != nil && len(e.group.Changes) > 0 {
+		e.History.Push(e.group)
+	}
+	e.group = nil
+}
+
+// Grouping reports whether a group is open
+func (e *Editor) Grouping() bool {
+	return e.group != nil
+}
+
+// Undo reverts the last command. It returns false if there is nothing to undo.
+func (e *Editor) Undo() bool {
+	e.End()
+	return e.History.Undo(e.World) != nil
+}
+
+// Redo applies the last undone command again. It returns false if there is nothing to redo.
+func (e *Editor) Redo() bool {
+	e.End()
+	return e.History.Redo(e.World) != nil
+}
+
+// Set places id at a cell, "" removes the block there
+func (e *Editor) Set(name string, c grid.Cell, id string) *Command {
+	return e.Apply(name, []Edit{{Cell: c, ID: id}})
+}
+
+// Fill sets every cell of r to id
+func (e *Editor) Fill(r Rect, id string) *Command {
+	var edits []Edit
+	r.Each(func(c grid.Cell) {
+		edits = append(edits, Edit{Cell: c, ID: id})
+	})
+	return e.Apply("fill", edits)
+}
+
+// Replace sets the cells of r holding from to to
+func (e *Editor) Replace(r Rect, from, to string) *Command {
+	var edits []Edit
+	r.Each(func(c grid.Cell) {
+		if e.World.Block(c.X, c.Y) == from {
+			edits = append(edits, Edit{Cell: c, ID: to})
+		}
+	})
+	return e.Apply("replace", edits)
+}
+
+// Line sets the cells on the line from a to b, both included, to id
+func (e *Editor) Line(a, b grid.Cell, id string) *Command {
+	var edits []Edit
+	for _, c := range LineCells(a, b) {
+		edits = append(edits, Edit{Cell: c, ID: id})
+	}
+	return e.Apply("line", edits)
+}
+
+// Flood sets the area of cells connected to start that hold the same block as start
+// to id. The area stays inside the selection if there is one; otherwise it may not
+// be larger than MaxFlood cells.
+func (e *Editor) Flood(start grid.Cell, id string) (*Command, error) {
+	target := e.World.Block(start.X, start.Y)
+	if target == id || (!e.Selection.Empty() && !e.Selection.Contains(start)) {
+		return nil, nil
+	}
+
+	seen := map[grid.Cell]bool{start: true}
+	queue := []grid.Cell{start}
+	var edits []Edit
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		edits = append(edits, Edit{Cell: c, ID: id})
+		if e.Selection.Empty() && len(edits) > e.MaxFlood {
+			return nil, ErrFloodTooLarge
+		}
+
+		for _, n := range c.Neighbors() {
+			if seen[n] || (!e.Selection.Empty() && !e.Selection.Contains(n)) {
+				continue
+			}
+			seen[n] = true
+			if e.World.Block(n.X, n.Y) == target {
+				queue = append(queue, n)
+			}
+		}
+	}
+	return e.Apply("flood", edits), nil
+}
+
+// Copy puts the blocks of r into the clipboard, to be pasted with their top-left corner
+// at the cursor
+func (e *Editor) Copy(r Rect) {
+	if r.Empty() {
+		return
+	}
+	e.Clipboard = e.Capture(r, grid.Cell{X: r.X, Y: r.Y})
+}
+
+// Capture returns the blocks and block entities of r as a region. origin is the
+// world cell that lands on the paste point.
+func (e *Editor) Capture(r Rect, origin grid.Cell) *Region {
+	region := &Region{W: r.W, H: r.H, Origin: grid.Cell{X: origin.X - r.X, Y: origin.Y - r.Y}, Blocks: make([]string, 0, r.W*r.H)}
+	entities, hasEntities := e.World.(EntityWorld)
+	r.Each(func(c grid.Cell) {
+		region.Blocks = append(region.Blocks, e.World.Block(c.X, c.Y))
+		if !hasEntities {
+			return
+		}
+		if data := entities.BlockEntity(c.X, c.Y); data != nil {
+			region.Entities = append(region.Entities, BlockEntity{Cell: grid.Cell{X: c.X - r.X, Y: c.Y - r.Y}, Data: cloneData(data)})
+		}
+	})
+	return region
+}
+
+// Cut copies the blocks of r into the clipboard and removes them
+func (e *Editor) Cut(r Rect) *Command {
+	e.Copy(r)
+	cmd := e.Fill(r, "")
+	if cmd != nil {
+		cmd.Name = "cut"
+	}
+	return cmd
+}
+
+// Paste puts the clipboard's origin at at, empty cells included
+func (e *Editor) Paste(at grid.Cell) *Command {
+	if e.Clipboard == nil {
+		return nil
+	}
+	return e.PasteRegion(e.Clipboard, at, true)
+}
+
+// PasteRegion puts region's origin at at. Empty cells of the region clear the world
+// only if air is true.
+func (e *Editor) PasteRegion(region *Region, at grid.Cell, air bool) *Command {
+	return e.Apply("paste", region.Edits(at, air))
+}
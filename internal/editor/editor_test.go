@@ -0,0 +1,251 @@
+package editor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wubinrui111/2d-game/internal/grid"
+	"github.com/wubinrui111/2d-game/internal/grid/gridtest"
+)
+
+// newGridWorld creates a world from rows of one-letter blocks, '.' for no block
+func newGridWorld(rows ...string) *gridtest.World {
+	w := gridtest.New()
+	w.SetRows(nil, rows...)
+	w.Sets = 0
+	return w
+}
+
+func TestApply(t *testing.T) {
+	w := newGridWorld("ab")
+	e := New(w, DefaultHistoryBytes)
+
+	cmd := e.Apply("test", []Edit{
+		{Cell: grid.Cell{X: 0, Y: 0}, ID: "a"}, // 没有变化
+		{Cell: grid.Cell{X: 1, Y: 0}, ID: "c"},
+		{Cell: grid.Cell{X: 2, Y: 0}, ID: "x"},
+		{Cell: grid.Cell{X: 2, Y: 0}, ID: "d"}, // 后面的修改覆盖前面的
+	})
+	want := []Change{{Cell: grid.Cell{X: 1, Y: 0}, Before: "b", After: "c"}, {Cell: grid.Cell{X: 2, Y: 0}, Before: "", After: "d"}}
+	if cmd == nil || !reflect.DeepEqual(cmd.Changes, want) {
+		t.Fatalf("Expected changes %v, got %+v", want, cmd)
+	}
+	if got := w.Rows(nil, 3, 1); got != "acd" {
+		t.Errorf("Expected acd, got %s", got)
+	}
+	if e.Apply("none", []Edit{{Cell: grid.Cell{X: 0, Y: 0}, ID: "a"}}) != nil || e.History.UndoCount() != 1 {
+		t.Error("Expected edits without changes not to be recorded")
+	}
+}
+
+func TestUndoRedo(t *testing.T) {
+	w := newGridWorld("...", "...")
+	e := New(w, DefaultHistoryBytes)
+
+	e.Fill(Rect{0, 0, 3, 2}, "s")
+	e.Line(grid.Cell{X: 0, Y: 0}, grid.Cell{X: 2, Y: 0}, "d")
+	if got := w.Rows(nil, 3, 2); got != "ddd/sss" {
+		t.Fatalf("Expected ddd/sss, got %s", got)
+	}
+
+	if !e.Undo() || w.Rows(nil, 3, 2) != "sss/sss" {
+		t.Errorf("Expected the line to be undone, got %s", w.Rows(nil, 3, 2))
+	}
+	if !e.Undo() || w.Rows(nil, 3, 2) != ".../..." || e.Undo() {
+		t.Errorf("Expected the fill to be undone, got %s", w.Rows(nil, 3, 2))
+	}
+	if !e.Redo() || w.Rows(nil, 3, 2) != "sss/sss" || e.History.RedoCount() != 1 {
+		t.Errorf("Expected the fill to be redone, got %s", w.Rows(nil, 3, 2))
+	}
+
+	// 新的修改清除可以重做的命令
+	e.Set("place", grid.Cell{X: 1, Y: 1}, "x")
+	if e.Redo() || e.History.RedoCount() != 0 {
+		t.Error("Expected a new command to clear the redo list")
+	}
+	if got := w.Rows(nil, 3, 2); got != "sss/sxs" {
+		t.Errorf("Expected sss/sxs, got %s", got)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	w := newGridWorld("...")
+	e := New(w, DefaultHistoryBytes)
+
+	e.Begin("stroke")
+	e.Set("place", grid.Cell{X: 0, Y: 0}, "a")
+	e.Set("place", grid.Cell{X: 1, Y: 0}, "a")
+	if !e.Grouping() || e.History.UndoCount() != 0 {
+		t.Fatal("Expected the group to be recorded when it ends")
+	}
+	e.End()
+	if e.History.UndoCount() != 1 {
+		t.Fatalf("Expected one command, got %d", e.History.UndoCount())
+	}
+
+	e.Begin("empty")
+	e.End()
+	if e.History.UndoCount() != 1 {
+		t.Error("Expected an empty group not to be recorded")
+	}
+
+	e.Undo()
+	if got := w.Rows(nil, 3, 1); got != "..." {
+		t.Errorf("Expected the stroke to be undone at once, got %s", got)
+	}
+}
+
+func TestHistoryMemoryCap(t *testing.T) {
+	w := newGridWorld()
+	one := (&Command{Name: "place", Changes: make([]Change, 1)}).size()
+	e := New(w, 3*one)
+
+	for x := 0; x < 5; x++ {
+		e.Set("place", grid.Cell{X: x, Y: 0}, "a")
+	}
+	if e.History.UndoCount() != 3 || e.History.Bytes() != 3*one {
+		t.Fatalf("Expected the 3 newest commands in %d bytes, got %d in %d", 3*one, e.History.UndoCount(), e.History.Bytes())
+	}
+	for e.Undo() {
+	}
+	if got := w.Rows(nil, 5, 1); got != "aa..." {
+		t.Errorf("Expected the oldest commands to be forgotten, got %s", got)
+	}
+
+	// 单独超过上限的命令不记录
+	e.Fill(Rect{0, 0, 10, 1}, "b")
+	if e.History.UndoCount() != 0 || e.History.RedoCount() != 0 || e.History.Bytes() != 0 {
+		t.Errorf("Expected a command larger than the cap to be dropped, got %d commands", e.History.UndoCount())
+	}
+}
+
+func TestFillAndReplace(t *testing.T) {
+	w := newGridWorld("aba", "bab")
+	e := New(w, DefaultHistoryBytes)
+
+	e.Replace(Rect{0, 0, 2, 2}, "a", "c")
+	if got := w.Rows(nil, 3, 2); got != "cba/bcb" {
+		t.Errorf("Expected cba/bcb, got %s", got)
+	}
+	e.Replace(Rect{1, 0, 2, 2}, "", "x")
+	if got := w.Rows(nil, 3, 2); got != "cba/bcb" {
+		t.Errorf("Expected no empty cells to replace, got %s", got)
+	}
+	e.Fill(Rect{1, 0, 2, 1}, "")
+	if got := w.Rows(nil, 3, 2); got != "c../bcb" {
+		t.Errorf("Expected c../bcb, got %s", got)
+	}
+	if e.Fill(Rect{}, "a") != nil {
+		t.Error("Expected an empty rectangle to change nothing")
+	}
+}
+
+func TestFlood(t *testing.T) {
+	w := newGridWorld(
+		"aaa.",
+		"a.a.",
+		"aaab",
+		"..b.",
+	)
+	e := New(w, DefaultHistoryBytes)
+
+	if _, err := e.Flood(grid.Cell{X: 0, Y: 0}, "c"); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Rows(nil, 4, 4); got != "ccc./c.c./cccb/..b." {
+		t.Errorf("Expected the connected a cells to change, got %s", got)
+	}
+
+	// 选区限制填充范围
+	e.Selection = Rect{0, 0, 2, 2}
+	if _, err := e.Flood(grid.Cell{X: 1, Y: 1}, "d"); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Rows(nil, 4, 4); got != "ccc./cdc./cccb/..b." {
+		t.Errorf("Expected the flood to stay in the selection, got %s", got)
+	}
+	if cmd, _ := e.Flood(grid.Cell{X: 3, Y: 3}, "d"); cmd != nil {
+		t.Error("Expected no flood from outside the selection")
+	}
+
+	// 没有选区时空白区域无限大
+	e.Selection = Rect{}
+	e.MaxFlood = 100
+	w.Sets = 0
+	if cmd, err := e.Flood(grid.Cell{X: 3, Y: 3}, "d"); err != ErrFloodTooLarge || cmd != nil || w.Sets != 0 {
+		t.Errorf("Expected ErrFloodTooLarge without changes, got %v, %d sets", err, w.Sets)
+	}
+}
+
+func TestClipboard(t *testing.T) {
+	w := newGridWorld("ab..", "c...", "....")
+	e := New(w, DefaultHistoryBytes)
+
+	if e.Paste(grid.Cell{X: 0, Y: 0}) != nil {
+		t.Error("Expected nothing to paste before copying")
+	}
+	e.Copy(Rect{0, 0, 2, 2})
+	want := &Region{W: 2, H: 2, Blocks: []string{"a", "b", "c", ""}}
+	if !reflect.DeepEqual(e.Clipboard, want) {
+		t.Fatalf("Expected clipboard %+v, got %+v", want, e.Clipboard)
+	}
+
+	w.Cells[grid.Cell{X: 3, Y: 2}] = "z"
+	e.Paste(grid.Cell{X: 2, Y: 1})
+	if got := w.Rows(nil, 4, 3); got != "ab../c.ab/..c." {
+		t.Errorf("Expected the region pasted with its empty cell, got %s", got)
+	}
+
+	e.Cut(Rect{0, 0, 1, 2})
+	if got := w.Rows(nil, 4, 3); got != ".b../..ab/..c." {
+		t.Errorf("Expected the cut cells to be removed, got %s", got)
+	}
+	if e.Clipboard.W != 1 || e.Clipboard.At(0, 1) != "c" {
+		t.Errorf("Expected the cut cells in the clipboard, got %+v", e.Clipboard)
+	}
+	e.Undo()
+	if got := w.Rows(nil, 4, 3); got != "ab../c.ab/..c." {
+		t.Errorf("Expected the cut to be undone, got %s", got)
+	}
+}
+
+func TestLineCells(t *testing.T) {
+	tests := []struct {
+		a, b grid.Cell
+		want []grid.Cell
+	}{
+		{grid.Cell{X: 0, Y: 0}, grid.Cell{X: 0, Y: 0}, []grid.Cell{{X: 0, Y: 0}}},
+		{grid.Cell{X: 0, Y: 0}, grid.Cell{X: 3, Y: 0}, []grid.Cell{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}}},
+		{grid.Cell{X: 2, Y: 2}, grid.Cell{X: 0, Y: 0}, []grid.Cell{{X: 2, Y: 2}, {X: 1, Y: 1}, {X: 0, Y: 0}}},
+		{grid.Cell{X: 0, Y: 0}, grid.Cell{X: 4, Y: 2}, []grid.Cell{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 1}, {X: 3, Y: 2}, {X: 4, Y: 2}}},
+		{grid.Cell{X: 0, Y: 0}, grid.Cell{X: -1, Y: 3}, []grid.Cell{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: -1, Y: 2}, {X: -1, Y: 3}}},
+	}
+	for _, tt := range tests {
+		if got := LineCells(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("LineCells(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestRectBetween(t *testing.T) {
+	r := RectBetween(grid.Cell{X: 3, Y: -1}, grid.Cell{X: 1, Y: 2})
+	if r != (Rect{1, -1, 3, 4}) {
+		t.Errorf("Unexpected rectangle %+v", r)
+	}
+	if !r.Contains(grid.Cell{X: 3, Y: 2}) || r.Contains(grid.Cell{X: 4, Y: 2}) || r.Contains(grid.Cell{X: 1, Y: -2}) {
+		t.Error("Expected both corners to be included and nothing beyond")
+	}
+}
+
+func TestToolNext(t *testing.T) {
+	tool := ToolPencil
+	for i := 0; i < int(toolCount); i++ {
+		if tool.String() == "unknown" {
+			t.Errorf("Tool %d has no name", tool)
+		}
+		tool = tool.Next()
+	}
+	if tool != ToolPencil {
+		t.Errorf("Expected the tools to wrap around, got %v", tool)
+	}
+}
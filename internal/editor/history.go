@@ -0,0 +1,157 @@
+package editor
+
+import "github.com/wubinrui111/2d-game/internal/grid"
+
+const (
+	// changeBytes approximates the memory of one recorded change: the cell and two
+	// string headers. Block IDs share their bytes with the block definitions.
+	changeBytes = 48
+
+	// commandBytes approximates the memory of a command without its changes
+	commandBytes = 64
+)
+
+// Change is what a command did to one cell
+type Change struct {
+	grid.Cell
+	Before, After string
+
+	// 方块实体数据，没有时为 nil；撤销和重做时一起恢复
+	BeforeEntity, AfterEntity map[string]string
+}
+
+// Command is a recorded group of changes, undone and redone at once
+type Command struct {
+	Name    string
+	Changes []Change
+}
+
+// size returns the approximate memory a command uses
+func (c *Command) size() int {
+	size := commandBytes + len(c.Name) + len(c.Changes)*changeBytes
+	for _, ch := range c.Changes {
+		size += dataBytes(ch.BeforeEntity) + dataBytes(ch.AfterEntity)
+	}
+	return size
+}
+
+// dataBytes approximates the memory of block entity data
+func dataBytes(data map[string]string) int {
+	if data == nil {
+		return 0
+	}
+	size := commandBytes
+	for k, v := range data {
+		size += len(k) + len(v) + 32
+	}
+	return size
+}
+
+// undo sets the changed cells back to their blocks and block entities before the
+// command, last change first
+func (c *Command) undo(w World) {
+	for i := len(c.Changes) - 1; i >= 0; i-- {
+		ch := c.Changes[i]
+		setCell(w, ch.Cell, ch.Before, ch.BeforeEntity)
+	}
+}
+
+// redo sets the changed cells to their blocks and block entities after the command
+func (c *Command) redo(w World) {
+	for _, ch := range c.Changes {
+		setCell(w, ch.Cell, ch.After, ch.AfterEntity)
+	}
+}
+
+// setCell places a block and, in a world with block entities, its entity data
+func setCell(w World, c grid.Cell, id string, entity map[string]string) {
+	w.SetBlock(c.X, c.Y, id)
+	if entities, ok := w.(EntityWorld); ok {
+		entities.SetBlockEntity(c.X, c.Y, cloneData(entity))
+	}
+}
+
+// History is the list of commands that can be undone and redone. When the commands
+// use more than MaxBytes, the oldest are forgotten.
+type History struct {
+	MaxBytes int
+
+	undo  []*Command
+	redo  []*Command
+	bytes int
+}
+
+// NewHistory creates an empty history using at most maxBytes
+func NewHistory(maxBytes int) *History {
+	return &History{MaxBytes: maxBytes}
+}
+
+// Push records a command that has been applied. The commands undone before are
+// forgotten, as they can no longer be redone.
+func (h *History) Push(cmd *Command) {
+	for _, c := range h.redo {
+		h.bytes -= c.size()
+	}
+	h.redo = nil
+
+	h.undo = append(h.undo, cmd)
+	h.bytes += cmd.size()
+	h.trim()
+}
+
+// trim forgets the oldest commands until the history fits in MaxBytes. A command
+// larger than MaxBytes on its own is not kept either.
+func (h *History) trim() {
+	drop := 0
+	for drop < len(h.undo) && h.bytes > h.MaxBytes {
+		h.bytes -= h.undo[drop].size()
+		drop++
+	}
+	if drop > 0 {
+		h.undo = append([]*Command(nil), h.undo[drop:]...)
+	}
+}
+
+// Undo reverts the last command on w and returns it, nil if there is none
+func (h *History) Undo(w World) *Command {
+	if len(h.undo) == 0 {
+		return nil
+	}
+	cmd := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+	cmd.undo(w)
+	h.redo = append(h.redo, cmd)
+	return cmd
+}
+
+// Redo applies the last undone command on w again and returns it, nil if there is none
+func (h *History) Redo(w World) *Command {
+	if len(h.redo) == 0 {
+		return nil
+	}
+	cmd := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+	cmd.redo(w)
+	h.undo = append(h.undo, cmd)
+	return cmd
+}
+
+// UndoCount returns the number of commands that can be undone
+func (h *History) UndoCount() int {
+	return len(h.undo)
+}
+
+// RedoCount returns the number of commands that can be redone
+func (h *History) RedoCount() int {
+	return len(h.redo)
+}
+
+// Bytes returns the approximate memory the history uses
+func (h *History) Bytes() int {
+	return h.bytes
+}
+
+// Clear forgets all commands
+func (h *History) Clear() {
+	h.undo, h.redo, h.bytes = nil, nil, 0
+}
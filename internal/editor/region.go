@@ -0,0 +1,117 @@
+package editor
+
+import "github.com/wubinrui111/2d-game/internal/grid"
+
+// Region is a rectangle of blocks: the clipboard, or a schematic saved to a file
+type Region struct {
+	Name   string // Schematic name, empty for copies
+	W, H   int
+	Origin grid.Cell // Cell of the region placed at the paste point, relative to its top-left corner
+	Blocks []string  // Row by row, "" for no block
+
+	// Entities holds the block entity data of the region's cells
+	Entities []BlockEntity
+}
+
+// BlockEntity is the data of a block entity at a cell of a region
+type BlockEntity struct {
+	grid.Cell // Relative to the region's top-left corner
+	Data      map[string]string
+}
+
+// At returns the block at x, y of the region
+func (r *Region) At(x, y int) string {
+	return r.Blocks[y*r.W+x]
+}
+
+// Bounds returns the world cells the region covers when its origin is at at
+func (r *Region) Bounds(at grid.Cell) Rect {
+	return Rect{X: at.X - r.Origin.X, Y: at.Y - r.Origin.Y, W: r.W, H: r.H}
+}
+
+// Edits returns the edits that paste the region with its origin at at. Empty cells
+// clear the world only if air is true.
+func (r *Region) Edits(at grid.Cell, air bool) []Edit {
+	bounds := r.Bounds(at)
+	entities := make(map[grid.Cell]map[string]string, len(r.Entities))
+	for _, be := range r.Entities {
+		entities[be.Cell] = be.Data
+	}
+	edits := make([]Edit, 0, len(r.Blocks))
+	for y := 0; y < r.H; y++ {
+		for x := 0; x < r.W; x++ {
+			id := r.At(x, y)
+			if id != "" || air {
+				edits = append(edits, Edit{Cell: grid.Cell{X: bounds.X + x, Y: bounds.Y + y}, ID: id, Entity: entities[grid.Cell{X: x, Y: y}]})
+			}
+		}
+	}
+	return edits
+}
+
+// Rotate returns the region turned clockwise by quarter turns; negative turns rotate
+// counterclockwise
+func (r *Region) Rotate(turns int) *Region {
+	turns = ((turns % 4) + 4) % 4
+	out := r
+	for i := 0; i < turns; i++ {
+		// 顺时针旋转 90 度：(x, y) -> (h-1-y, x)
+		h := out.H
+		out = out.transform(out.H, out.W, func(c grid.Cell) grid.Cell { return grid.Cell{X: h - 1 - c.Y, Y: c.X} })
+	}
+	if out == r {
+		out = r.transform(r.W, r.H, func(c grid.Cell) grid.Cell { return c })
+	}
+	return out
+}
+
+// Mirror returns the region flipped left to right
+func (r *Region) Mirror() *Region {
+	return r.transform(r.W, r.H, func(c grid.Cell) grid.Cell { return grid.Cell{X: r.W - 1 - c.X, Y: c.Y} })
+}
+
+// Flip returns the region flipped upside down
+func (r *Region) Flip() *Region {
+	return r.transform(r.W, r.H, func(c grid.Cell) grid.Cell { return grid.Cell{X: c.X, Y: r.H - 1 - c.Y} })
+}
+
+// transform returns a w x h copy of the region with every cell, the origin and the
+// block entities moved by f
+func (r *Region) transform(w, h int, f func(grid.Cell) grid.Cell) *Region {
+	out := &Region{Name: r.Name, W: w, H: h, Origin: f(r.Origin), Blocks: make([]string, w*h)}
+	for y := 0; y < r.H; y++ {
+		for x := 0; x < r.W; x++ {
+			c := f(grid.Cell{X: x, Y: y})
+			out.Blocks[c.Y*w+c.X] = r.At(x, y)
+		}
+	}
+	for _, be := range r.Entities {
+		out.Entities = append(out.Entities, BlockEntity{Cell: f(be.Cell), Data: cloneData(be.Data)})
+	}
+	return out
+}
+
+// sameData reports whether two sets of block entity data hold the same values
+func sameData(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, exists := b[k]; !exists || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+// cloneData copies block entity data, so regions and worlds do not share maps
+func cloneData(data map[string]string) map[string]string {
+	if data == nil {
+		return nil
+	}
+	out := make(map[string]string, len(data))
+	for k, v := range data {
+		out[k] = v
+	}
+	return out
+}
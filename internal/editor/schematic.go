@@ -0,0 +1,114 @@
+package editor
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/wubinrui111/2d-game/internal/grid"
+)
+
+const (
+	// SchematicDir is the asset directory of schematics
+	SchematicDir = "schematics"
+
+	// schematicVersion is the version of the schematic format written by EncodeSchematic
+	schematicVersion = 1
+)
+
+// schematicJSON is a region as written in a schematic file. Blocks are indexes into
+// the palette, row by row; index 0 is always no block.
+type schematicJSON struct {
+	Version  int               `json:"version"`
+	Name     string            `json:"name,omitempty"`
+	Width    int               `json:"width"`
+	Height   int               `json:"height"`
+	Origin   [2]int            `json:"origin"`
+	Palette  []string          `json:"palette"`
+	Blocks   []int             `json:"blocks"`
+	Entities []blockEntityJSON `json:"entities,omitempty"`
+}
+
+// blockEntityJSON is a block entity as written in a schematic file
+type blockEntityJSON struct {
+	X    int               `json:"x"`
+	Y    int               `json:"y"`
+	Data map[string]string `json:"data"`
+}
+
+// DecodeSchematic reads a region from a schematic file
+func DecodeSchematic(data []byte) (*Region, error) {
+	var file schematicJSON
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if file.Version != schematicVersion {
+		return nil, fmt.Errorf("editor: unsupported schematic version %d", file.Version)
+	}
+	if file.Width <= 0 || file.Height <= 0 {
+		return nil, fmt.Errorf("editor: schematic needs a positive size, got %dx%d", file.Width, file.Height)
+	}
+	if len(file.Blocks) != file.Width*file.Height {
+		return nil, fmt.Errorf("editor: schematic has %d blocks, expected %dx%d", len(file.Blocks), file.Width, file.Height)
+	}
+	if len(file.Palette) == 0 || file.Palette[0] != "" {
+		return nil, fmt.Errorf("editor: the first palette entry of a schematic must be \"\"")
+	}
+
+	region := &Region{
+		Name:   file.Name,
+		W:      file.Width,
+		H:      file.Height,
+		Origin: grid.Cell{X: file.Origin[0], Y: file.Origin[1]},
+		Blocks: make([]string, len(file.Blocks)),
+	}
+	for i, index := range file.Blocks {
+		if index < 0 || index >= len(file.Palette) {
+			return nil, fmt.Errorf("editor: schematic block %d is not in the palette", index)
+		}
+		region.Blocks[i] = file.Palette[index]
+	}
+	for _, be := range file.Entities {
+		if be.X < 0 || be.X >= region.W || be.Y < 0 || be.Y >= region.H {
+			return nil, fmt.Errorf("editor: schematic block entity at (%d, %d) is outside of the schematic", be.X, be.Y)
+		}
+		region.Entities = append(region.Entities, BlockEntity{Cell: grid.Cell{X: be.X, Y: be.Y}, Data: be.Data})
+	}
+	return region, nil
+}
+
+// EncodeSchematic writes a region as a schematic file
+func EncodeSchematic(region *Region) ([]byte, error) {
+	file := schematicJSON{
+		Version: schematicVersion,
+		Name:    region.Name,
+		Width:   region.W,
+		Height:  region.H,
+		Origin:  [2]int{region.Origin.X, region.Origin.Y},
+		Palette: []string{""},
+		Blocks:  make([]int, len(region.Blocks)),
+	}
+
+	// 调色板按方块 ID 排序，文件内容与方块顺序无关
+	indexes := map[string]int{"": 0}
+	var ids []string
+	for _, id := range region.Blocks {
+		if _, exists := indexes[id]; !exists {
+			indexes[id] = 0
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		indexes[id] = len(file.Palette)
+		file.Palette = append(file.Palette, id)
+	}
+	for i, id := range region.Blocks {
+		file.Blocks[i] = indexes[id]
+	}
+
+	for _, be := range region.Entities {
+		file.Entities = append(file.Entities, blockEntityJSON{X: be.X, Y: be.Y, Data: be.Data})
+	}
+	return json.MarshalIndent(file, "", "  ")
+}
@@ -0,0 +1,198 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/wubinrui111/2d-game/internal/grid"
+	"github.com/wubinrui111/2d-game/internal/grid/gridtest"
+)
+
+// entityWorld is a test world that also stores block entity data
+type entityWorld struct {
+	*gridtest.World
+	entities map[grid.Cell]map[string]string
+}
+
+func (w *entityWorld) BlockEntity(x, y int) map[string]string {
+	return w.entities[grid.Cell{X: x, Y: y}]
+}
+
+// SetBlock removes the block entity of the cell with its block, like the game does
+func (w *entityWorld) SetBlock(x, y int, id string) {
+	delete(w.entities, grid.Cell{X: x, Y: y})
+	w.World.SetBlock(x, y, id)
+}
+
+func (w *entityWorld) SetBlockEntity(x, y int, data map[string]string) {
+	if data == nil {
+		delete(w.entities, grid.Cell{X: x, Y: y})
+	} else {
+		w.entities[grid.Cell{X: x, Y: y}] = data
+	}
+}
+
+// testRegion returns a 3x2 region with an origin at its bottom center and a block entity
+func testRegion() *Region {
+	return &Region{
+		Name:     "hut",
+		W:        3,
+		H:        2,
+		Origin:   grid.Cell{X: 1, Y: 1},
+		Blocks:   []string{"a", "b", "", "c", "", "d"},
+		Entities: []BlockEntity{{Cell: grid.Cell{X: 2, Y: 1}, Data: map[string]string{"items": "3"}}},
+	}
+}
+
+func TestSchematicRoundTrip(t *testing.T) {
+	region := testRegion()
+	data, err := EncodeSchematic(region)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeSchematic(data)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, data)
+	}
+	if !reflect.DeepEqual(decoded, region) {
+		t.Errorf("Round trip changed the region:\n%s", data)
+	}
+}
+
+func TestDecodeSchematicErrors(t *testing.T) {
+	tests := map[string]string{
+		"invalid json":   `{`,
+		"version":        `{"version": 2, "width": 1, "height": 1, "palette": [""], "blocks": [0]}`,
+		"no size":        `{"version": 1, "palette": [""], "blocks": []}`,
+		"short blocks":   `{"version": 1, "width": 2, "height": 1, "palette": [""], "blocks": [0]}`,
+		"palette":        `{"version": 1, "width": 1, "height": 1, "palette": ["stone"], "blocks": [0]}`,
+		"bad index":      `{"version": 1, "width": 1, "height": 1, "palette": [""], "blocks": [1]}`,
+		"outside entity": `{"version": 1, "width": 1, "height": 1, "palette": [""], "blocks": [0], "entities": [{"x": 1, "y": 0}]}`,
+	}
+	for name, data := range tests {
+		if _, err := DecodeSchematic([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestRegionTransforms(t *testing.T) {
+	region := testRegion()
+
+	rotated := region.Rotate(1)
+	if rotated.W != 2 || rotated.H != 3 || !reflect.DeepEqual(rotated.Blocks, []string{"c", "a", "", "b", "d", ""}) {
+		t.Errorf("Unexpected rotation %+v", rotated)
+	}
+	if rotated.Origin != (grid.Cell{X: 0, Y: 1}) || rotated.Entities[0].Cell != (grid.Cell{X: 0, Y: 2}) {
+		t.Errorf("Expected the origin and entities to turn with the blocks, got %+v", rotated)
+	}
+	if back := region.Rotate(-3); !reflect.DeepEqual(back, rotated) {
+		t.Errorf("Expected -3 turns to equal 1 turn, got %+v", back)
+	}
+	if full := region.Rotate(4); !reflect.DeepEqual(full, region) || full == region {
+		t.Errorf("Expected 4 turns to return an equal copy, got %+v", full)
+	}
+
+	mirrored := region.Mirror()
+	if !reflect.DeepEqual(mirrored.Blocks, []string{"", "b", "a", "d", "", "c"}) || mirrored.Origin != (grid.Cell{X: 1, Y: 1}) || mirrored.Entities[0].Cell != (grid.Cell{X: 0, Y: 1}) {
+		t.Errorf("Unexpected mirror %+v", mirrored)
+	}
+	flipped := region.Flip()
+	if !reflect.DeepEqual(flipped.Blocks, []string{"c", "", "d", "a", "b", ""}) || flipped.Origin != (grid.Cell{X: 1, Y: 0}) {
+		t.Errorf("Unexpected flip %+v", flipped)
+	}
+
+	// 变换不修改原区域的数据
+	mirrored.Entities[0].Data["items"] = "0"
+	if region.Entities[0].Data["items"] != "3" {
+		t.Error("Expected transforms to copy block entity data")
+	}
+}
+
+func TestCaptureAndPasteRegion(t *testing.T) {
+	w := &entityWorld{World: newGridWorld("....", ".ab.", ".cz."), entities: map[grid.Cell]map[string]string{{X: 2, Y: 2}: {"items": "3"}}}
+	e := New(w, DefaultHistoryBytes)
+
+	region := e.Capture(Rect{1, 1, 2, 2}, grid.Cell{X: 1, Y: 2})
+	if region.Origin != (grid.Cell{X: 0, Y: 1}) || !reflect.DeepEqual(region.Blocks, []string{"a", "b", "c", "z"}) {
+		t.Fatalf("Unexpected capture %+v", region)
+	}
+	if len(region.Entities) != 1 || region.Entities[0].Cell != (grid.Cell{X: 1, Y: 1}) {
+		t.Fatalf("Expected the block entity in the region, got %+v", region.Entities)
+	}
+
+	// 不包括空白时保留原有方块
+	region.Blocks[1] = ""
+	e.PasteRegion(region, grid.Cell{X: 0, Y: 1}, false)
+	if got := w.Rows(nil, 4, 3); got != "a.../czb./.cz." {
+		t.Errorf("Expected the region above and left of the paste point, got %s", got)
+	}
+	if data := w.BlockEntity(1, 1); data["items"] != "3" {
+		t.Errorf("Expected the block entity to be pasted, got %v", w.entities)
+	}
+
+	e.Undo()
+	if got := w.Rows(nil, 4, 3); got != "..../.ab./.cz." {
+		t.Errorf("Expected the paste to be undone, got %s", got)
+	}
+}
+
+func TestUndoRestoresBlockEntities(t *testing.T) {
+	w := &entityWorld{World: newGridWorld(".c.", "###"), entities: map[grid.Cell]map[string]string{{X: 1, Y: 0}: {"items": "3"}}}
+	e := New(w, DefaultHistoryBytes)
+
+	e.Cut(Rect{0, 0, 3, 1})
+	if w.Block(1, 0) != "" || w.BlockEntity(1, 0) != nil {
+		t.Fatalf("Expected the cut to remove the block and its data, got %q %v", w.Block(1, 0), w.entities)
+	}
+	e.Undo()
+	if data := w.BlockEntity(1, 0); w.Block(1, 0) != "c" || data["items"] != "3" {
+		t.Fatalf("Expected undo to restore the block and its data, got %q %v", w.Block(1, 0), data)
+	}
+	e.Redo()
+	if w.BlockEntity(1, 0) != nil {
+		t.Errorf("Expected redo to remove the data again, got %v", w.entities)
+	}
+
+	// 粘贴带数据的方块后撤销和重做
+	e.Paste(grid.Cell{X: 0, Y: 2})
+	if data := w.BlockEntity(1, 2); data["items"] != "3" {
+		t.Fatalf("Expected the pasted block entity, got %v", w.entities)
+	}
+	e.Undo()
+	if w.BlockEntity(1, 2) != nil {
+		t.Errorf("Expected undoing the paste to remove its data, got %v", w.entities)
+	}
+	e.Redo()
+	if data := w.BlockEntity(1, 2); data["items"] != "3" {
+		t.Errorf("Expected redoing the paste to bring the data back, got %v", w.entities)
+	}
+
+	// 只改变数据也是一次修改
+	w.BlockEntity(1, 2)["items"] = "5"
+	if cmd := e.Set("pencil", grid.Cell{X: 1, Y: 2}, "c"); cmd == nil || w.BlockEntity(1, 2) != nil {
+		t.Errorf("Expected placing the same block without data to clear it, got %v", w.entities)
+	}
+	e.Undo()
+	if data := w.BlockEntity(1, 2); data["items"] != "5" {
+		t.Errorf("Expected undo to restore the changed data, got %v", data)
+	}
+}
+
+func TestDefaultSchematics(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "..", "assets", SchematicDir, "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Expected schematics in the assets, got %v (%v)", files, err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := DecodeSchematic(data); err != nil {
+			t.Errorf("%s: %v", file, err)
+		}
+	}
+}
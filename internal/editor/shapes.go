@@ -0,0 +1,70 @@
+package editor
+
+import "github.com/wubinrui111/2d-game/internal/grid"
+
+// Rect is a rectangle of cells. Rects with no width or height are empty.
+type Rect struct {
+	X, Y, W, H int
+}
+
+// RectBetween returns the rectangle with corners a and b, both included
+func RectBetween(a, b grid.Cell) Rect {
+	return Rect{X: min(a.X, b.X), Y: min(a.Y, b.Y), W: abs(a.X-b.X) + 1, H: abs(a.Y-b.Y) + 1}
+}
+
+// Empty reports whether the rectangle holds no cells
+func (r Rect) Empty() bool {
+	return r.W <= 0 || r.H <= 0
+}
+
+// Contains reports whether c is in the rectangle
+func (r Rect) Contains(c grid.Cell) bool {
+	return c.X >= r.X && c.X < r.X+r.W && c.Y >= r.Y && c.Y < r.Y+r.H
+}
+
+// Each calls f for every cell of the rectangle, row by row
+func (r Rect) Each(f func(grid.Cell)) {
+	for y := r.Y; y < r.Y+r.H; y++ {
+		for x := r.X; x < r.X+r.W; x++ {
+			f(grid.Cell{X: x, Y: y})
+		}
+	}
+}
+
+// LineCells returns the cells on the line from a to b, both included (Bresenham)
+func LineCells(a, b grid.Cell) []grid.Cell {
+	dx, dy := abs(b.X-a.X), -abs(b.Y-a.Y)
+	sx, sy := 1, 1
+	if a.X > b.X {
+		sx = -1
+	}
+	if a.Y > b.Y {
+		sy = -1
+	}
+
+	cells := make([]grid.Cell, 0, max(dx, -dy)+1)
+	err := dx + dy
+	for c := a; ; {
+		cells = append(cells, c)
+		if c == b {
+			return cells
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			c.X += sx
+		}
+		if e2 <= dx {
+			err += dx
+			c.Y += sy
+		}
+	}
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
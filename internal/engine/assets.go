@@ -0,0 +1,55 @@
+package game
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/wubinrui111/2d-game/internal/assets"
+	"github.com/wubinrui111/2d-game/internal/fonts"
+	"github.com/wubinrui111/2d-game/internal/i18n"
+)
+
+// loadTranslations loads every <locale>.json catalog under locales/ through the asset manager
+func loadTranslations() error {
+	loaded := 0
+	for _, key := range assets.Default().List("locales") {
+		if path.Ext(key) != ".json" {
+			continue
+		}
+		data, err := assets.Default().Data(key)
+		if err != nil {
+			return err
+		}
+		language := strings.TrimSuffix(path.Base(key), path.Ext(key))
+		if err := i18n.Default().LoadCatalog(language, data); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		loaded++
+	}
+	if loaded == 0 {
+		return fmt.Errorf("i18n: no catalogs found in locales")
+	}
+	return nil
+}
+
+// loadFonts loads the .ttf, .otf and .ttc files under fonts/ through the asset manager.
+// Fonts are optional: without any the built-in bitmap font is used.
+func loadFonts() error {
+	for _, key := range assets.Default().List("fonts") {
+		switch strings.ToLower(path.Ext(key)) {
+		case ".ttf", ".otf", ".ttc":
+		default:
+			continue
+		}
+		data, err := assets.Default().Data(key)
+		if err != nil {
+			return err
+		}
+		family := strings.TrimSuffix(path.Base(key), path.Ext(key))
+		if err := fonts.Default().LoadBytes(family, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,7 +2,14 @@
 package game
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/wubinrui111/2d-game/internal/assets"
+	"github.com/wubinrui111/2d-game/internal/config"
+	"github.com/wubinrui111/2d-game/internal/input"
 	"github.com/wubinrui111/2d-game/internal/scenes"
+	"github.com/wubinrui111/2d-game/internal/settings"
 	"github.com/wubinrui111/2d-game/internal/managers"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -10,10 +17,51 @@ import (
 
 type Game struct {
 	sceneManager *managers.SceneManager
+
+	// 回放时的世界和录像，回放结束后检查世界状态是否一致
+	world  *scenes.MainScene
+	replay *input.Recording
+}
+
+// Options are the command line options of the game
+type Options struct {
+	// RecordPath starts a new world right away and writes its input to this replay file on exit
+	RecordPath string
+
+	// ReplayPath plays a replay file back in the world it was recorded in
+	ReplayPath string
+
+	// Dev watches the asset files and reloads the ones that change
+	Dev bool
 }
 
 func (g *Game) Update() error {
-	return g.sceneManager.Update()
+	// 每帧先读取一次输入（或回放录像中的一帧），场景通过动作读取
+	input.Default().Update()
+	// 开发模式下检查资源文件是否变化
+	assets.Default().Update()
+	if err := g.sceneManager.Update(); err != nil {
+		return err
+	}
+
+	if g.replay != nil && !input.Default().Playing() {
+		g.checkReplay()
+	}
+	return nil
+}
+
+// checkReplay compares the world with the hash stored in the replay once it has been played
+func (g *Game) checkReplay() {
+	hash := g.world.WorldHash()
+	switch {
+	case g.replay.Hash == 0:
+		fmt.Printf("Replay finished after %d ticks, world hash %016x\n", len(g.replay.Ticks), hash)
+	case g.replay.Hash == hash:
+		fmt.Printf("Replay finished after %d ticks, world hash %016x matches\n", len(g.replay.Ticks), hash)
+	default:
+		fmt.Printf("Replay diverged: world hash %016x, recorded %016x\n", hash, g.replay.Hash)
+	}
+	g.replay = nil
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
@@ -24,18 +72,80 @@ func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
 	return 800, 600
 }
 
-func Run() error {
+func Run(opts Options) error {
+	assets.Default().Watch = opts.Dev
+
+	// 读取配置，失败时使用默认配置
+	cfg, err := config.Load(config.DefaultPath)
+	if err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+	}
+
+	// 加载界面文本
+	if err := loadTranslations(); err != nil {
+		fmt.Printf("Failed to load translations: %v\n", err)
+	}
+
+	// 加载字体，失败时使用内置位图字体
+	if err := loadFonts(); err != nil {
+		fmt.Printf("Failed to load fonts: %v\n", err)
+	}
+
+	// 读取用户偏好设置（默认值来自配置文件），并应用窗口、语言和按键等设置
+	store := settings.Default()
+	store.Defaults = settings.Defaults(cfg.Window.Width, cfg.Window.Height, cfg.Game.Language)
+	if err := store.Load(); err != nil {
+		fmt.Printf("Failed to load settings: %v\n", err)
+	}
+	if err := scenes.ApplySettings(store.Current()); err != nil {
+		fmt.Printf("Failed to apply settings: %v\n", err)
+	}
+	scenes.RandomTickSpeed = cfg.Game.RandomTickSpeed
+
 	game := &Game{
 		sceneManager: managers.NewSceneManager(),
 	}
 
-	game.sceneManager.SetScene(scenes.NewMainScene())
-	ebiten.SetWindowSize(800, 600)
+	// 录制和回放直接进入世界，跳过标题画面
+	actions := input.Default()
+	switch {
+	case opts.ReplayPath != "":
+		rec, err := input.LoadRecording(opts.ReplayPath)
+		if err != nil {
+			return err
+		}
+		game.world = scenes.NewMainSceneWithSeed(rec.Seed)
+		game.replay = rec
+		game.sceneManager.SetScene(game.world)
+		actions.Play(rec)
+	case opts.RecordPath != "":
+		game.world = scenes.NewMainScene()
+		game.sceneManager.SetScene(game.world)
+		actions.StartRecording(game.world.Seed())
+	default:
+		game.sceneManager.SetScene(scenes.NewTitleScene())
+	}
+
+	ebiten.SetTPS(int(scenes.TickRate))
 	ebiten.SetWindowTitle("2D Game Engine")
 
+	// 列出找不到的资源，以便补齐资源目录
+	if missing := assets.Default().Missing(); len(missing) > 0 {
+		fmt.Printf("Missing assets: %s\n", strings.Join(missing, ", "))
+	}
+
 	if err := ebiten.RunGame(game); err != nil {
 		return err
 	}
 
+	if opts.RecordPath != "" {
+		rec := actions.StopRecording()
+		rec.Hash = game.world.WorldHash()
+		if err := rec.Save(opts.RecordPath); err != nil {
+			return err
+		}
+		fmt.Printf("Recorded %d ticks to %s\n", len(rec.Ticks), opts.RecordPath)
+	}
+
 	return nil
 }
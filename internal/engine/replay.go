@@ -0,0 +1,41 @@
+package game
+
+import (
+	"errors"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/wubinrui111/2d-game/internal/input"
+	"github.com/wubinrui111/2d-game/internal/managers"
+	"github.com/wubinrui111/2d-game/internal/scenes"
+)
+
+// Replay plays a recording without a window, through the same Update as the game,
+// in a world created from the recording's seed. It returns the world state hash
+// after the last tick, to compare with Recording.Hash or with another run.
+func Replay(rec *input.Recording) (uint64, error) {
+	return replayIn(scenes.NewMainSceneWithSeed(rec.Seed), rec)
+}
+
+// replayIn plays a recording in world, which tests may change before the first tick
+func replayIn(world *scenes.MainScene, rec *input.Recording) (uint64, error) {
+	game := &Game{
+		sceneManager: managers.NewSceneManager(),
+		world:        world,
+	}
+	game.sceneManager.SetScene(world)
+
+	actions := input.Default()
+	actions.Play(rec)
+	defer actions.StopPlayback()
+
+	for actions.Playing() {
+		if err := game.Update(); err != nil {
+			// 录像中退出了游戏
+			if errors.Is(err, ebiten.Termination) {
+				break
+			}
+			return 0, err
+		}
+	}
+	return world.WorldHash(), nil
+}
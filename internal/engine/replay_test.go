@@ -0,0 +1,139 @@
+package game
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/wubinrui111/2d-game/internal/input"
+	"github.com/wubinrui111/2d-game/internal/scenes"
+)
+
+// walkAndDig walks right, jumps, breaks the block under the cursor and places it again
+func walkAndDig(seed int64) *input.Recording {
+	rec := input.NewRecording(seed)
+	for i := 0; i < 300; i++ {
+		state := input.State{Values: map[input.Action]float64{}, CursorX: 420, CursorY: 340}
+		switch {
+		case i < 60:
+			state.Values[input.ActionMoveRight] = 1
+		case i < 70:
+			state.Values[input.ActionJump] = 1
+		case i < 120:
+			state.Values[input.ActionAttack] = 1
+		case i < 130:
+			state.Values[input.ActionUseItem] = 1
+		case i < 140:
+			state.Values[input.ActionNextSlot] = 1
+		}
+		state.UI.CursorX, state.UI.CursorY = state.CursorX, state.CursorY
+		rec.Ticks = append(rec.Ticks, state)
+	}
+	return rec
+}
+
+// sandAndWater creates a world with a mound of sand under the spawn point and a water
+// source on top of it. Digging into the mound makes the sand above fall, and the
+// water flows down its sides.
+func sandAndWater(seed int64) *scenes.MainScene {
+	world := scenes.NewMainSceneWithSeed(seed)
+	spawn := world.Level().Spawn
+	x, y := int(spawn.X), int(spawn.Y)
+	for cy := y + 3; cy < y+20; cy++ {
+		for cx := x - 15; cx <= x+15; cx++ {
+			world.SetBlock(cx, cy, "sand")
+		}
+	}
+	world.SetBlock(x-4, y+2, "water")
+	return world
+}
+
+// digAndWait waits for the player to land, digs under the cursor and waits for the
+// sand to fall and the water to flow
+func digAndWait(seed int64) *input.Recording {
+	rec := input.NewRecording(seed)
+	for i := 0; i < 400; i++ {
+		state := input.State{Values: map[input.Action]float64{}, CursorX: 500, CursorY: 400}
+		if i >= 120 && i < 200 {
+			state.Values[input.ActionAttack] = 1
+		}
+		state.UI.CursorX, state.UI.CursorY = state.CursorX, state.CursorY
+		rec.Ticks = append(rec.Ticks, state)
+	}
+	return rec
+}
+
+func TestReplayIsDeterministic(t *testing.T) {
+	first, err := Replay(walkAndDig(1))
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	second, err := Replay(walkAndDig(1))
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if first != second {
+		t.Errorf("Expected the same world hash for the same input, got %016x and %016x", first, second)
+	}
+}
+
+func TestReplayWithFluidsAndFallingBlocks(t *testing.T) {
+	rec := digAndWait(2)
+	first, err := replayIn(sandAndWater(rec.Seed), rec)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	second, err := replayIn(sandAndWater(rec.Seed), rec)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if first != second {
+		t.Errorf("Expected the same world hash with flowing water and falling sand, got %016x and %016x", first, second)
+	}
+
+	// 少等一刻，下落的沙子和流动的水停在不同的状态
+	short := digAndWait(2)
+	short.Ticks = short.Ticks[:len(short.Ticks)-1]
+	hash, _ := replayIn(sandAndWater(short.Seed), short)
+	if hash == first {
+		t.Error("Expected one tick less to change the world hash")
+	}
+}
+
+func TestReplayDependsOnInput(t *testing.T) {
+	rec := walkAndDig(1)
+	expected, _ := Replay(rec)
+
+	// 少走一步，玩家位置不同
+	changed := walkAndDig(1)
+	changed.Ticks[0].Values = nil
+	hash, _ := Replay(changed)
+	if hash == expected {
+		t.Error("Expected different input to change the world hash")
+	}
+}
+
+func TestReplayFromFileMatchesRecordedHash(t *testing.T) {
+	rec := walkAndDig(3)
+	hash, err := Replay(rec)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	rec.Hash = hash
+
+	path := filepath.Join(t.TempDir(), "walk.replay")
+	if err := rec.Save(path); err != nil {
+		t.Fatalf("Failed to save replay: %v", err)
+	}
+	loaded, err := input.LoadRecording(path)
+	if err != nil {
+		t.Fatalf("Failed to load replay: %v", err)
+	}
+
+	replayed, err := Replay(loaded)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if replayed != loaded.Hash {
+		t.Errorf("Expected world hash %016x, got %016x", loaded.Hash, replayed)
+	}
+}
@@ -0,0 +1,56 @@
+package entities
+
+import (
+	"image/color"
+
+	"github.com/wubinrui111/2d-game/internal/components"
+)
+
+// FallingBlockLifetime is how long in seconds a falling block falls before it is
+// removed, so blocks falling where the world has no floor do not fall forever
+const FallingBlockLifetime = 10.0
+
+// FallingBlock is a block that lost its support and falls, with the same gravity and
+// block collisions as an item drop, until it lands back on the grid
+type FallingBlock struct {
+	components.Position
+	components.Box
+	BlockID  string              // ID of the block that falls
+	Color    color.RGBA          // Color of the block for rendering
+	Velocity components.Position // Velocity for movement
+	Gravity  *components.Gravity // Gravity component
+	Life     float64             // Seconds the block has been falling
+	Landed   bool                // Whether the block hit something below it
+}
+
+// NewFallingBlock creates a falling block of the given size at the top left corner x, y
+func NewFallingBlock(x, y, size float64, blockID string, c color.RGBA) *FallingBlock {
+	return &FallingBlock{
+		Position: components.Position{X: x, Y: y},
+		Box:      components.Box{X: x, Y: y, Width: size, Height: size},
+		BlockID:  blockID,
+		Color:    c,
+		Gravity: &components.Gravity{
+			Enabled: true,
+			Force:   GravityForce,
+		},
+	}
+}
+
+// Update moves the falling block down and checks whether it landed on one of blocks
+func (fb *FallingBlock) Update(deltaTime float64, blocks []components.BoxHolder) {
+	fb.Life += deltaTime
+	if fb.Landed {
+		return
+	}
+
+	// 与掉落物相同的重力、阻力和方块碰撞
+	applyGravity(&fb.Velocity, fb.Gravity, deltaTime)
+	fb.Landed = moveAndCollide(&fb.Position, &fb.Velocity, fb.Box.Width, fb.Box.Height, deltaTime, blocks)
+	fb.Box.X, fb.Box.Y = fb.Position.X, fb.Position.Y
+}
+
+// Expired reports whether the block has fallen for longer than FallingBlockLifetime
+func (fb *FallingBlock) Expired() bool {
+	return fb.Life >= FallingBlockLifetime
+}
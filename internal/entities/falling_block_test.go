@@ -0,0 +1,48 @@
+package entities
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/wubinrui111/2d-game/internal/components"
+)
+
+func TestFallingBlockLands(t *testing.T) {
+	fb := NewFallingBlock(0, 0, DefaultBlockSize, "sand", color.RGBA{220, 200, 130, 255})
+	floor := []components.BoxHolder{
+		NewSmallBlock(0, 5*DefaultBlockSize),
+		// 旁边的方块贴着下落路径，不应挡住它
+		NewSmallBlock(DefaultBlockSize, 2*DefaultBlockSize),
+	}
+
+	for i := 0; i < 600 && !fb.Landed; i++ {
+		fb.Update(1.0/60, floor)
+	}
+	if !fb.Landed {
+		t.Fatal("Expected the block to land")
+	}
+	if fb.Position.Y != 4*DefaultBlockSize || fb.Box.Y != fb.Position.Y || fb.Position.X != 0 {
+		t.Errorf("Expected the block to rest on the floor at y=%v, got (%v, %v)", 4*DefaultBlockSize, fb.Position.X, fb.Position.Y)
+	}
+
+	// 落地后不再移动
+	fb.Update(1.0/60, floor)
+	if fb.Position.Y != 4*DefaultBlockSize {
+		t.Errorf("Expected a landed block to stay, got y=%v", fb.Position.Y)
+	}
+}
+
+func TestFallingBlockExpires(t *testing.T) {
+	fb := NewFallingBlock(0, 0, DefaultBlockSize, "sand", color.RGBA{})
+	for i := 0; i < int(FallingBlockLifetime*60)-1; i++ {
+		fb.Update(1.0/60, nil)
+	}
+	if fb.Expired() || fb.Landed {
+		t.Fatal("Expected the block to still be falling")
+	}
+	fb.Update(1.0/60, nil)
+	fb.Update(1.0/60, nil)
+	if !fb.Expired() {
+		t.Error("Expected the block to expire without a floor")
+	}
+}
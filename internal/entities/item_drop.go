@@ -84,11 +84,6 @@ func (id *ItemDrop) Update(playerPos components.Position, deltaTime float64, blo
 	// Update life
 	id.Life += deltaTime
 	
-	// Apply gravity continuously (don't check OnGround to allow infinite falling)
-	if id.Gravity.Enabled {
-		id.Velocity.Y += id.Gravity.Force * deltaTime
-	}
-	
 	// Calculate distance to player
 	dx := playerPos.X - id.Position.X
 	dy := playerPos.Y - id.Position.Y
@@ -108,20 +103,11 @@ func (id *ItemDrop) Update(playerPos components.Position, deltaTime float64, blo
 		id.Velocity.Y += dy * attractionStrength * deltaTime
 	}
 	
-	// Apply some drag to slow down movement
-	id.Velocity.X *= math.Pow(0.9, deltaTime*60)
-	id.Velocity.Y *= math.Pow(0.9, deltaTime*60)
-	
-	// Store previous position for collision detection
-	prevX := id.Position.X
-	prevY := id.Position.Y
+	// Apply gravity continuously (don't check OnGround to allow infinite falling) and some drag
+	applyGravity(&id.Velocity, id.Gravity, deltaTime)
 	
-	// Update position based on velocity
-	id.Position.X += id.Velocity.X * deltaTime * 60
-	id.Position.Y += id.Velocity.Y * deltaTime * 60
-	
-	// Check block collisions
-	id.checkBlockCollisions(blocks, prevX, prevY)
+	// Move and check block collisions
+	moveAndCollide(&id.Position, &id.Velocity, ItemDropSize, ItemDropSize, deltaTime, blocks)
 	
 	// 移除地面碰撞检测调用，确保持续下落
 	// id.checkGroundCollision()
@@ -139,36 +125,6 @@ func (id *ItemDrop) Update(playerPos components.Position, deltaTime float64, blo
 	}
 }
 
-// checkBlockCollisions checks if the item drop collides with any blocks
-func (id *ItemDrop) checkBlockCollisions(blocks []components.BoxHolder, prevX, prevY float64) {
-	// Create a temporary box for the new position
-	tempBox := components.Box{
-		X:      id.Position.X,
-		Y:      id.Position.Y,
-		Width:  ItemDropSize,
-		Height: ItemDropSize,
-	}
-	
-	// Check collision with all blocks
-	for _, block := range blocks {
-		if tempBox.Intersects(block.GetBox()) {
-			// Calculate intersection depth
-			xDepth, yDepth := tempBox.GetIntersectionDepth(block.GetBox())
-			
-			// Determine the minimum translation vector
-			if math.Abs(xDepth) < math.Abs(yDepth) {
-				// Horizontal collision - stop horizontal movement
-				id.Position.X = prevX // Revert to previous X position
-				id.Velocity.X = 0
-			} else {
-				// Vertical collision - stop vertical movement
-				id.Position.Y = prevY // Revert to previous Y position
-				id.Velocity.Y = 0
-			}
-		}
-	}
-}
-
 // checkGroundCollision disables ground collision, allowing items to fall infinitely
 func (id *ItemDrop) checkGroundCollision() {
 	// Ground collision is disabled to allow infinite falling
@@ -0,0 +1,51 @@
+package entities
+
+import (
+	"math"
+
+	"github.com/wubinrui111/2d-game/internal/components"
+)
+
+// applyGravity accelerates velocity by gravity and slows it with the drag item drops
+// and falling blocks share
+func applyGravity(velocity *components.Position, gravity *components.Gravity, deltaTime float64) {
+	if gravity.Enabled {
+		velocity.Y += gravity.Force * deltaTime
+	}
+	velocity.X *= math.Pow(0.9, deltaTime*60)
+	velocity.Y *= math.Pow(0.9, deltaTime*60)
+}
+
+// moveAndCollide moves a box of the given size at position by velocity, then pushes it
+// out of the blocks it ran into: back along the axis it went in least deep, and onto
+// the top of a block it fell on. It reports whether the box landed on a block.
+func moveAndCollide(position, velocity *components.Position, width, height, deltaTime float64, blocks []components.BoxHolder) bool {
+	prevX, prevY := position.X, position.Y
+	position.X += velocity.X * deltaTime * 60
+	position.Y += velocity.Y * deltaTime * 60
+
+	landed := false
+	for _, block := range blocks {
+		box := components.Box{X: position.X, Y: position.Y, Width: width, Height: height}
+		other := block.GetBox()
+		if !box.Intersects(other) {
+			continue
+		}
+		xDepth, yDepth := box.GetIntersectionDepth(other)
+		switch {
+		case math.Abs(xDepth) < math.Abs(yDepth):
+			// 水平碰撞，停止水平移动
+			position.X = prevX
+			velocity.X = 0
+		case prevY+height <= other.Y:
+			// 从上方落到方块上，贴住方块顶部
+			position.Y = other.Y - height
+			velocity.Y = 0
+			landed = true
+		default:
+			position.Y = prevY
+			velocity.Y = 0
+		}
+	}
+	return landed
+}
@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/wubinrui111/2d-game/internal/components"
+)
+
+func TestItemDropRestsOnBlock(t *testing.T) {
+	drop := NewItemDrop(8, 0, &components.Item{ID: "stone"})
+	floor := []components.BoxHolder{NewSmallBlock(0, 3*DefaultBlockSize)}
+
+	// 玩家离得很远，掉落物只受重力
+	far := components.Position{X: 1000, Y: 1000}
+	for i := 0; i < 120; i++ {
+		drop.Update(far, 1.0/60, floor)
+	}
+	if drop.Position.Y != 3*DefaultBlockSize-ItemDropSize || drop.Velocity.Y != 0 {
+		t.Errorf("Expected the drop to rest on the block at y=%v, got y=%v moving %v", 3*DefaultBlockSize-ItemDropSize, drop.Position.Y, drop.Velocity.Y)
+	}
+}
+
+func TestMoveAndCollideStopsSideways(t *testing.T) {
+	pos := components.Position{X: 0, Y: 0}
+	vel := components.Position{X: 5, Y: 0}
+	wall := []components.BoxHolder{NewSmallBlock(DefaultBlockSize+2, 0)}
+	for i := 0; i < 10; i++ {
+		if moveAndCollide(&pos, &vel, DefaultBlockSize, DefaultBlockSize, 1.0/60, wall) {
+			t.Fatal("Expected running into a wall not to count as landing")
+		}
+	}
+	if pos.X > 2 || vel.X != 0 {
+		t.Errorf("Expected the wall to stop the box, got x=%v moving %v", pos.X, vel.X)
+	}
+}
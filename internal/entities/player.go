@@ -11,6 +11,8 @@ type Player struct {
 	components.Gravity        // Player's gravity
 	components.Health         // Player's health
 	components.Acceleration   // Player's movement acceleration
+	components.Jump           // Player's jump parameters
+	components.Animation      // Player's sprite animation
 	OnGround bool             // Whether the player is on the ground
 }
 
@@ -24,6 +26,8 @@ func NewPlayer(x, y float64) *Player {
 		Gravity:     *components.NewGravity(),
 		Health:      *components.NewHealth(100), // 100 HP by default
 		Acceleration: *components.NewAcceleration(), // Add acceleration
+		Jump:        *components.NewJump(),
+		Animation:   *components.NewAnimation(),
 		OnGround:    false,
 	}
 	
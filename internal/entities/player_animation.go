@@ -0,0 +1,70 @@
+package entities
+
+import (
+	"math"
+
+	"github.com/wubinrui111/2d-game/internal/components"
+)
+
+// Player animation clip names
+const (
+	PlayerIdle = "idle"
+	PlayerRun  = "run"
+	PlayerJump = "jump"
+	PlayerFall = "fall"
+	PlayerMine = "mine"
+)
+
+// PlayerAnimations lists the clips the player animation state machine uses
+var PlayerAnimations = []string{PlayerIdle, PlayerRun, PlayerJump, PlayerFall, PlayerMine}
+
+// RunThreshold is the horizontal speed above which the player runs and turns to face
+// the direction of movement (pixels per second)
+const RunThreshold = 20.0
+
+// UpdateAnimation picks the player's clip from its velocity and ground contact, faces it
+// toward the direction of movement and advances it by dt seconds. mining is whether the
+// player is breaking blocks this tick.
+func (p *Player) UpdateAnimation(dt float64, mining bool) {
+	// 精灵默认朝右，向左移动时水平翻转
+	if p.Velocity.X > RunThreshold {
+		p.Animation.FlipX = false
+	} else if p.Velocity.X < -RunThreshold {
+		p.Animation.FlipX = true
+	}
+
+	next := p.nextAnimation(mining)
+	if next == PlayerMine && p.Animation.Clip() == PlayerMine && p.Animation.Finished() {
+		// 继续挖掘时重新播放只播放一次的挖掘动画
+		p.Animation.Restart()
+	}
+	p.Animation.Play(next)
+	p.Animation.Update(dt)
+}
+
+// nextAnimation is the state machine: it returns the clip to play this tick
+func (p *Player) nextAnimation(mining bool) string {
+	// 空中：上升时播放跳跃动画，下落时播放下落动画
+	if !p.OnGround {
+		if p.Velocity.Y < 0 {
+			return PlayerJump
+		}
+		return PlayerFall
+	}
+
+	// 挖掘动画播放完之前不被打断（站在地面上时）
+	if mining || p.playingOnce(PlayerMine) {
+		return PlayerMine
+	}
+
+	if math.Abs(p.Velocity.X) > RunThreshold {
+		return PlayerRun
+	}
+	return PlayerIdle
+}
+
+// playingOnce reports whether clip is playing and is an unfinished clip played once
+func (p *Player) playingOnce(clip string) bool {
+	c := p.Animation.Clips[clip]
+	return p.Animation.Clip() == clip && c != nil && c.Loop == components.LoopOnce && !p.Animation.Finished()
+}
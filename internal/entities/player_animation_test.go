@@ -0,0 +1,91 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/wubinrui111/2d-game/internal/components"
+)
+
+// newAnimatedPlayer creates a player with a two-frame clip for every animation state
+func newAnimatedPlayer(mine components.LoopMode) *Player {
+	p := NewPlayer(0, 0)
+	for _, name := range PlayerAnimations {
+		p.Animation.Clips[name] = &components.AnimationClip{Frames: []components.AnimationFrame{
+			{Image: &ebiten.Image{}, Duration: 0.1},
+			{Image: &ebiten.Image{}, Duration: 0.1},
+		}}
+	}
+	p.Animation.Clips[PlayerMine].Loop = mine
+	return p
+}
+
+func TestPlayerAnimationStates(t *testing.T) {
+	tests := []struct {
+		name     string
+		vx, vy   float64
+		onGround bool
+		mining   bool
+		want     string
+	}{
+		{"standing", 0, 0, true, false, PlayerIdle},
+		{"slow drift", RunThreshold / 2, 0, true, false, PlayerIdle},
+		{"running", 200, 0, true, false, PlayerRun},
+		{"rising", 200, -300, false, false, PlayerJump},
+		{"falling", 0, 100, false, false, PlayerFall},
+		{"mining", 0, 0, true, true, PlayerMine},
+		{"mining while running", 200, 0, true, true, PlayerMine},
+		{"mining in the air", 0, 100, false, true, PlayerFall},
+	}
+	for _, tt := range tests {
+		p := newAnimatedPlayer(components.LoopForever)
+		p.Velocity.X, p.Velocity.Y = tt.vx, tt.vy
+		p.OnGround = tt.onGround
+		p.UpdateAnimation(1.0/60, tt.mining)
+		if p.Animation.Clip() != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, p.Animation.Clip())
+		}
+	}
+}
+
+func TestPlayerFacing(t *testing.T) {
+	p := newAnimatedPlayer(components.LoopForever)
+	p.OnGround = true
+
+	p.Velocity.X = -100
+	p.UpdateAnimation(1.0/60, false)
+	if !p.Animation.FlipX {
+		t.Error("Expected the player to face left when moving left")
+	}
+
+	// 停下时保持朝向
+	p.Velocity.X = 0
+	p.UpdateAnimation(1.0/60, false)
+	if !p.Animation.FlipX {
+		t.Error("Expected the player to keep facing left after stopping")
+	}
+
+	p.Velocity.X = 100
+	p.UpdateAnimation(1.0/60, false)
+	if p.Animation.FlipX {
+		t.Error("Expected the player to face right when moving right")
+	}
+}
+
+func TestPlayerMineFinishesBeforeIdle(t *testing.T) {
+	p := newAnimatedPlayer(components.LoopOnce)
+	p.OnGround = true
+
+	p.UpdateAnimation(0.05, true)
+	p.UpdateAnimation(0.05, false)
+	if p.Animation.Clip() != PlayerMine {
+		t.Errorf("Expected the mine clip to keep playing until it finishes, got %s", p.Animation.Clip())
+	}
+
+	// 两帧各0.1秒，播放完后回到待机
+	p.UpdateAnimation(0.2, false)
+	p.UpdateAnimation(0.05, false)
+	if p.Animation.Clip() != PlayerIdle {
+		t.Errorf("Expected idle after the mine clip finished, got %s", p.Animation.Clip())
+	}
+}
@@ -0,0 +1,262 @@
+// Package fluids simulates water and lava on the block grid. A fluid cell has a level
+// from 1 to MaxLevel: sources are always full, fluid falling from above is full, and
+// fluid flowing sideways loses its kind's decay per cell. Cells flow on scheduled
+// ticks, only where something changed, so still fluids cost nothing.
+package fluids
+
+import (
+	"io"
+
+	"github.com/wubinrui111/2d-game/internal/grid"
+)
+
+// MaxLevel is the level of sources and falling fluid
+const MaxLevel = 8
+
+// DefaultMaxFall is how far fluid falls through open cells before it stops, so it
+// does not fall forever where the world has no floor
+const DefaultMaxFall = 64
+
+// Kind is a kind of fluid
+type Kind struct {
+	ID    string // Block ID of the fluid's cells
+	Decay int    // Levels lost per cell flowing sideways
+	Delay int    // Ticks between flow steps
+
+	// Hardens is the block the fluid turns into when it touches another fluid, ""
+	// if it does not
+	Hardens string
+}
+
+var (
+	// Water flows quickly and far
+	Water = &Kind{ID: "water", Decay: 1, Delay: 5}
+
+	// Lava flows slowly, not as far, and turns to stone when water touches it
+	Lava = &Kind{ID: "lava", Decay: 2, Delay: 30, Hardens: "stone"}
+)
+
+// World is where fluids flow. The simulation sets fluid cells to their kind's block
+// ID, and "" when they drain; it also sets them when only the level changes, so the
+// world can draw the new level.
+type World interface {
+	Block(x, y int) string
+	SetBlock(x, y int, id string)
+}
+
+// State is the fluid in a cell
+type State struct {
+	Kind   *Kind
+	Level  int  // 1 to MaxLevel
+	Source bool // Sources are placed, never drain and do not flow away
+	fall   int  // Cells fallen through since the fluid last was on something
+}
+
+// Sim is the fluid simulation of a world
+type Sim struct {
+	World   World
+	Kinds   []*Kind
+	MaxFall int
+
+	cells map[grid.Cell]State
+	flows *grid.Scheduler // Cells waiting to flow
+
+	applying bool // Whether the simulation is setting a block, which is not an outside change
+}
+
+// New creates a simulation of kinds in world. Blocks of the kinds' IDs in the world
+// are sources once Changed reports them.
+func New(world World, kinds ...*Kind) *Sim {
+	return &Sim{
+		World:   world,
+		Kinds:   kinds,
+		MaxFall: DefaultMaxFall,
+		cells:   make(map[grid.Cell]State),
+		flows:   grid.NewScheduler(),
+	}
+}
+
+// Kind returns the kind of fluid with a block ID, nil if the block is not a fluid
+func (s *Sim) Kind(id string) *Kind {
+	for _, k := range s.Kinds {
+		if k.ID == id {
+			return k
+		}
+	}
+	return nil
+}
+
+// Fluid returns the fluid in a cell
+func (s *Sim) Fluid(x, y int) (State, bool) {
+	st, exists := s.cells[grid.Cell{X: x, Y: y}]
+	return st, exists
+}
+
+// Pending returns how many cells are waiting to flow
+func (s *Sim) Pending() int {
+	return s.flows.Pending()
+}
+
+// Changed tells the simulation that the block at a cell changed from outside: a
+// fluid block becomes a source, any other block or "" replaces the fluid there. The
+// fluids around the cell flow again.
+func (s *Sim) Changed(x, y int) {
+	if s.applying {
+		return
+	}
+	c := grid.Cell{X: x, Y: y}
+	if k := s.Kind(s.World.Block(x, y)); k != nil {
+		s.cells[c] = State{Kind: k, Level: MaxLevel, Source: true}
+	} else {
+		delete(s.cells, c)
+	}
+	s.scheduleAround(c, nil)
+}
+
+// Update advances the simulation one tick, flowing the cells due at it
+func (s *Sim) Update() {
+	for _, c := range s.flows.Advance() {
+		s.flow(c)
+	}
+}
+
+// flow updates the fluid in a cell from the cells around it
+func (s *Sim) flow(c grid.Cell) {
+	st, exists := s.cells[c]
+	if !exists && s.World.Block(c.X, c.Y) != "" {
+		// 其他方块挡住流体
+		return
+	}
+
+	// 接触其他流体时凝固，例如岩浆遇水变成石头
+	if exists && st.Kind.Hardens != "" && s.touchesOther(c, st.Kind) {
+		delete(s.cells, c)
+		s.setBlock(c, st.Kind.Hardens)
+		s.scheduleAround(c, st.Kind)
+		return
+	}
+	if exists && st.Source {
+		return
+	}
+
+	var only *Kind
+	if exists {
+		only = st.Kind
+	}
+	next, flows := s.next(c, only)
+	if flows == exists && next == st {
+		return
+	}
+	if flows {
+		s.cells[c] = next
+		s.setBlock(c, next.Kind.ID)
+		s.scheduleAround(c, next.Kind)
+	} else {
+		delete(s.cells, c)
+		s.setBlock(c, "")
+		s.scheduleAround(c, st.Kind)
+	}
+}
+
+// next returns the fluid flowing into a cell from above or from its sides. If only is
+// not nil, only that kind is considered. It returns false if no fluid reaches the cell.
+func (s *Sim) next(c grid.Cell, only *Kind) (State, bool) {
+	var best State
+	for _, k := range s.Kinds {
+		if only != nil && k != only {
+			continue
+		}
+
+		// 上方的流体落下来，保持满格
+		if above, exists := s.cells[grid.Cell{X: c.X, Y: c.Y - 1}]; exists && above.Kind == k && above.fall < s.MaxFall {
+			if best.Level < MaxLevel {
+				best = State{Kind: k, Level: MaxLevel, fall: above.fall + 1}
+			}
+			continue
+		}
+
+		// 两侧落在东西上的流体向旁边流，每格减少
+		for _, side := range [2]grid.Cell{{X: c.X - 1, Y: c.Y}, {X: c.X + 1, Y: c.Y}} {
+			n, exists := s.cells[side]
+			if !exists || n.Kind != k || !s.supported(side, k) {
+				continue
+			}
+			if level := n.Level - k.Decay; level > best.Level {
+				best = State{Kind: k, Level: level}
+			}
+		}
+	}
+	return best, best.Level > 0
+}
+
+// supported reports whether fluid of kind k at a cell rests on something, so it
+// spreads sideways instead of only falling
+func (s *Sim) supported(c grid.Cell, k *Kind) bool {
+	below := grid.Cell{X: c.X, Y: c.Y + 1}
+	if st, exists := s.cells[below]; exists {
+		return st.Source || st.Kind != k
+	}
+	return s.World.Block(below.X, below.Y) != ""
+}
+
+// touchesOther reports whether a cell is next to a fluid of another kind than k
+func (s *Sim) touchesOther(c grid.Cell, k *Kind) bool {
+	for _, n := range c.Neighbors() {
+		if st, exists := s.cells[n]; exists && st.Kind != k {
+			return true
+		}
+	}
+	return false
+}
+
+// setBlock sets a block of the world for the simulation
+func (s *Sim) setBlock(c grid.Cell, id string) {
+	s.applying = true
+	s.World.SetBlock(c.X, c.Y, id)
+	s.applying = false
+}
+
+// scheduleAround queues a cell and its neighbors to flow after the delay of kind k.
+// Without a kind, the fastest fluid in or next to the cell decides; if there is none,
+// nothing can flow.
+func (s *Sim) scheduleAround(c grid.Cell, k *Kind) {
+	delay := 0
+	if k != nil {
+		delay = k.Delay
+	}
+	neighbors := c.Neighbors()
+	around := append(neighbors[:], c)
+	for _, n := range around {
+		if st, exists := s.cells[n]; exists && k == nil && (delay == 0 || st.Kind.Delay < delay) {
+			delay = st.Kind.Delay
+		}
+	}
+	if delay == 0 {
+		return
+	}
+	for _, n := range around {
+		s.flows.Schedule(n, delay)
+	}
+}
+
+// Hash writes the state of the simulation to w in a fixed order: the fluid in every
+// cell and the cells waiting to flow, so two runs can be compared
+func (s *Sim) Hash(w io.Writer) {
+	grid.WriteInts(w, int64(len(s.cells)))
+	cells := make([]grid.Cell, 0, len(s.cells))
+	for c := range s.cells {
+		cells = append(cells, c)
+	}
+	grid.Sort(cells)
+	for _, c := range cells {
+		st := s.cells[c]
+		io.WriteString(w, st.Kind.ID)
+		grid.WriteInts(w, int64(c.X), int64(c.Y), int64(st.Level), int64(st.fall))
+		if st.Source {
+			grid.WriteInts(w, 1)
+		} else {
+			grid.WriteInts(w, 0)
+		}
+	}
+	s.flows.Hash(w)
+}
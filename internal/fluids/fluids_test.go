@@ -0,0 +1,202 @@
+package fluids
+
+import (
+	"hash/fnv"
+	"strings"
+	"testing"
+
+	"github.com/wubinrui111/2d-game/internal/grid"
+	"github.com/wubinrui111/2d-game/internal/grid/gridtest"
+)
+
+// letters are the blocks of the test grids: '#' stone, 'w' water and 'l' lava
+var letters = gridtest.Letters{'#': "stone", 'w': "water", 'l': "lava"}
+
+// gridWorld is a test world with a simulation of water and lava in it
+type gridWorld struct {
+	*gridtest.World
+	sim *Sim
+}
+
+// newGridWorld creates a world from rows of block letters, with the fluid blocks of
+// the rows as sources
+func newGridWorld(rows ...string) *gridWorld {
+	w := &gridWorld{World: gridtest.New()}
+	w.sim = New(w, Water, Lava)
+	w.Changed = w.sim.Changed
+	w.SetRows(letters, rows...)
+	return w
+}
+
+// run updates the simulation until nothing flows, at most max ticks
+func (w *gridWorld) run(t *testing.T, max int) {
+	t.Helper()
+	for i := 0; i < max; i++ {
+		if w.sim.Pending() == 0 {
+			return
+		}
+		w.sim.Update()
+	}
+	t.Fatalf("Expected the fluids to settle within %d ticks, %d cells still flow", max, w.sim.Pending())
+}
+
+// levels returns the fluid levels of a row, '.' where there is no fluid
+func (w *gridWorld) levels(y, width int) string {
+	var b strings.Builder
+	for x := 0; x < width; x++ {
+		if st, exists := w.sim.Fluid(x, y); exists {
+			b.WriteByte(byte('0' + st.Level))
+		} else {
+			b.WriteByte('.')
+		}
+	}
+	return b.String()
+}
+
+func TestWaterSpreadsSideways(t *testing.T) {
+	w := newGridWorld(
+		".........w.........",
+		"###################",
+	)
+	w.run(t, 1000)
+	if got := w.levels(0, 19); got != "..123456787654321.." {
+		t.Errorf("Expected the level to drop by one per cell, got %s", got)
+	}
+	if st, _ := w.sim.Fluid(9, 0); !st.Source {
+		t.Error("Expected the placed water to be a source")
+	}
+	if st, _ := w.sim.Fluid(8, 0); st.Source || st.Kind != Water {
+		t.Errorf("Expected flowing water next to the source, got %+v", st)
+	}
+}
+
+func TestWaterFallsThenSpreads(t *testing.T) {
+	w := newGridWorld(
+		"...w...",
+		"...#...",
+		".......",
+		".......",
+		"#######",
+	)
+	w.run(t, 1000)
+	// 源头下方是方块，向两侧流，到边缘后落下，落地后再向两侧流
+	want := "..www../" +
+		"..w#w../" +
+		"..w.w../" +
+		"wwwwwww/" +
+		"#######"
+	if got := w.Rows(letters, 7, 5); got != want {
+		t.Errorf("Expected the water to fall around the block, got %s", got)
+	}
+}
+
+func TestMaxFall(t *testing.T) {
+	w := newGridWorld("w")
+	w.sim.MaxFall = 5
+	w.run(t, 1000)
+	if _, exists := w.sim.Fluid(0, 5); !exists {
+		t.Error("Expected the water to fall 5 cells")
+	}
+	if _, exists := w.sim.Fluid(0, 6); exists {
+		t.Error("Expected the water to stop falling after 5 cells")
+	}
+}
+
+func TestSourceRemovalDrains(t *testing.T) {
+	w := newGridWorld(
+		"..w..",
+		"#####",
+	)
+	w.run(t, 1000)
+	if got := w.Rows(letters, 5, 1); got != "wwwww" {
+		t.Fatalf("Expected the water to cover the floor, got %s", got)
+	}
+
+	w.SetBlock(2, 0, "")
+	w.run(t, 1000)
+	if got := w.Rows(letters, 5, 1); got != "....." {
+		t.Errorf("Expected the flowing water to drain, got %s", got)
+	}
+
+	// 方块挡住流体，移除方块后继续流
+	w.SetBlock(0, 0, "stone")
+	w.SetBlock(2, 0, "water")
+	w.run(t, 1000)
+	if got := w.levels(0, 5); got != ".7876" {
+		t.Errorf("Expected the stone to stop the water, got %s", got)
+	}
+	w.SetBlock(0, 0, "")
+	w.run(t, 1000)
+	if got := w.levels(0, 5); got != "67876" {
+		t.Errorf("Expected the water to flow where the stone was, got %s", got)
+	}
+}
+
+func TestLavaFlowsSlowlyAndShort(t *testing.T) {
+	w := newGridWorld(
+		"....l....",
+		"#########",
+	)
+	for i := 1; i < Lava.Delay; i++ {
+		w.sim.Update()
+		if _, exists := w.sim.Fluid(3, 0); exists {
+			t.Fatalf("Expected lava to wait %d ticks before flowing, flowed after %d", Lava.Delay, i)
+		}
+	}
+	w.sim.Update()
+	if _, exists := w.sim.Fluid(3, 0); !exists {
+		t.Fatalf("Expected lava to flow after %d ticks", Lava.Delay)
+	}
+	w.run(t, 1000)
+	if got := w.levels(0, 9); got != ".2468642." {
+		t.Errorf("Expected lava to lose two levels per cell, got %s", got)
+	}
+}
+
+func TestWaterHardensLava(t *testing.T) {
+	w := newGridWorld(
+		"l...w",
+		"#####",
+	)
+	w.run(t, 2000)
+	// 流动的岩浆碰到水变成石头，水继续流
+	if got := w.Rows(letters, 5, 1); got != "l#www" {
+		t.Errorf("Expected stone where lava met water, got %s", got)
+	}
+
+	// 岩浆源头碰到水也会凝固
+	w.SetBlock(1, 0, "water")
+	w.run(t, 2000)
+	if got := w.Block(0, 0); got != "stone" {
+		t.Errorf("Expected the lava source to harden, got %q", got)
+	}
+}
+
+func TestHash(t *testing.T) {
+	hash := func(w *gridWorld) uint64 {
+		h := fnv.New64a()
+		w.sim.Hash(h)
+		return h.Sum64()
+	}
+	a := newGridWorld("....w....", "#########")
+	b := newGridWorld("....w....", "#########")
+	for i := 0; i < 20; i++ {
+		a.sim.Update()
+		b.sim.Update()
+	}
+	if hash(a) != hash(b) {
+		t.Error("Expected the same fluids to have the same hash")
+	}
+
+	// 流到一半的状态和计划都在哈希里
+	before := hash(a)
+	a.sim.Update()
+	if hash(a) == before {
+		t.Error("Expected a tick to change the hash")
+	}
+	b.sim.Update()
+	b.sim.flows.Schedule(grid.Cell{X: 100, Y: 100}, 100)
+	if hash(a) == hash(b) {
+		t.Error("Expected a scheduled cell to change the hash")
+	}
+}
@@ -0,0 +1,370 @@
+// internal/fonts/fonts.go
+package fonts
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/bitmapfont/v3"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+const (
+	// DefaultDir is the directory searched for TTF/OTF files at startup
+	DefaultDir = "./assets/fonts"
+
+	// DefaultSize is the font size used when a Style does not specify one
+	DefaultSize = 14.0
+
+	// bitmapSize is the size of the embedded bitmap font's glyphs
+	bitmapSize = 12.0
+)
+
+// Align determines how text is positioned relative to the x coordinate passed to Draw
+type Align int
+
+const (
+	// AlignLeft puts the start of each line at x
+	AlignLeft Align = iota
+	// AlignCenter centers each line on x
+	AlignCenter
+	// AlignRight puts the end of each line at x
+	AlignRight
+)
+
+// Style describes how a piece of text is rendered
+type Style struct {
+	// Family is the name of a loaded font (file name without extension), empty for the default
+	Family string
+
+	// Size is the font size in pixels, 0 means DefaultSize
+	Size float64
+
+	// Color is the fill color of the glyphs, nil means white
+	Color color.Color
+
+	// OutlineColor is the color of the outline drawn around the glyphs
+	OutlineColor color.Color
+
+	// OutlineWidth is the outline thickness in pixels, 0 disables the outline
+	OutlineWidth float64
+
+	// Align is the horizontal alignment of each line
+	Align Align
+
+	// LineSpacing is the distance between baselines, 0 means 1.25 * Size
+	LineSpacing float64
+
+	// WrapWidth is the maximum line width in pixels, 0 disables word wrapping
+	WrapWidth float64
+}
+
+// size returns the effective font size of the style
+func (s Style) size() float64 {
+	if s.Size <= 0 {
+		return DefaultSize
+	}
+	return s.Size
+}
+
+// lineSpacing returns the effective distance between baselines
+func (s Style) lineSpacing() float64 {
+	if s.LineSpacing > 0 {
+		return s.LineSpacing
+	}
+	return s.size() * 1.25
+}
+
+type faceKey struct {
+	family string
+	size   float64
+}
+
+// Manager loads font files and hands out cached faces for them.
+// Glyphs missing from the requested family fall back to the other loaded
+// fonts and finally to an embedded bitmap font that covers CJK.
+type Manager struct {
+	sources       map[string]*text.GoTextFaceSource
+	families      []string
+	defaultFamily string
+	bitmap        text.Face
+	faces         map[faceKey]text.Face
+
+	// scale enlarges all text, for the GUI scale setting
+	scale float64
+}
+
+// NewManager creates a font manager that only knows the bitmap fallback font
+func NewManager() *Manager {
+	return &Manager{
+		sources: make(map[string]*text.GoTextFaceSource),
+		bitmap:  text.NewGoXFace(bitmapfont.FaceSC),
+		faces:   make(map[faceKey]text.Face),
+		scale:   1,
+	}
+}
+
+var defaultManager = NewManager()
+
+// Default returns the font manager shared by the game's UI
+func Default() *Manager {
+	return defaultManager
+}
+
+// LoadDir loads every .ttf, .otf and .ttc file in dir.
+// Fonts are registered in file name order; the first one becomes the default family.
+func (m *Manager) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".ttf", ".otf", ".ttc":
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := m.LoadFile(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFile loads a single font file, registering it under its base name
+func (m *Manager) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	family := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return m.LoadBytes(family, data)
+}
+
+// LoadBytes registers font data under the given family name.
+// Font collections (.ttc) register each face as family-N.
+func (m *Manager) LoadBytes(family string, data []byte) error {
+	sources, err := text.NewGoTextFaceSourcesFromCollection(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("fonts: failed to parse %s: %w", family, err)
+	}
+
+	for i, source := range sources {
+		name := family
+		if len(sources) > 1 {
+			name = fmt.Sprintf("%s-%d", family, i)
+		}
+		if _, exists := m.sources[name]; !exists {
+			m.families = append(m.families, name)
+		}
+		m.sources[name] = source
+	}
+
+	if m.defaultFamily == "" && len(m.families) > 0 {
+		m.defaultFamily = m.families[0]
+	}
+
+	// 字体列表变化后需要重建带回退链的字体
+	m.faces = make(map[faceKey]text.Face)
+	return nil
+}
+
+// SetDefaultFamily selects the family used by styles without a Family
+func (m *Manager) SetDefaultFamily(family string) {
+	m.defaultFamily = family
+	m.faces = make(map[faceKey]text.Face)
+}
+
+// Families returns the names of all loaded font families
+func (m *Manager) Families() []string {
+	return append([]string(nil), m.families...)
+}
+
+// Face returns a face for the family at the given size.
+// The returned face falls back to the other loaded fonts and the bitmap font for missing glyphs.
+func (m *Manager) Face(family string, size float64) text.Face {
+	if family == "" {
+		family = m.defaultFamily
+	}
+	key := faceKey{family: family, size: size}
+	if face, exists := m.faces[key]; exists {
+		return face
+	}
+
+	var chain []text.Face
+	if source, exists := m.sources[family]; exists {
+		chain = append(chain, &text.GoTextFace{Source: source, Size: size})
+	}
+	for _, name := range m.families {
+		if name == family {
+			continue
+		}
+		chain = append(chain, &text.GoTextFace{Source: m.sources[name], Size: size})
+	}
+	chain = append(chain, m.bitmap)
+
+	var face text.Face = m.bitmap
+	if len(chain) > 1 {
+		if multi, err := text.NewMultiFace(chain...); err == nil {
+			face = multi
+		}
+	}
+
+	m.faces[key] = face
+	return face
+}
+
+// SetScale sets the factor applied to every text size; values <= 0 reset it to 1
+func (m *Manager) SetScale(scale float64) {
+	if scale <= 0 {
+		scale = 1
+	}
+	m.scale = scale
+}
+
+// Scale returns the factor applied to every text size
+func (m *Manager) Scale() float64 {
+	return m.scale
+}
+
+// styleFace returns the face for a style and the factor its glyphs are drawn scaled by
+func (m *Manager) styleFace(style Style) (text.Face, float64) {
+	if len(m.sources) == 0 {
+		// 只有位图字体时尺寸固定，按样式的尺寸缩放绘制
+		return m.bitmap, style.size() / bitmapSize * m.scale
+	}
+	return m.Face(style.Family, style.size()*m.scale), 1
+}
+
+// advance returns the width of a line drawn with face scaled by k
+func advance(s string, face text.Face, k float64) float64 {
+	return text.Advance(s, face) * k
+}
+
+// Advance returns the width of a single line of text
+func (m *Manager) Advance(s string, style Style) float64 {
+	face, k := m.styleFace(style)
+	return advance(s, face, k)
+}
+
+// Lines splits s into the lines that Draw renders, applying word wrapping
+func (m *Manager) Lines(s string, style Style) []string {
+	face, k := m.styleFace(style)
+	return wrapLines(s, style.WrapWidth, func(line string) float64 {
+		return advance(line, face, k)
+	})
+}
+
+// Truncate shortens a single line so that it fits in maxWidth, ending it with "..." when cut
+func (m *Manager) Truncate(s string, maxWidth float64, style Style) string {
+	face, k := m.styleFace(style)
+	if advance(s, face, k) <= maxWidth {
+		return s
+	}
+
+	const ellipsis = "..."
+	runes := []rune(s)
+	for n := len(runes) - 1; n > 0; n-- {
+		candidate := string(runes[:n]) + ellipsis
+		if advance(candidate, face, k) <= maxWidth {
+			return candidate
+		}
+	}
+	return ellipsis
+}
+
+// Measure returns the size of the area covered by the text when drawn with style
+func (m *Manager) Measure(s string, style Style) (width, height float64) {
+	face, k := m.styleFace(style)
+	lines := m.Lines(s, style)
+	for _, line := range lines {
+		if w := advance(line, face, k); w > width {
+			width = w
+		}
+	}
+	if len(lines) > 0 {
+		metrics := face.Metrics()
+		height = float64(len(lines)-1)*style.lineSpacing()*m.scale + (metrics.HAscent+metrics.HDescent)*k
+	}
+	width += 2 * style.OutlineWidth * m.scale
+	height += 2 * style.OutlineWidth * m.scale
+	return width, height
+}
+
+// Draw renders text with its top edge at y; x is interpreted according to style.Align
+func (m *Manager) Draw(dst *ebiten.Image, s string, x, y float64, style Style) {
+	face, k := m.styleFace(style)
+	lines := m.Lines(s, style)
+	spacing := style.lineSpacing() * m.scale
+
+	fill := style.Color
+	if fill == nil {
+		fill = color.White
+	}
+
+	for i, line := range lines {
+		lineY := y + float64(i)*spacing
+
+		if style.OutlineWidth > 0 && style.OutlineColor != nil {
+			w := style.OutlineWidth * m.scale
+			for _, offset := range [][2]float64{
+				{-w, -w}, {0, -w}, {w, -w},
+				{-w, 0}, {w, 0},
+				{-w, w}, {0, w}, {w, w},
+			} {
+				m.drawLine(dst, line, face, k, x+offset[0], lineY+offset[1], style.Align, style.OutlineColor)
+			}
+		}
+
+		m.drawLine(dst, line, face, k, x, lineY, style.Align, fill)
+	}
+}
+
+// drawLine renders a single line of text scaled by k
+func (m *Manager) drawLine(dst *ebiten.Image, line string, face text.Face, k, x, y float64, align Align, c color.Color) {
+	opts := &text.DrawOptions{}
+	switch align {
+	case AlignCenter:
+		opts.PrimaryAlign = text.AlignCenter
+	case AlignRight:
+		opts.PrimaryAlign = text.AlignEnd
+	}
+	opts.GeoM.Scale(k, k)
+	opts.GeoM.Translate(x, y)
+	opts.ColorScale.ScaleWithColor(c)
+	text.Draw(dst, line, face, opts)
+}
+
+// Draw renders text with the default manager
+func Draw(dst *ebiten.Image, s string, x, y float64, style Style) {
+	defaultManager.Draw(dst, s, x, y, style)
+}
+
+// Measure measures text with the default manager
+func Measure(s string, style Style) (width, height float64) {
+	return defaultManager.Measure(s, style)
+}
+
+// Truncate shortens text with the default manager
+func Truncate(s string, maxWidth float64, style Style) string {
+	return defaultManager.Truncate(s, maxWidth, style)
+}
+
+// Advance returns the width of a single line of text using the default manager
+func Advance(s string, style Style) float64 {
+	return defaultManager.Advance(s, style)
+}
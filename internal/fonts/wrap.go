@@ -0,0 +1,107 @@
+package fonts
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// isBreakableRune reports whether a line may break before and after r without a space.
+// CJK text has no spaces between words, so every ideograph is its own token.
+func isBreakableRune(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r) ||
+		(r >= 0x3000 && r <= 0x303F) || // CJK 标点
+		(r >= 0xFF00 && r <= 0xFFEF) // 全角字符
+}
+
+// splitTokens splits a line into words, runs of spaces and single CJK runes
+func splitTokens(line string) []string {
+	var tokens []string
+	start := -1
+	inSpace := false
+
+	flush := func(end int) {
+		if start >= 0 && end > start {
+			tokens = append(tokens, line[start:end])
+		}
+		start = -1
+	}
+
+	for i, r := range line {
+		switch {
+		case isBreakableRune(r):
+			flush(i)
+			tokens = append(tokens, string(r))
+		case r == ' ' || r == '\t':
+			if start >= 0 && !inSpace {
+				flush(i)
+			}
+			if start < 0 {
+				start = i
+				inSpace = true
+			}
+		default:
+			if start >= 0 && inSpace {
+				flush(i)
+			}
+			if start < 0 {
+				start = i
+				inSpace = false
+			}
+		}
+	}
+	flush(len(line))
+	return tokens
+}
+
+// wrapLines splits s on newlines and greedily wraps each line to maxWidth.
+// Words longer than maxWidth are broken between runes.
+func wrapLines(s string, maxWidth float64, advance func(string) float64) []string {
+	paragraphs := strings.Split(s, "\n")
+	if maxWidth <= 0 {
+		return paragraphs
+	}
+
+	var lines []string
+	for _, paragraph := range paragraphs {
+		current := ""
+		for _, token := range splitTokens(paragraph) {
+			candidate := current + token
+			if current == "" || advance(candidate) <= maxWidth {
+				current = candidate
+			} else {
+				lines = append(lines, strings.TrimRight(current, " \t"))
+				current = strings.TrimLeft(token, " \t")
+			}
+
+			// 单个单词超过行宽时按字符拆分
+			for current != "" && advance(current) > maxWidth && utf8.RuneCountInString(current) > 1 {
+				cut := breakIndex(current, maxWidth, advance)
+				lines = append(lines, current[:cut])
+				current = current[cut:]
+			}
+		}
+		lines = append(lines, strings.TrimRight(current, " \t"))
+	}
+	return lines
+}
+
+// breakIndex returns the byte index of the longest prefix of s that fits in maxWidth.
+// At least one rune is always kept so wrapping makes progress.
+func breakIndex(s string, maxWidth float64, advance func(string) float64) int {
+	_, first := utf8.DecodeRuneInString(s)
+	cut := first
+	for i := range s {
+		if i == 0 {
+			continue
+		}
+		if advance(s[:i]) > maxWidth {
+			break
+		}
+		cut = i
+	}
+	return cut
+}
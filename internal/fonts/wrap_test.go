@@ -0,0 +1,70 @@
+package fonts
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+// monospace measures every rune as 6 pixels wide
+func monospace(s string) float64 {
+	return float64(utf8.RuneCountInString(s) * 6)
+}
+
+func TestWrapLinesWithoutWidth(t *testing.T) {
+	lines := wrapLines("first line\nsecond line", 0, monospace)
+
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+
+	if lines[0] != "first line" || lines[1] != "second line" {
+		t.Errorf("Expected lines to be split on newlines only, got %q", lines)
+	}
+}
+
+func TestWrapLinesBreaksOnSpaces(t *testing.T) {
+	// 60 pixels fits 10 characters
+	lines := wrapLines("Press E to close inventory", 60, monospace)
+
+	expected := []string{"Press E to", "close", "inventory"}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d: %q", len(expected), len(lines), lines)
+	}
+
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("Expected line %d to be %q, got %q", i, expected[i], lines[i])
+		}
+	}
+}
+
+func TestWrapLinesBreaksCJK(t *testing.T) {
+	// 中文没有空格，每个字都可以换行
+	lines := wrapLines("按E键关闭物品栏", 24, monospace)
+
+	expected := []string{"按E键关", "闭物品栏"}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d: %q", len(expected), len(lines), lines)
+	}
+
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("Expected line %d to be %q, got %q", i, expected[i], lines[i])
+		}
+	}
+}
+
+func TestWrapLinesBreaksLongWords(t *testing.T) {
+	lines := wrapLines("abcdefghij", 24, monospace)
+
+	expected := []string{"abcd", "efgh", "ij"}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d: %q", len(expected), len(lines), lines)
+	}
+
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("Expected line %d to be %q, got %q", i, expected[i], lines[i])
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package graphics
+
+import (
+	"fmt"
+	"image"
+	"path"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/wubinrui111/2d-game/internal/components"
+)
+
+// Atlas holds named sprites from any number of sheets, and the animation clips defined
+// by the tags of JSON atlases. Every sprite is a SubImage of its sheet, so sprites of one
+// sheet share a texture.
+type Atlas struct {
+	sprites map[string]*ebiten.Image
+	clips   map[string]*components.AnimationClip
+}
+
+// NewAtlas creates an empty atlas
+func NewAtlas() *Atlas {
+	return &Atlas{
+		sprites: make(map[string]*ebiten.Image),
+		clips:   make(map[string]*components.AnimationClip),
+	}
+}
+
+// AssetSource provides asset files and decoded images by key, such as
+// the asset manager
+type AssetSource interface {
+	Data(key string) ([]byte, error)
+	Image(key string) (*ebiten.Image, error)
+}
+
+// LoadAtlas loads every sheet and JSON atlas listed in the manifest with the key manifestKey
+func LoadAtlas(src AssetSource, manifestKey string) (*Atlas, error) {
+	data, err := src.Data(manifestKey)
+	if err != nil {
+		return nil, err
+	}
+	m, err := ParseManifest(data, path.Dir(manifestKey))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", manifestKey, err)
+	}
+
+	atlas := NewAtlas()
+	for _, entry := range m.Sheets {
+		img, err := loadSheetImage(src, m.Path(entry.Image), entry.Layer)
+		if err != nil {
+			return nil, err
+		}
+		sheet := NewGridSheet(img, entry.TileWidth, entry.TileHeight, entry.Margin, entry.Spacing)
+		if err := atlas.AddSheet(sheet, entry.Sprites); err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Image, err)
+		}
+	}
+	for _, descKey := range m.Atlases {
+		if err := atlas.loadDescriptor(src, m.Path(descKey)); err != nil {
+			return nil, err
+		}
+	}
+	return atlas, nil
+}
+
+// loadSheetImage loads the image of a sheet, or one layer of it for .xcf images
+func loadSheetImage(src AssetSource, key, layer string) (*ebiten.Image, error) {
+	if layer == "" {
+		return src.Image(key)
+	}
+	data, err := src.Data(key)
+	if err != nil {
+		return nil, err
+	}
+	img, err := xcfSheetImage(data, layer)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+	return ebiten.NewImageFromImage(img), nil
+}
+
+// loadDescriptor loads a JSON atlas and its image
+func (a *Atlas) loadDescriptor(src AssetSource, key string) error {
+	data, err := src.Data(key)
+	if err != nil {
+		return err
+	}
+	desc, err := ParseAtlasDescriptor(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	img, err := src.Image(path.Join(path.Dir(key), desc.Image))
+	if err != nil {
+		return err
+	}
+	if err := a.AddDescriptor(img, desc); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
+
+// Add adds a sprite under name
+func (a *Atlas) Add(name string, sprite *ebiten.Image) error {
+	if _, exists := a.sprites[name]; exists {
+		return fmt.Errorf("graphics: sprite %q is defined twice", name)
+	}
+	a.sprites[name] = sprite
+	return nil
+}
+
+// AddSheet adds the sprites of a grid sheet, named by their index
+func (a *Atlas) AddSheet(sheet *SpriteSheet, names map[string]int) error {
+	for _, name := range sortedKeys(names) {
+		index := names[name]
+		if index < 0 || index >= sheet.Len() {
+			return fmt.Errorf("graphics: sprite %q has index %d, the sheet has %d sprites", name, index, sheet.Len())
+		}
+		if err := a.Add(name, sheet.GetSpriteByIndex(index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddDescriptor adds the frames of a parsed JSON atlas as SubImages of img, and
+// an animation clip for each of its tags
+func (a *Atlas) AddDescriptor(img *ebiten.Image, desc *AtlasDescriptor) error {
+	bounds := img.Bounds()
+	frames := make([]components.AnimationFrame, len(desc.Frames))
+	for i, frame := range desc.Frames {
+		rect := image.Rect(frame.X, frame.Y, frame.X+frame.W, frame.Y+frame.H).Add(bounds.Min)
+		if !rect.In(bounds) {
+			return fmt.Errorf("graphics: frame %q is outside the %dx%d image", frame.Name, bounds.Dx(), bounds.Dy())
+		}
+		sprite := img.SubImage(rect).(*ebiten.Image)
+		if err := a.Add(frame.Name, sprite); err != nil {
+			return err
+		}
+		frames[i] = components.AnimationFrame{Image: sprite, Duration: float64(frame.Duration) / 1000}
+	}
+
+	for _, tag := range desc.Tags {
+		if _, exists := a.clips[tag.Name]; exists {
+			return fmt.Errorf("graphics: clip %q is defined twice", tag.Name)
+		}
+		a.clips[tag.Name] = clipFromTag(frames, tag)
+	}
+	return nil
+}
+
+// clipFromTag builds the clip of an Aseprite tag. A tag played once becomes a LoopOnce
+// clip; other repeat counts loop forever.
+func clipFromTag(frames []components.AnimationFrame, tag AtlasTag) *components.AnimationClip {
+	clip := &components.AnimationClip{
+		Frames: append([]components.AnimationFrame(nil), frames[tag.From:tag.To+1]...),
+	}
+	if tag.Direction == "reverse" || tag.Direction == "pingpong_reverse" {
+		for i, j := 0, len(clip.Frames)-1; i < j; i, j = i+1, j-1 {
+			clip.Frames[i], clip.Frames[j] = clip.Frames[j], clip.Frames[i]
+		}
+	}
+
+	switch {
+	case tag.Direction == "pingpong" || tag.Direction == "pingpong_reverse":
+		clip.Loop = components.LoopPingPong
+	case tag.Repeat == 1:
+		clip.Loop = components.LoopOnce
+	default:
+		clip.Loop = components.LoopForever
+	}
+	return clip
+}
+
+// Clip returns the animation clip named name
+func (a *Atlas) Clip(name string) (*components.AnimationClip, bool) {
+	clip, exists := a.clips[name]
+	return clip, exists
+}
+
+// Sprite returns the sprite named name
+func (a *Atlas) Sprite(name string) (*ebiten.Image, bool) {
+	sprite, exists := a.sprites[name]
+	return sprite, exists
+}
+
+// Names returns the names of all sprites, sorted
+func (a *Atlas) Names() []string {
+	return sortedKeys(a.sprites)
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,172 @@
+package graphics
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AtlasFrame is a named region of an atlas image
+type AtlasFrame struct {
+	Name       string
+	X, Y, W, H int
+	Duration   int // Frame duration in milliseconds from Aseprite, 0 if not given
+}
+
+// AtlasTag is a named range of frames, such as an Aseprite animation tag
+type AtlasTag struct {
+	Name      string
+	From, To  int    // Indices into AtlasDescriptor.Frames, inclusive
+	Direction string // "forward", "reverse", "pingpong" or "pingpong_reverse"
+	Repeat    int    // How many times the tag plays, 0 for forever
+}
+
+// AtlasDescriptor is a parsed JSON atlas: the image file and its named regions
+type AtlasDescriptor struct {
+	Image  string // Image path as written in the descriptor, relative to it
+	Frames []AtlasFrame
+	Tags   []AtlasTag
+}
+
+// atlasFrameJSON is one frame in TexturePacker and Aseprite JSON
+type atlasFrameJSON struct {
+	Filename string `json:"filename"` // Only in the array format
+	Frame    struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+		W int `json:"w"`
+		H int `json:"h"`
+	} `json:"frame"`
+	Rotated  bool `json:"rotated"`
+	Duration int  `json:"duration"`
+}
+
+// atlasJSON is the common layout of TexturePacker ("JSON (Hash)" and "JSON (Array)")
+// and Aseprite ("Hash" and "Array") sprite sheet exports
+type atlasJSON struct {
+	Frames json.RawMessage `json:"frames"`
+	Meta   struct {
+		Image     string `json:"image"`
+		FrameTags []struct {
+			Name      string `json:"name"`
+			From      int    `json:"from"`
+			To        int    `json:"to"`
+			Direction string `json:"direction"`
+			Repeat    string `json:"repeat"` // Aseprite writes the count as a string
+		} `json:"frameTags"`
+	} `json:"meta"`
+}
+
+// ParseAtlasDescriptor parses a TexturePacker or Aseprite JSON atlas. Frames may be a hash
+// keyed by name or an array with "filename" fields. Frame names lose their file extension,
+// so "grass.png" is named "grass". Rotated frames are not supported; trimmed frames are
+// used as packed, without their trimmed transparent border.
+func ParseAtlasDescriptor(data []byte) (*AtlasDescriptor, error) {
+	var raw atlasJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if raw.Meta.Image == "" {
+		return nil, fmt.Errorf("graphics: atlas has no meta.image")
+	}
+
+	var frames []atlasFrameJSON
+	trimmed := strings.TrimSpace(string(raw.Frames))
+	switch {
+	case strings.HasPrefix(trimmed, "["):
+		if err := json.Unmarshal(raw.Frames, &frames); err != nil {
+			return nil, err
+		}
+	case strings.HasPrefix(trimmed, "{"):
+		var byName map[string]atlasFrameJSON
+		if err := json.Unmarshal(raw.Frames, &byName); err != nil {
+			return nil, err
+		}
+		// JSON 对象没有顺序，按名称排序（Aseprite 的帧名称以帧号结尾）
+		names := make([]string, 0, len(byName))
+		for name := range byName {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool { return naturalLess(names[i], names[j]) })
+		for _, name := range names {
+			frame := byName[name]
+			frame.Filename = name
+			frames = append(frames, frame)
+		}
+	default:
+		return nil, fmt.Errorf("graphics: atlas has no frames")
+	}
+
+	desc := &AtlasDescriptor{Image: raw.Meta.Image}
+	for _, f := range frames {
+		if f.Rotated {
+			return nil, fmt.Errorf("graphics: atlas frame %q is rotated, which is not supported", f.Filename)
+		}
+		desc.Frames = append(desc.Frames, AtlasFrame{
+			Name:     strings.TrimSuffix(f.Filename, path.Ext(f.Filename)),
+			X:        f.Frame.X,
+			Y:        f.Frame.Y,
+			W:        f.Frame.W,
+			H:        f.Frame.H,
+			Duration: f.Duration,
+		})
+	}
+	for _, tag := range raw.Meta.FrameTags {
+		if tag.From < 0 || tag.To >= len(desc.Frames) || tag.From > tag.To {
+			return nil, fmt.Errorf("graphics: atlas tag %q has invalid frames %d-%d", tag.Name, tag.From, tag.To)
+		}
+		repeat := 0
+		if tag.Repeat != "" {
+			n, err := strconv.Atoi(tag.Repeat)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("graphics: atlas tag %q has invalid repeat %q", tag.Name, tag.Repeat)
+			}
+			repeat = n
+		}
+		desc.Tags = append(desc.Tags, AtlasTag{Name: tag.Name, From: tag.From, To: tag.To, Direction: tag.Direction, Repeat: repeat})
+	}
+	return desc, nil
+}
+
+// naturalLess compares strings with embedded numbers by value, so "walk 2" sorts before "walk 10"
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			na, restA := splitNumber(a)
+			nb, restB := splitNumber(b)
+			if na != nb {
+				// 位数不同时位数少的数字更小（忽略前导零）
+				ta, tb := strings.TrimLeft(na, "0"), strings.TrimLeft(nb, "0")
+				if len(ta) != len(tb) {
+					return len(ta) < len(tb)
+				}
+				if ta != tb {
+					return ta < tb
+				}
+			}
+			a, b = restA, restB
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+// splitNumber splits the leading digits off s
+func splitNumber(s string) (string, string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,124 @@
+package graphics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTexturePackerHash(t *testing.T) {
+	data := []byte(`{
+		"frames": {
+			"grass.png": {"frame": {"x": 0, "y": 0, "w": 16, "h": 16}, "rotated": false, "trimmed": false},
+			"sand.png": {"frame": {"x": 16, "y": 0, "w": 16, "h": 16}, "rotated": false, "trimmed": true}
+		},
+		"meta": {"image": "tiles.png", "size": {"w": 32, "h": 16}}
+	}`)
+	desc, err := ParseAtlasDescriptor(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.Image != "tiles.png" {
+		t.Errorf("Expected image tiles.png, got %q", desc.Image)
+	}
+	if len(desc.Frames) != 2 {
+		t.Fatalf("Expected 2 frames, got %d", len(desc.Frames))
+	}
+	want := AtlasFrame{Name: "sand", X: 16, Y: 0, W: 16, H: 16}
+	if desc.Frames[1] != want {
+		t.Errorf("Expected %+v, got %+v", want, desc.Frames[1])
+	}
+}
+
+func TestParseAsepriteArray(t *testing.T) {
+	data := []byte(`{
+		"frames": [
+			{"filename": "player 0.aseprite", "frame": {"x": 0, "y": 0, "w": 32, "h": 32}, "duration": 100},
+			{"filename": "player 1.aseprite", "frame": {"x": 32, "y": 0, "w": 32, "h": 32}, "duration": 150},
+			{"filename": "player 2.aseprite", "frame": {"x": 64, "y": 0, "w": 32, "h": 32}, "duration": 100}
+		],
+		"meta": {
+			"image": "player.png",
+			"frameTags": [{"name": "walk", "from": 1, "to": 2, "direction": "pingpong"}]
+		}
+	}`)
+	desc, err := ParseAtlasDescriptor(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.Frames[1].Name != "player 1" || desc.Frames[1].Duration != 150 {
+		t.Errorf("Unexpected frame %+v", desc.Frames[1])
+	}
+	if len(desc.Tags) != 1 || desc.Tags[0] != (AtlasTag{Name: "walk", From: 1, To: 2, Direction: "pingpong"}) {
+		t.Errorf("Unexpected tags %+v", desc.Tags)
+	}
+}
+
+func TestParseAsepriteHashKeepsFrameOrder(t *testing.T) {
+	data := []byte(`{
+		"frames": {
+			"run 10.aseprite": {"frame": {"x": 0, "y": 0, "w": 8, "h": 8}},
+			"run 2.aseprite": {"frame": {"x": 8, "y": 0, "w": 8, "h": 8}},
+			"run 1.aseprite": {"frame": {"x": 16, "y": 0, "w": 8, "h": 8}}
+		},
+		"meta": {"image": "run.png"}
+	}`)
+	desc, err := ParseAtlasDescriptor(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, frame := range desc.Frames {
+		names = append(names, frame.Name)
+	}
+	want := []string{"run 1", "run 2", "run 10"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected frames in order %v, got %v", want, names)
+	}
+}
+
+func TestParseAtlasDescriptorErrors(t *testing.T) {
+	tests := map[string]string{
+		"invalid json":  `{`,
+		"no image":      `{"frames": {}, "meta": {}}`,
+		"no frames":     `{"meta": {"image": "a.png"}}`,
+		"rotated frame": `{"frames": {"a": {"frame": {"x": 0, "y": 0, "w": 1, "h": 1}, "rotated": true}}, "meta": {"image": "a.png"}}`,
+		"bad tag":       `{"frames": [], "meta": {"image": "a.png", "frameTags": [{"name": "t", "from": 0, "to": 3}]}}`,
+	}
+	for name, data := range tests {
+		if _, err := ParseAtlasDescriptor([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"walk 2", "walk 10", true},
+		{"walk 10", "walk 2", false},
+		{"a", "b", true},
+		{"tile", "tile 0", true},
+		{"x02", "x1", false},
+	}
+	for _, tt := range tests {
+		if got := naturalLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("naturalLess(%q, %q) = %v, expected %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestParseAsepriteRepeat(t *testing.T) {
+	data := []byte(`{
+		"frames": [{"filename": "a 0", "frame": {"x": 0, "y": 0, "w": 8, "h": 8}}],
+		"meta": {"image": "a.png", "frameTags": [{"name": "once", "from": 0, "to": 0, "direction": "forward", "repeat": "1"}]}
+	}`)
+	desc, err := ParseAtlasDescriptor(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.Tags[0].Repeat != 1 {
+		t.Errorf("Expected repeat 1, got %d", desc.Tags[0].Repeat)
+	}
+}
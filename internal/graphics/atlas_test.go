@@ -0,0 +1,98 @@
+package graphics
+
+import (
+	"image"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/wubinrui111/2d-game/internal/components"
+)
+
+func TestGridSheetUsesSubImages(t *testing.T) {
+	// 1像素边距，2像素间隔，2x2 个 16x16 的精灵
+	img := ebiten.NewImage(36, 36)
+	sheet := NewGridSheet(img, 16, 16, 1, 2)
+	if sheet.Len() != 4 {
+		t.Fatalf("Expected 4 sprites, got %d", sheet.Len())
+	}
+
+	sprite := sheet.GetSpriteByIndex(3)
+	if want := image.Rect(19, 19, 35, 35); sprite.Bounds() != want {
+		t.Errorf("Expected sprite bounds %v, got %v", want, sprite.Bounds())
+	}
+	if len(sheet.GetSpriteMap()) != 4 {
+		t.Errorf("Expected 4 sprites in the map, got %d", len(sheet.GetSpriteMap()))
+	}
+}
+
+func TestAtlasAddSheet(t *testing.T) {
+	sheet := NewGridSheet(ebiten.NewImage(64, 32), 32, 32, 0, 0)
+
+	atlas := NewAtlas()
+	if err := atlas.AddSheet(sheet, map[string]int{"stone": 0, "dirt": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if dirt, exists := atlas.Sprite("dirt"); !exists || dirt.Bounds() != image.Rect(32, 0, 64, 32) {
+		t.Errorf("Expected dirt to be the second sprite, got %v", dirt)
+	}
+	if names := atlas.Names(); len(names) != 2 || names[0] != "dirt" {
+		t.Errorf("Expected sorted names, got %v", names)
+	}
+
+	if err := atlas.AddSheet(sheet, map[string]int{"stone": 1}); err == nil {
+		t.Error("Expected an error for a name defined twice")
+	}
+	if err := NewAtlas().AddSheet(sheet, map[string]int{"wood": 2}); err == nil {
+		t.Error("Expected an error for an index outside the sheet")
+	}
+}
+
+func TestAtlasAddDescriptor(t *testing.T) {
+	img := ebiten.NewImage(32, 16)
+	desc := &AtlasDescriptor{Frames: []AtlasFrame{{Name: "grass", X: 16, Y: 0, W: 16, H: 16}}}
+
+	atlas := NewAtlas()
+	if err := atlas.AddDescriptor(img, desc); err != nil {
+		t.Fatal(err)
+	}
+	if grass, exists := atlas.Sprite("grass"); !exists || grass.Bounds() != image.Rect(16, 0, 32, 16) {
+		t.Errorf("Unexpected grass sprite %v", grass)
+	}
+
+	desc.Frames[0] = AtlasFrame{Name: "sand", X: 24, Y: 0, W: 16, H: 16}
+	if err := atlas.AddDescriptor(img, desc); err == nil {
+		t.Error("Expected an error for a frame outside the image")
+	}
+}
+
+func TestAtlasClipsFromTags(t *testing.T) {
+	img := ebiten.NewImage(96, 32)
+	desc := &AtlasDescriptor{
+		Frames: []AtlasFrame{
+			{Name: "player 0", X: 0, W: 32, H: 32, Duration: 100},
+			{Name: "player 1", X: 32, W: 32, H: 32, Duration: 200},
+			{Name: "player 2", X: 64, W: 32, H: 32},
+		},
+		Tags: []AtlasTag{
+			{Name: "player_run", From: 0, To: 2, Direction: "forward"},
+			{Name: "player_mine", From: 1, To: 2, Direction: "reverse", Repeat: 1},
+			{Name: "player_idle", From: 0, To: 1, Direction: "pingpong"},
+		},
+	}
+	atlas := NewAtlas()
+	if err := atlas.AddDescriptor(img, desc); err != nil {
+		t.Fatal(err)
+	}
+
+	run, _ := atlas.Clip("player_run")
+	if len(run.Frames) != 3 || run.Loop != components.LoopForever || run.Frames[1].Duration != 0.2 {
+		t.Errorf("Unexpected run clip %+v", run)
+	}
+	mine, _ := atlas.Clip("player_mine")
+	if second, _ := atlas.Sprite("player 2"); mine.Loop != components.LoopOnce || mine.Frames[0].Image != second {
+		t.Errorf("Expected a reversed clip played once, got %+v", mine)
+	}
+	if idle, _ := atlas.Clip("player_idle"); idle.Loop != components.LoopPingPong {
+		t.Errorf("Expected a ping-pong clip, got %+v", idle)
+	}
+}
@@ -0,0 +1,58 @@
+package graphics
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/wubinrui111/2d-game/internal/camera"
+)
+
+// LightOverlay darkens the world by how much light each cell gets. The darkness of the
+// cells in view is written into a small image, one pixel per cell, which is drawn
+// scaled up with linear filtering so light fades smoothly from cell to cell.
+type LightOverlay struct {
+	tileSize float64
+	image    *ebiten.Image
+	pixels   []byte
+}
+
+// NewLightOverlay creates an overlay for cells of tileSize world pixels
+func NewLightOverlay(tileSize float64) *LightOverlay {
+	return &LightOverlay{tileSize: tileSize}
+}
+
+// Draw darkens the cells in the camera's view. darkness returns how dark a cell is
+// drawn, from 0 (fully lit) to 1 (black).
+func (o *LightOverlay) Draw(screen *ebiten.Image, cam *camera.Camera, darkness func(x, y int) float64) {
+	// 视野外多取一格，边缘的插值也正确
+	view := cam.ViewRect()
+	minX := int(math.Floor(view.X/o.tileSize)) - 1
+	minY := int(math.Floor(view.Y/o.tileSize)) - 1
+	maxX := int(math.Ceil((view.X+view.W)/o.tileSize)) + 1
+	maxY := int(math.Ceil((view.Y+view.H)/o.tileSize)) + 1
+	w, h := maxX-minX, maxY-minY
+
+	if o.image == nil || o.image.Bounds().Dx() != w || o.image.Bounds().Dy() != h {
+		if o.image != nil {
+			o.image.Deallocate()
+		}
+		o.image = ebiten.NewImage(w, h)
+		o.pixels = make([]byte, 4*w*h)
+	}
+
+	// 黑色，透明度为暗度（预乘 alpha，颜色分量都是 0）
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			d := math.Max(0, math.Min(1, darkness(minX+x, minY+y)))
+			o.pixels[4*(y*w+x)+3] = uint8(d*255 + 0.5)
+		}
+	}
+	o.image.WritePixels(o.pixels)
+
+	// 每个像素的中心对准格子的中心
+	opts := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear}
+	opts.GeoM.Scale(o.tileSize, o.tileSize)
+	opts.GeoM.Translate(float64(minX)*o.tileSize, float64(minY)*o.tileSize)
+	opts.GeoM.Concat(cam.GeoM())
+	screen.DrawImage(o.image, opts)
+}
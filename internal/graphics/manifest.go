@@ -0,0 +1,78 @@
+package graphics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// DefaultManifestKey is the asset key of the asset manifest
+const DefaultManifestKey = "manifest.json"
+
+// SheetEntry is a grid sprite sheet in the manifest
+type SheetEntry struct {
+	Image      string `json:"image"`
+	TileWidth  int    `json:"tileWidth"`
+	TileHeight int    `json:"tileHeight"`
+	Margin     int    `json:"margin"`  // Pixels around the grid
+	Spacing    int    `json:"spacing"` // Pixels between sprites
+
+	// Layer picks one layer of a GIMP .xcf image; without it the visible layers are flattened
+	Layer string `json:"layer"`
+
+	// Sprites names sprites by their index, counted left to right, top to bottom
+	Sprites map[string]int `json:"sprites"`
+}
+
+// Manifest lists the images the game loads: grid sprite sheets and JSON atlases.
+// Paths are slash-separated and relative to the manifest file.
+type Manifest struct {
+	Sheets  []SheetEntry `json:"sheets"`
+	Atlases []string     `json:"atlases"` // TexturePacker or Aseprite JSON files
+
+	dir string
+}
+
+// ParseManifest parses a manifest whose paths are relative to dir, a slash-separated path
+func ParseManifest(data []byte, dir string) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	for i, sheet := range m.Sheets {
+		if sheet.Image == "" {
+			return nil, fmt.Errorf("graphics: manifest sheet %d has no image", i)
+		}
+		if sheet.TileWidth <= 0 || sheet.TileHeight <= 0 {
+			return nil, fmt.Errorf("graphics: manifest sheet %s needs a positive tile size", sheet.Image)
+		}
+		if sheet.Margin < 0 || sheet.Spacing < 0 {
+			return nil, fmt.Errorf("graphics: manifest sheet %s has a negative margin or spacing", sheet.Image)
+		}
+		if sheet.Layer != "" && path.Ext(sheet.Image) != ".xcf" {
+			return nil, fmt.Errorf("graphics: manifest sheet %s has a layer but is not an .xcf image", sheet.Image)
+		}
+	}
+	m.dir = dir
+	return &m, nil
+}
+
+// LoadManifest reads a manifest file
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := ParseManifest(data, filepath.ToSlash(filepath.Dir(path)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Path resolves a path from the manifest against the manifest's directory
+func (m *Manifest) Path(p string) string {
+	return path.Join(m.dir, p)
+}
@@ -0,0 +1,75 @@
+package graphics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "manifest.json")
+	content := `{
+		"sheets": [{"image": "tiles.png", "tileWidth": 16, "tileHeight": 16, "margin": 1, "spacing": 2, "sprites": {"grass": 0, "sand": 3}}],
+		"atlases": ["characters/player.json"]
+	}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := LoadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Sheets) != 1 || m.Sheets[0].Spacing != 2 || m.Sheets[0].Sprites["sand"] != 3 {
+		t.Errorf("Unexpected sheets %+v", m.Sheets)
+	}
+	if got := m.Path(m.Atlases[0]); got != filepath.ToSlash(filepath.Join(dir, "characters", "player.json")) {
+		t.Errorf("Expected atlas paths relative to the manifest, got %s", got)
+	}
+}
+
+func TestParseManifestErrors(t *testing.T) {
+	tests := map[string]string{
+		"invalid json":     `[`,
+		"no image":         `{"sheets": [{"tileWidth": 32, "tileHeight": 32}]}`,
+		"no tile size":     `{"sheets": [{"image": "a.png"}]}`,
+		"negative spacing": `{"sheets": [{"image": "a.png", "tileWidth": 32, "tileHeight": 32, "spacing": -1}]}`,
+		"layer of a png":   `{"sheets": [{"image": "a.png", "tileWidth": 32, "tileHeight": 32, "layer": "背景"}]}`,
+	}
+	for name, data := range tests {
+		if _, err := ParseManifest([]byte(data), "."); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestDefaultManifest(t *testing.T) {
+	m, err := LoadManifest(filepath.Join("..", "..", "assets", DefaultManifestKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sheet := range m.Sheets {
+		if _, err := os.Stat(m.Path(sheet.Image)); err != nil {
+			t.Errorf("Manifest image %s: %v", sheet.Image, err)
+		}
+	}
+}
+
+func TestGridCount(t *testing.T) {
+	tests := []struct {
+		length, size, margin, spacing, want int
+	}{
+		{640, 32, 0, 0, 20},
+		{100, 32, 0, 0, 3},
+		{36, 16, 1, 2, 2}, // 1 + 16 + 2 + 16 + 1
+		{35, 16, 1, 2, 1}, // 第二个精灵放不下
+		{10, 16, 0, 0, 0},
+		{10, 16, 8, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := gridCount(tt.length, tt.size, tt.margin, tt.spacing); got != tt.want {
+			t.Errorf("gridCount(%d, %d, %d, %d) = %d, expected %d", tt.length, tt.size, tt.margin, tt.spacing, got, tt.want)
+		}
+	}
+}
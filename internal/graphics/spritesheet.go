@@ -1,6 +1,7 @@
 package graphics
 
 import (
+	"fmt"
 	"image"
 	_ "image/png"
 	"os"
@@ -8,85 +9,124 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
-// SpriteSheet represents a sprite sheet image
+// SpriteSheet is an image divided into a grid of equally sized sprites.
+// Sprites are SubImages of the sheet: they share its texture, so Ebiten can batch
+// draws of different sprites from the same sheet.
 type SpriteSheet struct {
-	image *ebiten.Image
-	width int
-	height int
-	spriteWidth int
+	image        *ebiten.Image
+	spriteWidth  int
 	spriteHeight int
+	margin       int // Pixels around the grid
+	spacing      int // Pixels between sprites
+	columns      int
+	rows         int
 }
 
-// NewSpriteSheet loads a sprite sheet from a file
+// NewSpriteSheet loads a sprite sheet from a file, with sprites packed without margin or spacing
 func NewSpriteSheet(filePath string, spriteWidth, spriteHeight int) (*SpriteSheet, error) {
-	// Open the file
-	file, err := os.Open(filePath)
+	img, err := loadImage(filePath)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
+	return NewGridSheet(img, spriteWidth, spriteHeight, 0, 0), nil
+}
 
-	// Decode the image
-	img, _, err := image.Decode(file)
+// NewXCFSheet loads a sprite sheet from one layer of a GIMP .xcf file, with sprites packed
+// without margin or spacing. Layer offsets are kept, so the grid lines up with the canvas.
+// An empty layer name uses all visible layers flattened.
+func NewXCFSheet(filePath, layer string, spriteWidth, spriteHeight int) (*SpriteSheet, error) {
+	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
+	img, err := xcfSheetImage(data, layer)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return NewGridSheet(ebiten.NewImageFromImage(img), spriteWidth, spriteHeight, 0, 0), nil
+}
 
-	// Convert to ebiten image
-	ebitenImg := ebiten.NewImageFromImage(img)
-
-	// Create and return the sprite sheet
+// NewGridSheet divides img into sprites of spriteWidth x spriteHeight pixels, with margin
+// pixels around the grid and spacing pixels between sprites
+func NewGridSheet(img *ebiten.Image, spriteWidth, spriteHeight, margin, spacing int) *SpriteSheet {
+	bounds := img.Bounds()
 	return &SpriteSheet{
-		image: ebitenImg,
-		width: img.Bounds().Dx(),
-		height: img.Bounds().Dy(),
-		spriteWidth: spriteWidth,
+		image:        img,
+		spriteWidth:  spriteWidth,
 		spriteHeight: spriteHeight,
-	}, nil
+		margin:       margin,
+		spacing:      spacing,
+		columns:      gridCount(bounds.Dx(), spriteWidth, margin, spacing),
+		rows:         gridCount(bounds.Dy(), spriteHeight, margin, spacing),
+	}
 }
 
-// GetSprite returns a sub-image from the sprite sheet at the specified grid position
-func (ss *SpriteSheet) GetSprite(x, y int) *ebiten.Image {
-	// Calculate the position in pixels
-	px := x * ss.spriteWidth
-	py := y * ss.spriteHeight
+// gridCount returns how many sprites of size fit into length
+func gridCount(length, size, margin, spacing int) int {
+	if size <= 0 {
+		return 0
+	}
+	n := (length - 2*margin + spacing) / (size + spacing)
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// loadImage decodes an image file into an ebiten image. GIMP .xcf files are flattened.
+func loadImage(path string) (*ebiten.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
 
-	// Create options for drawing the sub-image
-	opts := &ebiten.DrawImageOptions{}
-	opts.GeoM.Translate(float64(-px), float64(-py))
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+	return ebiten.NewImageFromImage(img), nil
+}
 
-	// Create a new image for the sprite
-	sprite := ebiten.NewImage(ss.spriteWidth, ss.spriteHeight)
+// Image returns the whole sheet
+func (ss *SpriteSheet) Image() *ebiten.Image {
+	return ss.image
+}
 
-	// Draw the sub-image onto the sprite
-	sprite.DrawImage(ss.image, opts)
+// Len returns the number of sprites in the sheet
+func (ss *SpriteSheet) Len() int {
+	return ss.columns * ss.rows
+}
+
+// SpriteRect returns the area of the sprite at the specified grid position
+func (ss *SpriteSheet) SpriteRect(x, y int) image.Rectangle {
+	origin := ss.image.Bounds().Min
+	px := origin.X + ss.margin + x*(ss.spriteWidth+ss.spacing)
+	py := origin.Y + ss.margin + y*(ss.spriteHeight+ss.spacing)
+	return image.Rect(px, py, px+ss.spriteWidth, py+ss.spriteHeight)
+}
 
-	return sprite
+// GetSprite returns the sprite at the specified grid position.
+// The sprite is a SubImage of the sheet, nothing is copied.
+func (ss *SpriteSheet) GetSprite(x, y int) *ebiten.Image {
+	return ss.image.SubImage(ss.SpriteRect(x, y)).(*ebiten.Image)
 }
 
-// GetSpriteByIndex returns a sub-image from the sprite sheet at the specified index
+// GetSpriteByIndex returns the sprite at the specified index
 // Index goes from left to right, top to bottom
 func (ss *SpriteSheet) GetSpriteByIndex(index int) *ebiten.Image {
-	// Calculate grid position from index
-	spritesPerRow := ss.width / ss.spriteWidth
-	x := index % spritesPerRow
-	y := index / spritesPerRow
-
-	return ss.GetSprite(x, y)
+	if ss.columns == 0 {
+		return nil
+	}
+	return ss.GetSprite(index%ss.columns, index/ss.columns)
 }
 
 // GetSpriteMap returns a map of sprites from the sprite sheet
 // The map keys correspond to the indices in the sprite sheet
 func (ss *SpriteSheet) GetSpriteMap() map[int]*ebiten.Image {
-	spriteMap := make(map[int]*ebiten.Image)
-	
-	spritesPerRow := ss.width / ss.spriteWidth
-	spritesPerColumn := ss.height / ss.spriteHeight
-	totalSprites := spritesPerRow * spritesPerColumn
-	
-	for i := 0; i < totalSprites; i++ {
+	spriteMap := make(map[int]*ebiten.Image, ss.Len())
+	for i := 0; i < ss.Len(); i++ {
 		spriteMap[i] = ss.GetSpriteByIndex(i)
 	}
-	
 	return spriteMap
-}
\ No newline at end of file
+}
@@ -0,0 +1,85 @@
+package graphics
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// TileAtlas packs tile sprites into one image, so a whole chunk is drawn with a single
+// DrawTriangles call
+type TileAtlas struct {
+	image    *ebiten.Image
+	tileSize int
+	regions  map[string]image.Rectangle
+	plain    image.Rectangle // White tile with a black border, tinted for tiles without a sprite
+}
+
+// NewTileAtlas packs sprites of tileSize x tileSize pixels, keyed by tile name.
+// Names sharing a sprite share its region. sprites may be nil.
+func NewTileAtlas(tileSize int, sprites map[string]*ebiten.Image) *TileAtlas {
+	// 按名称排序，打包结果稳定
+	names := make([]string, 0, len(sprites))
+	for name, sprite := range sprites {
+		if sprite != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	slots := make(map[*ebiten.Image]int)
+	var unique []*ebiten.Image
+	for _, name := range names {
+		if _, exists := slots[sprites[name]]; !exists {
+			slots[sprites[name]] = len(unique)
+			unique = append(unique, sprites[name])
+		}
+	}
+
+	// 第0格是纯色方块，其余依次放置精灵
+	count := len(unique) + 1
+	perRow := int(math.Ceil(math.Sqrt(float64(count))))
+	rows := (count + perRow - 1) / perRow
+	atlas := &TileAtlas{
+		image:    ebiten.NewImage(perRow*tileSize, rows*tileSize),
+		tileSize: tileSize,
+		regions:  make(map[string]image.Rectangle, len(names)),
+	}
+	slotRect := func(slot int) image.Rectangle {
+		x, y := (slot%perRow)*tileSize, (slot/perRow)*tileSize
+		return image.Rect(x, y, x+tileSize, y+tileSize)
+	}
+
+	atlas.plain = slotRect(0)
+	plain := atlas.image.SubImage(atlas.plain).(*ebiten.Image)
+	plain.Fill(color.Black)
+	plain.SubImage(atlas.plain.Inset(1)).(*ebiten.Image).Fill(color.White)
+
+	for i, sprite := range unique {
+		rect := slotRect(i + 1)
+		opts := &ebiten.DrawImageOptions{}
+		opts.GeoM.Translate(float64(rect.Min.X), float64(rect.Min.Y))
+		atlas.image.DrawImage(sprite, opts)
+	}
+	for _, name := range names {
+		atlas.regions[name] = slotRect(slots[sprites[name]] + 1)
+	}
+	return atlas
+}
+
+// Image returns the packed image
+func (a *TileAtlas) Image() *ebiten.Image {
+	return a.image
+}
+
+// Region returns where a tile's sprite is in the atlas. Tiles without a sprite get the
+// plain tile, and tinted is true: the tile's color should be used as the vertex color.
+func (a *TileAtlas) Region(name string) (rect image.Rectangle, tinted bool) {
+	if rect, exists := a.regions[name]; exists {
+		return rect, false
+	}
+	return a.plain, true
+}
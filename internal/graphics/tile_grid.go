@@ -0,0 +1,138 @@
+package graphics
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/wubinrui111/2d-game/internal/grid"
+)
+
+// ChunkSize is the width and height of a render chunk in tiles
+const ChunkSize = 16
+
+// Tile is what is drawn in one grid cell
+type Tile struct {
+	Name  string     // Sprite name in the atlas, tiles without a sprite use the plain tile
+	Color color.RGBA // Tint of the plain tile
+
+	// Tint multiplies the sprite or the plain tile's color, such as by the biome of
+	// grass; zero leaves them as they are
+	Tint color.RGBA
+}
+
+// ChunkKey identifies a chunk by its position in chunks
+type ChunkKey struct {
+	X, Y int
+}
+
+// chunk is a ChunkSize x ChunkSize square of tiles
+type chunk struct {
+	tiles map[[2]int]Tile // Keyed by cell position in the world
+	dirty bool            // Whether the cached image is out of date
+}
+
+// TileGrid stores tiles by cell and groups them into chunks, so that finding the tiles
+// in view costs the same however large the world is
+type TileGrid struct {
+	chunks map[ChunkKey]*chunk
+	count  int
+}
+
+// NewTileGrid creates an empty grid
+func NewTileGrid() *TileGrid {
+	return &TileGrid{chunks: make(map[ChunkKey]*chunk)}
+}
+
+// ChunkOf returns the chunk containing a cell
+func ChunkOf(cellX, cellY int) ChunkKey {
+	return ChunkKey{X: grid.FloorDiv(cellX, ChunkSize), Y: grid.FloorDiv(cellY, ChunkSize)}
+}
+
+// Set puts a tile into a cell, replacing the tile there
+func (g *TileGrid) Set(cellX, cellY int, tile Tile) {
+	key := ChunkOf(cellX, cellY)
+	c := g.chunks[key]
+	if c == nil {
+		c = &chunk{tiles: make(map[[2]int]Tile)}
+		g.chunks[key] = c
+	}
+	cell := [2]int{cellX, cellY}
+	old, exists := c.tiles[cell]
+	if exists && old == tile {
+		return
+	}
+	if !exists {
+		g.count++
+	}
+	c.tiles[cell] = tile
+	c.dirty = true
+}
+
+// Remove empties a cell
+func (g *TileGrid) Remove(cellX, cellY int) {
+	key := ChunkOf(cellX, cellY)
+	c := g.chunks[key]
+	if c == nil {
+		return
+	}
+	if _, exists := c.tiles[[2]int{cellX, cellY}]; !exists {
+		return
+	}
+	delete(c.tiles, [2]int{cellX, cellY})
+	g.count--
+	c.dirty = true
+}
+
+// At returns the tile in a cell
+func (g *TileGrid) At(cellX, cellY int) (Tile, bool) {
+	c := g.chunks[ChunkOf(cellX, cellY)]
+	if c == nil {
+		return Tile{}, false
+	}
+	tile, exists := c.tiles[[2]int{cellX, cellY}]
+	return tile, exists
+}
+
+// Len returns the number of tiles in the grid
+func (g *TileGrid) Len() int {
+	return g.count
+}
+
+// VisibleChunks returns the chunks with tiles that overlap the world rectangle (x, y, w, h)
+// for cells of tileSize world pixels. Only the chunks under the rectangle are looked up.
+func (g *TileGrid) VisibleChunks(x, y, w, h, tileSize float64) []ChunkKey {
+	chunkPixels := tileSize * ChunkSize
+	minX := int(math.Floor(x / chunkPixels))
+	minY := int(math.Floor(y / chunkPixels))
+	maxX := int(math.Floor((x + w) / chunkPixels))
+	maxY := int(math.Floor((y + h) / chunkPixels))
+
+	var keys []ChunkKey
+	for cy := minY; cy <= maxY; cy++ {
+		for cx := minX; cx <= maxX; cx++ {
+			key := ChunkKey{X: cx, Y: cy}
+			if c := g.chunks[key]; c != nil && len(c.tiles) > 0 {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// tilesOf returns the tiles of a chunk keyed by cell, nil if the chunk is empty
+func (g *TileGrid) tilesOf(key ChunkKey) map[[2]int]Tile {
+	if c := g.chunks[key]; c != nil {
+		return c.tiles
+	}
+	return nil
+}
+
+// takeDirty reports whether a chunk changed since the last call, and marks it clean
+func (g *TileGrid) takeDirty(key ChunkKey) bool {
+	c := g.chunks[key]
+	if c == nil || !c.dirty {
+		return false
+	}
+	c.dirty = false
+	return true
+}
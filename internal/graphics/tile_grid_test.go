@@ -0,0 +1,120 @@
+package graphics
+
+import (
+	"fmt"
+	"image/color"
+	"testing"
+)
+
+func TestChunkOf(t *testing.T) {
+	tests := []struct {
+		x, y int
+		want ChunkKey
+	}{
+		{0, 0, ChunkKey{0, 0}},
+		{ChunkSize - 1, ChunkSize, ChunkKey{0, 1}},
+		{-1, -ChunkSize, ChunkKey{-1, -1}},
+		{-ChunkSize - 1, 3, ChunkKey{-2, 0}},
+	}
+	for _, tt := range tests {
+		if got := ChunkOf(tt.x, tt.y); got != tt.want {
+			t.Errorf("ChunkOf(%d, %d) = %v, expected %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestTileGridSetRemove(t *testing.T) {
+	g := NewTileGrid()
+	stone := Tile{Name: "stone", Color: color.RGBA{128, 128, 128, 255}}
+
+	g.Set(3, -2, stone)
+	g.Set(3, -2, stone)
+	if g.Len() != 1 {
+		t.Errorf("Expected 1 tile, got %d", g.Len())
+	}
+	if tile, exists := g.At(3, -2); !exists || tile != stone {
+		t.Errorf("Expected stone at (3, -2), got %v %v", tile, exists)
+	}
+
+	g.Remove(3, -2)
+	g.Remove(3, -2)
+	if g.Len() != 0 {
+		t.Errorf("Expected no tiles, got %d", g.Len())
+	}
+	if _, exists := g.At(3, -2); exists {
+		t.Error("Expected the tile to be removed")
+	}
+}
+
+func TestTileGridDirty(t *testing.T) {
+	g := NewTileGrid()
+	key := ChunkOf(1, 1)
+
+	g.Set(1, 1, Tile{Name: "dirt"})
+	if !g.takeDirty(key) {
+		t.Error("Expected a new tile to mark its chunk dirty")
+	}
+	if g.takeDirty(key) {
+		t.Error("Expected takeDirty to mark the chunk clean")
+	}
+
+	// 设置相同的方块不需要重绘
+	g.Set(1, 1, Tile{Name: "dirt"})
+	if g.takeDirty(key) {
+		t.Error("Expected an unchanged tile to keep the chunk clean")
+	}
+
+	g.Set(ChunkSize, 0, Tile{Name: "dirt"})
+	if g.takeDirty(key) {
+		t.Error("Expected a tile in another chunk to keep this chunk clean")
+	}
+
+	g.Remove(1, 1)
+	if !g.takeDirty(key) {
+		t.Error("Expected removing a tile to mark its chunk dirty")
+	}
+}
+
+func TestVisibleChunks(t *testing.T) {
+	g := NewTileGrid()
+	g.Set(0, 0, Tile{Name: "a"})                 // 区块 (0, 0)
+	g.Set(ChunkSize*2, 0, Tile{Name: "b"})       // 区块 (2, 0)，视野外
+	g.Set(-1, -1, Tile{Name: "c"})               // 区块 (-1, -1)
+	g.Set(ChunkSize, ChunkSize, Tile{Name: "d"}) // 区块 (1, 1)
+	g.Remove(ChunkSize, ChunkSize)               // 区块 (1, 1) 变空
+
+	// 32像素的方块，一个区块512像素；视野覆盖区块 -1..1
+	keys := g.VisibleChunks(-100, -100, 800, 600, 32)
+	want := map[ChunkKey]bool{{0, 0}: true, {-1, -1}: true}
+	if len(keys) != len(want) {
+		t.Fatalf("Expected chunks %v, got %v", want, keys)
+	}
+	for _, key := range keys {
+		if !want[key] {
+			t.Errorf("Unexpected visible chunk %v", key)
+		}
+	}
+}
+
+// fillGrid fills a square world of size x size tiles
+func fillGrid(size int) *TileGrid {
+	g := NewTileGrid()
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			g.Set(x, y, Tile{Name: "stone"})
+		}
+	}
+	return g
+}
+
+// BenchmarkVisibleChunks shows that finding the chunks in view does not depend on the world size
+func BenchmarkVisibleChunks(b *testing.B) {
+	for _, size := range []int{64, 256, 1024} {
+		g := fillGrid(size)
+		b.Run(fmt.Sprintf("world=%dx%d", size, size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				g.VisibleChunks(1000, 1000, 800, 600, 32)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package graphics
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/wubinrui111/2d-game/internal/camera"
+)
+
+// chunkEvictFrames is how many frames a chunk can be off screen before its cached image is freed
+const chunkEvictFrames = 300
+
+// RenderStats describes the work of the last TileRenderer.Draw
+type RenderStats struct {
+	VisibleChunks  int // Chunks with tiles in view, each drawn with one DrawImage
+	RenderedChunks int // Chunks whose cached image was rebuilt because they changed
+	CachedChunks   int // Chunk images kept in memory
+}
+
+// cachedChunk is the rendered image of a chunk
+type cachedChunk struct {
+	image     *ebiten.Image
+	lastFrame int // Frame the chunk was last in view
+}
+
+// TileRenderer draws the tiles of a TileGrid. Each chunk is rendered once into a cached
+// image with one DrawTriangles call from the atlas, and rendered again only when it
+// changes. Only chunks in the camera's view are drawn, so the cost of a frame depends
+// on the view, not on the size of the world.
+type TileRenderer struct {
+	grid     *TileGrid
+	atlas    *TileAtlas
+	tileSize float64
+
+	chunks map[ChunkKey]*cachedChunk
+	frame  int
+	stats  RenderStats
+
+	// 复用的顶点缓冲区
+	vertices []ebiten.Vertex
+	indices  []uint16
+}
+
+// NewTileRenderer creates a renderer for the tiles of grid, with cells of tileSize world pixels
+func NewTileRenderer(grid *TileGrid, atlas *TileAtlas, tileSize float64) *TileRenderer {
+	return &TileRenderer{
+		grid:     grid,
+		atlas:    atlas,
+		tileSize: tileSize,
+		chunks:   make(map[ChunkKey]*cachedChunk),
+	}
+}
+
+// SetAtlas replaces the atlas and renders every chunk again
+func (r *TileRenderer) SetAtlas(atlas *TileAtlas) {
+	r.atlas = atlas
+	for key, cached := range r.chunks {
+		cached.image.Deallocate()
+		delete(r.chunks, key)
+	}
+}
+
+// Stats returns the work of the last Draw
+func (r *TileRenderer) Stats() RenderStats {
+	return r.stats
+}
+
+// Draw draws the tiles in the camera's view onto screen
+func (r *TileRenderer) Draw(screen *ebiten.Image, cam *camera.Camera) {
+	r.frame++
+	r.stats = RenderStats{}
+
+	view := cam.ViewRect()
+	camGeoM := cam.GeoM()
+	chunkPixels := r.tileSize * ChunkSize
+	for _, key := range r.grid.VisibleChunks(view.X, view.Y, view.W, view.H, r.tileSize) {
+		cached := r.chunks[key]
+		dirty := r.grid.takeDirty(key)
+		if cached == nil {
+			size := int(chunkPixels)
+			cached = &cachedChunk{image: ebiten.NewImage(size, size)}
+			r.chunks[key] = cached
+			dirty = true
+		}
+		if dirty {
+			r.renderChunk(cached.image, key)
+			r.stats.RenderedChunks++
+		}
+		cached.lastFrame = r.frame
+
+		opts := &ebiten.DrawImageOptions{}
+		opts.GeoM.Translate(float64(key.X)*chunkPixels, float64(key.Y)*chunkPixels)
+		opts.GeoM.Concat(camGeoM)
+		screen.DrawImage(cached.image, opts)
+		r.stats.VisibleChunks++
+	}
+
+	// 释放长时间不在视野内的区块图像，内存占用只与最近看到的区域有关
+	for key, cached := range r.chunks {
+		if r.frame-cached.lastFrame > chunkEvictFrames {
+			cached.image.Deallocate()
+			delete(r.chunks, key)
+		}
+	}
+	r.stats.CachedChunks = len(r.chunks)
+}
+
+// renderChunk draws all tiles of a chunk into its image with one DrawTriangles call
+func (r *TileRenderer) renderChunk(dst *ebiten.Image, key ChunkKey) {
+	dst.Clear()
+	tiles := r.grid.tilesOf(key)
+	if len(tiles) == 0 {
+		return
+	}
+
+	r.vertices = r.vertices[:0]
+	r.indices = r.indices[:0]
+	originX, originY := key.X*ChunkSize, key.Y*ChunkSize
+	for cell, tile := range tiles {
+		x := float32(float64(cell[0]-originX) * r.tileSize)
+		y := float32(float64(cell[1]-originY) * r.tileSize)
+		r.appendQuad(x, y, tile)
+	}
+
+	dst.DrawTriangles(r.vertices, r.indices, r.atlas.Image(), &ebiten.DrawTrianglesOptions{})
+}
+
+// appendQuad adds the two triangles of a tile at (x, y) in chunk pixels
+func (r *TileRenderer) appendQuad(x, y float32, tile Tile) {
+	src, tinted := r.atlas.Region(tile.Name)
+	cr, cg, cb, ca := float32(1), float32(1), float32(1), float32(1)
+	if tinted {
+		cr = float32(tile.Color.R) / 255
+		cg = float32(tile.Color.G) / 255
+		cb = float32(tile.Color.B) / 255
+		ca = float32(tile.Color.A) / 255
+	}
+	if tile.Tint != (color.RGBA{}) {
+		cr *= float32(tile.Tint.R) / 255
+		cg *= float32(tile.Tint.G) / 255
+		cb *= float32(tile.Tint.B) / 255
+		ca *= float32(tile.Tint.A) / 255
+	}
+
+	size := float32(r.tileSize)
+	base := uint16(len(r.vertices))
+	corners := [4]struct {
+		dx, dy float32
+		sx, sy int
+	}{
+		{0, 0, src.Min.X, src.Min.Y},
+		{size, 0, src.Max.X, src.Min.Y},
+		{0, size, src.Min.X, src.Max.Y},
+		{size, size, src.Max.X, src.Max.Y},
+	}
+	for _, c := range corners {
+		r.vertices = append(r.vertices, ebiten.Vertex{
+			DstX: x + c.dx, DstY: y + c.dy,
+			SrcX: float32(c.sx), SrcY: float32(c.sy),
+			ColorR: cr, ColorG: cg, ColorB: cb, ColorA: ca,
+		})
+	}
+	r.indices = append(r.indices, base, base+1, base+2, base+1, base+3, base+2)
+}
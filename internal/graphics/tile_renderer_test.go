@@ -0,0 +1,94 @@
+package graphics
+
+import (
+	"fmt"
+	"image/color"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/wubinrui111/2d-game/internal/camera"
+)
+
+// newTestRenderer creates a renderer for a square world of size x size plain tiles
+func newTestRenderer(size int) (*TileRenderer, *TileGrid) {
+	g := NewTileGrid()
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			g.Set(x, y, Tile{Color: color.RGBA{128, 128, 128, 255}})
+		}
+	}
+	return NewTileRenderer(g, NewTileAtlas(32, nil), 32), g
+}
+
+func TestTileRendererCachesChunks(t *testing.T) {
+	r, g := newTestRenderer(64)
+	screen := ebiten.NewImage(800, 600)
+	cam := camera.New(800, 600)
+	cam.CenterOn(1000, 1000)
+
+	r.Draw(screen, cam)
+	first := r.Stats()
+	if first.VisibleChunks == 0 || first.RenderedChunks != first.VisibleChunks {
+		t.Errorf("Expected every visible chunk to be rendered on the first draw, got %+v", first)
+	}
+
+	r.Draw(screen, cam)
+	if r.Stats().RenderedChunks != 0 {
+		t.Errorf("Expected unchanged chunks to be reused, got %+v", r.Stats())
+	}
+
+	// 只重绘修改过的区块
+	g.Remove(31, 31)
+	r.Draw(screen, cam)
+	if r.Stats().RenderedChunks != 1 {
+		t.Errorf("Expected one chunk to be rendered again, got %+v", r.Stats())
+	}
+}
+
+func TestTileRendererCullsByView(t *testing.T) {
+	small, _ := newTestRenderer(64)
+	large, _ := newTestRenderer(512)
+	screen := ebiten.NewImage(800, 600)
+	cam := camera.New(800, 600)
+	cam.CenterOn(1000, 1000)
+
+	small.Draw(screen, cam)
+	large.Draw(screen, cam)
+	if small.Stats().VisibleChunks != large.Stats().VisibleChunks {
+		t.Errorf("Expected the same chunks in view, got %d and %d", small.Stats().VisibleChunks, large.Stats().VisibleChunks)
+	}
+}
+
+func TestTileAtlasRegions(t *testing.T) {
+	sprite := ebiten.NewImage(32, 32)
+	atlas := NewTileAtlas(32, map[string]*ebiten.Image{"stone": sprite, "SmallBlock": sprite})
+
+	stone, tinted := atlas.Region("stone")
+	if tinted {
+		t.Error("Expected a sprite region not to be tinted")
+	}
+	if small, _ := atlas.Region("SmallBlock"); small != stone {
+		t.Errorf("Expected names sharing a sprite to share a region, got %v and %v", small, stone)
+	}
+	if plain, tinted := atlas.Region("unknown"); !tinted || plain == stone {
+		t.Errorf("Expected unknown tiles to use the tinted plain tile, got %v %v", plain, tinted)
+	}
+}
+
+// BenchmarkTileRendererDraw draws the same view of worlds of growing size.
+// The time per frame stays the same, as only the chunks in view are drawn.
+func BenchmarkTileRendererDraw(b *testing.B) {
+	for _, size := range []int{64, 256, 1024} {
+		r, _ := newTestRenderer(size)
+		screen := ebiten.NewImage(800, 600)
+		cam := camera.New(800, 600)
+		cam.CenterOn(1000, 1000)
+		r.Draw(screen, cam)
+
+		b.Run(fmt.Sprintf("world=%dx%d", size, size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				r.Draw(screen, cam)
+			}
+		})
+	}
+}
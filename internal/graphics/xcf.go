@@ -0,0 +1,691 @@
+package graphics
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+	"strings"
+)
+
+// xcfMagic starts every GIMP XCF file, followed by the version such as "v011"
+const xcfMagic = "gimp xcf "
+
+// xcfTileSize is the edge length of the tiles XCF stores pixels in
+const xcfTileSize = 64
+
+// Limits on the size of what is decoded, checked before any pixels are allocated
+const (
+	// xcfMaxSize is the largest width or height of a canvas or layer, the same as GIMP's
+	xcfMaxSize = 524288
+
+	// xcfMaxPixels is the most pixels a canvas or layer may have
+	xcfMaxPixels = 1 << 25
+
+	// xcfMaxLayerScale is how many times the canvas's width or height a layer may be
+	xcfMaxLayerScale = 4
+)
+
+// XCF property types used by the decoder
+const (
+	xcfPropEnd          = 0
+	xcfPropColormap     = 1
+	xcfPropOpacity      = 6
+	xcfPropVisible      = 8
+	xcfPropOffsets      = 15
+	xcfPropCompression  = 17
+	xcfPropGroupItem    = 29
+	xcfPropItemPath     = 30
+	xcfPropFloatOpacity = 33
+)
+
+// XCF tile compressions
+const (
+	xcfCompressNone = 0
+	xcfCompressRLE  = 1
+	xcfCompressZlib = 2
+)
+
+// XCFLayer is one layer of a GIMP image
+type XCFLayer struct {
+	Name string
+
+	// Image holds the layer's pixels; its bounds are the layer's position on the canvas
+	Image *image.NRGBA
+
+	Opacity float64 // 0 to 1
+	Visible bool
+
+	// Group marks a layer group. Its own pixels are ignored; its children follow it
+	// in XCFImage.Layers.
+	Group bool
+
+	path []uint32 // Position in the layer tree, one index per level
+}
+
+// XCFImage is a decoded GIMP image: the canvas size and the layers, top-most first.
+// Layer masks, channels and layer modes other than normal are not supported; all
+// layers are composited as if in normal mode.
+type XCFImage struct {
+	Width, Height int
+	Layers        []*XCFLayer
+}
+
+func init() {
+	// 注册后 image.Decode 可以直接读取 .xcf 文件（得到合并后的图像）
+	image.RegisterFormat("xcf", xcfMagic, decodeXCFImage, decodeXCFConfig)
+}
+
+// DecodeXCF decodes a GIMP .xcf file with all of its layers. Layers may be uncompressed,
+// RLE or zlib compressed, RGB, grayscale or indexed, with 8 or 16 bit integer precision.
+func DecodeXCF(r io.Reader) (*XCFImage, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	d, err := newXCFDecoder(data)
+	if err != nil {
+		return nil, err
+	}
+	return d.decode()
+}
+
+// decodeXCFImage decodes an XCF file into its flattened image, for image.Decode
+func decodeXCFImage(r io.Reader) (image.Image, error) {
+	x, err := DecodeXCF(r)
+	if err != nil {
+		return nil, err
+	}
+	return x.Flatten(), nil
+}
+
+// decodeXCFConfig reads the canvas size of an XCF file, for image.DecodeConfig
+func decodeXCFConfig(r io.Reader) (image.Config, error) {
+	header := make([]byte, 14+8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return image.Config{}, err
+	}
+	if !strings.HasPrefix(string(header), xcfMagic) {
+		return image.Config{}, errors.New("graphics: not an xcf file")
+	}
+	return image.Config{
+		ColorModel: color.NRGBAModel,
+		Width:      int(binary.BigEndian.Uint32(header[14:])),
+		Height:     int(binary.BigEndian.Uint32(header[18:])),
+	}, nil
+}
+
+// Layer returns the layer named name
+func (x *XCFImage) Layer(name string) (*XCFLayer, bool) {
+	for _, layer := range x.Layers {
+		if layer.Name == name {
+			return layer, true
+		}
+	}
+	return nil, false
+}
+
+// LayerCanvas returns the pixels of the layer named name on a canvas-sized image,
+// ignoring its visibility and opacity, so a hidden layer can serve as a sprite sheet
+func (x *XCFImage) LayerCanvas(name string) (*image.NRGBA, error) {
+	layer, exists := x.Layer(name)
+	if !exists {
+		return nil, fmt.Errorf("graphics: xcf has no layer %q", name)
+	}
+	canvas := image.NewNRGBA(image.Rect(0, 0, x.Width, x.Height))
+	draw.Draw(canvas, layer.Image.Bounds(), layer.Image, layer.Image.Bounds().Min, draw.Src)
+	return canvas, nil
+}
+
+// xcfSheetImage decodes XCF data into the image of a sprite sheet: the layer named
+// layer on a canvas-sized image, or the flattened image when layer is empty
+func xcfSheetImage(data []byte, layer string) (image.Image, error) {
+	x, err := DecodeXCF(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if layer == "" {
+		return x.Flatten(), nil
+	}
+	return x.LayerCanvas(layer)
+}
+
+// Flatten composites the visible layers onto a transparent canvas, as GIMP exports it
+// when all layers use normal mode. Hidden groups hide their children and group opacity
+// applies to each child.
+func (x *XCFImage) Flatten() *image.RGBA {
+	canvas := image.NewRGBA(image.Rect(0, 0, x.Width, x.Height))
+
+	// 从最底层开始向上绘制
+	for i := len(x.Layers) - 1; i >= 0; i-- {
+		layer := x.Layers[i]
+		if layer.Group {
+			continue
+		}
+		visible, opacity := x.effective(layer)
+		if !visible || opacity <= 0 {
+			continue
+		}
+		mask := image.NewUniform(color.Alpha16{A: uint16(math.Round(opacity * 0xffff))})
+		bounds := layer.Image.Bounds()
+		draw.DrawMask(canvas, bounds, layer.Image, bounds.Min, mask, image.Point{}, draw.Over)
+	}
+	return canvas
+}
+
+// effective returns the visibility and opacity of a layer combined with its groups
+func (x *XCFImage) effective(layer *XCFLayer) (bool, float64) {
+	visible, opacity := layer.Visible, layer.Opacity
+	for depth := len(layer.path) - 1; depth > 0; depth-- {
+		parent := x.itemAt(layer.path[:depth])
+		if parent == nil {
+			break
+		}
+		visible = visible && parent.Visible
+		opacity *= parent.Opacity
+	}
+	return visible, opacity
+}
+
+// itemAt returns the layer at a position in the layer tree
+func (x *XCFImage) itemAt(path []uint32) *XCFLayer {
+	for _, layer := range x.Layers {
+		if len(layer.path) != len(path) {
+			continue
+		}
+		match := true
+		for i := range path {
+			if layer.path[i] != path[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return layer
+		}
+	}
+	return nil
+}
+
+// xcfDecoder reads an XCF file held in memory. Offsets in the file are absolute.
+type xcfDecoder struct {
+	data []byte
+	pos  int
+	err  error
+
+	version     int
+	width       int // Canvas size
+	height      int
+	wide        bool // Version 11 and later store 64 bit offsets
+	compression int
+	component   int  // Bytes per color component
+	linear      bool // Components are linear light rather than sRGB
+	colormap    []color.NRGBA
+}
+
+// newXCFDecoder reads the header of an XCF file
+func newXCFDecoder(data []byte) (*xcfDecoder, error) {
+	if len(data) < 14 || string(data[:len(xcfMagic)]) != xcfMagic || data[13] != 0 {
+		return nil, errors.New("graphics: not an xcf file")
+	}
+	version := 0
+	switch v := string(data[9:13]); {
+	case v == "file":
+		version = 0
+	case v[0] == 'v':
+		if _, err := fmt.Sscanf(v[1:], "%03d", &version); err != nil {
+			return nil, fmt.Errorf("graphics: xcf has invalid version %q", v)
+		}
+	default:
+		return nil, fmt.Errorf("graphics: xcf has invalid version %q", v)
+	}
+	return &xcfDecoder{data: data, pos: 14, wide: version >= 11, component: 1, version: version}, nil
+}
+
+// decode reads the image header, properties and layers
+func (d *xcfDecoder) decode() (*XCFImage, error) {
+	x := &XCFImage{Width: int(d.u32()), Height: int(d.u32())}
+	d.u32() // 基本颜色类型，每个图层另有自己的类型
+	if d.err != nil {
+		return nil, d.err
+	}
+	if err := checkXCFSize(x.Width, x.Height, xcfMaxSize, xcfMaxSize); err != nil {
+		return nil, fmt.Errorf("graphics: xcf canvas %w", err)
+	}
+	d.width, d.height = x.Width, x.Height
+	if d.version >= 4 {
+		if err := d.setPrecision(d.u32()); err != nil {
+			return nil, err
+		}
+	}
+
+	d.readProperties(func(kind uint32, payload []byte) {
+		switch kind {
+		case xcfPropCompression:
+			if len(payload) > 0 {
+				d.compression = int(payload[0])
+			}
+		case xcfPropColormap:
+			d.colormap = parseXCFColormap(payload)
+		}
+	})
+	if d.compression > xcfCompressZlib {
+		return nil, fmt.Errorf("graphics: xcf compression %d is not supported", d.compression)
+	}
+
+	var layers []int
+	for offset := d.ptr(); offset != 0 && d.err == nil; offset = d.ptr() {
+		layers = append(layers, offset)
+	}
+	for _, offset := range layers {
+		layer, err := d.layer(offset)
+		if err != nil {
+			return nil, err
+		}
+		x.Layers = append(x.Layers, layer)
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return x, nil
+}
+
+// setPrecision applies the precision field; versions 4 to 6 use older codes
+func (d *xcfDecoder) setPrecision(precision uint32) error {
+	if d.version < 7 {
+		switch precision {
+		case 0:
+			d.component = 1
+		case 1:
+			d.component = 2
+		default:
+			return fmt.Errorf("graphics: xcf precision %d is not supported", precision)
+		}
+		return nil
+	}
+
+	// 线性或感知（gamma）的 8/16 位整数
+	switch precision {
+	case 100, 150:
+		d.component = 1
+	case 200, 250:
+		d.component = 2
+	default:
+		return fmt.Errorf("graphics: xcf precision %d is not supported, only 8 and 16 bit integer", precision)
+	}
+	d.linear = precision == 100 || precision == 200
+	return nil
+}
+
+// layer reads the layer at offset
+func (d *xcfDecoder) layer(offset int) (*XCFLayer, error) {
+	d.seek(offset)
+	width, height, kind := int(d.u32()), int(d.u32()), d.u32()
+	layer := &XCFLayer{Name: d.str(), Opacity: 1}
+
+	x, y := 0, 0
+	d.readProperties(func(prop uint32, payload []byte) {
+		switch prop {
+		case xcfPropOpacity:
+			if len(payload) >= 4 {
+				layer.Opacity = float64(binary.BigEndian.Uint32(payload)) / 255
+			}
+		case xcfPropFloatOpacity:
+			if len(payload) >= 4 {
+				layer.Opacity = float64(math.Float32frombits(binary.BigEndian.Uint32(payload)))
+			}
+		case xcfPropVisible:
+			if len(payload) >= 4 {
+				layer.Visible = binary.BigEndian.Uint32(payload) != 0
+			}
+		case xcfPropOffsets:
+			if len(payload) >= 8 {
+				x = int(int32(binary.BigEndian.Uint32(payload)))
+				y = int(int32(binary.BigEndian.Uint32(payload[4:])))
+			}
+		case xcfPropGroupItem:
+			layer.Group = true
+		case xcfPropItemPath:
+			for i := 0; i+4 <= len(payload); i += 4 {
+				layer.path = append(layer.path, binary.BigEndian.Uint32(payload[i:]))
+			}
+		}
+	})
+	hierarchy := d.ptr()
+	if d.err != nil {
+		return nil, d.err
+	}
+	if kind > 5 {
+		return nil, fmt.Errorf("graphics: xcf layer %q has unknown type %d", layer.Name, kind)
+	}
+	// 图层可以超出画布，但不会比画布大得多
+	maxWidth, maxHeight := min(xcfMaxSize, d.width*xcfMaxLayerScale), min(xcfMaxSize, d.height*xcfMaxLayerScale)
+	if err := checkXCFSize(width, height, maxWidth, maxHeight); err != nil {
+		return nil, fmt.Errorf("graphics: xcf layer %q %w", layer.Name, err)
+	}
+
+	pixels, err := d.hierarchy(hierarchy, width, height, xcfChannels[kind])
+	if err != nil {
+		return nil, fmt.Errorf("graphics: xcf layer %q: %w", layer.Name, err)
+	}
+	layer.Image = image.NewNRGBA(image.Rect(x, y, x+width, y+height))
+	d.convert(layer.Image, pixels, kind)
+	return layer, nil
+}
+
+// checkXCFSize returns an error if a size is empty or larger than maxWidth x maxHeight
+// or xcfMaxPixels
+func checkXCFSize(width, height, maxWidth, maxHeight int) error {
+	switch {
+	case width <= 0 || height <= 0:
+		return fmt.Errorf("is empty (%dx%d)", width, height)
+	case width > maxWidth || height > maxHeight || width*height > xcfMaxPixels:
+		return fmt.Errorf("is too large (%dx%d)", width, height)
+	}
+	return nil
+}
+
+// xcfChannels is the number of channels of each layer type:
+// RGB, RGBA, gray, gray with alpha, indexed, indexed with alpha
+var xcfChannels = [6]int{3, 4, 1, 2, 1, 2}
+
+// hierarchy reads the full-size level of a layer's pixels as interleaved bytes
+func (d *xcfDecoder) hierarchy(offset, width, height, channels int) ([]byte, error) {
+	d.seek(offset)
+	d.u32()
+	d.u32()
+	bpp := int(d.u32())
+	level := d.ptr()
+	if d.err != nil {
+		return nil, d.err
+	}
+	if bpp != channels*d.component {
+		return nil, fmt.Errorf("%d bytes per pixel, expected %d", bpp, channels*d.component)
+	}
+
+	// 只读取第一层（原始尺寸），其余层级是 GIMP 不再使用的缩小版本
+	d.seek(level)
+	if w, h := int(d.u32()), int(d.u32()); w != width || h != height {
+		return nil, fmt.Errorf("level is %dx%d, expected %dx%d", w, h, width, height)
+	}
+	columns := (width + xcfTileSize - 1) / xcfTileSize
+	rows := (height + xcfTileSize - 1) / xcfTileSize
+
+	// 截断的文件放不下所有图块的指针，在分配像素之前拒绝
+	ptrSize := 4
+	if d.wide {
+		ptrSize = 8
+	}
+	if columns*rows*ptrSize > len(d.data)-d.pos {
+		return nil, fmt.Errorf("%d tiles do not fit in the file: %w", columns*rows, io.ErrUnexpectedEOF)
+	}
+	pixels := make([]byte, width*height*bpp)
+	for t := 0; t < columns*rows; t++ {
+		tile := d.ptr()
+		if d.err != nil {
+			return nil, d.err
+		}
+		if tile == 0 {
+			return nil, fmt.Errorf("missing tile %d of %d", t, columns*rows)
+		}
+
+		tx, ty := t%columns*xcfTileSize, t/columns*xcfTileSize
+		tw, th := min(xcfTileSize, width-tx), min(xcfTileSize, height-ty)
+		data, err := d.tile(tile, tw*th, bpp)
+		if err != nil {
+			return nil, fmt.Errorf("tile %d: %w", t, err)
+		}
+		for row := 0; row < th; row++ {
+			start := ((ty+row)*width + tx) * bpp
+			copy(pixels[start:start+tw*bpp], data[row*tw*bpp:])
+		}
+	}
+	return pixels, nil
+}
+
+// tile decodes the n pixels of a tile at offset into interleaved bytes
+func (d *xcfDecoder) tile(offset, n, bpp int) ([]byte, error) {
+	if offset < 0 || offset > len(d.data) {
+		return nil, errors.New("offset outside the file")
+	}
+	src := d.data[offset:]
+	out := make([]byte, n*bpp)
+
+	switch d.compression {
+	case xcfCompressNone:
+		if len(src) < len(out) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		copy(out, src)
+	case xcfCompressRLE:
+		// 每个字节通道（按顺序）分别做游程编码
+		for channel := 0; channel < bpp; channel++ {
+			read, err := decodeXCFRLE(src, out[channel:], n, bpp)
+			if err != nil {
+				return nil, err
+			}
+			src = src[read:]
+		}
+	case xcfCompressZlib:
+		zr, err := zlib.NewReader(bytes.NewReader(src))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		if _, err := io.ReadFull(zr, out); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// decodeXCFRLE decodes one RLE-compressed channel of n values into out, one value every
+// stride bytes, and returns how many bytes of src it used
+func decodeXCFRLE(src, out []byte, n, stride int) (int, error) {
+	pos, i := 0, 0
+	next := func() (byte, bool) {
+		if pos >= len(src) {
+			return 0, false
+		}
+		pos++
+		return src[pos-1], true
+	}
+
+	for i < n {
+		op, ok := next()
+		if !ok {
+			return 0, io.ErrUnexpectedEOF
+		}
+
+		// 操作码：0-126 短重复，127 长重复，128 长原样，129-255 短原样
+		count, repeat := 0, false
+		switch {
+		case op <= 126:
+			count, repeat = int(op)+1, true
+		case op == 127, op == 128:
+			hi, ok1 := next()
+			lo, ok2 := next()
+			if !ok1 || !ok2 {
+				return 0, io.ErrUnexpectedEOF
+			}
+			count, repeat = int(hi)<<8|int(lo), op == 127
+		default:
+			count = 256 - int(op)
+		}
+		if i+count > n {
+			return 0, errors.New("rle run overflows the tile")
+		}
+
+		if repeat {
+			value, ok := next()
+			if !ok {
+				return 0, io.ErrUnexpectedEOF
+			}
+			for ; count > 0; count-- {
+				out[i*stride] = value
+				i++
+			}
+			continue
+		}
+		if pos+count > len(src) {
+			return 0, io.ErrUnexpectedEOF
+		}
+		for _, value := range src[pos : pos+count] {
+			out[i*stride] = value
+			i++
+		}
+		pos += count
+	}
+	return pos, nil
+}
+
+// convert turns interleaved pixels of a layer type into NRGBA
+func (d *xcfDecoder) convert(img *image.NRGBA, pixels []byte, kind uint32) {
+	channels := xcfChannels[kind]
+	stride := channels * d.component
+	for p := 0; p*stride < len(pixels); p++ {
+		px := pixels[p*stride:]
+		c := color.NRGBA{A: 255}
+		switch kind {
+		case 0, 1: // RGB(A)
+			c.R, c.G, c.B = d.value(px, 0), d.value(px, 1), d.value(px, 2)
+		case 2, 3: // 灰度
+			c.R = d.value(px, 0)
+			c.G, c.B = c.R, c.R
+		case 4, 5: // 索引颜色
+			if index := int(px[0]); index < len(d.colormap) {
+				c = d.colormap[index]
+			}
+		}
+		if channels == 2 || channels == 4 {
+			c.A = d.alpha(px, channels-1)
+		}
+		img.Pix[p*4], img.Pix[p*4+1], img.Pix[p*4+2], img.Pix[p*4+3] = c.R, c.G, c.B, c.A
+	}
+}
+
+// componentAt returns component i of a pixel scaled to 0-1
+func (d *xcfDecoder) componentAt(px []byte, i int) float64 {
+	if d.component == 2 {
+		return float64(binary.BigEndian.Uint16(px[i*2:])) / 0xffff
+	}
+	return float64(px[i]) / 0xff
+}
+
+// value returns color component i of a pixel as an 8 bit sRGB value
+func (d *xcfDecoder) value(px []byte, i int) uint8 {
+	if d.component == 1 && !d.linear {
+		return px[i]
+	}
+	v := d.componentAt(px, i)
+	if d.linear {
+		v = linearToSRGB(v)
+	}
+	return uint8(math.Round(v * 0xff))
+}
+
+// alpha returns alpha component i of a pixel as 8 bits; alpha is never gamma encoded
+func (d *xcfDecoder) alpha(px []byte, i int) uint8 {
+	if d.component == 1 {
+		return px[i]
+	}
+	return uint8(math.Round(d.componentAt(px, i) * 0xff))
+}
+
+// linearToSRGB encodes a linear light value with the sRGB transfer function
+func linearToSRGB(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// parseXCFColormap reads the palette of an indexed image
+func parseXCFColormap(payload []byte) []color.NRGBA {
+	if len(payload) < 4 {
+		return nil
+	}
+	n := int(binary.BigEndian.Uint32(payload))
+	payload = payload[4:]
+	colormap := make([]color.NRGBA, 0, n)
+	for i := 0; i < n && i*3+3 <= len(payload); i++ {
+		colormap = append(colormap, color.NRGBA{payload[i*3], payload[i*3+1], payload[i*3+2], 255})
+	}
+	return colormap
+}
+
+// readProperties calls fn for each property until the end property
+func (d *xcfDecoder) readProperties(fn func(kind uint32, payload []byte)) {
+	for d.err == nil {
+		kind, length := d.u32(), int(d.u32())
+		if kind == xcfPropEnd {
+			return
+		}
+		payload := d.bytes(length)
+		if d.err == nil {
+			fn(kind, payload)
+		}
+	}
+}
+
+// seek moves to an absolute offset
+func (d *xcfDecoder) seek(offset int) {
+	if d.err == nil && (offset < 0 || offset > len(d.data)) {
+		d.err = fmt.Errorf("graphics: xcf offset %d is outside the file", offset)
+	}
+	d.pos = offset
+}
+
+// bytes reads n bytes; after an error it returns nil
+func (d *xcfDecoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || d.pos+n > len(d.data) {
+		d.err = fmt.Errorf("graphics: xcf is truncated: %w", io.ErrUnexpectedEOF)
+		return nil
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b
+}
+
+func (d *xcfDecoder) u32() uint32 {
+	b := d.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+// ptr reads a file offset, 64 bits wide in version 11 and later
+func (d *xcfDecoder) ptr() int {
+	if !d.wide {
+		return int(d.u32())
+	}
+	b := d.bytes(8)
+	if b == nil {
+		return 0
+	}
+	offset := binary.BigEndian.Uint64(b)
+	if offset > uint64(len(d.data)) {
+		d.err = fmt.Errorf("graphics: xcf offset %d is outside the file", offset)
+		return 0
+	}
+	return int(offset)
+}
+
+// str reads a string: its length including the terminating zero, then the bytes
+func (d *xcfDecoder) str() string {
+	n := int(d.u32())
+	if n == 0 {
+		return ""
+	}
+	return strings.TrimRight(string(d.bytes(n)), "\x00")
+}
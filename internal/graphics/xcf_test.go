@@ -0,0 +1,357 @@
+package graphics
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// decodeXCFFile decodes one of the sample .xcf files in the repository
+func decodeXCFFile(t *testing.T, name string) *XCFImage {
+	t.Helper()
+	f, err := os.Open(filepath.Join("..", "..", "image", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	x, err := DecodeXCF(f)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return x
+}
+
+func TestDecodeXCFSamples(t *testing.T) {
+	tests := []struct {
+		name   string
+		layers []string
+	}{
+		{"test.xcf", []string{"背景 副本 #1", "背景 副本 #6", "背景 副本 #5", "背景 副本 #4", "背景 副本 #3", "背景 副本 #2", "背景 副本", "背景"}},
+		{"无标题.xcf", []string{"背景 副本 #1", "背景 副本", "背景"}},
+	}
+	for _, tt := range tests {
+		x := decodeXCFFile(t, tt.name)
+		if x.Width != 640 || x.Height != 640 {
+			t.Errorf("%s: expected 640x640, got %dx%d", tt.name, x.Width, x.Height)
+		}
+		var names []string
+		for _, layer := range x.Layers {
+			names = append(names, layer.Name)
+			if !layer.Visible || layer.Opacity != 1 || layer.Group {
+				t.Errorf("%s: unexpected layer %q %+v", tt.name, layer.Name, layer)
+			}
+		}
+		if !reflect.DeepEqual(names, tt.layers) {
+			t.Errorf("%s: expected layers %q, got %q", tt.name, tt.layers, names)
+		}
+	}
+
+	// 图层偏移决定图层在画布上的位置
+	x := decodeXCFFile(t, "test.xcf")
+	layer, _ := x.Layer("背景 副本 #3")
+	if got := layer.Image.Bounds(); got != image.Rect(96, 0, 128, 32) {
+		t.Errorf("Expected the layer at its offset, got %v", got)
+	}
+}
+
+func TestFlattenXCFMatchesExport(t *testing.T) {
+	x := decodeXCFFile(t, "test.xcf")
+	flat := x.Flatten()
+
+	f, err := os.Open(filepath.Join("..", "..", "assets", "images", "test.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	exported, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 导出 test.png 之后精灵 0 和 5 又被修改过，其余部分应该完全一致
+	edited := []image.Rectangle{image.Rect(0, 0, 32, 32), image.Rect(160, 0, 192, 32)}
+	for y := 0; y < x.Height; y++ {
+		for px := 0; px < x.Width; px++ {
+			p := image.Pt(px, y)
+			if p.In(edited[0]) || p.In(edited[1]) {
+				continue
+			}
+			want := color.NRGBAModel.Convert(exported.At(px, y))
+			if got := color.NRGBAModel.Convert(flat.At(px, y)); got != want {
+				t.Fatalf("Pixel %v: expected %v, got %v", p, want, got)
+			}
+		}
+	}
+}
+
+func TestXCFLayerCanvas(t *testing.T) {
+	x := decodeXCFFile(t, "无标题.xcf")
+	canvas, err := x.LayerCanvas("背景 副本")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if canvas.Bounds() != image.Rect(0, 0, 640, 640) {
+		t.Errorf("Expected a canvas-sized image, got %v", canvas.Bounds())
+	}
+	if canvas.NRGBAAt(0, 0).A != 0 {
+		t.Error("Expected pixels outside the layer to be transparent")
+	}
+	layer, _ := x.Layer("背景 副本")
+	if got, want := canvas.NRGBAAt(100, 10), layer.Image.NRGBAAt(100, 10); got != want {
+		t.Errorf("Expected the layer pixel %v, got %v", want, got)
+	}
+
+	if _, err := x.LayerCanvas("none"); err == nil {
+		t.Error("Expected an error for a missing layer")
+	}
+}
+
+func TestImageDecodeXCF(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "image", "test.xcf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || format != "xcf" || config.Width != 640 || config.Height != 640 {
+		t.Errorf("Unexpected config %+v, format %q, error %v", config, format, err)
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil || format != "xcf" || img.Bounds() != image.Rect(0, 0, 640, 640) {
+		t.Errorf("Unexpected image %v, format %q, error %v", img, format, err)
+	}
+}
+
+func TestDecodeXCFRLE(t *testing.T) {
+	// 3 个重复的 7，原样的 1 2，长重复 300 个 9
+	src := []byte{2, 7, 254, 1, 2, 127, 1, 44, 9}
+	out := make([]byte, 305*2)
+	read, err := decodeXCFRLE(src, out, 305, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read != len(src) {
+		t.Errorf("Expected to read %d bytes, read %d", len(src), read)
+	}
+	if out[0] != 7 || out[4] != 7 || out[6] != 1 || out[8] != 2 || out[10] != 9 || out[608] != 9 || out[1] != 0 {
+		t.Errorf("Unexpected output %v", out[:12])
+	}
+
+	if _, err := decodeXCFRLE([]byte{10, 1}, make([]byte, 4), 4, 1); err == nil {
+		t.Error("Expected an error for a run past the end of the tile")
+	}
+	if _, err := decodeXCFRLE([]byte{250, 1}, make([]byte, 6), 6, 1); err == nil {
+		t.Error("Expected an error for truncated data")
+	}
+}
+
+// xcfLayerSpec describes a layer for buildXCF
+type xcfLayerSpec struct {
+	name    string
+	x, y    int
+	pixels  []color.NRGBA // 2x2 RGBA
+	opacity uint32
+	visible bool
+	group   bool
+	path    []uint32
+}
+
+// buildXCF writes a version 3 (32 bit offsets) RGB image of 2x2 layers
+func buildXCF(t *testing.T, width, height int, compression byte, layers []xcfLayerSpec) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	u32 := func(v uint32) { binary.Write(&buf, binary.BigEndian, v) }
+	prop := func(kind uint32, payload ...uint32) {
+		u32(kind)
+		u32(uint32(4 * len(payload)))
+		for _, v := range payload {
+			u32(v)
+		}
+	}
+	patch := func(at int, v int) { binary.BigEndian.PutUint32(buf.Bytes()[at:], uint32(v)) }
+
+	buf.WriteString("gimp xcf v003\x00")
+	u32(uint32(width))
+	u32(uint32(height))
+	u32(0)
+	u32(xcfPropCompression)
+	u32(1)
+	buf.WriteByte(compression)
+	prop(xcfPropEnd)
+	pointers := buf.Len()
+	for range layers {
+		u32(0)
+	}
+	u32(0) // 图层列表结束
+	u32(0) // 通道列表结束
+
+	for i, spec := range layers {
+		patch(pointers+4*i, buf.Len())
+		u32(2)
+		u32(2)
+		u32(1) // RGBA
+		u32(uint32(len(spec.name) + 1))
+		buf.WriteString(spec.name + "\x00")
+		prop(xcfPropOpacity, spec.opacity)
+		visible := uint32(0)
+		if spec.visible {
+			visible = 1
+		}
+		prop(xcfPropVisible, visible)
+		prop(xcfPropOffsets, uint32(spec.x), uint32(spec.y))
+		if spec.group {
+			prop(xcfPropGroupItem)
+		}
+		if spec.path != nil {
+			prop(xcfPropItemPath, spec.path...)
+		}
+		prop(xcfPropEnd)
+		hierarchy := buf.Len()
+		u32(0)
+		u32(0) // 没有图层蒙版
+
+		patch(hierarchy, buf.Len())
+		u32(2)
+		u32(2)
+		u32(4)
+		level := buf.Len()
+		u32(0)
+		u32(0)
+
+		patch(level, buf.Len())
+		u32(2)
+		u32(2)
+		tile := buf.Len()
+		u32(0)
+		u32(0)
+		patch(tile, buf.Len())
+
+		var raw []byte
+		for _, c := range spec.pixels {
+			raw = append(raw, c.R, c.G, c.B, c.A)
+		}
+		switch compression {
+		case xcfCompressNone:
+			buf.Write(raw)
+		case xcfCompressZlib:
+			zw := zlib.NewWriter(&buf)
+			zw.Write(raw)
+			zw.Close()
+		default:
+			t.Fatalf("Unsupported compression %d", compression)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeXCFCompressionAndGroups(t *testing.T) {
+	red := color.NRGBA{255, 0, 0, 255}
+	blue := color.NRGBA{0, 0, 255, 255}
+	green := color.NRGBA{0, 255, 0, 255}
+	clear := color.NRGBA{}
+	layers := []xcfLayerSpec{
+		// 隐藏的图层组及其子图层
+		{name: "group", visible: false, group: true, opacity: 255, path: []uint32{0}, pixels: make([]color.NRGBA, 4)},
+		{name: "hidden child", visible: true, opacity: 255, path: []uint32{0, 0}, pixels: []color.NRGBA{green, green, green, green}},
+		{name: "top", x: 1, y: 1, visible: true, opacity: 255, path: []uint32{1}, pixels: []color.NRGBA{red, clear, clear, clear}},
+		{name: "bottom", visible: true, opacity: 255, path: []uint32{2}, pixels: []color.NRGBA{blue, blue, blue, clear}},
+	}
+
+	for _, compression := range []byte{xcfCompressNone, xcfCompressZlib} {
+		x, err := DecodeXCF(bytes.NewReader(buildXCF(t, 3, 3, compression, layers)))
+		if err != nil {
+			t.Fatalf("Compression %d: %v", compression, err)
+		}
+		if len(x.Layers) != 4 || !x.Layers[0].Group || x.Layers[2].Image.Bounds() != image.Rect(1, 1, 3, 3) {
+			t.Fatalf("Compression %d: unexpected layers %+v", compression, x.Layers)
+		}
+
+		flat := x.Flatten()
+		want := map[image.Point]color.NRGBA{
+			{0, 0}: blue, {1, 0}: blue, {0, 1}: blue,
+			{1, 1}: red, // 上层覆盖下层
+			{2, 2}: clear,
+		}
+		for p, c := range want {
+			if got := color.NRGBAModel.Convert(flat.At(p.X, p.Y)); got != c {
+				t.Errorf("Compression %d: pixel %v expected %v, got %v", compression, p, c, got)
+			}
+		}
+	}
+}
+
+func TestFlattenXCFOpacity(t *testing.T) {
+	white := color.NRGBA{255, 255, 255, 255}
+	data := buildXCF(t, 2, 2, xcfCompressNone, []xcfLayerSpec{
+		{name: "half", visible: true, opacity: 128, pixels: []color.NRGBA{white, white, white, white}},
+	})
+	x, err := DecodeXCF(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := color.NRGBAModel.Convert(x.Flatten().At(0, 0)).(color.NRGBA).A; a != 128 {
+		t.Errorf("Expected alpha 128, got %d", a)
+	}
+}
+
+func TestDecodeXCFErrors(t *testing.T) {
+	valid := buildXCF(t, 2, 2, xcfCompressNone, []xcfLayerSpec{
+		{name: "layer", visible: true, opacity: 255, pixels: make([]color.NRGBA, 4)},
+	})
+	tests := map[string][]byte{
+		"not xcf":   []byte("\x89PNG\r\n\x1a\n"),
+		"truncated": valid[:len(valid)-3],
+		"header":    valid[:20],
+	}
+	for name, data := range tests {
+		if _, err := DecodeXCF(bytes.NewReader(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// resizeXCF returns a copy of an image from buildXCF with the canvas and the 2x2 sizes
+// of its layers (in the layer, hierarchy and level headers) changed
+func resizeXCF(data []byte, canvasW, canvasH, layerW, layerH uint32) []byte {
+	out := append([]byte(nil), data...)
+	binary.BigEndian.PutUint32(out[14:], canvasW)
+	binary.BigEndian.PutUint32(out[18:], canvasH)
+	two := []byte{0, 0, 0, 2, 0, 0, 0, 2}
+	for i := 22; i+len(two) <= len(out); i++ {
+		if bytes.Equal(out[i:i+len(two)], two) {
+			binary.BigEndian.PutUint32(out[i:], layerW)
+			binary.BigEndian.PutUint32(out[i+4:], layerH)
+		}
+	}
+	return out
+}
+
+func TestDecodeXCFRejectsBadSizes(t *testing.T) {
+	valid := buildXCF(t, 2, 2, xcfCompressNone, []xcfLayerSpec{
+		{name: "layer", visible: true, opacity: 255, pixels: make([]color.NRGBA, 4)},
+	})
+	if _, err := DecodeXCF(bytes.NewReader(resizeXCF(valid, 2, 2, 2, 2))); err != nil {
+		t.Fatalf("Expected the unchanged image to decode, got %v", err)
+	}
+
+	tests := map[string][]byte{
+		"empty canvas":      resizeXCF(valid, 0, 2, 2, 2),
+		"oversized canvas":  resizeXCF(valid, 1<<31, 1<<31, 2, 2),
+		"empty layer":       resizeXCF(valid, 2, 2, 0, 2),
+		"oversized layer":   resizeXCF(valid, 2, 2, 1<<31, 2),
+		"layer over canvas": resizeXCF(valid, 2, 2, 100, 100),
+		// 尺寸合法但文件放不下这么多图块
+		"truncated tiles": resizeXCF(valid, 4096, 4096, 4096, 4096),
+	}
+	for name, data := range tests {
+		if _, err := DecodeXCF(bytes.NewReader(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Package grid holds what the systems of the block grid share: its cells and the
+// scheduler that runs cells at a later tick.
+package grid
+
+import (
+	"encoding/binary"
+	"io"
+	"sort"
+)
+
+// Cell is a cell of the block grid
+type Cell struct {
+	X, Y int
+}
+
+// Neighbors returns the four cells next to c: above, left, right and below
+func (c Cell) Neighbors() [4]Cell {
+	return [4]Cell{{c.X, c.Y - 1}, {c.X - 1, c.Y}, {c.X + 1, c.Y}, {c.X, c.Y + 1}}
+}
+
+// Sort sorts cells by row, then by column
+func Sort(cells []Cell) {
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].Y != cells[j].Y {
+			return cells[i].Y < cells[j].Y
+		}
+		return cells[i].X < cells[j].X
+	})
+}
+
+// FloorDiv divides rounding toward negative infinity, so cell -1 is in chunk -1
+func FloorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// WriteInts writes values to w, for the Hash methods that compare two runs
+func WriteInts(w io.Writer, values ...int64) {
+	binary.Write(w, binary.LittleEndian, values)
+}
@@ -0,0 +1,93 @@
+// Package gridtest provides a block grid world backed by a map, for the tests of the
+// packages that edit and simulate the grid.
+package gridtest
+
+import (
+	"strings"
+
+	"github.com/wubinrui111/2d-game/internal/grid"
+)
+
+// Letters maps the letters of test rows to block IDs. Letters that are not in it are
+// their own block ID, and '.' is no block.
+type Letters map[byte]string
+
+// World is a world backed by a map, counting SetBlock calls
+type World struct {
+	Cells map[grid.Cell]string
+	Sets  int
+
+	// Changed, if set, is called after every SetBlock, such as a simulation's Changed
+	Changed func(x, y int)
+}
+
+// New creates an empty world
+func New() *World {
+	return &World{Cells: make(map[grid.Cell]string)}
+}
+
+// Block returns the block at a cell, "" for none
+func (w *World) Block(x, y int) string {
+	return w.Cells[grid.Cell{X: x, Y: y}]
+}
+
+// SetBlock sets the block at a cell, "" for none
+func (w *World) SetBlock(x, y int, id string) {
+	w.Sets++
+	if id == "" {
+		delete(w.Cells, grid.Cell{X: x, Y: y})
+	} else {
+		w.Cells[grid.Cell{X: x, Y: y}] = id
+	}
+	if w.Changed != nil {
+		w.Changed(x, y)
+	}
+}
+
+// SetRows sets the blocks of the area at the origin from rows of letters with
+// SetBlock; '.' cells are left as they are
+func (w *World) SetRows(letters Letters, rows ...string) {
+	for y, row := range rows {
+		for x := 0; x < len(row); x++ {
+			if row[x] != '.' {
+				w.SetBlock(x, y, letters.id(row[x]))
+			}
+		}
+	}
+}
+
+// Rows returns the width x height area at the origin as rows of letters, separated
+// by '/'
+func (w *World) Rows(letters Letters, width, height int) string {
+	var b strings.Builder
+	for y := 0; y < height; y++ {
+		if y > 0 {
+			b.WriteByte('/')
+		}
+		for x := 0; x < width; x++ {
+			b.WriteString(letters.letter(w.Block(x, y)))
+		}
+	}
+	return b.String()
+}
+
+// id returns the block ID of a letter
+func (l Letters) id(letter byte) string {
+	if id, exists := l[letter]; exists {
+		return id
+	}
+	return string(letter)
+}
+
+// letter returns the letter of a block ID
+func (l Letters) letter(id string) string {
+	if id == "" {
+		return "."
+	}
+	for letter, lid := range l {
+		if lid == id {
+			return string(letter)
+		}
+	}
+	return id
+}
@@ -0,0 +1,86 @@
+package grid
+
+import (
+	"io"
+	"sort"
+)
+
+// Scheduler queues cells to run at a target tick. A cell is queued once: scheduling
+// it again keeps the earlier of the two ticks.
+type Scheduler struct {
+	tick  int64
+	queue map[int64][]Cell // Cells to run at each tick
+	due   map[Cell]int64   // Tick each queued cell runs at
+}
+
+// NewScheduler creates a scheduler at tick 0
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		queue: make(map[int64][]Cell),
+		due:   make(map[Cell]int64),
+	}
+}
+
+// Tick returns the number of the current tick
+func (s *Scheduler) Tick() int64 {
+	return s.tick
+}
+
+// Pending returns how many cells are queued
+func (s *Scheduler) Pending() int {
+	return len(s.due)
+}
+
+// ScheduleAt queues a cell to run at a target tick, unless it runs earlier already.
+// Targets that have passed run on the next tick.
+func (s *Scheduler) ScheduleAt(c Cell, tick int64) {
+	if tick <= s.tick {
+		tick = s.tick + 1
+	}
+	if due, exists := s.due[c]; exists && due <= tick {
+		return
+	}
+	s.due[c] = tick
+	s.queue[tick] = append(s.queue[tick], c)
+}
+
+// Schedule queues a cell to run after delay ticks, unless it runs earlier already
+func (s *Scheduler) Schedule(c Cell, delay int) {
+	s.ScheduleAt(c, s.tick+int64(delay))
+}
+
+// Advance moves to the next tick and returns the cells due at it, in the order they
+// were queued. They are no longer queued, so running them may queue them again.
+func (s *Scheduler) Advance() []Cell {
+	s.tick++
+	queued := s.queue[s.tick]
+	delete(s.queue, s.tick)
+	cells := queued[:0]
+	for _, c := range queued {
+		// 提前改过时间的格子留在旧的队列里，不会执行
+		if s.due[c] != s.tick {
+			continue
+		}
+		delete(s.due, c)
+		cells = append(cells, c)
+	}
+	return cells
+}
+
+// Hash writes the state of the scheduler to w in a fixed order: the tick and the
+// queued cells, tick by tick in the order they run
+func (s *Scheduler) Hash(w io.Writer) {
+	WriteInts(w, s.tick, int64(len(s.due)))
+	ticks := make([]int64, 0, len(s.queue))
+	for tick := range s.queue {
+		ticks = append(ticks, tick)
+	}
+	sort.Slice(ticks, func(i, j int) bool { return ticks[i] < ticks[j] })
+	for _, tick := range ticks {
+		for _, c := range s.queue[tick] {
+			if s.due[c] == tick {
+				WriteInts(w, tick, int64(c.X), int64(c.Y))
+			}
+		}
+	}
+}
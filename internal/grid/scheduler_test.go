@@ -0,0 +1,60 @@
+package grid
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestScheduler(t *testing.T) {
+	s := NewScheduler()
+	s.Schedule(Cell{0, 0}, 3)
+	s.Schedule(Cell{1, 0}, 2)
+	s.Schedule(Cell{0, 0}, 1) // 更早的时间优先
+	s.Schedule(Cell{1, 0}, 5) // 更晚的时间不改变
+	s.ScheduleAt(Cell{2, 0}, -4)
+	if s.Pending() != 3 {
+		t.Fatalf("Expected 3 queued cells, got %d", s.Pending())
+	}
+
+	// 已经过去的时间在下一刻执行
+	if got := s.Advance(); !reflect.DeepEqual(got, []Cell{{0, 0}, {2, 0}}) {
+		t.Errorf("Expected the first cells at tick 1, got %v", got)
+	}
+	if got := s.Advance(); !reflect.DeepEqual(got, []Cell{{1, 0}}) {
+		t.Errorf("Expected the second cell at tick 2, got %v", got)
+	}
+	if got := s.Advance(); len(got) != 0 || s.Pending() != 0 || s.Tick() != 3 {
+		t.Errorf("Expected nothing left at tick 3, got %v and %d queued", got, s.Pending())
+	}
+}
+
+func TestSchedulerHash(t *testing.T) {
+	hash := func(cells ...Cell) []byte {
+		s := NewScheduler()
+		for _, c := range cells {
+			s.Schedule(c, 5)
+		}
+		s.Schedule(Cell{9, 9}, 2)
+		s.Schedule(Cell{9, 9}, 1)
+		var b bytes.Buffer
+		s.Hash(&b)
+		return b.Bytes()
+	}
+
+	// 同一刻执行的格子按排队的顺序
+	if bytes.Equal(hash(Cell{0, 0}, Cell{1, 0}), hash(Cell{1, 0}, Cell{0, 0})) {
+		t.Error("Expected the queue order to change the hash")
+	}
+	if !bytes.Equal(hash(Cell{0, 0}, Cell{1, 0}), hash(Cell{0, 0}, Cell{1, 0})) {
+		t.Error("Expected the same queue to give the same hash")
+	}
+}
+
+func TestFloorDiv(t *testing.T) {
+	for _, tc := range []struct{ a, b, want int }{{7, 16, 0}, {16, 16, 1}, {-1, 16, -1}, {-16, 16, -1}, {-17, 16, -2}} {
+		if got := FloorDiv(tc.a, tc.b); got != tc.want {
+			t.Errorf("Expected %d / %d = %d, got %d", tc.a, tc.b, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+// internal/i18n/i18n.go
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultDir is the directory containing one <locale>.json catalog per language
+	DefaultDir = "./assets/locales"
+
+	// FallbackLanguage is used for keys missing from the current language
+	FallbackLanguage = "en-US"
+)
+
+// message is a catalog entry, either a plain string or a set of plural forms
+type message struct {
+	forms map[string]string
+}
+
+// UnmarshalJSON accepts "text" or {"one": "...", "other": "..."}
+func (m *message) UnmarshalJSON(data []byte) error {
+	var plain string
+	if err := json.Unmarshal(data, &plain); err == nil {
+		m.forms = map[string]string{"other": plain}
+		return nil
+	}
+
+	var forms map[string]string
+	if err := json.Unmarshal(data, &forms); err != nil {
+		return err
+	}
+	if _, exists := forms["other"]; !exists {
+		return fmt.Errorf("plural message is missing the \"other\" form")
+	}
+	m.forms = forms
+	return nil
+}
+
+// Catalog holds the messages of a single language
+type Catalog struct {
+	Language string
+	messages map[string]message
+}
+
+// ParseCatalog parses a JSON catalog for the given language
+func ParseCatalog(language string, data []byte) (*Catalog, error) {
+	messages := make(map[string]message)
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, fmt.Errorf("i18n: failed to parse %s catalog: %w", language, err)
+	}
+	return &Catalog{Language: language, messages: messages}, nil
+}
+
+// Keys returns all message keys of the catalog in sorted order
+func (c *Catalog) Keys() []string {
+	keys := make([]string, 0, len(c.messages))
+	for key := range c.messages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Bundle holds the catalogs of all languages and the currently selected one
+type Bundle struct {
+	catalogs map[string]*Catalog
+	language string
+	fallback string
+}
+
+// NewBundle creates an empty bundle that falls back to the given language
+func NewBundle(fallback string) *Bundle {
+	return &Bundle{
+		catalogs: make(map[string]*Catalog),
+		language: fallback,
+		fallback: fallback,
+	}
+}
+
+var defaultBundle = NewBundle(FallbackLanguage)
+
+// Default returns the bundle used by the game's UI
+func Default() *Bundle {
+	return defaultBundle
+}
+
+// LoadDir loads every <locale>.json file in dir
+func (b *Bundle) LoadDir(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("i18n: no catalogs found in %s", dir)
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		language := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if err := b.LoadCatalog(language, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadCatalog adds or replaces the catalog of a language
+func (b *Bundle) LoadCatalog(language string, data []byte) error {
+	catalog, err := ParseCatalog(language, data)
+	if err != nil {
+		return err
+	}
+	b.catalogs[language] = catalog
+	return nil
+}
+
+// Languages returns the languages that have a catalog, in sorted order
+func (b *Bundle) Languages() []string {
+	languages := make([]string, 0, len(b.catalogs))
+	for language := range b.catalogs {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Catalog returns the catalog of a language, or nil if it is not loaded
+func (b *Bundle) Catalog(language string) *Catalog {
+	return b.catalogs[language]
+}
+
+// Language returns the currently selected language
+func (b *Bundle) Language() string {
+	return b.language
+}
+
+// SetLanguage switches the current language at runtime
+func (b *Bundle) SetLanguage(language string) error {
+	if _, exists := b.catalogs[language]; !exists {
+		return fmt.Errorf("i18n: no catalog for language %q", language)
+	}
+	b.language = language
+	return nil
+}
+
+// lookup finds a message in the current language, then in the fallback language
+func (b *Bundle) lookup(key string) (message, string, bool) {
+	for _, language := range []string{b.language, b.fallback} {
+		if catalog, exists := b.catalogs[language]; exists {
+			if msg, exists := catalog.messages[key]; exists {
+				return msg, language, true
+			}
+		}
+	}
+	return message{}, "", false
+}
+
+// Has reports whether a key is translated in the current or fallback language
+func (b *Bundle) Has(key string) bool {
+	_, _, exists := b.lookup(key)
+	return exists
+}
+
+// T returns the translation of key formatted with args.
+// Missing keys are returned unchanged so they are easy to spot in the UI.
+func (b *Bundle) T(key string, args ...any) string {
+	msg, _, exists := b.lookup(key)
+	if !exists {
+		return key
+	}
+	return format(msg.forms["other"], args)
+}
+
+// N returns the plural form of key that matches count, formatted with args
+func (b *Bundle) N(key string, count int, args ...any) string {
+	msg, language, exists := b.lookup(key)
+	if !exists {
+		return key
+	}
+
+	form, exists := msg.forms[PluralCategory(language, count)]
+	if !exists {
+		form = msg.forms["other"]
+	}
+	return format(form, args)
+}
+
+// format applies fmt-style arguments only when some are given so literal % signs survive
+func format(text string, args []any) string {
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// T translates key with the default bundle
+func T(key string, args ...any) string {
+	return defaultBundle.T(key, args...)
+}
+
+// N translates a plural key with the default bundle
+func N(key string, count int, args ...any) string {
+	return defaultBundle.N(key, count, args...)
+}
+
+// Has reports whether the default bundle can translate key
+func Has(key string) bool {
+	return defaultBundle.Has(key)
+}
+
+// SetLanguage switches the language of the default bundle
+func SetLanguage(language string) error {
+	return defaultBundle.SetLanguage(language)
+}
@@ -0,0 +1,148 @@
+package i18n
+
+import (
+	"testing"
+)
+
+func newTestBundle(t *testing.T) *Bundle {
+	bundle := NewBundle("en-US")
+
+	en := `{
+		"greeting": "Hello",
+		"health": "Health: %d/%d",
+		"drops": {"one": "%d item drop", "other": "%d item drops"},
+		"only_english": "English only"
+	}`
+	zh := `{
+		"greeting": "你好",
+		"health": "生命值：%d/%d",
+		"drops": {"other": "%d 个掉落物"}
+	}`
+
+	if err := bundle.LoadCatalog("en-US", []byte(en)); err != nil {
+		t.Fatal(err)
+	}
+	if err := bundle.LoadCatalog("zh-CN", []byte(zh)); err != nil {
+		t.Fatal(err)
+	}
+	return bundle
+}
+
+func TestTranslateAndSwitchLanguage(t *testing.T) {
+	bundle := newTestBundle(t)
+
+	if got := bundle.T("greeting"); got != "Hello" {
+		t.Errorf("Expected 'Hello', got '%s'", got)
+	}
+
+	if err := bundle.SetLanguage("zh-CN"); err != nil {
+		t.Fatalf("Expected language switch to succeed, got %v", err)
+	}
+
+	if got := bundle.T("greeting"); got != "你好" {
+		t.Errorf("Expected '你好', got '%s'", got)
+	}
+
+	if got := bundle.T("health", 80, 100); got != "生命值：80/100" {
+		t.Errorf("Expected formatted translation, got '%s'", got)
+	}
+}
+
+func TestFallbackAndMissingKeys(t *testing.T) {
+	bundle := newTestBundle(t)
+	bundle.SetLanguage("zh-CN")
+
+	// 中文缺少的键回退到英文
+	if got := bundle.T("only_english"); got != "English only" {
+		t.Errorf("Expected fallback translation, got '%s'", got)
+	}
+
+	if got := bundle.T("missing.key"); got != "missing.key" {
+		t.Errorf("Expected missing key to be returned unchanged, got '%s'", got)
+	}
+
+	if bundle.Has("missing.key") {
+		t.Error("Expected Has to be false for a missing key")
+	}
+}
+
+func TestSetUnknownLanguage(t *testing.T) {
+	bundle := newTestBundle(t)
+
+	if err := bundle.SetLanguage("fr-FR"); err == nil {
+		t.Error("Expected an error when switching to a language without a catalog")
+	}
+
+	if bundle.Language() != "en-US" {
+		t.Errorf("Expected language to stay 'en-US', got '%s'", bundle.Language())
+	}
+}
+
+func TestPluralForms(t *testing.T) {
+	bundle := newTestBundle(t)
+
+	if got := bundle.N("drops", 1, 1); got != "1 item drop" {
+		t.Errorf("Expected singular form, got '%s'", got)
+	}
+
+	if got := bundle.N("drops", 3, 3); got != "3 item drops" {
+		t.Errorf("Expected plural form, got '%s'", got)
+	}
+
+	bundle.SetLanguage("zh-CN")
+	if got := bundle.N("drops", 1, 1); got != "1 个掉落物" {
+		t.Errorf("Expected Chinese form, got '%s'", got)
+	}
+}
+
+func TestPluralCategory(t *testing.T) {
+	cases := []struct {
+		language string
+		count    int
+		expected string
+	}{
+		{"en-US", 0, "other"},
+		{"en-US", 1, "one"},
+		{"en-US", 2, "other"},
+		{"zh-CN", 1, "other"},
+		{"fr-FR", 0, "one"},
+		{"ru-RU", 21, "one"},
+		{"ru-RU", 3, "few"},
+		{"ru-RU", 12, "many"},
+	}
+
+	for _, c := range cases {
+		if got := PluralCategory(c.language, c.count); got != c.expected {
+			t.Errorf("PluralCategory(%s, %d): expected '%s', got '%s'", c.language, c.count, c.expected, got)
+		}
+	}
+}
+
+func TestInvalidPluralMessage(t *testing.T) {
+	bundle := NewBundle("en-US")
+
+	if err := bundle.LoadCatalog("en-US", []byte(`{"drops": {"one": "a drop"}}`)); err == nil {
+		t.Error("Expected an error for a plural message without an 'other' form")
+	}
+}
+
+func TestRepositoryCatalogsHaveSameKeys(t *testing.T) {
+	bundle := NewBundle(FallbackLanguage)
+	if err := bundle.LoadDir("../../assets/locales"); err != nil {
+		t.Fatalf("Expected catalogs to load, got %v", err)
+	}
+
+	reference := bundle.Catalog(FallbackLanguage)
+	if reference == nil {
+		t.Fatalf("Expected a %s catalog", FallbackLanguage)
+	}
+
+	for _, language := range bundle.Languages() {
+		catalog := bundle.Catalog(language)
+		for _, key := range reference.Keys() {
+			if _, exists := catalog.messages[key]; !exists {
+				t.Errorf("Catalog %s is missing key '%s'", language, key)
+			}
+		}
+	}
+}
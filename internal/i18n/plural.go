@@ -0,0 +1,43 @@
+package i18n
+
+import "strings"
+
+// PluralCategory returns the CLDR plural category ("one", "few", "many" or "other")
+// that a language uses for an integer count
+func PluralCategory(language string, count int) string {
+	base := strings.ToLower(language)
+	if i := strings.IndexAny(base, "-_"); i >= 0 {
+		base = base[:i]
+	}
+
+	n := count
+	if n < 0 {
+		n = -n
+	}
+
+	switch base {
+	case "zh", "ja", "ko", "vi", "th", "id":
+		// 东亚语言没有复数变化
+		return "other"
+	case "fr", "pt":
+		if n <= 1 {
+			return "one"
+		}
+		return "other"
+	case "ru", "uk":
+		mod10, mod100 := n%10, n%100
+		switch {
+		case mod10 == 1 && mod100 != 11:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	default:
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	}
+}
@@ -0,0 +1,296 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/wubinrui111/2d-game/internal/settings"
+	"github.com/wubinrui111/2d-game/internal/ui"
+)
+
+// Action is something the player can do, independent of the input device.
+// The values match the action names used in the settings file.
+type Action string
+
+const (
+	ActionMoveLeft       Action = "move_left"
+	ActionMoveRight      Action = "move_right"
+	ActionMoveDown       Action = "move_down"
+	ActionJump           Action = "jump"
+	ActionAttack         Action = "attack"
+	ActionUseItem        Action = "use_item"
+	ActionPickBlock      Action = "pick_block"
+	ActionOpenInventory  Action = "inventory"
+	ActionToggleGameMode Action = "game_mode"
+	ActionToggleGrid     Action = "toggle_grid"
+	ActionExportLevel    Action = "export_level"
+	ActionToggleEditor   Action = "editor"
+	ActionEditorTool     Action = "editor_tool" // Cycles the editor tools
+	ActionFill           Action = "fill"        // Fills the editor selection
+	ActionReplace        Action = "replace"     // Replaces the block under the cursor in the selection
+	ActionDelete         Action = "delete"      // Clears the editor selection
+	ActionCopy           Action = "copy"
+	ActionCut            Action = "cut"
+	ActionPaste          Action = "paste"
+	ActionUndo           Action = "undo"
+	ActionRedo           Action = "redo"
+	ActionSaveSchematic  Action = "save_schematic" // Saves the editor selection as a schematic file
+	ActionNextSchematic  Action = "next_schematic" // Loads the next schematic into the clipboard
+	ActionRotate         Action = "rotate"         // Rotates the clipboard clockwise
+	ActionMirror         Action = "mirror"         // Mirrors the clipboard left to right
+	ActionFreezeTime     Action = "freeze_time"    // Stops or restarts the world clock (debug)
+	ActionSkipTime       Action = "skip_time"      // Skips to the next sunrise, noon, sunset or midnight (debug)
+	ActionSetTime        Action = "set_time"       // Opens a prompt to type the time to set (debug)
+	ActionPause          Action = "pause"
+	ActionNextSlot       Action = "next_slot"
+	ActionPrevSlot       Action = "prev_slot"
+	ActionZoom           Action = "zoom" // Held to zoom the camera with the slot actions (mouse wheel)
+)
+
+// HotbarAction returns the action that selects hotbar slot i (0-8)
+func HotbarAction(i int) Action {
+	return Action(fmt.Sprintf("hotbar_%d", i+1))
+}
+
+const (
+	// DefaultDeadzone is the stick deflection that is ignored
+	DefaultDeadzone = 0.2
+
+	// DefaultCursorSpeed is how far the virtual cursor moves per tick at full stick deflection
+	DefaultCursorSpeed = 8.0
+
+	// pressThreshold is the value above which an analog action counts as pressed
+	pressThreshold = 0.5
+)
+
+// State is the input of one tick, reduced to action values and the cursor.
+// It is all the game reads, so it can be recorded and played back.
+type State struct {
+	// Values holds the strength (0-1) of every active action
+	Values map[Action]float64 `json:"values,omitempty"`
+
+	// CursorX and CursorY are the mouse or virtual cursor position in screen coordinates
+	CursorX float64 `json:"cursor_x"`
+	CursorY float64 `json:"cursor_y"`
+
+	// UI is the input for interface widgets such as the inventory, with the same cursor
+	UI ui.Input `json:"ui"`
+}
+
+// ActionMap turns device input into action values using rebindable bindings
+type ActionMap struct {
+	// Deadzone is the stick deflection that is ignored
+	Deadzone float64
+
+	// CursorSpeed is how far the right stick moves the virtual cursor per tick
+	CursorSpeed float64
+
+	// ScreenWidth and ScreenHeight bound the virtual cursor
+	ScreenWidth, ScreenHeight float64
+
+	bindings map[Action][]Binding
+	current  State
+	previous State
+
+	// lastMouseX and lastMouseY detect mouse movement, which takes the cursor back from the stick
+	lastMouseX, lastMouseY int
+
+	// 录制和回放，见 recording.go
+	recording    *Recording
+	playback     *Recording
+	playbackTick int
+}
+
+// NewActionMap creates an action map with no bindings
+func NewActionMap() *ActionMap {
+	return &ActionMap{
+		Deadzone:     DefaultDeadzone,
+		CursorSpeed:  DefaultCursorSpeed,
+		ScreenWidth:  800,
+		ScreenHeight: 600,
+		bindings:     make(map[Action][]Binding),
+	}
+}
+
+var defaultMap = NewActionMap()
+
+func init() {
+	if err := defaultMap.SetBindings(settings.DefaultBindings()); err != nil {
+		panic(err)
+	}
+}
+
+// Default returns the action map used by the game
+func Default() *ActionMap {
+	return defaultMap
+}
+
+// SetBindings replaces the bindings with inputs given by name, see ParseBinding.
+// Nothing is changed if a name is invalid.
+func (m *ActionMap) SetBindings(names map[string][]string) error {
+	bindings := make(map[Action][]Binding, len(names))
+	var errs []error
+	for action, inputs := range names {
+		for _, name := range inputs {
+			binding, err := ParseBinding(name)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("action %q: %w", action, err))
+				continue
+			}
+			bindings[Action(action)] = append(bindings[Action(action)], binding)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	m.bindings = bindings
+	return nil
+}
+
+// Bindings returns the inputs bound to an action
+func (m *ActionMap) Bindings(action Action) []Binding {
+	return m.bindings[action]
+}
+
+// Update reads the devices, or the recording being played, for a new tick
+func (m *ActionMap) Update() {
+	m.SetState(m.nextState())
+}
+
+// SetState starts a new tick with the given input instead of reading the devices
+func (m *ActionMap) SetState(state State) {
+	m.previous = m.current
+	m.current = state
+}
+
+// State returns the input of the current tick
+func (m *ActionMap) State() State {
+	return m.current
+}
+
+// poll reads all bindings and the cursor from the devices
+func (m *ActionMap) poll() State {
+	gamepads := standardGamepads()
+	_, wheelY := ebiten.Wheel()
+
+	state := State{Values: make(map[Action]float64)}
+	for action, bindings := range m.bindings {
+		value := 0.0
+		for _, binding := range bindings {
+			value = max(value, binding.value(gamepads, wheelY, m.Deadzone))
+		}
+		if value > 0 {
+			state.Values[action] = value
+		}
+	}
+
+	// 鼠标移动时使用鼠标位置，否则用右摇杆移动虚拟光标
+	state.CursorX, state.CursorY = m.current.CursorX, m.current.CursorY
+	mouseX, mouseY := ebiten.CursorPosition()
+	if mouseX != m.lastMouseX || mouseY != m.lastMouseY {
+		state.CursorX, state.CursorY = float64(mouseX), float64(mouseY)
+		m.lastMouseX, m.lastMouseY = mouseX, mouseY
+	}
+	for _, id := range gamepads {
+		dx := stickValue(ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisRightStickHorizontal), m.Deadzone)
+		dy := stickValue(ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisRightStickVertical), m.Deadzone)
+		state.CursorX += dx * m.CursorSpeed
+		state.CursorY += dy * m.CursorSpeed
+	}
+	state.CursorX = math.Max(0, math.Min(m.ScreenWidth-1, state.CursorX))
+	state.CursorY = math.Max(0, math.Min(m.ScreenHeight-1, state.CursorY))
+
+	state.UI = ui.PollInput()
+	state.UI.CursorX, state.UI.CursorY = state.CursorX, state.CursorY
+	return state
+}
+
+// stickValue applies the deadzone to a signed stick axis
+func stickValue(v, deadzone float64) float64 {
+	if v < 0 {
+		return -applyDeadzone(-v, deadzone)
+	}
+	return applyDeadzone(v, deadzone)
+}
+
+// Value returns the strength of an action this tick, from 0 to 1
+func (m *ActionMap) Value(action Action) float64 {
+	return m.current.Values[action]
+}
+
+// Axis returns the value of positive minus the value of negative, from -1 to 1
+func (m *ActionMap) Axis(negative, positive Action) float64 {
+	return m.Value(positive) - m.Value(negative)
+}
+
+// IsPressed reports whether the action is held this tick
+func (m *ActionMap) IsPressed(action Action) bool {
+	return m.current.Values[action] >= pressThreshold
+}
+
+// IsJustPressed reports whether the action started this tick
+func (m *ActionMap) IsJustPressed(action Action) bool {
+	return m.IsPressed(action) && m.previous.Values[action] < pressThreshold
+}
+
+// IsJustReleased reports whether the action stopped this tick
+func (m *ActionMap) IsJustReleased(action Action) bool {
+	return !m.IsPressed(action) && m.previous.Values[action] >= pressThreshold
+}
+
+// Cursor returns the mouse or virtual cursor position
+func (m *ActionMap) Cursor() (float64, float64) {
+	return m.current.CursorX, m.current.CursorY
+}
+
+// BindingName returns the name of the input shown in hints for an action:
+// the first keyboard binding if there is one, or "?" if the action is unbound
+func (m *ActionMap) BindingName(action Action) string {
+	bindings := m.bindings[action]
+	for _, binding := range bindings {
+		if binding.Device() == DeviceKeyboard {
+			return binding.String()
+		}
+	}
+	if len(bindings) > 0 {
+		return bindings[0].String()
+	}
+	return "?"
+}
+
+// SetBindings replaces the bindings of the default action map
+func SetBindings(names map[string][]string) error {
+	return defaultMap.SetBindings(names)
+}
+
+// IsActionPressed reports whether an action of the default action map is held
+func IsActionPressed(action Action) bool {
+	return defaultMap.IsPressed(action)
+}
+
+// IsActionJustPressed reports whether an action of the default action map started this tick
+func IsActionJustPressed(action Action) bool {
+	return defaultMap.IsJustPressed(action)
+}
+
+// ActionValue returns the strength of an action of the default action map
+func ActionValue(action Action) float64 {
+	return defaultMap.Value(action)
+}
+
+// Cursor returns the cursor position of the default action map
+func Cursor() (float64, float64) {
+	return defaultMap.Cursor()
+}
+
+// UIInput returns the widget input of the default action map
+func UIInput() ui.Input {
+	return defaultMap.current.UI
+}
+
+// BindingName returns the hint name for an action of the default action map
+func BindingName(action Action) string {
+	return defaultMap.BindingName(action)
+}
@@ -0,0 +1,132 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/wubinrui111/2d-game/internal/settings"
+)
+
+func TestParseBindingRoundTrip(t *testing.T) {
+	names := []string{"Space", "ArrowLeft", "Digit1", "Mouse:Left", "Mouse:WheelUp", "Mouse:WheelDown", "Pad:A", "Pad:Start", "Pad:LeftStickX-", "Pad:RightStickY+"}
+	for _, name := range names {
+		binding, err := ParseBinding(name)
+		if err != nil {
+			t.Errorf("Expected %q to parse, got %v", name, err)
+			continue
+		}
+		if binding.String() != name {
+			t.Errorf("Expected %q to round-trip, got %q", name, binding.String())
+		}
+	}
+}
+
+func TestParseBindingRejectsUnknownNames(t *testing.T) {
+	for _, name := range []string{"", "NoSuchKey", "Mouse:Thumb", "Pad:Z", "Pad:LeftStickX", "Pad:LeftStickZ+"} {
+		if _, err := ParseBinding(name); err == nil {
+			t.Errorf("Expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestBindingDevice(t *testing.T) {
+	cases := map[string]Device{
+		"E":               DeviceKeyboard,
+		"Mouse:Right":     DeviceMouse,
+		"Mouse:WheelUp":   DeviceMouse,
+		"Pad:X":           DeviceGamepad,
+		"Pad:LeftStickY+": DeviceGamepad,
+	}
+	for name, expected := range cases {
+		binding, _ := ParseBinding(name)
+		if binding.Device() != expected {
+			t.Errorf("Expected %q to be device %d, got %d", name, expected, binding.Device())
+		}
+	}
+}
+
+func TestDefaultBindingsParse(t *testing.T) {
+	m := NewActionMap()
+	if err := m.SetBindings(settings.DefaultBindings()); err != nil {
+		t.Fatalf("Expected default bindings to parse, got %v", err)
+	}
+}
+
+func TestSetBindingsKeepsOldBindingsOnError(t *testing.T) {
+	m := NewActionMap()
+	m.SetBindings(map[string][]string{"jump": {"Space"}})
+
+	if err := m.SetBindings(map[string][]string{"jump": {"W", "Bogus"}}); err == nil {
+		t.Fatal("Expected an error for an unknown input")
+	}
+	if got := m.BindingName(ActionJump); got != "Space" {
+		t.Errorf("Expected old binding Space to be kept, got %q", got)
+	}
+}
+
+func TestActionMapPressedAndReleased(t *testing.T) {
+	m := NewActionMap()
+
+	m.SetState(State{Values: map[Action]float64{ActionJump: 1}})
+	if !m.IsPressed(ActionJump) || !m.IsJustPressed(ActionJump) {
+		t.Error("Expected jump to be just pressed")
+	}
+
+	m.SetState(State{Values: map[Action]float64{ActionJump: 1}})
+	if !m.IsPressed(ActionJump) || m.IsJustPressed(ActionJump) {
+		t.Error("Expected jump to be held but not just pressed")
+	}
+
+	m.SetState(State{})
+	if m.IsPressed(ActionJump) || !m.IsJustReleased(ActionJump) {
+		t.Error("Expected jump to be just released")
+	}
+}
+
+func TestActionMapAnalogValues(t *testing.T) {
+	m := NewActionMap()
+	m.SetState(State{Values: map[Action]float64{ActionMoveLeft: 0.3, ActionMoveRight: 0.8}})
+
+	if m.IsPressed(ActionMoveLeft) {
+		t.Error("Expected a small deflection not to count as pressed")
+	}
+	if !m.IsPressed(ActionMoveRight) {
+		t.Error("Expected a large deflection to count as pressed")
+	}
+	if axis := m.Axis(ActionMoveLeft, ActionMoveRight); axis < 0.49 || axis > 0.51 {
+		t.Errorf("Expected axis 0.5, got %v", axis)
+	}
+}
+
+func TestApplyDeadzone(t *testing.T) {
+	cases := []struct{ in, expected float64 }{
+		{-1, 0},
+		{0.1, 0},
+		{0.2, 0},
+		{0.6, 0.5},
+		{1, 1},
+		{1.2, 1},
+	}
+	for _, c := range cases {
+		if got := applyDeadzone(c.in, 0.2); got < c.expected-1e-9 || got > c.expected+1e-9 {
+			t.Errorf("applyDeadzone(%v) = %v, expected %v", c.in, got, c.expected)
+		}
+	}
+}
+
+func TestBindingNamePrefersKeyboard(t *testing.T) {
+	m := NewActionMap()
+	m.SetBindings(map[string][]string{
+		"inventory": {"Pad:X", "E"},
+		"attack":    {"Mouse:Left"},
+	})
+
+	if got := m.BindingName(ActionOpenInventory); got != "E" {
+		t.Errorf("Expected E, got %q", got)
+	}
+	if got := m.BindingName(ActionAttack); got != "Mouse:Left" {
+		t.Errorf("Expected Mouse:Left, got %q", got)
+	}
+	if got := m.BindingName(ActionPause); got != "?" {
+		t.Errorf("Expected ? for an unbound action, got %q", got)
+	}
+}
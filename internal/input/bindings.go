@@ -0,0 +1,238 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Binding prefixes for devices other than the keyboard
+const (
+	mousePrefix   = "Mouse:"
+	gamepadPrefix = "Pad:"
+)
+
+// bindingKind is the device a binding reads from
+type bindingKind int
+
+const (
+	bindKey bindingKind = iota
+	bindMouse
+	bindWheel
+	bindPadButton
+	bindPadAxis
+)
+
+// Binding is a single physical input that can trigger an action
+type Binding struct {
+	kind   bindingKind
+	key    ebiten.Key
+	mouse  ebiten.MouseButton
+	button ebiten.StandardGamepadButton
+	axis   ebiten.StandardGamepadAxis
+
+	// sign is the wheel or stick direction, -1 or 1
+	sign float64
+}
+
+var mouseButtonNames = map[string]ebiten.MouseButton{
+	"Left":   ebiten.MouseButtonLeft,
+	"Right":  ebiten.MouseButtonRight,
+	"Middle": ebiten.MouseButtonMiddle,
+}
+
+// gamepadButtonNames names the buttons of Ebiten's standard gamepad layout
+// after an Xbox controller
+var gamepadButtonNames = map[string]ebiten.StandardGamepadButton{
+	"A":     ebiten.StandardGamepadButtonRightBottom,
+	"B":     ebiten.StandardGamepadButtonRightRight,
+	"X":     ebiten.StandardGamepadButtonRightLeft,
+	"Y":     ebiten.StandardGamepadButtonRightTop,
+	"LB":    ebiten.StandardGamepadButtonFrontTopLeft,
+	"RB":    ebiten.StandardGamepadButtonFrontTopRight,
+	"LT":    ebiten.StandardGamepadButtonFrontBottomLeft,
+	"RT":    ebiten.StandardGamepadButtonFrontBottomRight,
+	"Back":  ebiten.StandardGamepadButtonCenterLeft,
+	"Start": ebiten.StandardGamepadButtonCenterRight,
+	"LS":    ebiten.StandardGamepadButtonLeftStick,
+	"RS":    ebiten.StandardGamepadButtonRightStick,
+	"Up":    ebiten.StandardGamepadButtonLeftTop,
+	"Down":  ebiten.StandardGamepadButtonLeftBottom,
+	"Left":  ebiten.StandardGamepadButtonLeftLeft,
+	"Right": ebiten.StandardGamepadButtonLeftRight,
+	"Home":  ebiten.StandardGamepadButtonCenterCenter,
+}
+
+var gamepadAxisNames = map[string]ebiten.StandardGamepadAxis{
+	"LeftStickX":  ebiten.StandardGamepadAxisLeftStickHorizontal,
+	"LeftStickY":  ebiten.StandardGamepadAxisLeftStickVertical,
+	"RightStickX": ebiten.StandardGamepadAxisRightStickHorizontal,
+	"RightStickY": ebiten.StandardGamepadAxisRightStickVertical,
+}
+
+// ParseBinding parses an input name: a key name such as "Space" (see ebiten.Key.String),
+// "Mouse:Left", "Mouse:WheelUp", a gamepad button such as "Pad:A" or a stick
+// direction such as "Pad:LeftStickX-"
+func ParseBinding(name string) (Binding, error) {
+	switch {
+	case strings.HasPrefix(name, mousePrefix):
+		button := strings.TrimPrefix(name, mousePrefix)
+		switch button {
+		case "WheelUp":
+			return Binding{kind: bindWheel, sign: 1}, nil
+		case "WheelDown":
+			return Binding{kind: bindWheel, sign: -1}, nil
+		}
+		if mouse, exists := mouseButtonNames[button]; exists {
+			return Binding{kind: bindMouse, mouse: mouse}, nil
+		}
+
+	case strings.HasPrefix(name, gamepadPrefix):
+		control := strings.TrimPrefix(name, gamepadPrefix)
+		if button, exists := gamepadButtonNames[control]; exists {
+			return Binding{kind: bindPadButton, button: button}, nil
+		}
+		if n := len(control); n > 1 {
+			sign := map[byte]float64{'-': -1, '+': 1}[control[n-1]]
+			if axis, exists := gamepadAxisNames[control[:n-1]]; exists && sign != 0 {
+				return Binding{kind: bindPadAxis, axis: axis, sign: sign}, nil
+			}
+		}
+
+	default:
+		var key ebiten.Key
+		if err := key.UnmarshalText([]byte(name)); err == nil {
+			return Binding{kind: bindKey, key: key}, nil
+		}
+	}
+	return Binding{}, fmt.Errorf("input: unknown input %q", name)
+}
+
+// String returns the name ParseBinding accepts for the binding
+func (b Binding) String() string {
+	switch b.kind {
+	case bindMouse:
+		for name, mouse := range mouseButtonNames {
+			if mouse == b.mouse {
+				return mousePrefix + name
+			}
+		}
+	case bindWheel:
+		if b.sign > 0 {
+			return mousePrefix + "WheelUp"
+		}
+		return mousePrefix + "WheelDown"
+	case bindPadButton:
+		for name, button := range gamepadButtonNames {
+			if button == b.button {
+				return gamepadPrefix + name
+			}
+		}
+	case bindPadAxis:
+		for name, axis := range gamepadAxisNames {
+			if axis == b.axis {
+				if b.sign < 0 {
+					return gamepadPrefix + name + "-"
+				}
+				return gamepadPrefix + name + "+"
+			}
+		}
+	}
+	return b.key.String()
+}
+
+// Device is the kind of device a binding belongs to
+type Device int
+
+const (
+	DeviceKeyboard Device = iota
+	DeviceMouse
+	DeviceGamepad
+)
+
+// Device returns the device the binding reads from
+func (b Binding) Device() Device {
+	switch b.kind {
+	case bindMouse, bindWheel:
+		return DeviceMouse
+	case bindPadButton, bindPadAxis:
+		return DeviceGamepad
+	}
+	return DeviceKeyboard
+}
+
+// value reads the binding from the devices: 1 for held buttons,
+// the stick deflection past deadzone (rescaled to 0-1) for stick axes
+func (b Binding) value(gamepads []ebiten.GamepadID, wheelY, deadzone float64) float64 {
+	switch b.kind {
+	case bindKey:
+		return boolValue(ebiten.IsKeyPressed(b.key))
+	case bindMouse:
+		return boolValue(ebiten.IsMouseButtonPressed(b.mouse))
+	case bindWheel:
+		return boolValue(wheelY*b.sign > 0)
+	case bindPadButton:
+		for _, id := range gamepads {
+			if ebiten.IsStandardGamepadButtonPressed(id, b.button) {
+				return 1
+			}
+		}
+	case bindPadAxis:
+		best := 0.0
+		for _, id := range gamepads {
+			best = max(best, applyDeadzone(ebiten.StandardGamepadAxisValue(id, b.axis)*b.sign, deadzone))
+		}
+		return best
+	}
+	return 0
+}
+
+// boolValue converts a button state to an action value
+func boolValue(pressed bool) float64 {
+	if pressed {
+		return 1
+	}
+	return 0
+}
+
+// applyDeadzone ignores small stick deflections and rescales the rest to 0-1
+func applyDeadzone(v, deadzone float64) float64 {
+	if v <= deadzone {
+		return 0
+	}
+	return min(1, (v-deadzone)/(1-deadzone))
+}
+
+// standardGamepads returns the connected gamepads that have the standard layout
+func standardGamepads() []ebiten.GamepadID {
+	var ids []ebiten.GamepadID
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// CaptureBinding returns the name of the first key, mouse button or gamepad button
+// pressed this frame, for rebinding actions in game
+func CaptureBinding() (string, bool) {
+	if keys := inpututil.AppendJustPressedKeys(nil); len(keys) > 0 {
+		return Binding{kind: bindKey, key: keys[0]}.String(), true
+	}
+	for _, mouse := range []ebiten.MouseButton{ebiten.MouseButtonLeft, ebiten.MouseButtonRight, ebiten.MouseButtonMiddle} {
+		if inpututil.IsMouseButtonJustPressed(mouse) {
+			return Binding{kind: bindMouse, mouse: mouse}.String(), true
+		}
+	}
+	for _, id := range standardGamepads() {
+		for _, button := range gamepadButtonNames {
+			if inpututil.IsStandardGamepadButtonJustPressed(id, button) {
+				return Binding{kind: bindPadButton, button: button}.String(), true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,112 @@
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// RecordingVersion is the replay file format written by Save
+const RecordingVersion = 1
+
+// Recording is a replay: the world seed and the input of every tick.
+// Played back against a world created with the same seed, it reproduces the run exactly.
+type Recording struct {
+	Version int   `json:"version"`
+	Seed    int64 `json:"seed"`
+
+	// Hash is the world state hash after the last tick, 0 if it was not stored
+	Hash uint64 `json:"hash,omitempty"`
+
+	Ticks []State `json:"ticks"`
+}
+
+// NewRecording creates an empty recording for a world created with seed
+func NewRecording(seed int64) *Recording {
+	return &Recording{Version: RecordingVersion, Seed: seed}
+}
+
+// LoadRecording reads a replay file
+func LoadRecording(path string) (*Recording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rec Recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("input: failed to parse replay %s: %w", path, err)
+	}
+	if rec.Version != RecordingVersion {
+		return nil, fmt.Errorf("input: replay %s has version %d, expected %d", path, rec.Version, RecordingVersion)
+	}
+	return &rec, nil
+}
+
+// Save writes the recording to path
+func (r *Recording) Save(path string) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// StartRecording records the input of every following tick into a new recording
+func (m *ActionMap) StartRecording(seed int64) {
+	m.restart()
+	m.recording = NewRecording(seed)
+}
+
+// StopRecording stops recording and returns what was recorded, or nil if nothing was
+func (m *ActionMap) StopRecording() *Recording {
+	rec := m.recording
+	m.recording = nil
+	return rec
+}
+
+// Play feeds the ticks of rec to Update instead of reading the devices.
+// The devices are read again once all ticks are used.
+func (m *ActionMap) Play(rec *Recording) {
+	m.restart()
+	m.playback = rec
+	m.playbackTick = 0
+}
+
+// Playing reports whether recorded ticks are left to play
+func (m *ActionMap) Playing() bool {
+	return m.playback != nil && m.playbackTick < len(m.playback.Ticks)
+}
+
+// StopPlayback drops the rest of the recording being played
+func (m *ActionMap) StopPlayback() {
+	m.playback = nil
+	m.playbackTick = 0
+}
+
+// restart forgets the input of earlier ticks, so the first recorded tick is
+// "just pressed" the same way when it is recorded and when it is played back
+func (m *ActionMap) restart() {
+	m.current = State{}
+	m.previous = State{}
+
+	// 下一次读取设备时重新使用鼠标位置
+	m.lastMouseX, m.lastMouseY = -1, -1
+}
+
+// nextState returns the input of a new tick from the recording being played or from the devices,
+// and records it if recording
+func (m *ActionMap) nextState() State {
+	var state State
+	if m.Playing() {
+		state = m.playback.Ticks[m.playbackTick]
+		m.playbackTick++
+	} else {
+		state = m.poll()
+	}
+
+	if m.recording != nil {
+		m.recording.Ticks = append(m.recording.Ticks, state)
+	}
+	return state
+}
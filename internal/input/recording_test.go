@@ -0,0 +1,101 @@
+package input
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func testRecording() *Recording {
+	rec := NewRecording(7)
+	rec.Ticks = []State{
+		{Values: map[Action]float64{ActionJump: 1}, CursorX: 10, CursorY: 20},
+		{Values: map[Action]float64{ActionJump: 1, ActionMoveRight: 0.75}, CursorX: 12, CursorY: 20},
+		{CursorX: 12, CursorY: 24},
+	}
+	return rec
+}
+
+func TestPlaybackFeedsRecordedTicks(t *testing.T) {
+	m := NewActionMap()
+	rec := testRecording()
+	m.Play(rec)
+
+	m.Update()
+	if !m.IsJustPressed(ActionJump) {
+		t.Error("Expected jump to be just pressed on the first tick")
+	}
+
+	m.Update()
+	if m.IsJustPressed(ActionJump) || m.Value(ActionMoveRight) != 0.75 {
+		t.Errorf("Expected the second tick to be played, got %+v", m.State())
+	}
+
+	m.Update()
+	if !m.IsJustReleased(ActionJump) {
+		t.Error("Expected jump to be released on the last tick")
+	}
+	if x, y := m.Cursor(); x != 12 || y != 24 {
+		t.Errorf("Expected cursor (12, 24), got (%v, %v)", x, y)
+	}
+	if m.Playing() {
+		t.Error("Expected playback to end after the last tick")
+	}
+}
+
+func TestPlaybackStartsWithoutEarlierInput(t *testing.T) {
+	m := NewActionMap()
+	m.SetState(State{Values: map[Action]float64{ActionJump: 1}})
+
+	m.Play(testRecording())
+	m.Update()
+	if !m.IsJustPressed(ActionJump) {
+		t.Error("Expected the first played tick not to depend on input before playback")
+	}
+}
+
+func TestRecordingWhilePlaying(t *testing.T) {
+	m := NewActionMap()
+	rec := testRecording()
+	m.Play(rec)
+	m.StartRecording(rec.Seed)
+	for m.Playing() {
+		m.Update()
+	}
+
+	recorded := m.StopRecording()
+	if recorded.Seed != rec.Seed || !reflect.DeepEqual(recorded.Ticks, rec.Ticks) {
+		t.Errorf("Expected the played ticks to be recorded, got %+v", recorded)
+	}
+	if m.StopRecording() != nil {
+		t.Error("Expected nothing to be recorded after stopping")
+	}
+}
+
+func TestRecordingSaveAndLoad(t *testing.T) {
+	rec := testRecording()
+	rec.Hash = 0xdeadbeefcafe
+	path := filepath.Join(t.TempDir(), "test.replay")
+	if err := rec.Save(path); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+
+	loaded, err := LoadRecording(path)
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, rec) {
+		t.Errorf("Expected %+v, got %+v", rec, loaded)
+	}
+}
+
+func TestLoadRecordingRejectsOtherVersions(t *testing.T) {
+	rec := testRecording()
+	rec.Version = RecordingVersion + 1
+	path := filepath.Join(t.TempDir(), "test.replay")
+	rec.Save(path)
+
+	if _, err := LoadRecording(path); err == nil {
+		t.Error("Expected an error for an unknown version")
+	}
+}
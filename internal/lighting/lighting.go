@@ -0,0 +1,392 @@
+// Package lighting propagates light over the block grid: sunlight falling from the
+// sky and light given off by blocks such as torches. Light levels go from 0 (dark)
+// to MaxLevel and drop by one per cell away from their source; opaque blocks stop
+// them.
+//
+// The world is unbounded, but light only differs from open sky near blocks, so the
+// engine stores levels for the area around them. Changes are applied lazily: placing
+// or removing a block marks the cells it can affect, which are propagated again on
+// the next query.
+package lighting
+
+import "github.com/wubinrui111/2d-game/internal/grid"
+
+// MaxLevel is the level of sunlight and of the brightest block light
+const MaxLevel = 15
+
+// Block is how a block affects light
+type Block struct {
+	Opaque   bool // Stops light, including sunlight from above
+	Emission int  // Light level the block gives off, 0 for none
+}
+
+// rect is a rectangle of cells, Max excluded
+type rect struct {
+	MinX, MinY, MaxX, MaxY int
+}
+
+func (r rect) empty() bool {
+	return r.MinX >= r.MaxX || r.MinY >= r.MaxY
+}
+
+func (r rect) contains(x, y int) bool {
+	return x >= r.MinX && x < r.MaxX && y >= r.MinY && y < r.MaxY
+}
+
+// union returns the smallest rectangle containing r and o
+func (r rect) union(o rect) rect {
+	if r.empty() {
+		return o
+	}
+	if o.empty() {
+		return r
+	}
+	return rect{min(r.MinX, o.MinX), min(r.MinY, o.MinY), max(r.MaxX, o.MaxX), max(r.MaxY, o.MaxY)}
+}
+
+// intersect returns the cells in both r and o
+func (r rect) intersect(o rect) rect {
+	return rect{max(r.MinX, o.MinX), max(r.MinY, o.MinY), min(r.MaxX, o.MaxX), min(r.MaxY, o.MaxY)}
+}
+
+// grow returns r extended by n cells on every side
+func (r rect) grow(n int) rect {
+	return rect{r.MinX - n, r.MinY - n, r.MaxX + n, r.MaxY + n}
+}
+
+// Engine keeps the light levels of the world up to date as blocks change
+type Engine struct {
+	blocks map[grid.Cell]Block
+	top    map[int]int // Y of the highest opaque block of each column that has one
+
+	// bounds covers every block placed so far. Levels are stored for region, bounds
+	// grown by more than MaxLevel: nothing farther away is lit by the blocks, and
+	// cells outside of it are lit as computed by sky.
+	bounds rect
+	region rect
+	sky    []uint8 // Sunlight of the region's cells, row by row
+	block  []uint8 // Block light of the region's cells
+
+	dirty rect // Cells to propagate again
+	grow  bool // Whether the region has to grow to cover bounds
+
+	// ambient is the level of sunlight in the open, lower at night. It only scales
+	// what the cells get, so changing it propagates nothing.
+	ambient float64
+}
+
+// New creates an engine for a world without blocks, in full daylight
+func New() *Engine {
+	return &Engine{blocks: make(map[grid.Cell]Block), top: make(map[int]int), ambient: MaxLevel}
+}
+
+// SetAmbient sets the level of sunlight in the open, from 0 to MaxLevel. Sunlight
+// reaching a cell is dimmed by as much as the ambient level is below MaxLevel.
+func (e *Engine) SetAmbient(level float64) {
+	e.ambient = max(0, min(MaxLevel, level))
+}
+
+// Ambient returns the level of sunlight in the open
+func (e *Engine) Ambient() float64 {
+	return e.ambient
+}
+
+// Set places a block at a cell, replacing the block there. Blocks that neither stop
+// nor give off light are the same as no block.
+func (e *Engine) Set(x, y int, b Block) {
+	if !b.Opaque && b.Emission <= 0 {
+		e.Remove(x, y)
+		return
+	}
+	c := grid.Cell{X: x, Y: y}
+	if old, exists := e.blocks[c]; exists && old == b {
+		return
+	}
+	e.blocks[c] = b
+	if !e.bounds.contains(x, y) {
+		e.bounds = e.bounds.union(rect{x, y, x + 1, y + 1})
+		e.grow = true
+	}
+	e.changed(x, y)
+}
+
+// Remove removes the block at a cell
+func (e *Engine) Remove(x, y int) {
+	c := grid.Cell{X: x, Y: y}
+	if _, exists := e.blocks[c]; !exists {
+		return
+	}
+	delete(e.blocks, c)
+	e.changed(x, y)
+}
+
+// Reset removes all blocks, keeping the ambient level
+func (e *Engine) Reset() {
+	ambient := e.ambient
+	*e = *New()
+	e.ambient = ambient
+}
+
+// changed updates the highest opaque block of the column after the block at x, y
+// changed, and marks the cells whose light it can change
+func (e *Engine) changed(x, y int) {
+	oldTop, hadTop := e.top[x]
+	e.updateTop(x, y)
+	newTop, hasTop := e.top[x]
+
+	// 列的最高方块变化时，新旧最高方块之间的格子是否被阳光直射也变了
+	minY, maxY := y, y
+	for _, top := range [2]struct {
+		y      int
+		exists bool
+	}{{oldTop, hadTop}, {newTop, hasTop}} {
+		if top.exists {
+			minY, maxY = min(minY, top.y), max(maxY, top.y)
+		} else {
+			maxY = max(maxY, e.region.MaxY)
+		}
+	}
+	e.dirty = e.dirty.union(rect{x, minY, x + 1, maxY + 1}.grow(MaxLevel))
+}
+
+// updateTop finds the highest opaque block of column x after the cell at y changed
+func (e *Engine) updateTop(x, y int) {
+	top, exists := e.top[x]
+	if e.blocks[grid.Cell{X: x, Y: y}].Opaque {
+		if !exists || y < top {
+			e.top[x] = y
+		}
+		return
+	}
+	if !exists || y != top {
+		return
+	}
+	delete(e.top, x)
+	for below := y + 1; below < e.bounds.MaxY; below++ {
+		if e.blocks[grid.Cell{X: x, Y: below}].Opaque {
+			e.top[x] = below
+			return
+		}
+	}
+}
+
+// Opaque reports whether the block at a cell stops light
+func (e *Engine) Opaque(x, y int) bool {
+	return e.blocks[grid.Cell{X: x, Y: y}].Opaque
+}
+
+// exposed reports whether nothing above a cell stops sunlight
+func (e *Engine) exposed(x, y int) bool {
+	top, exists := e.top[x]
+	return !exists || y < top
+}
+
+// Sky returns the level of sunlight at a cell in full daylight
+func (e *Engine) Sky(x, y int) int {
+	e.update()
+	return e.skyAt(x, y)
+}
+
+// BlockLight returns the level of light from blocks at a cell
+func (e *Engine) BlockLight(x, y int) int {
+	e.update()
+	return e.blockAt(x, y)
+}
+
+// Level returns the light level of a cell, rounded down: the brighter of sunlight,
+// dimmed by the ambient level, and block light
+func (e *Engine) Level(x, y int) int {
+	return int(e.level(x, y))
+}
+
+// Display returns the light level a cell is drawn with. Opaque blocks have no light
+// of their own, so they are lit like their brightest open neighbor and the surface
+// of the ground catches the light around it.
+func (e *Engine) Display(x, y int) float64 {
+	if !e.Opaque(x, y) {
+		return e.level(x, y)
+	}
+	level := float64(e.BlockLight(x, y))
+	for _, n := range (grid.Cell{X: x, Y: y}).Neighbors() {
+		if !e.Opaque(n.X, n.Y) {
+			level = max(level, e.level(n.X, n.Y))
+		}
+	}
+	return level
+}
+
+// level returns the light level of a cell
+func (e *Engine) level(x, y int) float64 {
+	sky := max(0, float64(e.Sky(x, y))-(MaxLevel-e.ambient))
+	return max(sky, float64(e.BlockLight(x, y)))
+}
+
+// skyAt returns the sunlight of a cell without applying changes first
+func (e *Engine) skyAt(x, y int) int {
+	if e.Opaque(x, y) {
+		return 0
+	}
+	if e.exposed(x, y) {
+		return MaxLevel
+	}
+	if e.region.contains(x, y) {
+		return int(e.sky[e.index(x, y)])
+	}
+
+	// 区域外被遮住的格子在所有方块下方，只有附近没有方块的列照得到
+	for d := 1; d < MaxLevel; d++ {
+		if e.exposed(x-d, y) || e.exposed(x+d, y) {
+			return MaxLevel - d
+		}
+	}
+	return 0
+}
+
+// blockAt returns the block light of a cell without applying changes first
+func (e *Engine) blockAt(x, y int) int {
+	if !e.region.contains(x, y) {
+		return 0
+	}
+	return int(e.block[e.index(x, y)])
+}
+
+// index returns the position of a region cell in the level slices
+func (e *Engine) index(x, y int) int {
+	return (y-e.region.MinY)*(e.region.MaxX-e.region.MinX) + x - e.region.MinX
+}
+
+// update propagates light again where blocks changed since the last update
+func (e *Engine) update() {
+	var area []rect
+	if e.grow {
+		e.grow = false
+		area = e.growRegion(e.bounds.grow(MaxLevel + 1))
+	}
+	if box := e.dirty.intersect(e.region); !box.empty() {
+		area = append(area, box)
+	}
+	e.dirty = rect{}
+	if len(area) == 0 {
+		return
+	}
+
+	e.propagate(area, e.sky, func(x, y int) int {
+		if e.exposed(x, y) && !e.Opaque(x, y) {
+			return MaxLevel
+		}
+		return 0
+	}, e.skyAt)
+	e.propagate(area, e.block, func(x, y int) int {
+		return min(e.blocks[grid.Cell{X: x, Y: y}].Emission, MaxLevel)
+	}, e.blockAt)
+}
+
+// growRegion grows the region to r, keeping the levels of the cells it already had,
+// and returns the new cells, which have to be propagated. The old levels stay right:
+// no blocks are near the new cells, so they were lit the way skyAt lights cells
+// outside of the region.
+func (e *Engine) growRegion(r rect) []rect {
+	old, sky, block := e.region, e.sky, e.block
+	e.region = r
+	size := (r.MaxX - r.MinX) * (r.MaxY - r.MinY)
+	e.sky = make([]uint8, size)
+	e.block = make([]uint8, size)
+	if old.empty() {
+		return []rect{r}
+	}
+
+	// 旧区域的亮度按行复制到新位置
+	width := old.MaxX - old.MinX
+	for y := old.MinY; y < old.MaxY; y++ {
+		from := (y - old.MinY) * width
+		to := e.index(old.MinX, y)
+		copy(e.sky[to:to+width], sky[from:from+width])
+		copy(e.block[to:to+width], block[from:from+width])
+	}
+
+	// 新格子围在旧区域四周
+	var area []rect
+	for _, part := range [4]rect{
+		{r.MinX, r.MinY, r.MaxX, old.MinY},
+		{r.MinX, old.MaxY, r.MaxX, r.MaxY},
+		{r.MinX, old.MinY, old.MinX, old.MaxY},
+		{old.MaxX, old.MinY, r.MaxX, old.MaxY},
+	} {
+		if !part.empty() {
+			area = append(area, part)
+		}
+	}
+	return area
+}
+
+// propagate computes the levels of the cells in area from the sources in it and the
+// light of the cells around it, which the changes did not reach
+func (e *Engine) propagate(area []rect, levels []uint8, source func(x, y int) int, around func(x, y int) int) {
+	inArea := func(x, y int) bool {
+		for _, box := range area {
+			if box.contains(x, y) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// 按亮度从高到低处理，每个格子只需扩散一次
+	var queues [MaxLevel + 1][]grid.Cell
+	light := func(x, y, level int) {
+		if level <= 0 {
+			return
+		}
+		i := e.index(x, y)
+		if int(levels[i]) >= level {
+			return
+		}
+		levels[i] = uint8(level)
+		queues[level] = append(queues[level], grid.Cell{X: x, Y: y})
+	}
+	enter := func(x, y, fromX, fromY int) {
+		if !e.Opaque(x, y) && !inArea(fromX, fromY) {
+			light(x, y, around(fromX, fromY)-1)
+		}
+	}
+
+	for _, box := range area {
+		for y := box.MinY; y < box.MaxY; y++ {
+			for x := box.MinX; x < box.MaxX; x++ {
+				levels[e.index(x, y)] = 0
+			}
+		}
+	}
+	for _, box := range area {
+		for y := box.MinY; y < box.MaxY; y++ {
+			for x := box.MinX; x < box.MaxX; x++ {
+				light(x, y, source(x, y))
+			}
+		}
+	}
+	// 区域外的格子这次不变，它们的光从边上照进来
+	for _, box := range area {
+		for x := box.MinX; x < box.MaxX; x++ {
+			enter(x, box.MinY, x, box.MinY-1)
+			enter(x, box.MaxY-1, x, box.MaxY)
+		}
+		for y := box.MinY; y < box.MaxY; y++ {
+			enter(box.MinX, y, box.MinX-1, y)
+			enter(box.MaxX-1, y, box.MaxX, y)
+		}
+	}
+
+	for level := MaxLevel; level > 1; level-- {
+		for i := 0; i < len(queues[level]); i++ {
+			c := queues[level][i]
+			if int(levels[e.index(c.X, c.Y)]) != level {
+				continue
+			}
+			for _, n := range c.Neighbors() {
+				if inArea(n.X, n.Y) && !e.Opaque(n.X, n.Y) {
+					light(n.X, n.Y, level-1)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package lighting
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/wubinrui111/2d-game/internal/grid"
+)
+
+var (
+	stone = Block{Opaque: true}
+	torch = Block{Emission: 14}
+	lava  = Block{Opaque: true, Emission: MaxLevel}
+)
+
+// reference computes the sky and block light of the cells in window by relaxing
+// every cell until nothing changes, without any of the engine's shortcuts
+func reference(blocks map[grid.Cell]Block, window rect) (sky, block map[grid.Cell]int) {
+	top := make(map[int]int)
+	for c, b := range blocks {
+		if t, exists := top[c.X]; b.Opaque && (!exists || c.Y < t) {
+			top[c.X] = c.Y
+		}
+	}
+	exposed := func(x, y int) bool {
+		t, exists := top[x]
+		return !exists || y < t
+	}
+	sky, block = make(map[grid.Cell]int), make(map[grid.Cell]int)
+	for y := window.MinY; y < window.MaxY; y++ {
+		for x := window.MinX; x < window.MaxX; x++ {
+			c := grid.Cell{X: x, Y: y}
+			if exposed(x, y) {
+				sky[c] = MaxLevel
+			}
+			block[c] = blocks[c].Emission
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for y := window.MinY; y < window.MaxY; y++ {
+			for x := window.MinX; x < window.MaxX; x++ {
+				c := grid.Cell{X: x, Y: y}
+				if blocks[c].Opaque {
+					continue
+				}
+				for _, n := range c.Neighbors() {
+					if sky[n]-1 > sky[c] {
+						sky[c], changed = sky[n]-1, true
+					}
+					if block[n]-1 > block[c] {
+						block[c], changed = block[n]-1, true
+					}
+				}
+			}
+		}
+	}
+	return sky, block
+}
+
+// check compares the engine with the reference on every cell of window
+func check(t *testing.T, e *Engine, blocks map[grid.Cell]Block, window rect) {
+	t.Helper()
+	sky, block := reference(blocks, window.grow(2*MaxLevel))
+	for y := window.MinY; y < window.MaxY; y++ {
+		for x := window.MinX; x < window.MaxX; x++ {
+			c := grid.Cell{X: x, Y: y}
+			if got := e.Sky(x, y); got != sky[c] {
+				t.Fatalf("Expected sky light %d at %v, got %d", sky[c], c, got)
+			}
+			if got := e.BlockLight(x, y); got != block[c] {
+				t.Fatalf("Expected block light %d at %v, got %d", block[c], c, got)
+			}
+		}
+	}
+}
+
+func TestSkyLight(t *testing.T) {
+	e := New()
+	if e.Sky(3, 100) != MaxLevel || e.BlockLight(3, 100) != 0 {
+		t.Fatal("Expected full sunlight and no block light without blocks")
+	}
+
+	// 一层地面，下方一格处的洞只能从侧面照到
+	for x := -20; x <= 20; x++ {
+		e.Set(x, 0, stone)
+	}
+	if got := e.Sky(0, -1); got != MaxLevel {
+		t.Errorf("Expected full sunlight above the ground, got %d", got)
+	}
+	if got := e.Sky(0, 0); got != 0 {
+		t.Errorf("Expected no light in an opaque block, got %d", got)
+	}
+	if got := e.Sky(0, 1); got != 0 {
+		t.Errorf("Expected no sunlight 21 cells from the edge of the ground, got %d", got)
+	}
+	if got := e.Sky(20, 1); got != MaxLevel-1 {
+		t.Errorf("Expected sunlight one step from the edge, got %d", got)
+	}
+	if got := e.Display(0, 0); got != MaxLevel {
+		t.Errorf("Expected the ground to be drawn lit from above, got %v", got)
+	}
+
+	// 挖开一格后阳光直射下去
+	e.Remove(0, 0)
+	if got := e.Sky(0, 5); got != MaxLevel {
+		t.Errorf("Expected sunlight through the hole, got %d", got)
+	}
+	if got := e.Sky(3, 1); got != MaxLevel-3 {
+		t.Errorf("Expected sunlight to spread from the hole, got %d", got)
+	}
+}
+
+func TestBlockLight(t *testing.T) {
+	e := New()
+	e.Set(0, 0, torch)
+	if got := e.BlockLight(0, 0); got != 14 {
+		t.Errorf("Expected the torch's light at its cell, got %d", got)
+	}
+	if got := e.BlockLight(3, -2); got != 9 {
+		t.Errorf("Expected the light to drop by one per cell, got %d", got)
+	}
+	if got := e.BlockLight(14, 0); got != 0 {
+		t.Errorf("Expected no light 14 cells away, got %d", got)
+	}
+
+	// 墙挡住光，光只能绕过去
+	for y := -3; y <= 3; y++ {
+		e.Set(1, y, stone)
+	}
+	if got := e.BlockLight(2, 0); got != 14-10 {
+		t.Errorf("Expected the light to go around the wall, got %d", got)
+	}
+	if got := e.Display(1, 0); got != MaxLevel {
+		t.Errorf("Expected the wall to be drawn lit by its open neighbors, got %v", got)
+	}
+
+	e.Remove(0, 0)
+	if got := e.Level(-1, 0); got != MaxLevel {
+		t.Errorf("Expected only sunlight after removing the torch, got %d", got)
+	}
+	if got := e.BlockLight(-1, 0); got != 0 {
+		t.Errorf("Expected no block light after removing the torch, got %d", got)
+	}
+}
+
+func TestRandomUpdates(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	e := New()
+	blocks := make(map[grid.Cell]Block)
+	window := rect{-12, -12, 12, 12}
+
+	// 先放一块地面，再随机放置和移除方块，每次修改后与参考结果比较
+	for x := -10; x < 10; x++ {
+		for y := 2; y < 6; y++ {
+			e.Set(x, y, stone)
+			blocks[grid.Cell{X: x, Y: y}] = stone
+		}
+	}
+	check(t, e, blocks, window)
+
+	kinds := []Block{stone, stone, torch, lava, {}}
+	for i := 0; i < 150; i++ {
+		c := grid.Cell{X: rng.Intn(20) - 10, Y: rng.Intn(16) - 8}
+		b := kinds[rng.Intn(len(kinds))]
+		if b == (Block{}) {
+			e.Remove(c.X, c.Y)
+			delete(blocks, c)
+		} else {
+			e.Set(c.X, c.Y, b)
+			blocks[c] = b
+		}
+		// 有时连续修改几次再查询
+		if rng.Intn(3) == 0 {
+			check(t, e, blocks, window)
+		}
+	}
+	check(t, e, blocks, window.grow(MaxLevel))
+}
+
+func TestAmbient(t *testing.T) {
+	e := New()
+	e.Set(0, 0, torch)
+	e.SetAmbient(4)
+	if got := e.Level(5, 0); got != 9 {
+		t.Errorf("Expected the torch to outshine the night sky, got %d", got)
+	}
+	if got := e.Level(20, 0); got != 4 {
+		t.Errorf("Expected the ambient level in the open, got %d", got)
+	}
+	if got := e.Sky(20, 0); got != MaxLevel {
+		t.Errorf("Expected Sky to ignore the ambient level, got %d", got)
+	}
+	if got := e.Display(30, 0); got != 4 {
+		t.Errorf("Expected the ambient level to be drawn, got %v", got)
+	}
+
+	e.SetAmbient(-3)
+	if e.Ambient() != 0 || e.Level(20, 0) != 0 {
+		t.Errorf("Expected the ambient level to be clamped, got %v", e.Ambient())
+	}
+	e.Reset()
+	if e.Ambient() != 0 {
+		t.Error("Expected a reset to keep the ambient level")
+	}
+}
+
+func TestReset(t *testing.T) {
+	e := New()
+	e.Set(0, 0, lava)
+	e.Reset()
+	if e.Opaque(0, 0) || e.BlockLight(0, 1) != 0 {
+		t.Error("Expected no blocks after a reset")
+	}
+}
+
+func TestRegionGrowsIncrementally(t *testing.T) {
+	// 在一大片地面旁边放一个方块，只有区域新增的格子需要计算
+	e := New()
+	for x := -100; x < 100; x++ {
+		for y := 0; y < 100; y++ {
+			e.Set(x, y, stone)
+		}
+	}
+	e.Sky(0, 0)
+	e.Set(100, 0, stone)
+	e.grow = false
+	area := 0
+	for _, box := range e.growRegion(e.bounds.grow(MaxLevel + 1)) {
+		area += (box.MaxX - box.MinX) * (box.MaxY - box.MinY)
+	}
+	if height := e.region.MaxY - e.region.MinY; area != height {
+		t.Errorf("Expected growing the region by a column to light only its %d cells, got %d", height, area)
+	}
+
+	// 地面上有洞穴和火把，方块向四周放得越来越远，每次增长后都和参考结果一致
+	e = New()
+	blocks := make(map[grid.Cell]Block)
+	set := func(x, y int, b Block) {
+		e.Set(x, y, b)
+		blocks[grid.Cell{X: x, Y: y}] = b
+	}
+	for x := -30; x < 30; x++ {
+		for y := 0; y < 30; y++ {
+			if x*x+(y-15)*(y-15) > 36 {
+				set(x, y, stone)
+			}
+		}
+	}
+	set(0, 15, torch)
+	check(t, e, blocks, rect{-10, 5, 10, 25})
+	for _, c := range []grid.Cell{{X: 35, Y: 10}, {X: -37, Y: 25}, {X: 0, Y: 45}, {X: 10, Y: -20}, {X: 45, Y: 50}, {X: -45, Y: -25}} {
+		set(c.X, c.Y, stone)
+		set(c.X+1, c.Y+2, lava)
+		check(t, e, blocks, rect{c.X - 20, c.Y - 20, c.X + 20, c.Y + 20})
+	}
+	check(t, e, blocks, rect{-50, -30, 50, 60})
+}
@@ -1,27 +1,243 @@
 package managers
 
 import (
+	"image/color"
+
 	"github.com/wubinrui111/2d-game/internal/scenes"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
+// DefaultFadeFrames is the length of each half of a fade transition, in frames
+const DefaultFadeFrames = 20
+
+// transition is a pending change to the scene stack
+type transition struct {
+	apply func()
+	fade  bool
+}
+
+// SceneManager keeps a stack of scenes. Only the top scene is updated;
+// it is drawn on top of the scenes below it when it is an overlay.
 type SceneManager struct {
-	currentScene scenes.Scene
+	stack []scenes.Scene
+
+	// FadeFrames is the number of frames used to fade out and again to fade in
+	FadeFrames int
+
+	pending []transition
+
+	// 渐变状态：fadeOut 为 true 时画面逐渐变黑，变黑后执行 fadeApply
+	fading    bool
+	fadeOut   bool
+	fadeFrame int
+	fadeApply func()
+
+	quit bool
 }
 
 func NewSceneManager() *SceneManager {
-	return &SceneManager{}
+	return &SceneManager{FadeFrames: DefaultFadeFrames}
 }
 
+// SetScene replaces the whole stack with scene immediately, without a transition
 func (sm *SceneManager) SetScene(scene scenes.Scene) {
-	sm.currentScene = scene
+	sm.reset(scene)
+}
+
+// Current returns the top scene, or nil if the stack is empty
+func (sm *SceneManager) Current() scenes.Scene {
+	if len(sm.stack) == 0 {
+		return nil
+	}
+	return sm.stack[len(sm.stack)-1]
+}
+
+// Len returns the number of scenes on the stack
+func (sm *SceneManager) Len() int {
+	return len(sm.stack)
+}
+
+// Transitioning reports whether a fade transition is in progress
+func (sm *SceneManager) Transitioning() bool {
+	return sm.fading
+}
+
+// Push shows scene on top of the current one
+func (sm *SceneManager) Push(scene scenes.Scene) {
+	sm.pending = append(sm.pending, transition{apply: func() { sm.push(scene) }})
+}
+
+// Pop closes the top scene
+func (sm *SceneManager) Pop() {
+	sm.pending = append(sm.pending, transition{apply: sm.pop})
+}
+
+// Replace swaps the top scene for scene with a fade transition
+func (sm *SceneManager) Replace(scene scenes.Scene) {
+	sm.pending = append(sm.pending, transition{apply: func() { sm.replace(scene) }, fade: true})
+}
+
+// Reset closes every scene and shows scene with a fade transition
+func (sm *SceneManager) Reset(scene scenes.Scene) {
+	sm.pending = append(sm.pending, transition{apply: func() { sm.reset(scene) }, fade: true})
+}
+
+// Quit makes the next Update return ebiten.Termination
+func (sm *SceneManager) Quit() {
+	sm.quit = true
+}
+
+// enter prepares a scene that is added to the stack
+func (sm *SceneManager) enter(scene scenes.Scene) {
+	if setter, ok := scene.(scenes.NavigatorSetter); ok {
+		setter.SetNavigator(sm)
+	}
+	if enterer, ok := scene.(scenes.Enterer); ok {
+		enterer.OnEnter()
+	}
+}
+
+// exit notifies a scene that is removed from the stack
+func exit(scene scenes.Scene) {
+	if exiter, ok := scene.(scenes.Exiter); ok {
+		exiter.OnExit()
+	}
+}
+
+func (sm *SceneManager) push(scene scenes.Scene) {
+	if pauser, ok := sm.Current().(scenes.Pauser); ok {
+		pauser.OnPause()
+	}
+	sm.stack = append(sm.stack, scene)
+	sm.enter(scene)
+}
+
+func (sm *SceneManager) pop() {
+	if len(sm.stack) == 0 {
+		return
+	}
+	top := sm.stack[len(sm.stack)-1]
+	sm.stack = sm.stack[:len(sm.stack)-1]
+	exit(top)
+
+	if resumer, ok := sm.Current().(scenes.Resumer); ok {
+		resumer.OnResume()
+	}
+}
+
+func (sm *SceneManager) replace(scene scenes.Scene) {
+	if len(sm.stack) == 0 {
+		sm.push(scene)
+		return
+	}
+	top := sm.stack[len(sm.stack)-1]
+	sm.stack[len(sm.stack)-1] = scene
+	exit(top)
+	sm.enter(scene)
+}
+
+func (sm *SceneManager) reset(scene scenes.Scene) {
+	// 从栈顶开始依次退出
+	for i := len(sm.stack) - 1; i >= 0; i-- {
+		exit(sm.stack[i])
+	}
+	sm.stack = []scenes.Scene{scene}
+	sm.enter(scene)
+}
+
+// applyPending runs the stack changes requested during the last update.
+// Changes with a fade start a transition and wait for the screen to go black.
+func (sm *SceneManager) applyPending() {
+	for len(sm.pending) > 0 && !sm.fading {
+		next := sm.pending[0]
+		sm.pending = sm.pending[1:]
+
+		if next.fade && sm.FadeFrames > 0 {
+			sm.fading = true
+			sm.fadeOut = true
+			sm.fadeFrame = 0
+			sm.fadeApply = next.apply
+			return
+		}
+		next.apply()
+	}
+}
+
+// updateFade advances the fade transition by one frame
+func (sm *SceneManager) updateFade() {
+	sm.fadeFrame++
+	if sm.fadeFrame < sm.FadeFrames {
+		return
+	}
+
+	if sm.fadeOut {
+		// 画面全黑时切换场景，然后淡入
+		sm.fadeApply()
+		sm.fadeApply = nil
+		sm.fadeOut = false
+		sm.fadeFrame = 0
+		return
+	}
+	sm.fading = false
+}
+
+// fadeAlpha returns the opacity of the black fade overlay
+func (sm *SceneManager) fadeAlpha() float64 {
+	if !sm.fading || sm.FadeFrames <= 0 {
+		return 0
+	}
+	progress := float64(sm.fadeFrame) / float64(sm.FadeFrames)
+	if sm.fadeOut {
+		return progress
+	}
+	return 1 - progress
 }
 
 func (sm *SceneManager) Update() error {
-	return sm.currentScene.Update()
+	if sm.quit {
+		return ebiten.Termination
+	}
+
+	// 过渡期间冻结所有场景
+	if sm.fading {
+		sm.updateFade()
+		if !sm.fading {
+			sm.applyPending()
+		}
+		return nil
+	}
+
+	if current := sm.Current(); current != nil {
+		if err := current.Update(); err != nil {
+			return err
+		}
+	}
+	sm.applyPending()
+
+	if sm.quit {
+		return ebiten.Termination
+	}
+	return nil
 }
 
 func (sm *SceneManager) Draw(screen *ebiten.Image) {
-	sm.currentScene.Draw(screen)
-}
\ No newline at end of file
+	// 从最上层向下找到第一个不是覆盖层的场景，从它开始绘制
+	start := len(sm.stack) - 1
+	for start > 0 {
+		if overlay, ok := sm.stack[start].(scenes.Overlay); !ok || !overlay.IsOverlay() {
+			break
+		}
+		start--
+	}
+	for i := max(start, 0); i < len(sm.stack); i++ {
+		sm.stack[i].Draw(screen)
+	}
+
+	if alpha := sm.fadeAlpha(); alpha > 0 {
+		bounds := screen.Bounds()
+		vector.DrawFilledRect(screen, float32(bounds.Min.X), float32(bounds.Min.Y),
+			float32(bounds.Dx()), float32(bounds.Dy()), color.RGBA{0, 0, 0, uint8(255 * alpha)}, false)
+	}
+}
@@ -0,0 +1,141 @@
+package managers
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/wubinrui111/2d-game/internal/scenes"
+)
+
+// recordingScene logs its lifecycle events into a shared slice
+type recordingScene struct {
+	name    string
+	events  *[]string
+	updates int
+	nav     scenes.Navigator
+}
+
+func (s *recordingScene) Update() error                     { s.updates++; return nil }
+func (s *recordingScene) Draw(*ebiten.Image)                {}
+func (s *recordingScene) OnEnter()                          { *s.events = append(*s.events, s.name+".enter") }
+func (s *recordingScene) OnExit()                           { *s.events = append(*s.events, s.name+".exit") }
+func (s *recordingScene) OnPause()                          { *s.events = append(*s.events, s.name+".pause") }
+func (s *recordingScene) OnResume()                         { *s.events = append(*s.events, s.name+".resume") }
+func (s *recordingScene) SetNavigator(nav scenes.Navigator) { s.nav = nav }
+
+func TestSceneManagerPushPop(t *testing.T) {
+	var events []string
+	world := &recordingScene{name: "world", events: &events}
+	pause := &recordingScene{name: "pause", events: &events}
+
+	sm := NewSceneManager()
+	sm.SetScene(world)
+	if world.nav == nil {
+		t.Fatal("Expected the navigator to be set on the scene")
+	}
+
+	sm.Push(pause)
+	if sm.Len() != 1 {
+		t.Fatal("Expected push to wait for the next update")
+	}
+	sm.Update()
+
+	if sm.Current() != pause || sm.Len() != 2 {
+		t.Fatalf("Expected pause scene on top of a stack of 2, got %d scenes", sm.Len())
+	}
+
+	// Only the top scene is updated
+	sm.Update()
+	if world.updates != 1 || pause.updates != 1 {
+		t.Errorf("Expected only the top scene to update, got world=%d pause=%d", world.updates, pause.updates)
+	}
+
+	sm.Pop()
+	sm.Update()
+
+	expected := []string{"world.enter", "world.pause", "pause.enter", "pause.exit", "world.resume"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected events %v, got %v", expected, events)
+	}
+}
+
+func TestSceneManagerReplaceFades(t *testing.T) {
+	var events []string
+	title := &recordingScene{name: "title", events: &events}
+	world := &recordingScene{name: "world", events: &events}
+
+	sm := NewSceneManager()
+	sm.FadeFrames = 3
+	sm.SetScene(title)
+
+	sm.Replace(world)
+	sm.Update()
+	if !sm.Transitioning() || sm.Current() != title {
+		t.Fatal("Expected the old scene to stay while fading out")
+	}
+
+	// Fade out completes and the scene is swapped
+	for i := 0; i < 3; i++ {
+		sm.Update()
+	}
+	if sm.Current() != world {
+		t.Fatal("Expected the new scene after fading out")
+	}
+	if !sm.Transitioning() {
+		t.Fatal("Expected the new scene to fade in")
+	}
+
+	for i := 0; i < 3; i++ {
+		sm.Update()
+	}
+	if sm.Transitioning() {
+		t.Error("Expected the transition to be finished")
+	}
+	if title.updates != 1 || world.updates != 0 {
+		t.Errorf("Expected scenes to be frozen during the transition, got title=%d world=%d", title.updates, world.updates)
+	}
+
+	expected := []string{"title.enter", "title.exit", "world.enter"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected events %v, got %v", expected, events)
+	}
+}
+
+func TestSceneManagerReset(t *testing.T) {
+	var events []string
+	world := &recordingScene{name: "world", events: &events}
+	pause := &recordingScene{name: "pause", events: &events}
+	title := &recordingScene{name: "title", events: &events}
+
+	sm := NewSceneManager()
+	sm.FadeFrames = 0
+	sm.SetScene(world)
+	sm.Push(pause)
+	sm.Update()
+
+	events = nil
+	sm.Reset(title)
+	sm.Update()
+
+	if sm.Len() != 1 || sm.Current() != title {
+		t.Fatalf("Expected only the title scene to remain, got %d scenes", sm.Len())
+	}
+
+	expected := []string{"pause.exit", "world.exit", "title.enter"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected events %v, got %v", expected, events)
+	}
+}
+
+func TestSceneManagerQuit(t *testing.T) {
+	var events []string
+	sm := NewSceneManager()
+	sm.SetScene(&recordingScene{name: "title", events: &events})
+
+	sm.Quit()
+	if err := sm.Update(); !errors.Is(err, ebiten.Termination) {
+		t.Errorf("Expected ebiten.Termination, got %v", err)
+	}
+}
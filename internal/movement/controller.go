@@ -0,0 +1,220 @@
+// Package movement turns movement input into player velocity.
+// It does not read devices, so it can be tested and replayed without a window.
+package movement
+
+import (
+	"math"
+
+	"github.com/wubinrui111/2d-game/internal/components"
+)
+
+// DefaultDoubleTapTime is the longest time between two jump presses that toggles flight
+const DefaultDoubleTapTime = 0.3
+
+// Input is the movement input of one tick
+type Input struct {
+	Horizontal float64 // -1 (left) to 1 (right), analog sticks give values in between
+	Down       float64 // 0 to 1
+	Jump       bool    // Whether jump is held
+}
+
+// Body is the moving entity's state and parameters, read and changed by the controller
+type Body struct {
+	Velocity     *components.Velocity
+	Acceleration *components.Acceleration
+	Jump         *components.Jump
+	Gravity      *components.Gravity
+
+	OnGround  bool    // Ground contact found by the last collision check
+	InWater   bool    // Whether the body is in water or another fluid
+	Submerged float64 // How much of the body is in the fluid, 0 to 1, for buoyancy
+}
+
+// Controller is the player movement controller. It picks the movement mode and keeps
+// the state that spans ticks: coyote time, the jump buffer and whether the current
+// jump can still be cut short.
+type Controller struct {
+	// Modes holds the physics of every movement mode
+	Modes map[Mode]ModeParams
+
+	// CanFly allows toggling flight by pressing jump twice (creative mode)
+	CanFly bool
+
+	// Spectator moves freely through blocks
+	Spectator bool
+
+	// DoubleTapTime is the longest time between two jump presses that toggles flight
+	DoubleTapTime float64
+
+	mode   Mode
+	flying bool
+
+	coyoteTimer    float64 // Time left to jump after leaving the ground
+	bufferTimer    float64 // Time left for a jump pressed before landing
+	sinceJumpPress float64 // Time since the last jump press, for double taps
+	jumpHeld       bool    // Whether jump was held last tick
+	rising         bool    // Whether the player is rising from a jump that can be cut
+}
+
+// NewController creates a controller with the default mode physics
+func NewController() *Controller {
+	return &Controller{
+		Modes:          DefaultModes(),
+		DoubleTapTime:  DefaultDoubleTapTime,
+		sinceJumpPress: math.Inf(1),
+	}
+}
+
+// Mode returns the movement mode of the last tick
+func (c *Controller) Mode() Mode {
+	return c.mode
+}
+
+// Reset forgets the jump state and stops flying, for example after respawning
+func (c *Controller) Reset() {
+	c.mode = ModeWalk
+	c.flying = false
+	c.coyoteTimer = 0
+	c.bufferTimer = 0
+	c.sinceJumpPress = math.Inf(1)
+	c.jumpHeld = false
+	c.rising = false
+}
+
+// Update applies one tick of input, gravity, drag and speed caps to the body's velocity.
+// dt is the tick length in seconds.
+func (c *Controller) Update(in Input, dt float64, body Body) {
+	pressed := in.Jump && !c.jumpHeld
+	c.jumpHeld = in.Jump
+	c.updateMode(pressed, dt, body)
+
+	params := c.Modes[c.mode]
+	velocity := body.Velocity
+	if c.mode == ModeWalk {
+		c.walk(in, pressed, dt, body)
+	} else {
+		c.rising = false
+
+		// 飞行、游泳和旁观模式：跳跃键上升，下移键下降
+		vertical := in.Down
+		if in.Jump {
+			vertical -= 1
+		}
+		velocity.X += in.Horizontal * params.Speed * dt
+		velocity.Y += vertical * params.Speed * dt
+	}
+
+	// 重力，浸在流体中的部分受到浮力
+	if body.Gravity != nil && body.Gravity.Enabled {
+		velocity.Y += body.Gravity.Force * (params.GravityScale - params.Buoyancy*body.Submerged) * dt
+	}
+
+	// 阻力：行走时使用地面摩擦或空气阻力，只作用于水平方向
+	if c.mode == ModeWalk {
+		if body.OnGround {
+			velocity.X *= body.Acceleration.GroundFriction
+		} else {
+			velocity.X *= body.Acceleration.AirResistance
+		}
+	} else {
+		velocity.X *= params.Drag
+		velocity.Y *= params.Drag
+	}
+
+	// 速度上限（包括下落的终端速度）
+	velocity.X = math.Max(-params.MaxSpeedX, math.Min(params.MaxSpeedX, velocity.X))
+	velocity.Y = math.Max(-params.MaxRiseSpeed, math.Min(params.MaxFallSpeed, velocity.Y))
+}
+
+// updateMode picks the movement mode for this tick. Pressing jump twice quickly toggles
+// flight when flying is allowed, landing stops it.
+func (c *Controller) updateMode(pressed bool, dt float64, body Body) {
+	c.sinceJumpPress += dt
+	if pressed {
+		if c.CanFly && c.sinceJumpPress <= c.DoubleTapTime {
+			c.flying = !c.flying
+			if c.flying {
+				body.Velocity.Y = 0
+			}
+			// 第三次按下不算新的双击
+			c.sinceJumpPress = math.Inf(1)
+		} else {
+			c.sinceJumpPress = 0
+		}
+	}
+	if !c.CanFly || (c.flying && body.OnGround) {
+		c.flying = false
+	}
+
+	switch {
+	case c.Spectator:
+		c.mode = ModeSpectator
+	case c.flying:
+		c.mode = ModeFly
+	case body.InWater:
+		c.mode = ModeSwim
+	default:
+		c.mode = ModeWalk
+	}
+}
+
+// walk applies walking input: horizontal acceleration, jumping and moving down
+func (c *Controller) walk(in Input, pressed bool, dt float64, body Body) {
+	var speed float64
+	if body.OnGround {
+		speed = body.Acceleration.GroundSpeed
+	} else {
+		speed = body.Acceleration.AirSpeed
+	}
+
+	// 水平移动，摇杆按推动幅度加速
+	body.Velocity.X += in.Horizontal * speed * dt
+
+	c.updateJump(in.Jump, pressed, dt, body)
+
+	// 向下移动（在某些游戏中可能有用）
+	if in.Down > 0 {
+		downSpeed := speed * in.Down
+		if body.OnGround {
+			downSpeed *= 0.5 // 向下移动速度较慢
+		}
+		body.Velocity.Y += downSpeed * dt
+	}
+}
+
+// updateJump starts a jump on a new press (or a buffered one) while on the ground or within
+// coyote time, and cuts the upward velocity once when jump is released during the rise
+func (c *Controller) updateJump(held, pressed bool, dt float64, body Body) {
+	velocity, jump := body.Velocity, body.Jump
+
+	// 按住跳跃键不会连续跳跃，只有新的按下才会记录
+	if pressed {
+		c.bufferTimer = jump.BufferTime
+	}
+	if body.OnGround {
+		c.coyoteTimer = jump.CoyoteTime
+	}
+
+	wantsJump := pressed || c.bufferTimer > 0
+	canJump := body.OnGround || c.coyoteTimer > 0
+	if wantsJump && canJump {
+		velocity.Y = -body.Acceleration.JumpForce
+		c.bufferTimer = 0
+		c.coyoteTimer = 0
+		c.rising = true
+	} else {
+		c.bufferTimer -= dt
+		if !body.OnGround {
+			c.coyoteTimer -= dt
+		}
+	}
+
+	// 提前松开跳跃键时减小上升速度，跳得更低
+	if c.rising && !held && velocity.Y < 0 {
+		velocity.Y *= jump.CutMultiplier
+		c.rising = false
+	}
+	if velocity.Y >= 0 {
+		c.rising = false
+	}
+}
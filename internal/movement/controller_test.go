@@ -0,0 +1,359 @@
+package movement
+
+import (
+	"math"
+	"testing"
+
+	"github.com/wubinrui111/2d-game/internal/components"
+)
+
+const dt = 1.0 / 60.0
+
+// body is a player without collisions or gravity: onGround is set by the test
+type body struct {
+	controller   *Controller
+	velocity     components.Velocity
+	acceleration components.Acceleration
+	jump         components.Jump
+	gravity      components.Gravity
+	inWater      bool
+	submerged    float64
+}
+
+func newBody() *body {
+	return &body{
+		controller:   NewController(),
+		acceleration: *components.NewAcceleration(),
+		jump:         *components.NewJump(),
+	}
+}
+
+// update runs one tick of input
+func (b *body) update(in Input, onGround bool) {
+	b.controller.Update(in, dt, Body{
+		Velocity:     &b.velocity,
+		Acceleration: &b.acceleration,
+		Jump:         &b.jump,
+		Gravity:      &b.gravity,
+		OnGround:     onGround,
+		InWater:      b.inWater,
+		Submerged:    b.submerged,
+	})
+}
+
+// step runs one tick and returns whether it started a jump.
+// There is no gravity, so only a jump makes the vertical velocity smaller.
+func (b *body) step(jump, onGround bool) bool {
+	if onGround {
+		b.velocity.Y = 0
+	}
+	before := b.velocity.Y
+	b.update(Input{Jump: jump}, onGround)
+	return b.velocity.Y < before
+}
+
+func TestJumpOnlyOnNewPress(t *testing.T) {
+	b := newBody()
+	if !b.step(true, true) {
+		t.Fatal("Expected a jump when pressing on the ground")
+	}
+
+	// 落地后仍按住跳跃键，不应再次跳跃
+	b.step(true, false)
+	if b.step(true, true) {
+		t.Error("Expected holding jump not to jump again after landing")
+	}
+}
+
+func TestCoyoteTime(t *testing.T) {
+	b := newBody()
+	b.step(false, true)
+
+	// 离开平台 3 帧后仍可起跳
+	for i := 0; i < 3; i++ {
+		b.step(false, false)
+	}
+	if !b.step(true, false) {
+		t.Error("Expected a jump within coyote time")
+	}
+
+	b = newBody()
+	b.step(false, true)
+	for i := 0; i < 10; i++ {
+		b.step(false, false)
+	}
+	if b.step(true, false) {
+		t.Error("Expected no jump after coyote time")
+	}
+}
+
+func TestCoyoteTimeIsUsedOnce(t *testing.T) {
+	b := newBody()
+	b.step(false, true)
+	b.step(false, false)
+	if !b.step(true, false) {
+		t.Fatal("Expected a jump within coyote time")
+	}
+
+	b.step(false, false)
+	if b.step(true, false) {
+		t.Error("Expected no second jump in the air")
+	}
+}
+
+func TestJumpBuffer(t *testing.T) {
+	b := newBody()
+	b.step(false, false)
+
+	// 落地前 3 帧按下并松开跳跃键，落地时自动起跳
+	b.step(true, false)
+	b.step(false, false)
+	b.step(false, false)
+	if !b.step(false, true) {
+		t.Error("Expected a buffered jump on landing")
+	}
+
+	b = newBody()
+	b.step(true, false)
+	for i := 0; i < 10; i++ {
+		b.step(false, false)
+	}
+	if b.step(false, true) {
+		t.Error("Expected a press long before landing to be forgotten")
+	}
+}
+
+func TestNoCoyoteOrBufferWhenDisabled(t *testing.T) {
+	b := newBody()
+	b.jump.CoyoteTime = 0
+	b.jump.BufferTime = 0
+
+	b.step(false, true)
+	if b.step(true, false) {
+		t.Error("Expected no jump in the air without coyote time")
+	}
+	b.step(false, false)
+	b.step(true, false)
+	if b.step(true, true) {
+		t.Error("Expected no jump on landing without a jump buffer")
+	}
+	b.step(false, true)
+	if !b.step(true, true) {
+		t.Error("Expected a new press on the ground to jump")
+	}
+}
+
+func TestVariableJumpHeight(t *testing.T) {
+	b := newBody()
+	b.step(true, true)
+
+	// 按住时上升速度不变
+	b.step(true, false)
+	if b.velocity.Y != -b.acceleration.JumpForce {
+		t.Errorf("Expected held jump to keep velocity %v, got %v", -b.acceleration.JumpForce, b.velocity.Y)
+	}
+
+	// 松开后上升速度减半，且只减一次
+	b.step(false, false)
+	expected := -b.acceleration.JumpForce * b.jump.CutMultiplier
+	if math.Abs(b.velocity.Y-expected) > 1e-9 {
+		t.Errorf("Expected released jump to cut velocity to %v, got %v", expected, b.velocity.Y)
+	}
+	b.step(false, false)
+	if math.Abs(b.velocity.Y-expected) > 1e-9 {
+		t.Errorf("Expected the cut to happen once, got %v", b.velocity.Y)
+	}
+}
+
+func TestReleaseWhileFallingDoesNotCut(t *testing.T) {
+	b := newBody()
+	b.step(true, true)
+	b.velocity.Y = 50 // 已经开始下落
+	b.step(false, false)
+	if b.velocity.Y != 50 {
+		t.Errorf("Expected falling velocity to be kept, got %v", b.velocity.Y)
+	}
+}
+
+func TestHorizontalSpeedDependsOnGround(t *testing.T) {
+	b := newBody()
+	b.update(Input{Horizontal: 1}, true)
+	ground := b.velocity.X
+
+	b = newBody()
+	b.update(Input{Horizontal: 1}, false)
+	air := b.velocity.X
+
+	expectedGround := b.acceleration.GroundSpeed * dt * b.acceleration.GroundFriction
+	expectedAir := b.acceleration.AirSpeed * dt * b.acceleration.AirResistance
+	if ground != expectedGround || air != expectedAir {
+		t.Errorf("Expected ground %v and air %v, got %v and %v", expectedGround, expectedAir, ground, air)
+	}
+}
+
+func TestTerminalVelocity(t *testing.T) {
+	b := newBody()
+	b.gravity = *components.NewGravity()
+	for i := 0; i < 60*30; i++ {
+		b.update(Input{}, false)
+	}
+
+	expected := b.controller.Modes[ModeWalk].MaxFallSpeed
+	if b.velocity.Y != expected {
+		t.Errorf("Expected falling to stop at %v, got %v", expected, b.velocity.Y)
+	}
+}
+
+func TestDoubleTapTogglesFlightInCreative(t *testing.T) {
+	b := newBody()
+	b.controller.CanFly = true
+
+	b.step(true, true)
+	b.step(false, false)
+	b.step(true, false)
+	if b.controller.Mode() != ModeFly {
+		t.Fatalf("Expected double tap to start flying, got %v", b.controller.Mode())
+	}
+
+	// 飞行时没有重力，松开按键后减速停下
+	b.gravity = *components.NewGravity()
+	for i := 0; i < 120; i++ {
+		b.update(Input{}, false)
+	}
+	if math.Abs(b.velocity.Y) > 1 {
+		t.Errorf("Expected to hover while flying, got vertical velocity %v", b.velocity.Y)
+	}
+
+	b.step(true, false)
+	b.step(false, false)
+	b.step(true, false)
+	if b.controller.Mode() != ModeWalk {
+		t.Errorf("Expected a second double tap to stop flying, got %v", b.controller.Mode())
+	}
+}
+
+func TestNoFlightOutsideCreative(t *testing.T) {
+	b := newBody()
+	b.step(true, true)
+	b.step(false, false)
+	b.step(true, false)
+	if b.controller.Mode() != ModeWalk {
+		t.Errorf("Expected double tap to do nothing without flight, got %v", b.controller.Mode())
+	}
+}
+
+func TestSlowDoubleTapDoesNotFly(t *testing.T) {
+	b := newBody()
+	b.controller.CanFly = true
+	b.step(true, true)
+	for i := 0; i < 30; i++ {
+		b.step(false, false)
+	}
+	b.step(true, false)
+	if b.controller.Mode() != ModeWalk {
+		t.Errorf("Expected presses far apart not to start flying, got %v", b.controller.Mode())
+	}
+}
+
+func TestLandingStopsFlight(t *testing.T) {
+	b := newBody()
+	b.controller.CanFly = true
+	b.step(true, true)
+	b.step(false, false)
+	b.step(true, false)
+
+	b.update(Input{}, true)
+	if b.controller.Mode() != ModeWalk {
+		t.Errorf("Expected landing to stop flying, got %v", b.controller.Mode())
+	}
+}
+
+func TestFlyingRisesAndSinksWithinCaps(t *testing.T) {
+	b := newBody()
+	b.controller.CanFly = true
+	b.step(true, true)
+	b.step(false, false)
+	b.step(true, false)
+
+	for i := 0; i < 120; i++ {
+		b.update(Input{Jump: true, Horizontal: 1}, false)
+	}
+	params := b.controller.Modes[ModeFly]
+	if b.velocity.Y >= 0 || b.velocity.Y < -params.MaxRiseSpeed {
+		t.Errorf("Expected to rise below %v, got %v", params.MaxRiseSpeed, b.velocity.Y)
+	}
+	if b.velocity.X <= 0 || b.velocity.X > params.MaxSpeedX {
+		t.Errorf("Expected horizontal speed below %v, got %v", params.MaxSpeedX, b.velocity.X)
+	}
+
+	for i := 0; i < 120; i++ {
+		b.update(Input{Down: 1}, false)
+	}
+	if b.velocity.Y <= 0 {
+		t.Errorf("Expected to sink when moving down, got %v", b.velocity.Y)
+	}
+}
+
+func TestSwimmingInWater(t *testing.T) {
+	b := newBody()
+	b.inWater = true
+	b.gravity = *components.NewGravity()
+
+	for i := 0; i < 600; i++ {
+		b.update(Input{}, false)
+	}
+	params := b.controller.Modes[ModeSwim]
+	if b.controller.Mode() != ModeSwim {
+		t.Fatalf("Expected to swim in water, got %v", b.controller.Mode())
+	}
+	if b.velocity.Y <= 0 || b.velocity.Y > params.MaxFallSpeed {
+		t.Errorf("Expected to sink slowly, at most %v, got %v", params.MaxFallSpeed, b.velocity.Y)
+	}
+
+	for i := 0; i < 120; i++ {
+		b.update(Input{Jump: true}, false)
+	}
+	if b.velocity.Y >= 0 {
+		t.Errorf("Expected holding jump to swim up, got %v", b.velocity.Y)
+	}
+}
+
+func TestBuoyancy(t *testing.T) {
+	b := newBody()
+	b.inWater = true
+	b.submerged = 1
+	b.gravity = *components.NewGravity()
+
+	for i := 0; i < 600; i++ {
+		b.update(Input{}, false)
+	}
+	if b.velocity.Y >= 0 {
+		t.Errorf("Expected a fully submerged body to float up, got %v", b.velocity.Y)
+	}
+
+	// 只有一小部分浸在水中时下沉
+	b.submerged = 0.2
+	for i := 0; i < 600; i++ {
+		b.update(Input{}, false)
+	}
+	if b.velocity.Y <= 0 {
+		t.Errorf("Expected a body barely in the water to sink, got %v", b.velocity.Y)
+	}
+}
+
+func TestSpectatorModeIgnoresBlocks(t *testing.T) {
+	b := newBody()
+	b.controller.Spectator = true
+	b.gravity = *components.NewGravity()
+	b.update(Input{}, true)
+
+	if b.controller.Mode() != ModeSpectator || b.controller.Mode().Collides() {
+		t.Errorf("Expected spectator mode without collisions, got %v", b.controller.Mode())
+	}
+	if b.velocity.Y != 0 {
+		t.Errorf("Expected no gravity in spectator mode, got %v", b.velocity.Y)
+	}
+	if !ModeWalk.Collides() || !ModeFly.Collides() || !ModeSwim.Collides() {
+		t.Error("Expected other modes to collide with blocks")
+	}
+}
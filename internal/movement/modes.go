@@ -0,0 +1,77 @@
+package movement
+
+// Mode is a way the player moves, each with its own physics
+type Mode int
+
+const (
+	ModeWalk      Mode = iota // Gravity, jumping and ground friction
+	ModeFly                   // Creative flight: no gravity, jump rises and down sinks
+	ModeSwim                  // Slow sinking in water, jump swims up
+	ModeSpectator             // Like flying, faster and through blocks
+)
+
+// String returns the mode name used in debug output
+func (m Mode) String() string {
+	switch m {
+	case ModeFly:
+		return "fly"
+	case ModeSwim:
+		return "swim"
+	case ModeSpectator:
+		return "spectator"
+	}
+	return "walk"
+}
+
+// Collides reports whether the body is stopped by blocks in this mode
+func (m Mode) Collides() bool {
+	return m != ModeSpectator
+}
+
+// ModeParams are the physics of one movement mode
+type ModeParams struct {
+	GravityScale float64 // Multiplier for the body's gravity force
+	Buoyancy     float64 // Upward force of a fully submerged body, as a multiplier of its gravity force
+	Speed        float64 // Acceleration from input (pixels per second squared), walking uses Acceleration instead
+	Drag         float64 // Velocity multiplier per tick on both axes (0.0 to 1.0), walking uses ground friction and air resistance instead
+	MaxSpeedX    float64 // Horizontal speed cap (pixels per second)
+	MaxFallSpeed float64 // Downward speed cap, the terminal velocity (pixels per second)
+	MaxRiseSpeed float64 // Upward speed cap (pixels per second)
+}
+
+// DefaultModes returns the default physics of every mode
+func DefaultModes() map[Mode]ModeParams {
+	return map[Mode]ModeParams{
+		ModeWalk: {
+			GravityScale: 1.0,
+			MaxSpeedX:    700.0,  // Above the walking speed reached with the default friction
+			MaxFallSpeed: 1000.0, // Terminal velocity
+			MaxRiseSpeed: 1000.0,
+		},
+		ModeFly: {
+			GravityScale: 0,
+			Speed:        3000.0,
+			Drag:         0.85,
+			MaxSpeedX:    500.0,
+			MaxFallSpeed: 400.0,
+			MaxRiseSpeed: 400.0,
+		},
+		ModeSwim: {
+			GravityScale: 0.25, // Sink slowly
+			Buoyancy:     0.4,  // Float up when fully submerged, so the body rests near the surface
+			Speed:        1500.0,
+			Drag:         0.85,
+			MaxSpeedX:    200.0,
+			MaxFallSpeed: 120.0,
+			MaxRiseSpeed: 150.0,
+		},
+		ModeSpectator: {
+			GravityScale: 0,
+			Speed:        6000.0,
+			Drag:         0.85,
+			MaxSpeedX:    1000.0,
+			MaxFallSpeed: 1000.0,
+			MaxRiseSpeed: 1000.0,
+		},
+	}
+}
@@ -0,0 +1,250 @@
+package scenes
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/wubinrui111/2d-game/internal/assets"
+	"github.com/wubinrui111/2d-game/internal/editor"
+	"github.com/wubinrui111/2d-game/internal/grid"
+	"github.com/wubinrui111/2d-game/internal/input"
+)
+
+// SchematicSaveDir is where saved schematics are written: the schematic directory of the
+// ./assets asset source, so the next schematic action finds them
+var SchematicSaveDir = filepath.Join("assets", editor.SchematicDir)
+
+var (
+	// selectionColor outlines the editor selection
+	selectionColor = color.RGBA{255, 255, 0, 255}
+
+	// previewColor fills the cells a line or paste would change
+	previewColor = color.RGBA{255, 255, 255, 80}
+)
+
+// toggleEditor switches editor mode on or off
+func (ms *MainScene) toggleEditor() {
+	ms.editing = !ms.editing
+	ms.editor.End()
+	ms.editorDrag = nil
+}
+
+// editorBlock returns the block the editor tools place: the one of the selected item,
+// "" (no block, so the tools erase) if the selected item places none
+func (ms *MainScene) editorBlock() string {
+	selected := ms.inventory.GetSelectedItem()
+	if selected == nil {
+		return ""
+	}
+	if def, exists := ms.blockDefs.Get(selected.Item.ID); exists {
+		return def.ID
+	}
+	return ""
+}
+
+// handleEditorInput applies the editor tools and commands at the cursor. The use key
+// (right button) places the selected block, the attack key (left button) erases.
+func (ms *MainScene) handleEditorInput(worldX, worldY float64) {
+	e := ms.editor
+	cell := worldCell(worldX, worldY)
+	block := ms.editorBlock()
+	attack := input.IsActionPressed(input.ActionAttack)
+	use := input.IsActionPressed(input.ActionUseItem)
+
+	if input.IsActionJustPressed(input.ActionEditorTool) {
+		e.End()
+		e.Tool = e.Tool.Next()
+		ms.editorDrag = nil
+	}
+
+	switch e.Tool {
+	case editor.ToolPencil:
+		// 一笔修改的所有格子作为一次操作撤销
+		if !attack && !use {
+			e.End()
+			break
+		}
+		if !e.Grouping() {
+			e.Begin("pencil")
+		}
+		if use {
+			e.Set("place", cell, block)
+		} else {
+			e.Set("erase", cell, "")
+		}
+
+	case editor.ToolSelect:
+		// 攻击键拖出选区，使用键取消选区
+		if input.IsActionJustPressed(input.ActionUseItem) {
+			e.Selection = editor.Rect{}
+			ms.editorDrag = nil
+		}
+		if attack && ms.editorDrag == nil {
+			ms.editorDrag = &cell
+		}
+		if ms.editorDrag != nil {
+			e.Selection = editor.RectBetween(*ms.editorDrag, cell)
+			if !attack {
+				ms.editorDrag = nil
+			}
+		}
+
+	case editor.ToolLine:
+		// 松开按键时从起点画线到光标
+		if (attack || use) && ms.editorDrag == nil {
+			ms.editorDrag = &cell
+			ms.editorErase = attack
+		}
+		if ms.editorDrag != nil && !attack && !use {
+			if ms.editorErase {
+				block = ""
+			}
+			e.Line(*ms.editorDrag, cell, block)
+			ms.editorDrag = nil
+		}
+
+	case editor.ToolFlood:
+		var err error
+		if input.IsActionJustPressed(input.ActionUseItem) {
+			_, err = e.Flood(cell, block)
+		} else if input.IsActionJustPressed(input.ActionAttack) {
+			_, err = e.Flood(cell, "")
+		}
+		if err != nil {
+			fmt.Printf("Failed to flood fill: %v\n", err)
+		}
+	}
+
+	// 选区命令
+	if !e.Selection.Empty() {
+		switch {
+		case input.IsActionJustPressed(input.ActionFill):
+			e.Fill(e.Selection, block)
+		case input.IsActionJustPressed(input.ActionReplace):
+			e.Replace(e.Selection, ms.Block(cell.X, cell.Y), block)
+		case input.IsActionJustPressed(input.ActionDelete):
+			e.Fill(e.Selection, "")
+		case input.IsActionJustPressed(input.ActionCopy):
+			e.Copy(e.Selection)
+		case input.IsActionJustPressed(input.ActionCut):
+			e.Cut(e.Selection)
+		}
+	}
+	if input.IsActionJustPressed(input.ActionPaste) {
+		e.Paste(cell)
+	}
+
+	// 结构：选区保存为文件（光标处为粘贴点），加载、旋转和镜像剪贴板
+	if input.IsActionJustPressed(input.ActionSaveSchematic) && !e.Selection.Empty() {
+		if file, err := saveSchematic(e.Capture(e.Selection, cell)); err != nil {
+			fmt.Printf("Failed to save schematic: %v\n", err)
+		} else {
+			fmt.Printf("Saved schematic to %s\n", file)
+		}
+	}
+	if input.IsActionJustPressed(input.ActionNextSchematic) {
+		ms.loadNextSchematic()
+	}
+	if e.Clipboard != nil {
+		if input.IsActionJustPressed(input.ActionRotate) {
+			e.Clipboard = e.Clipboard.Rotate(1)
+		}
+		if input.IsActionJustPressed(input.ActionMirror) {
+			e.Clipboard = e.Clipboard.Mirror()
+		}
+	}
+
+	// 撤销和重做
+	if input.IsActionJustPressed(input.ActionUndo) {
+		e.Undo()
+	} else if input.IsActionJustPressed(input.ActionRedo) {
+		e.Redo()
+	}
+}
+
+// drawEditor draws the selection and a preview of what the current tool would change
+func (ms *MainScene) drawEditor(screen *ebiten.Image, cursor grid.Cell) {
+	e := ms.editor
+
+	// 直线工具拖动时预览直线
+	if e.Tool == editor.ToolLine && ms.editorDrag != nil {
+		for _, c := range editor.LineCells(*ms.editorDrag, cursor) {
+			ms.drawCells(screen, editor.Rect{X: c.X, Y: c.Y, W: 1, H: 1}, previewColor, color.RGBA{})
+		}
+	}
+
+	// 选择工具下预览粘贴的范围
+	if e.Tool == editor.ToolSelect && e.Clipboard != nil && ms.editorDrag == nil {
+		ms.drawCells(screen, e.Clipboard.Bounds(cursor), color.RGBA{}, previewColor)
+	}
+
+	if !e.Selection.Empty() {
+		ms.drawCells(screen, e.Selection, color.RGBA{}, selectionColor)
+	}
+}
+
+// drawCells draws a rectangle of cells with a fill and a border
+func (ms *MainScene) drawCells(screen *ebiten.Image, r editor.Rect, fillColor, borderColor color.Color) {
+	ms.drawBoxWithBorder(screen, float64(r.X)*GridSize, float64(r.Y)*GridSize, float64(r.W)*GridSize, float64(r.H)*GridSize, fillColor, borderColor)
+}
+
+// saveSchematic writes a region to a new file in SchematicSaveDir, named after the
+// first free schematic_<n>, and returns the file's path
+func saveSchematic(region *editor.Region) (string, error) {
+	if err := os.MkdirAll(SchematicSaveDir, 0o755); err != nil {
+		return "", err
+	}
+	for n := 1; ; n++ {
+		region.Name = fmt.Sprintf("schematic_%d", n)
+		file := filepath.Join(SchematicSaveDir, region.Name+".json")
+		if _, err := os.Stat(file); err == nil {
+			continue
+		}
+		data, err := editor.EncodeSchematic(region)
+		if err != nil {
+			return "", err
+		}
+		return file, os.WriteFile(file, data, 0o644)
+	}
+}
+
+// loadNextSchematic loads the schematic after the last loaded one into the clipboard
+func (ms *MainScene) loadNextSchematic() {
+	var keys []string
+	for _, key := range assets.Default().List(editor.SchematicDir) {
+		if path.Ext(key) == ".json" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+	ms.schematicIndex = (ms.schematicIndex + 1) % len(keys)
+	region, err := loadSchematic(keys[ms.schematicIndex])
+	if err != nil {
+		fmt.Printf("Failed to load schematic: %v\n", err)
+		return
+	}
+	ms.editor.Clipboard = region
+}
+
+// loadSchematic reads a schematic through the asset manager. Schematics without a
+// name are named after their file.
+func loadSchematic(key string) (*editor.Region, error) {
+	data, err := assets.Default().Data(key)
+	if err != nil {
+		return nil, err
+	}
+	region, err := editor.DecodeSchematic(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+	if region.Name == "" {
+		region.Name = path.Base(key[:len(key)-len(path.Ext(key))])
+	}
+	return region, nil
+}
@@ -0,0 +1,89 @@
+package scenes
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/wubinrui111/2d-game/internal/entities"
+	"github.com/wubinrui111/2d-game/internal/grid"
+)
+
+// falls reports whether the block at a cell falls when it is not supported
+func (ms *MainScene) falls(cell grid.Cell) bool {
+	block := ms.blockAt(cell)
+	if block == nil {
+		return false
+	}
+	def, exists := ms.blockDefs.Lookup(block.Name)
+	return exists && def.Falls
+}
+
+// supported reports whether the cell below a cell holds something a block rests on;
+// fluids do not hold blocks up
+func (ms *MainScene) supported(cell grid.Cell) bool {
+	below := ms.blockAt(grid.Cell{X: cell.X, Y: cell.Y + 1})
+	return below != nil && !ms.isFluid(below)
+}
+
+// checkFalling turns the block at a cell into a falling block if it falls and nothing
+// holds it up. SetBlock calls it for the cell above every cell that stops holding a
+// block up, so the blocks stacked on a falling block follow it.
+func (ms *MainScene) checkFalling(cell grid.Cell) {
+	if !ms.falls(cell) || ms.supported(cell) {
+		return
+	}
+	block := ms.blockAt(cell)
+	fb := entities.NewFallingBlock(block.Position.X, block.Position.Y, GridSize, block.Name, block.GetColor())
+	ms.fallingBlocks = append(ms.fallingBlocks, fb)
+
+	// 下落不记入撤销历史，和流体一样由世界自己改变
+	ms.SetBlock(cell.X, cell.Y, "")
+}
+
+// updateFallingBlocks moves the falling blocks and puts the ones that landed back on
+// the grid
+func (ms *MainScene) updateFallingBlocks() {
+	if len(ms.fallingBlocks) == 0 {
+		return
+	}
+	remaining := ms.fallingBlocks[:0]
+	for _, fb := range ms.fallingBlocks {
+		fb.Update(1/TickRate, ms.solidBlocksNear(fb.Box, moveMargin(fb.Velocity)))
+		switch {
+		case fb.Landed:
+			ms.landFallingBlock(fb)
+		case !fb.Expired():
+			remaining = append(remaining, fb)
+		}
+	}
+	for i := len(remaining); i < len(ms.fallingBlocks); i++ {
+		ms.fallingBlocks[i] = nil
+	}
+	ms.fallingBlocks = remaining
+}
+
+// landFallingBlock puts a landed block into the cell it landed in, replacing any fluid
+// there. If a solid block took the cell, the block breaks into an item drop instead.
+func (ms *MainScene) landFallingBlock(fb *entities.FallingBlock) {
+	cell := worldCell(fb.Position.X+fb.Box.Width/2, fb.Position.Y+fb.Box.Height/2)
+	if block := ms.blockAt(cell); block != nil && !ms.isFluid(block) {
+		item := ms.itemFor(fb.BlockID, fb.Color)
+		ms.itemDrops = append(ms.itemDrops, entities.NewItemDrop(fb.Position.X+8, fb.Position.Y+8, item))
+		return
+	}
+	ms.SetBlock(cell.X, cell.Y, fb.BlockID)
+
+	// 落地的方块下面可能已经空了
+	ms.checkFalling(cell)
+}
+
+// drawFallingBlocks draws the falling blocks with their sprites, or their colors if
+// they have none
+func (ms *MainScene) drawFallingBlocks(screen *ebiten.Image) {
+	for _, fb := range ms.fallingBlocks {
+		if sprite := ms.blockSprite(fb.BlockID); sprite != nil {
+			w, h := float64(sprite.Bounds().Dx()), float64(sprite.Bounds().Dy())
+			ms.drawWorldImage(screen, sprite, fb.Position.X, fb.Position.Y, fb.Box.Width/w, fb.Box.Height/h)
+			continue
+		}
+		ms.drawBoxWithBorder(screen, fb.Position.X, fb.Position.Y, fb.Box.Width, fb.Box.Height, fb.Color, fb.Color)
+	}
+}
@@ -0,0 +1,99 @@
+package scenes
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/wubinrui111/2d-game/internal/components"
+	"github.com/wubinrui111/2d-game/internal/entities"
+	"github.com/wubinrui111/2d-game/internal/fluids"
+	"github.com/wubinrui111/2d-game/internal/grid"
+	graphicsSystem "github.com/wubinrui111/2d-game/internal/systems"
+)
+
+const (
+	// lavaDamage is the damage the player takes in lava every lavaDamageInterval seconds
+	lavaDamage         = 4
+	lavaDamageInterval = 0.5
+)
+
+// isFluid reports whether a block is a fluid, which entities move through
+func (ms *MainScene) isFluid(block *entities.SmallBlock) bool {
+	return ms.fluids.Kind(block.Name) != nil
+}
+
+// flowingFluid reports whether a cell holds fluid that is not a source
+func (ms *MainScene) flowingFluid(cell grid.Cell) bool {
+	st, exists := ms.fluids.Fluid(cell.X, cell.Y)
+	return exists && !st.Source
+}
+
+// solidBlocksNear returns the blocks entities collide with in the cells a box covers,
+// grown by margin pixels on every side, through the block index
+func (ms *MainScene) solidBlocksNear(box components.Box, margin float64) []components.BoxHolder {
+	minX, maxX := int(math.Floor((box.X-margin)/GridSize)), int(math.Floor((box.X+box.Width+margin)/GridSize))
+	minY, maxY := int(math.Floor((box.Y-margin)/GridSize)), int(math.Floor((box.Y+box.Height+margin)/GridSize))
+
+	var holders []components.BoxHolder
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			if block := ms.blockAt(grid.Cell{X: x, Y: y}); block != nil && !ms.isFluid(block) {
+				holders = append(holders, block)
+			}
+		}
+	}
+	return holders
+}
+
+// moveMargin is how far around an entity moving at velocity (pixels per tick) to look
+// for blocks: as far as it can move this tick, plus a cell for what pushes it aside
+func moveMargin(velocity components.Position) float64 {
+	return math.Abs(velocity.X) + math.Abs(velocity.Y) + GridSize
+}
+
+// playerSubmersion returns how much of the player's height is in the fluid id, from
+// 0 to 1. A row of cells counts if any of its cells next to the player holds the fluid.
+func (ms *MainScene) playerSubmersion(id string) float64 {
+	box := ms.player.Box
+	minX, maxX := int(math.Floor(box.X/GridSize)), int(math.Ceil((box.X+box.Width)/GridSize))
+	minY, maxY := int(math.Floor(box.Y/GridSize)), int(math.Ceil((box.Y+box.Height)/GridSize))
+
+	var depth float64
+	for y := minY; y < maxY; y++ {
+		for x := minX; x < maxX; x++ {
+			if ms.Block(x, y) != id {
+				continue
+			}
+			top := math.Max(box.Y, float64(y)*GridSize)
+			bottom := math.Min(box.Y+box.Height, float64(y+1)*GridSize)
+			depth += bottom - top
+			break
+		}
+	}
+	return math.Min(1, depth/box.Height)
+}
+
+// updateLavaDamage hurts the player in lava at intervals, only in survival mode
+func (ms *MainScene) updateLavaDamage() {
+	if ms.inventorySystem.GameMode != graphicsSystem.GameModeSurvival || ms.playerSubmersion(fluids.Lava.ID) == 0 {
+		ms.lavaTimer = 0
+		return
+	}
+	ms.lavaTimer -= 1 / TickRate
+	if ms.lavaTimer <= 0 {
+		ms.player.TakeDamage(lavaDamage)
+		ms.lavaTimer = lavaDamageInterval
+	}
+}
+
+// fluidColor returns the color a fluid block is drawn with: flowing fluid is more
+// see-through the lower its level
+func (ms *MainScene) fluidColor(x, y int, c color.RGBA) color.RGBA {
+	st, exists := ms.fluids.Fluid(x, y)
+	if !exists {
+		return c
+	}
+	// 颜色是预乘透明度的，所有通道一起缩放
+	scale := float64(st.Level+fluids.MaxLevel) / (2 * fluids.MaxLevel)
+	return color.RGBA{uint8(float64(c.R) * scale), uint8(float64(c.G) * scale), uint8(float64(c.B) * scale), uint8(float64(c.A) * scale)}
+}
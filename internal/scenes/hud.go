@@ -0,0 +1,196 @@
+package scenes
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/wubinrui111/2d-game/internal/i18n"
+	"github.com/wubinrui111/2d-game/internal/input"
+	"github.com/wubinrui111/2d-game/internal/ui"
+)
+
+// hud is the heads-up display in the top left corner: FPS, health bar and debug overlay
+type hud struct {
+	root        *ui.Panel
+	fpsLabel    *ui.Label
+	healthBar   *ui.ProgressBar
+	healthLabel *ui.Label
+
+	// 编辑器状态，只在编辑器模式下显示
+	editor          *ui.Panel
+	editorTool      *ui.Label
+	editorSelection *ui.Label
+	editorHistory   *ui.Label
+	editorClipboard *ui.Label
+
+	// 调试信息
+	debug      *ui.Panel
+	playerPos  *ui.Label
+	mousePos   *ui.Label
+	movement   *ui.Label
+	gridStatus *ui.Label
+	items      *ui.Label
+	itemsHint  *ui.Label
+	drops      *ui.Label
+	chunks     *ui.Label
+	time       *ui.Label
+	light      *ui.Label
+
+	// 设置时间的输入框，按设置时间键打开
+	ctx       *ui.Context
+	timeInput *ui.TextInput
+	timeHint  *ui.Label
+}
+
+// newHUD builds the HUD widgets
+func newHUD() *hud {
+	h := &hud{
+		fpsLabel:    ui.NewLabel("", debugTextStyle),
+		healthBar:   ui.NewProgressBar(200, 20),
+		healthLabel: ui.NewLabel("", healthTextStyle),
+		playerPos:   ui.NewLabel("", debugTextStyle),
+		mousePos:    ui.NewLabel("", debugTextStyle),
+		movement:    ui.NewLabel("", debugTextStyle),
+		gridStatus:  ui.NewLabel("", debugTextStyle),
+		items:       ui.NewLabel("", debugTextStyle),
+		itemsHint:   ui.NewLabel("", debugTextStyle),
+		drops:       ui.NewLabel("", debugTextStyle),
+		chunks:      ui.NewLabel("", debugTextStyle),
+		time:        ui.NewLabel("", debugTextStyle),
+		light:       ui.NewLabel("", debugTextStyle),
+		ctx:         ui.NewContext(),
+		timeInput:   ui.NewTextInput(420),
+		timeHint:    ui.NewLabel("", debugTextStyle),
+
+		editorTool:      ui.NewLabel("", debugTextStyle),
+		editorSelection: ui.NewLabel("", debugTextStyle),
+		editorHistory:   ui.NewLabel("", debugTextStyle),
+		editorClipboard: ui.NewLabel("", debugTextStyle),
+	}
+	h.healthBar.Border = color.RGBA{255, 255, 255, 255}
+	h.healthBar.FillColor = healthColor
+
+	h.debug = ui.NewPanel(ui.Vertical)
+	h.debug.Spacing = 4
+	h.debug.PassThrough = true
+	h.debug.Add(h.playerPos, h.mousePos, h.movement, h.gridStatus, h.items, h.itemsHint, h.drops, h.chunks, h.time, h.light)
+
+	h.editor = ui.NewPanel(ui.Vertical)
+	h.editor.Spacing = 4
+	h.editor.PassThrough = true
+	h.editor.Add(h.editorTool, h.editorSelection, h.editorClipboard, h.editorHistory)
+
+	h.root = ui.NewPanel(ui.Vertical)
+	h.root.Padding = 10
+	h.root.Spacing = 5
+	h.root.PassThrough = true
+	h.timeInput.Style = debugTextStyle
+	h.timeInput.MaxLength = 20
+	h.timeInput.Hidden = true
+	h.timeHint.Hidden = true
+	h.timeHint.PassThrough = true
+
+	h.root.Add(h.fpsLabel, h.healthBar, h.healthLabel, h.timeInput, h.timeHint, h.editor, h.debug)
+	return h
+}
+
+// healthColor fades the health bar from green to yellow to red as health drops
+func healthColor(value float64) color.Color {
+	healthPercentage := value * 100
+	if healthPercentage > 50 {
+		// Green to yellow transition (high health)
+		redValue := uint8(255 * (100 - healthPercentage) / 50)
+		return color.RGBA{redValue, 255, 0, 255}
+	}
+	// Yellow to red transition (low health)
+	greenValue := uint8(255 * healthPercentage / 50)
+	return color.RGBA{255, greenValue, 0, 255}
+}
+
+// update refreshes the HUD texts from the scene state
+func (h *hud) update(ms *MainScene, mouseX, mouseY float64) {
+	h.fpsLabel.Text = i18n.T("hud.fps", ebiten.ActualFPS())
+
+	healthPercentage := ms.player.Health.GetHealthPercentage()
+	h.healthBar.Value = healthPercentage / 100
+	h.healthLabel.Text = i18n.T("hud.health", ms.player.Health.Current, ms.player.Health.Max, healthPercentage)
+
+	// 编辑器工具、选区和撤销历史
+	h.editor.Hidden = !ms.editing
+	if ms.editing {
+		e := ms.editor
+		h.editorTool.Text = i18n.T("hud.editor", i18n.T("editor.tool."+e.Tool.String()), input.BindingName(input.ActionEditorTool))
+		if sel := e.Selection; sel.Empty() {
+			h.editorSelection.Text = i18n.T("hud.editor_no_selection")
+		} else {
+			h.editorSelection.Text = i18n.T("hud.editor_selection", sel.W, sel.H, sel.X, sel.Y)
+		}
+		h.editorHistory.Text = i18n.T("hud.editor_history", e.History.UndoCount(), e.History.RedoCount())
+		if clip := e.Clipboard; clip == nil {
+			h.editorClipboard.Text = i18n.T("hud.editor_empty_clipboard")
+		} else {
+			name := clip.Name
+			if name == "" {
+				name = i18n.T("editor.copied_region")
+			}
+			h.editorClipboard.Text = i18n.T("hud.editor_clipboard", name, clip.W, clip.H)
+		}
+	}
+
+	// 绘制玩家坐标和鼠标坐标信息
+	h.playerPos.Text = i18n.T("debug.player", ms.player.Position.X, ms.player.Position.Y)
+	h.mousePos.Text = i18n.T("debug.mouse", mouseX, mouseY)
+	h.movement.Text = i18n.T("debug.movement", i18n.T("movement."+ms.playerMovement.Mode().String()))
+
+	// 绘制网格状态信息
+	gridStatus := i18n.T("debug.on")
+	if !ms.showGrid {
+		gridStatus = i18n.T("debug.off")
+	}
+	h.gridStatus.Text = i18n.T("debug.grid", gridStatus, input.BindingName(input.ActionToggleGrid))
+
+	// 显示所有物品类型，当前选中的用方括号标记
+	h.items.Hidden = len(ms.itemTypes) == 0
+	h.itemsHint.Hidden = h.items.Hidden
+	itemsText := i18n.T("debug.items")
+	for i, itemType := range ms.itemTypes {
+		itemName := i18n.T("item." + itemType)
+		if i == ms.currentItemIndex {
+			itemsText += fmt.Sprintf("[%s] ", itemName)
+		} else {
+			itemsText += fmt.Sprintf("%s ", itemName)
+		}
+	}
+	h.items.Text = itemsText
+	h.itemsHint.Text = i18n.T("debug.items_hint")
+
+	// 绘制掉落物数量
+	h.drops.Text = i18n.N("debug.drops", len(ms.itemDrops), len(ms.itemDrops))
+
+	// 区块渲染统计
+	stats := ms.tileRenderer.Stats()
+	h.chunks.Text = i18n.T("debug.chunks", stats.VisibleChunks, stats.RenderedChunks, stats.CachedChunks)
+
+	// 世界时间，以及玩家所在格子的光照和敌对生物能否生成
+	hour, minute := ms.clock.HourMinute()
+	frozen := ""
+	if ms.clock.Frozen {
+		frozen = i18n.T("debug.time_frozen")
+	}
+	h.time.Text = i18n.T("debug.time", ms.clock.Day()+1, hour, minute, frozen, input.BindingName(input.ActionFreezeTime), input.BindingName(input.ActionSkipTime), input.BindingName(input.ActionSetTime))
+	cell := worldCell(ms.playerCenter())
+	mobs := i18n.T("debug.mobs_no_spawn")
+	if ms.hostileMobsCanSpawn(cell) {
+		mobs = i18n.T("debug.mobs_spawn")
+	}
+	h.light.Text = i18n.T("debug.light", ms.light.Level(cell.X, cell.Y), mobs)
+}
+
+// draw lays out and renders the HUD
+func (h *hud) draw(screen *ebiten.Image) {
+	w, ht := h.root.PreferredSize()
+	h.root.SetBounds(ui.Rect{X: 0, Y: 0, W: w, H: ht})
+	ui.Layout(h.root)
+	ui.Draw(screen, h.root)
+}
@@ -0,0 +1,216 @@
+package scenes
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/wubinrui111/2d-game/internal/components"
+	"github.com/wubinrui111/2d-game/internal/entities"
+	"github.com/wubinrui111/2d-game/internal/grid"
+	"github.com/wubinrui111/2d-game/internal/tiled"
+)
+
+const (
+	// DefaultLevelKey is the asset key of the Tiled map a new world starts with
+	DefaultLevelKey = "levels/start.tmx"
+
+	// LevelExportPath is where the export action writes the world as a Tiled map
+	LevelExportPath = "exported_level.tmx"
+
+	// itemEntityClass is the class of map objects that become item drops. Their
+	// "item" property is a block ID and "count" the stack size (1 if not set).
+	itemEntityClass = "item"
+
+	// structureEntityClass is the class of map objects that place a schematic, whose
+	// origin lands on the object. Their "schematic" property is the schematic's asset
+	// key; with "air" set to true its empty cells clear the blocks below.
+	structureEntityClass = "structure"
+
+	// blockEntityClass is the class of map objects holding the block entity data of
+	// the block in their cell, one property per value
+	blockEntityClass = "block_entity"
+
+	// timeProperty is the map property holding the world clock, in ticks
+	timeProperty = "time"
+)
+
+// loadLevel places the blocks, the spawn point and the entities of a Tiled map, read
+// with read: an asset key with assets.Default().Data or a file path with readFile
+func (ms *MainScene) loadLevel(key string, read tiled.ReadFunc) error {
+	m, err := tiled.Load(key, read)
+	if err != nil {
+		return err
+	}
+	level, err := tiled.Import(m, ms.blockDefs)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	// 世界时间随关卡保存，没有时从第一天日出开始
+	if _, exists := level.Properties.Get(timeProperty); exists {
+		ticks, err := level.Properties.Int(timeProperty, 0)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		ms.clock.Ticks = int64(ticks)
+	}
+
+	// 开启地形生成的关卡在玩家附近生成区块列，已生成的区块列随关卡保存
+	if err := ms.loadGenerator(level.Properties); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	// 关卡的方块不记入撤销历史
+	for _, b := range level.Blocks {
+		ms.SetBlock(b.X, b.Y, b.ID)
+	}
+
+	if level.Spawn != nil {
+		ms.spawnX, ms.spawnY = level.Spawn.X*GridSize, level.Spawn.Y*GridSize
+		ms.player.Position.X, ms.player.Position.Y = ms.spawnX, ms.spawnY
+	}
+
+	for _, e := range level.Entities {
+		if err := ms.addEntity(e); err != nil {
+			fmt.Printf("Failed to place level object %q: %v\n", e.Name, err)
+		}
+	}
+	return nil
+}
+
+// addEntity creates the game entity for a map object
+func (ms *MainScene) addEntity(e *tiled.Entity) error {
+	switch e.Class {
+	case structureEntityClass:
+		return ms.addStructure(e)
+	case blockEntityClass:
+		return ms.addBlockEntity(e)
+	}
+	if e.Class != itemEntityClass {
+		return fmt.Errorf("unknown class %q", e.Class)
+	}
+	id, _ := e.Properties.Get("item")
+	def, exists := ms.blockDefs.Lookup(id)
+	if !exists {
+		return fmt.Errorf("unknown item %q", id)
+	}
+	count, err := e.Properties.Int("count", 1)
+	if err != nil {
+		return err
+	}
+
+	item := &components.Item{ID: def.ID, Name: def.Name, Count: count, MaxStack: 64, Color: def.Color}
+	ms.itemDrops = append(ms.itemDrops, entities.NewItemDrop(e.X*GridSize, e.Y*GridSize, item))
+	return nil
+}
+
+// addStructure places the schematic of a structure object with its block entities.
+// Like the level's blocks, it is not part of the undo history.
+func (ms *MainScene) addStructure(e *tiled.Entity) error {
+	key, _ := e.Properties.Get("schematic")
+	region, err := loadSchematic(key)
+	if err != nil {
+		return err
+	}
+	air, _ := e.Properties.Get("air")
+	at := grid.Cell{X: int(math.Floor(e.X)), Y: int(math.Floor(e.Y))}
+	for _, edit := range region.Edits(at, air == "true") {
+		ms.SetBlock(edit.X, edit.Y, edit.ID)
+		ms.SetBlockEntity(edit.X, edit.Y, edit.Entity)
+	}
+	return nil
+}
+
+// addBlockEntity sets the block entity data of a block entity object on the block in
+// its cell
+func (ms *MainScene) addBlockEntity(e *tiled.Entity) error {
+	x, y := int(math.Floor(e.X)), int(math.Floor(e.Y))
+	if ms.Block(x, y) == "" {
+		return fmt.Errorf("no block at %d,%d", x, y)
+	}
+	data := make(map[string]string, len(e.Properties))
+	for _, p := range e.Properties {
+		data[p.Name] = p.Value
+	}
+	ms.SetBlockEntity(x, y, data)
+	return nil
+}
+
+// Level returns the world as a level: its blocks, their block entities, the spawn
+// point, the item drops and the world clock. Of the fluids only the sources are
+// saved; flowing fluid would become sources when loaded.
+func (ms *MainScene) Level() *tiled.Level {
+	level := &tiled.Level{
+		Spawn: &tiled.Entity{Name: "player", Class: tiled.SpawnClass, X: ms.spawnX / GridSize, Y: ms.spawnY / GridSize, Point: true},
+	}
+	level.Properties.Set(timeProperty, "int", fmt.Sprint(ms.clock.Ticks))
+	ms.saveGenerator(&level.Properties)
+	for _, block := range ms.blocks {
+		x, y := blockCell(block)
+		if st, exists := ms.fluids.Fluid(x, y); exists && !st.Source {
+			// 只保存流体源，载入后流动的流体重新流出来
+			continue
+		}
+		level.Blocks = append(level.Blocks, tiled.Block{X: x, Y: y, ID: ms.itemForBlock(block).ID})
+	}
+	for _, cell := range sortedCells(ms.blockEntities) {
+		data := ms.blockEntities[cell]
+		keys := make([]string, 0, len(data))
+		for k := range data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		entity := &tiled.Entity{Class: blockEntityClass, X: float64(cell.X), Y: float64(cell.Y), Point: true}
+		for _, k := range keys {
+			entity.Properties = append(entity.Properties, tiled.Property{Name: k, Type: "string", Value: data[k]})
+		}
+		level.Entities = append(level.Entities, entity)
+	}
+	for _, drop := range ms.itemDrops {
+		item := drop.GetItem()
+		level.Entities = append(level.Entities, &tiled.Entity{
+			Class: itemEntityClass,
+			X:     drop.Position.X / GridSize,
+			Y:     drop.Position.Y / GridSize,
+			Point: true,
+			Properties: tiled.Properties{
+				{Name: "item", Type: "string", Value: item.ID},
+				{Name: "count", Type: "int", Value: fmt.Sprint(item.Count)},
+			},
+		})
+	}
+	return level
+}
+
+// exportLevel writes the world to a .tmx or .tmj file, to be edited in Tiled
+func (ms *MainScene) exportLevel(path string) error {
+	data, err := tiled.Encode(path, tiled.Export(ms.Level(), int(GridSize), int(GridSize)))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// readFile reads a file on disk by its slash-separated path, for levels outside the assets
+func readFile(name string) ([]byte, error) {
+	return os.ReadFile(filepath.FromSlash(name))
+}
+
+// sortedCells returns the cells of a map by row, then column, so exports do not
+// depend on map order
+func sortedCells[V any](m map[grid.Cell]V) []grid.Cell {
+	cells := make([]grid.Cell, 0, len(m))
+	for c := range m {
+		cells = append(cells, c)
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].Y != cells[j].Y {
+			return cells[i].Y < cells[j].Y
+		}
+		return cells[i].X < cells[j].X
+	})
+	return cells
+}
@@ -0,0 +1,47 @@
+package scenes
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/wubinrui111/2d-game/internal/lighting"
+)
+
+const (
+	// lightFalloff is how much each light level below lighting.MaxLevel dims a cell
+	lightFalloff = 0.8
+
+	// minBrightness keeps the darkest cells faintly visible
+	minBrightness = 0.06
+)
+
+// lightBlock returns how a block affects light, as its definition says. Blocks
+// without a definition are opaque and give off no light.
+func (ms *MainScene) lightBlock(id string) lighting.Block {
+	if def, exists := ms.blockDefs.Lookup(id); exists {
+		return lighting.Block{Opaque: !def.Transparent, Emission: def.Light}
+	}
+	return lighting.Block{Opaque: true}
+}
+
+// rebuildLight computes the light of every block again
+func (ms *MainScene) rebuildLight() {
+	ms.light.Reset()
+	for _, block := range ms.blocks {
+		x, y := blockCell(block)
+		ms.light.Set(x, y, ms.lightBlock(block.Name))
+	}
+}
+
+// brightness returns how bright a cell is drawn, from minBrightness to 1
+func (ms *MainScene) brightness(x, y int) float64 {
+	level := ms.light.Display(x, y)
+	return math.Max(minBrightness, math.Pow(lightFalloff, float64(lighting.MaxLevel-level)))
+}
+
+// drawLight darkens what has been drawn of the world by the light of each cell
+func (ms *MainScene) drawLight(screen *ebiten.Image) {
+	ms.lightOverlay.Draw(screen, ms.camera, func(x, y int) float64 {
+		return 1 - ms.brightness(x, y)
+	})
+}
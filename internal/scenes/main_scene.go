@@ -6,41 +6,71 @@ import (
 	"image/color"
 	"math"
 	"math/rand"
-	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/wubinrui111/2d-game/internal/assets"
+	"github.com/wubinrui111/2d-game/internal/blocks"
+	"github.com/wubinrui111/2d-game/internal/camera"
+	"github.com/wubinrui111/2d-game/internal/editor"
 	"github.com/wubinrui111/2d-game/internal/entities"
+	"github.com/wubinrui111/2d-game/internal/fluids"
 	"github.com/wubinrui111/2d-game/internal/input"
+	"github.com/wubinrui111/2d-game/internal/lighting"
+	"github.com/wubinrui111/2d-game/internal/movement"
+	"github.com/wubinrui111/2d-game/internal/worldgen"
+	"github.com/wubinrui111/2d-game/internal/worldtime"
 	"github.com/wubinrui111/2d-game/internal/components"
+	"github.com/wubinrui111/2d-game/internal/fonts"
 	"github.com/wubinrui111/2d-game/internal/graphics"
+	"github.com/wubinrui111/2d-game/internal/grid"
 	graphicsSystem "github.com/wubinrui111/2d-game/internal/systems"
+	"github.com/wubinrui111/2d-game/internal/ticks"
+	"github.com/wubinrui111/2d-game/internal/tiled"
 )
 
 const (
 	// Game constants
 	GroundLevel = 550.0 // Y position of the ground surface
 	GridSize    = 32.0  // Size of the grid for alignment
+	
+	// zoomStep is the zoom factor of one mouse wheel step
+	zoomStep = 1.1
+	
+	// TickRate is the number of simulation ticks per second. Every tick advances the
+	// world by exactly 1/TickRate seconds, so replays of recorded input are deterministic.
+	TickRate = 60.0
+)
+
+var (
+	// debugTextStyle is used for the FPS counter and debug overlay
+	debugTextStyle = fonts.Style{Color: color.White, OutlineColor: color.Black, OutlineWidth: 1}
+
+	// gridLabelStyle is used for coordinate labels on the debug grid
+	gridLabelStyle = fonts.Style{Size: 10, Color: color.RGBA{255, 255, 255, 200}}
+
+	// healthTextStyle is used for the text below the health bar
+	healthTextStyle = fonts.Style{Size: 12, Color: color.White, OutlineColor: color.Black, OutlineWidth: 1}
 )
 
 type MainScene struct {
 	player    *entities.Player
 	inputMgr  *input.InputManager
+	playerMovement *movement.Controller // 玩家移动控制（移动模式、土狼时间、跳跃缓冲等）
 	blocks    []*entities.SmallBlock
+	blockIndex map[grid.Cell]int // 格子到 ms.blocks 下标的索引
+	blockEntities map[grid.Cell]map[string]string // 方块实体数据（如箱子的内容），随方块复制和粘贴
+	tiles     *graphics.TileGrid // 方块按区块索引，用于渲染
+	tileRenderer *graphics.TileRenderer // 区块缓存渲染（视野裁剪、图集批量绘制）
 	itemDrops []*entities.ItemDrop // 掉落物列表
-	cameraX   float64  // 添加摄像机X坐标
-	cameraY   float64  // 添加摄像机Y坐标
+	camera    *camera.Camera // 摄像机（跟随玩家、缩放、震动）
 	selectedBlock *entities.SmallBlock // 添加选中的方块
+	playerMining bool // 本帧是否在破坏方块，用于播放挖掘动画
 	// 添加鼠标点击状态跟踪，避免重复处理同一点击
 	leftClickProcessed   bool
 	rightClickProcessed  bool
 	// 添加网格显示控制字段
 	showGrid  bool
-	// 添加帧率计算相关字段
-	fps       float64
-	frameCount int
-	lastFpsUpdate time.Time
 	// 添加F3按键状态跟踪
 	f3Pressed bool
 	// 添加物品系统相关字段
@@ -51,6 +81,17 @@ type MainScene struct {
 	inventory       *components.Inventory
 	inventorySystem *graphicsSystem.InventorySystem
 	
+	// HUD（帧率、血条和调试信息）
+	hud *hud
+	
+	// 场景切换（用于打开暂停菜单），单独测试场景时为 nil
+	nav Navigator
+	
+	// 世界随机数，由种子决定，回放时使用同一种子
+	seed int64
+	rng  *rand.Rand
+	rngSource *countingSource // 记录取了多少个随机数，用于世界哈希
+	
 	// 添加鼠标跟随方块相关字段
 	draggedBlockType string // 被拖拽的方块类型
 	draggedBlockColor color.RGBA // 被拖拽方块的颜色
@@ -59,138 +100,261 @@ type MainScene struct {
 	// 添加精灵相关字段
 	playerSprite *ebiten.Image
 	blockSprites map[string]*ebiten.Image
+	
+	// 方块定义（外观和掉落物）
+	blockDefs *blocks.Registry
+	
+	// 玩家出生点（像素），来自关卡地图
+	spawnX, spawnY float64
+	
+	// 上次加载资源时资源管理器的重新加载次数，资源文件变化后重新加载
+	assetReloads uint64
+	
+	// 编辑器：所有方块修改都通过它记录，用于撤销和重做
+	editor  *editor.Editor
+	editing bool // 是否处于编辑器模式
+	
+	// 选区和直线工具拖动的起点，没有拖动时为 nil；editorErase 表示用攻击键（清除）拖动
+	editorDrag  *grid.Cell
+	editorErase bool
+	
+	// 上次加载到剪贴板的结构在结构列表中的位置
+	schematicIndex int
+	
+	// 光照：方块修改时增量更新，绘制时按亮度压暗画面
+	light        *lighting.Engine
+	lightOverlay *graphics.LightOverlay
+	
+	// 世界时钟：决定天空颜色、太阳和月亮的位置以及阳光的亮度
+	clock      *worldtime.Clock
+	skyImage   *ebiten.Image
+	sunSprite  *ebiten.Image
+	moonSprite *ebiten.Image
+	
+	// 流体：水和岩浆按计划的时刻流动，方块修改时通知它
+	fluids    *fluids.Sim
+	lavaTimer float64 // 距离下次岩浆伤害的秒数
+	
+	// 正在下落的方块（沙子、沙砾），落地后回到网格
+	fallingBlocks []*entities.FallingBlock
+	
+	// 方块刻：草蔓延、作物和树苗生长、树叶消失
+	ticks *ticks.Sim
+	
+	// 地形生成器，关卡没有开启生成时为 nil；columns 是已生成的区块列和它们的群系
+	generator *worldgen.Generator
+	columns   map[int]*worldgen.ChunkColumn
 }
 
-// NewMainScene creates a new main scene
+// NewMainScene creates a new main scene with a random seed
 func NewMainScene() *MainScene {
+	return NewMainSceneWithSeed(rand.Int63())
+}
+
+// NewMainSceneWithSeed creates a new main scene whose randomness is determined by seed
+func NewMainSceneWithSeed(seed int64) *MainScene {
+	scene, err := newMainScene(seed, DefaultLevelKey, assets.Default().Data)
+	if err != nil {
+		fmt.Printf("Failed to load level: %v\n", err)
+	}
+	return scene
+}
+
+// LoadMainScene creates a main scene with the world saved in a .tmx or .tmj file on
+// disk, such as the one the export action writes to LevelExportPath
+func LoadMainScene(path string) (*MainScene, error) {
+	return newMainScene(rand.Int63(), path, readFile)
+}
+
+// newMainScene creates a main scene whose randomness is determined by seed and places
+// the level read from name with read. The scene is usable even if the level fails to load.
+func newMainScene(seed int64, name string, read tiled.ReadFunc) (*MainScene, error) {
 	// Create the scene
 	scene := &MainScene{
 		player: entities.NewPlayer(320, 160), // Start player at (320, 160)
 		inputMgr: &input.InputManager{},
-		blocks: []*entities.SmallBlock{
-			entities.NewSmallBlock(192, 192),  // 对齐到32像素网格 (32*6, 32*6)
-			entities.NewSmallBlock(384, 288),  // 对齐到32像素网格 (32*12, 32*9)
-			entities.NewSmallBlock(96, 384),   // 对齐到32像素网格 (32*3, 32*12)
-			entities.NewSmallBlock(288, 128),  // 对齐到32像素网格 (32*9, 32*4)
-			entities.NewSmallBlock(480, 224),  // 对齐到32像素网格 (32*15, 32*7)
-			
-			entities.NewSmallBlock(192, 576),  // 对齐到32像素网格 (32*6, 32*18)
-			entities.NewSmallBlock(224, 640),  // 对齐到32像素网格 (32*7, 32*20)
-			entities.NewSmallBlock(288, 672),  // 对齐到32像素网格 (32*9, 32*21)
-			
-			entities.NewSmallBlock(384, 96),   // 空中方块 (32*12, 32*3)
-			entities.NewSmallBlock(480, 192),  // 空中方块 (32*15, 32*6)
-			entities.NewSmallBlock(576, 288),  // 空中方块 (32*18, 32*9)
-			
-			entities.NewSmallBlock(128, 672),  // 深地下方块 (32*4, 32*21)
-			entities.NewSmallBlock(288, 768),  // 深地下方块 (32*9, 32*24)
-			entities.NewSmallBlock(416, 864),  // 深地下方块 (32*13, 32*27)
-			
-			entities.NewSmallBlock(96, 448),   // 地面附近方块 (32*3, 32*14)
-			entities.NewSmallBlock(192, 480),  // 地面附近方块 (32*6, 32*15)
-			entities.NewSmallBlock(320, 512),  // 地面附近方块 (32*10, 32*16)
-		},
+		playerMovement: movement.NewController(),
+		blocks: []*entities.SmallBlock{}, // 方块来自关卡地图
+		blockIndex: make(map[grid.Cell]int),
+		blockEntities: make(map[grid.Cell]map[string]string),
 		itemDrops: []*entities.ItemDrop{}, // 初始化空的掉落物列表
-		cameraX:   0,
-		cameraY:      0,
+		camera:    camera.New(screenWidth, screenHeight),
 		selectedBlock: nil,
 		leftClickProcessed:   false,
 		rightClickProcessed:  false,
 		showGrid:  false,
-		fps:       0,
-		frameCount: 0,
-		lastFpsUpdate: time.Now(),
 		f3Pressed: false,
-		itemTypes: []string{"SmallBlock", "RedBlock", "BlueBlock", "GreenBlock"},
+		itemTypes: []string{"small_block", "red_block", "blue_block", "green_block"},
 		currentItemIndex: 0,
 		inventory:       components.NewInventory(27, 9),
 		inventorySystem: graphicsSystem.NewInventorySystem(),
+		hud:             newHUD(),
+		tiles:           graphics.NewTileGrid(),
+		spawnX:          320,
+		spawnY:          160,
+		schematicIndex:  -1,
+		seed:            seed,
 		draggedBlockType: "",
 		draggedBlockColor: color.RGBA{0, 0, 0, 0},
 		showDraggedBlock: false,
 		blockSprites: make(map[string]*ebiten.Image), // 初始化方块精灵映射
+		blockDefs:    blocks.NewRegistry(),
+		light:        lighting.New(),
+		lightOverlay: graphics.NewLightOverlay(GridSize),
+		clock:        worldtime.New(worldtime.DefaultTicksPerDay),
 	}
 	
-	// 初始化摄像机位置跟随玩家
-	scene.cameraX = scene.player.Position.X - 400  // 400是屏幕宽度的一半
-	scene.cameraY = scene.player.Position.Y - 300  // 300是屏幕高度的一半
+	// 方块修改经过编辑器，记录撤销历史
+	scene.editor = editor.New(scene, editor.DefaultHistoryBytes)
+	scene.rngSource = &countingSource{Source64: rand.NewSource(seed).(rand.Source64)}
+	scene.rng = rand.New(scene.rngSource)
+	scene.fluids = fluids.New(scene, fluids.Water, fluids.Lava)
+	scene.ticks = ticks.New(scene, scene.blockDefs, scene.rng)
 	
-	// 尝试加载精灵表
-	spriteSheet, err := graphics.NewSpriteSheet("./image/test.png", 32, 32)
-	if err == nil {
-		// 获取精灵映射
-		spriteMap := spriteSheet.GetSpriteMap()
-		
-		// 为玩家获取精灵（使用索引0）
-		if playerSprite, exists := spriteMap[0]; exists {
-			scene.playerSprite = playerSprite
+	// 加载精灵和方块定义，方块渲染按区块缓存
+	scene.tileRenderer = graphics.NewTileRenderer(scene.tiles, graphics.NewTileAtlas(int(GridSize), nil), GridSize)
+	scene.loadAssets()
+	
+	// 按关卡地图放置方块、出生点和物品
+	err := scene.loadLevel(name, read)
+	
+	// 初始化摄像机位置，玩家居中
+	scene.camera.CenterOn(scene.playerCenter())
+	
+	// 添加一些初始物品到物品栏
+	scene.initializeInventory()
+	
+	return scene, err
+}
+
+// loadAssets loads the sprites and block definitions through the asset manager. It is
+// called again when the asset files change; assets that fail to reload keep their old version.
+func (ms *MainScene) loadAssets() {
+	ms.assetReloads = assets.Default().Reloads()
+	
+	// 方块定义
+	if data, err := assets.Default().Data(blocks.DefaultKey); err != nil {
+		fmt.Printf("Failed to load block definitions: %v\n", err)
+	} else if registry, err := blocks.Parse(data); err != nil {
+		fmt.Printf("Failed to load block definitions: %v\n", err)
+	} else {
+		ms.blockDefs = registry
+		ms.ticks.Blocks = registry
+		ms.inventorySystem.SetBlockDefs(registry)
+	}
+	
+	// 按资源清单加载精灵，失败时使用默认颜色渲染
+	atlas, err := graphics.LoadAtlas(assets.Default(), graphics.DefaultManifestKey)
+	if err != nil {
+		fmt.Printf("Failed to load sprites: %v\n", err)
+	} else {
+		// 玩家、太阳、月亮和方块的精灵分开保存
+		ms.playerSprite, _ = atlas.Sprite("Player")
+		ms.sunSprite, _ = atlas.Sprite("sun")
+		ms.moonSprite, _ = atlas.Sprite("moon")
+		ms.blockSprites = make(map[string]*ebiten.Image)
+		for _, name := range atlas.Names() {
+			if name != "Player" && name != "sun" && name != "moon" {
+				ms.blockSprites[name], _ = atlas.Sprite(name)
+			}
 		}
 		
-		// 为不同类型方块建立精灵映射
-		for blockType, index := range graphics.BlockSpriteMapping {
-			if blockSprite, exists := spriteMap[index]; exists {
-				// 为玩家和方块分别处理
-				if blockType == "Player" {
-					scene.playerSprite = blockSprite
-				} else {
-					scene.blockSprites[blockType] = blockSprite
-				}
+		// 玩家动画使用图集中的 player_<状态> 片段，没有时显示静态精灵
+		ms.player.Animation.Clips = make(map[string]*components.AnimationClip)
+		for _, name := range entities.PlayerAnimations {
+			if clip, exists := atlas.Clip("player_" + name); exists {
+				ms.player.Animation.Clips[name] = clip
+			} else if ms.playerSprite != nil {
+				ms.player.Animation.Clips[name] = components.StaticClip(ms.playerSprite)
 			}
 		}
+		if ms.player.Animation.Clip() == "" {
+			ms.player.Animation.Play(entities.PlayerIdle)
+		}
+		ms.player.Animation.Restart()
 		
 		// 将方块精灵映射传递给物品栏系统
-		scene.inventorySystem.SetBlockSprites(scene.blockSprites)
-	} else {
-		// 如果加载失败，打印错误信息但继续运行（使用默认颜色渲染）
-		fmt.Printf("Failed to load sprite sheet: %v\n", err)
-		scene.playerSprite = nil
-		scene.blockSprites = nil
+		ms.inventorySystem.SetBlockSprites(ms.blockSprites)
 	}
 	
-	// 添加一些初始物品到物品栏
-	scene.initializeInventory()
+	// 方块精灵打包到图集，所有方块按新的定义和精灵重新渲染
+	ms.tileRenderer.SetAtlas(graphics.NewTileAtlas(int(GridSize), ms.blockSprites))
+	for _, block := range ms.blocks {
+		ms.updateTile(block)
+	}
 	
-	return scene
+	// 方块的发光和透明度可能变了，重新计算光照
+	ms.rebuildLight()
 }
 
-// updateFps 更新帧率计算
-func (ms *MainScene) updateFps() {
-	ms.frameCount++
-	
-	// 每秒更新一次FPS
-	if time.Since(ms.lastFpsUpdate) >= time.Second {
-		ms.fps = float64(ms.frameCount) / time.Since(ms.lastFpsUpdate).Seconds()
-		ms.frameCount = 0
-		ms.lastFpsUpdate = time.Now()
-	}
+// Seed returns the seed of the world's randomness
+func (ms *MainScene) Seed() int64 {
+	return ms.seed
+}
+
+// SetNavigator implements NavigatorSetter
+func (ms *MainScene) SetNavigator(nav Navigator) {
+	ms.nav = nav
 }
 
 // Update updates the scene state
 func (ms *MainScene) Update() error {
+	// 输入时间时世界暂停，Esc 关闭输入框
+	if ms.updateTimePrompt() {
+		return nil
+	}
+	
+	// Esc 先关闭物品栏，否则打开暂停菜单
+	if input.IsActionJustPressed(input.ActionPause) {
+		if ms.inventorySystem.Visible {
+			ms.inventorySystem.Visible = false
+		} else if ms.nav != nil {
+			ms.nav.Push(NewPauseScene())
+			return nil
+		}
+	}
+	
+	// 资源文件变化后重新加载精灵和方块定义（开发模式）
+	if assets.Default().Reloads() != ms.assetReloads {
+		ms.loadAssets()
+	}
+	
 	// Update inventory system (handles key presses for inventory, etc.)
 	ms.inventorySystem.Update(ms.inventory)
 	
-	// Update player with gravity and input
-	ms.inputMgr.Update(&ms.player.Velocity, &ms.player.Acceleration, ms.player.OnGround)
+	// 推进世界时钟，阳光随时间变化
+	ms.updateTime()
+	ms.fluids.Update()
+	ms.updateFallingBlocks()
+	ms.updateBlockTicks()
+	ms.updateGeneration()
 	
-	// Apply gravity if enabled
-	if ms.player.Gravity.Enabled {
-		ms.player.Velocity.Y += ms.player.Gravity.Force / 60.0 // 60 FPS
+	// 切换编辑器模式
+	if input.IsActionJustPressed(input.ActionToggleEditor) {
+		ms.toggleEditor()
 	}
 	
-	// Apply friction/resistance based on whether player is on ground
-	if ms.player.OnGround {
-		ms.player.Velocity.X *= ms.player.Acceleration.GroundFriction
-	} else {
-		ms.player.Velocity.X *= ms.player.Acceleration.AirResistance
-	}
+	// Update player with input, gravity, drag and speed caps of the current movement mode
+	// 创造模式和编辑器模式下双击跳跃切换飞行，旁观模式可以穿过方块
+	ms.playerMovement.CanFly = ms.inventorySystem.GameMode == graphicsSystem.GameModeCreative || ms.editing
+	ms.playerMovement.Spectator = ms.inventorySystem.GameMode == graphicsSystem.GameModeSpectator
+	water, lava := ms.playerSubmersion(fluids.Water.ID), ms.playerSubmersion(fluids.Lava.ID)
+	ms.playerMovement.Update(ms.inputMgr.Movement(), 1/TickRate, movement.Body{
+		Velocity:     &ms.player.Velocity,
+		Acceleration: &ms.player.Acceleration,
+		Jump:         &ms.player.Jump,
+		Gravity:      &ms.player.Gravity,
+		OnGround:     ms.player.OnGround,
+		InWater:      water > 0 || lava > 0,
+		Submerged:    math.Min(1, water+lava),
+	})
 	
 	// Store previous Y velocity for fall damage calculation
 	prevVelocityY := ms.player.Velocity.Y
 	
 	// Update player position
-	ms.player.Position.X += ms.player.Velocity.X / 60.0 // 60 FPS
-	ms.player.Position.Y += ms.player.Velocity.Y / 60.0 // 60 FPS
+	ms.player.Position.X += ms.player.Velocity.X / TickRate
+	ms.player.Position.Y += ms.player.Velocity.Y / TickRate
 	
 	// Reset onGround status
 	ms.player.OnGround = false
@@ -201,77 +365,73 @@ func (ms *MainScene) Update() error {
 	// Check ground collision (no longer used)
 	// ms.checkGroundCollision()
 	
-	// Check block collisions
-	ms.resolveCollisions()
-	
-	// Update FPS counter
-	ms.updateFps()
+	// Check block collisions (not in spectator mode)
+	if ms.playerMovement.Mode().Collides() {
+		ms.resolveCollisions()
+	}
 	
 	// Handle player fall damage
 	ms.handleFallDamage(prevVelocityY)
 	
-	// Example: Take damage when colliding with certain blocks
-	// Only take damage in survival mode
-	if ms.inventorySystem.GameMode == 0 {
-		for _, block := range ms.blocks {
-			// Check if block is a "dangerous" block (example implementation)
-			if block.Name == "LavaBlock" && ms.player.Box.Intersects(&block.Box) {
-				ms.player.TakeDamage(5) // Take 5 damage per frame
-			}
-		}
-	}
+	// 在岩浆中定时受到伤害（仅生存模式）
+	ms.updateLavaDamage()
 	
 	// Check if player is dead
 	if !ms.player.IsAlive() {
 		// In creative mode, player cannot die
-		if ms.inventorySystem.GameMode == 1 {
+		if ms.inventorySystem.GameMode != graphicsSystem.GameModeSurvival {
 			ms.player.Heal(ms.player.Max) // Restore full health
 		} else {
 			// Respawn player at initial position
-			ms.player.Position.X = 320
-			ms.player.Position.Y = 160
+			ms.player.Position.X = ms.spawnX
+			ms.player.Position.Y = ms.spawnY
 			ms.player.Velocity.X = 0
 			ms.player.Velocity.Y = 0
 			ms.player.Heal(ms.player.Max) // Restore full health
 			
 			// Reset player state to prevent getting stuck
 			ms.player.OnGround = false
+			ms.playerMovement.Reset()
 		}
 	}
 	
-	// 平滑跟随摄像机实现
-	// 计算摄像机目标位置（玩家位置居中）
-	targetX := ms.player.Position.X - 400
-	targetY := ms.player.Position.Y - 300
-	
-	// 使用线性插值实现平滑跟随
-	smoothFactor := 0.1
-	ms.cameraX += (targetX - ms.cameraX) * smoothFactor
-	ms.cameraY += (targetY - ms.cameraY) * smoothFactor
+	// 摄像机跟随玩家（死区、按速度向前看、平滑移动和震动）
+	centerX, centerY := ms.playerCenter()
+	ms.camera.Update(centerX, centerY, ms.player.Velocity.X, ms.player.Velocity.Y, 1/TickRate)
 	
 	// 处理鼠标点击事件
 	ms.handleMouseInput()
 	
-	// 处理物品切换
-	ms.handleItemSwitching()
+	// 根据速度、是否着地和是否在挖掘切换玩家动画
+	ms.player.UpdateAnimation(1/TickRate, ms.playerMining)
+	
+	// 按住缩放键（默认Ctrl）时滚轮缩放摄像机，否则切换物品
+	if input.IsActionPressed(input.ActionZoom) {
+		ms.handleZoom()
+	} else {
+		ms.handleItemSwitching()
+	}
 	
 	// 处理F3按键切换网格显示
-	if inpututil.IsKeyJustPressed(ebiten.KeyF3) {
+	// 将当前世界导出为 Tiled 地图
+	if input.IsActionJustPressed(input.ActionExportLevel) {
+		if err := ms.exportLevel(LevelExportPath); err != nil {
+			fmt.Printf("Failed to export level: %v\n", err)
+		} else {
+			fmt.Printf("Exported level to %s\n", LevelExportPath)
+		}
+	}
+	
+	if input.IsActionJustPressed(input.ActionToggleGrid) {
 		ms.showGrid = !ms.showGrid
 	}
 	
-	// Update item drops
+	// Update item drops, which fall through fluids
 	for i := len(ms.itemDrops) - 1; i >= 0; i-- {
 		itemDrop := ms.itemDrops[i]
 		
-		// Convert blocks to BoxHolder interface
-		boxHolders := make([]components.BoxHolder, len(ms.blocks))
-		for j, block := range ms.blocks {
-			boxHolders[j] = block
-		}
-		
-		// Update item drop behavior with block collision
-		itemDrop.Update(ms.player.Position, 1.0/60.0, boxHolders)
+		// Update item drop behavior with collisions against the blocks near it
+		itemDrop.Update(ms.player.Position, 1.0/TickRate, ms.solidBlocksNear(itemDrop.Box, moveMargin(itemDrop.Velocity)))
 		
 		// Check if item should disappear (lifetime exceeded)
 		if itemDrop.ShouldDisappear() {
@@ -304,8 +464,8 @@ func (ms *MainScene) Update() error {
 
 // handleFallDamage calculates and applies fall damage to the player
 func (ms *MainScene) handleFallDamage(prevVelocityY float64) {
-	// 在创造模式下不受到摔落伤害
-	if ms.inventorySystem.GameMode == 1 {
+	// 在创造模式和旁观模式下不受到摔落伤害
+	if ms.inventorySystem.GameMode != graphicsSystem.GameModeSurvival {
 		return
 	}
 	
@@ -342,27 +502,42 @@ func (ms *MainScene) handleFallDamage(prevVelocityY float64) {
 			
 			// Apply the damage to the player
 			ms.player.TakeDamage(damage)
+			
+			// 摔得越重，画面震动越强
+			ms.camera.Shake(float64(damage) / maxFallDamage)
 		}
 	}
 }
 
+// playerCenter returns the world position of the center of the player, which the camera follows
+func (ms *MainScene) playerCenter() (float64, float64) {
+	return ms.player.Position.X + ms.player.Box.Width/2, ms.player.Position.Y + ms.player.Box.Height/2
+}
+
+// handleZoom 处理摄像机缩放（滚轮向上放大，向下缩小）
+func (ms *MainScene) handleZoom() {
+	if input.IsActionJustPressed(input.ActionPrevSlot) {
+		ms.camera.ZoomBy(zoomStep)
+	} else if input.IsActionJustPressed(input.ActionNextSlot) {
+		ms.camera.ZoomBy(1 / zoomStep)
+	}
+}
+
 // handleItemSwitching 处理物品切换输入
 func (ms *MainScene) handleItemSwitching() {
-	// 处理滚轮切换物品（原始方向）
-	_, wheelY := ebiten.Wheel()
-	if wheelY > 0 {
-		// 向上滚动，切换到下一个物品
+	// 处理滚轮（或手柄肩键）切换物品，方向与物品栏一致
+	if input.IsActionJustPressed(input.ActionNextSlot) {
+		// 切换到下一个物品
 		ms.currentItemIndex = (ms.currentItemIndex + 1) % len(ms.itemTypes)
-	} else if wheelY < 0 {
-		// 向下滚动，切换到上一个物品
+	} else if input.IsActionJustPressed(input.ActionPrevSlot) {
+		// 切换到上一个物品
 		ms.currentItemIndex = (ms.currentItemIndex - 1 + len(ms.itemTypes)) % len(ms.itemTypes)
 	}
 	
-	// 处理数字键切换物品 (1-9)
-	for i := 1; i <= 9 && i <= len(ms.itemTypes); i++ {
-		key := ebiten.Key(int(ebiten.Key1) + i - 1)
-		if ebiten.IsKeyPressed(key) {
-			ms.currentItemIndex = i - 1
+	// 处理快捷栏按键切换物品 (默认为数字键1-9)
+	for i := 0; i < 9 && i < len(ms.itemTypes); i++ {
+		if input.IsActionPressed(input.HotbarAction(i)) {
+			ms.currentItemIndex = i
 			break
 		}
 	}
@@ -370,16 +545,42 @@ func (ms *MainScene) handleItemSwitching() {
 
 // handleMouseInput 处理鼠标输入事件
 func (ms *MainScene) handleMouseInput() {
-	// 获取鼠标位置并转换为世界坐标
-	mouseX, mouseY := ebiten.CursorPosition()
-	worldX := float64(mouseX) + ms.cameraX
-	worldY := float64(mouseY) + ms.cameraY
+	// 获取光标位置（鼠标或手柄虚拟光标）并转换为世界坐标
+	worldX, worldY := ms.camera.ScreenToWorld(input.Cursor())
 	
 	// 更新鼠标跟随方块的位置
 	ms.updateDraggedBlock(worldX, worldY)
 	
-	// 处理左键点击（破坏方块）
-	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+	// 鼠标在物品栏界面上时不操作世界中的方块
+	ms.playerMining = false
+	if ms.inventorySystem.WantsMouse() {
+		ms.editor.End()
+		return
+	}
+	
+	// 编辑器模式下使用编辑工具
+	if ms.editing {
+		ms.handleEditorInput(worldX, worldY)
+		return
+	}
+	
+	// 旁观模式下不能修改世界
+	if ms.inventorySystem.GameMode == graphicsSystem.GameModeSpectator {
+		return
+	}
+	
+	// 按住攻击或使用键连续修改的方块作为一次操作撤销
+	if input.IsActionPressed(input.ActionAttack) || input.IsActionPressed(input.ActionUseItem) {
+		if !ms.editor.Grouping() {
+			ms.editor.Begin("stroke")
+		}
+	} else {
+		ms.editor.End()
+	}
+	
+	// 处理攻击（默认左键，破坏方块）
+	if input.IsActionPressed(input.ActionAttack) {
+		ms.playerMining = true
 		// 连续破坏方块
 		ms.removeBlockAt(worldX, worldY)
 		// 注意：我们不设置leftClickProcessed为true，这样可以实现连续破坏
@@ -387,8 +588,8 @@ func (ms *MainScene) handleMouseInput() {
 		ms.leftClickProcessed = false
 	}
 	
-	// 处理右键点击（放置方块）
-	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight) {
+	// 处理使用物品（默认右键，放置方块）
+	if input.IsActionPressed(input.ActionUseItem) {
 		// 连续放置方块
 		ms.placeBlockAt(worldX, worldY)
 		// 注意：我们不设置rightClickProcessed为true，这样可以实现连续放置
@@ -396,128 +597,43 @@ func (ms *MainScene) handleMouseInput() {
 		ms.rightClickProcessed = false
 	}
 	
-	// 处理中键点击（拾取方块）
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonMiddle) {
+	// 处理拾取方块（默认中键）
+	if input.IsActionJustPressed(input.ActionPickBlock) {
 		ms.pickBlockAt(worldX, worldY)
 	}
 }
 
 // removeBlockAt 在指定位置移除方块
 func (ms *MainScene) removeBlockAt(x, y float64) {
-	// 计算方块应该所在的网格位置（强制对齐到GridSize像素网格）
-	gridX := math.Floor(x/GridSize) * GridSize
-	gridY := math.Floor(y/GridSize) * GridSize
-	
-	for i, block := range ms.blocks {
-		// 检查方块是否在计算出的网格位置上
-		if block.Position.X == gridX && block.Position.Y == gridY {
-			// 创建掉落物
-			var item *components.Item
-			
-			// 根据方块类型创建相应的物品
-			switch block.Name {
-			case "RedBlock":
-				item = &components.Item{
-					ID:       "red_block",
-					Name:     "Red Block",
-					Count:    1,
-					MaxStack: 64,
-					Color:    color.RGBA{200, 50, 50, 255},
-				}
-			case "BlueBlock":
-				item = &components.Item{
-					ID:       "blue_block",
-					Name:     "Blue Block",
-					Count:    1,
-					MaxStack: 64,
-					Color:    color.RGBA{50, 50, 200, 255},
-				}
-			case "GreenBlock":
-				item = &components.Item{
-					ID:       "green_block",
-					Name:     "Green Block",
-					Count:    1,
-					MaxStack: 64,
-					Color:    color.RGBA{50, 200, 50, 255},
-				}
-			case "SmallBlock":
-				fallthrough
-			default:
-				// 根据颜色判断方块类型
-				blockColor := block.GetColor()
-				if blockColor.R == 128 && blockColor.G == 128 && blockColor.B == 128 {
-					// Stone block
-					item = &components.Item{
-						ID:       "stone",
-						Name:     "Stone",
-						Count:    1,
-						MaxStack: 64,
-						Color:    color.RGBA{128, 128, 128, 255},
-					}
-				} else if blockColor.R == 100 && blockColor.G == 50 && blockColor.B == 0 {
-					// Dirt block
-					item = &components.Item{
-						ID:       "dirt",
-						Name:     "Dirt",
-						Count:    1,
-						MaxStack: 64,
-						Color:    color.RGBA{100, 50, 0, 255},
-					}
-				} else if blockColor.R == 100 && blockColor.G == 70 && blockColor.B == 30 {
-					// Wood block
-					item = &components.Item{
-						ID:       "wood",
-						Name:     "Wood",
-						Count:    1,
-						MaxStack: 64,
-						Color:    color.RGBA{100, 70, 30, 255},
-					}
-				} else {
-					// Default small block
-					item = &components.Item{
-						ID:       "small_block",
-						Name:     "Small Block",
-						Count:    1,
-						MaxStack: 64,
-						Color:    color.RGBA{200, 200, 50, 255},
-					}
-				}
-			}
-			
-			// 在方块位置创建掉落物，稍微偏移一点位置以避免重叠
-			itemDrop := entities.NewItemDrop(gridX+8, gridY+8, item)
-			ms.itemDrops = append(ms.itemDrops, itemDrop)
-			
-			// 从切片中移除方块
-			ms.blocks = append(ms.blocks[:i], ms.blocks[i+1:]...)
-			
-			// 成功移除方块后立即返回
-			// 注意：由于我们移除了一个元素，索引i现在指向下一个元素
-			// 但由于我们直接返回，所以不需要处理这种情况
-			return
-		}
+	// 计算方块所在的网格（强制对齐到GridSize像素网格）
+	cell := worldCell(x, y)
+	block := ms.blockAt(cell)
+	if block == nil || ms.flowingFluid(cell) {
+		// 该位置没有方块，什么也不做；流动的流体不能挖掉
+		return
 	}
-	// 如果没有找到相交的方块，什么也不做
+	
+	// 按方块定义创建掉落物，稍微偏移一点位置以避免重叠
+	item := ms.itemForBlock(block)
+	itemDrop := entities.NewItemDrop(block.Position.X+8, block.Position.Y+8, item)
+	ms.itemDrops = append(ms.itemDrops, itemDrop)
+	
+	ms.editor.Set("break", cell, "")
 }
 
 // placeBlockAt 在指定位置放置新方块
 func (ms *MainScene) placeBlockAt(x, y float64) {
-	// 计算方块应该放置的网格位置（强制对齐到GridSize像素网格）
-	gridX := math.Floor(x/GridSize) * GridSize
-	gridY := math.Floor(y/GridSize) * GridSize
+	// 计算方块应该放置的网格（强制对齐到GridSize像素网格）
+	cell := worldCell(x, y)
 	
 	// 检查该位置是否已经有方块
-	for _, block := range ms.blocks {
-		if block.Position.X == gridX && block.Position.Y == gridY {
-			// 该位置已有方块，不放置新方块
-			return
-		}
+	if ms.blockAt(cell) != nil && !ms.flowingFluid(cell) {
+		// 该位置已有方块，不放置新方块；流动的流体可以被替换
+		return
 	}
 	
 	// 检查是否试图在玩家位置放置方块
-	playerGridX := math.Floor(ms.player.Position.X/GridSize) * GridSize
-	playerGridY := math.Floor(ms.player.Position.Y/GridSize) * GridSize
-	if gridX == playerGridX && gridY == playerGridY {
+	if cell == worldCell(ms.player.Position.X, ms.player.Position.Y) {
 		// 不允许在玩家位置放置方块
 		return
 	}
@@ -529,38 +645,164 @@ func (ms *MainScene) placeBlockAt(x, y float64) {
 		return
 	}
 	
-	// 根据当前选中的物品类型创建相应的方块
-	var newBlock *entities.SmallBlock
-	switch selectedItem.Item.ID {
-	case "red_block":
-		newBlock = entities.NewRedBlock(gridX, gridY)
-	case "blue_block":
-		newBlock = entities.NewBlueBlock(gridX, gridY)
-	case "green_block":
-		newBlock = entities.NewGreenBlock(gridX, gridY)
-	case "stone":
-		newBlock = entities.NewSmallBlockWithColor(gridX, gridY, color.RGBA{128, 128, 128, 255})
-		newBlock.SetName("stone")
-	case "dirt":
-		newBlock = entities.NewSmallBlockWithColor(gridX, gridY, color.RGBA{100, 50, 0, 255})
-		newBlock.SetName("dirt")
-	case "wood":
-		newBlock = entities.NewSmallBlockWithColor(gridX, gridY, color.RGBA{100, 70, 30, 255})
-		newBlock.SetName("wood")
-	default: // "small_block" 或其他情况
-		newBlock = entities.NewSmallBlock(gridX, gridY)
-		newBlock.SetName("small_block")
+	// 根据当前选中物品的方块定义放置方块，没有方块定义的物品不能放置
+	def, exists := ms.blockDefs.Get(selectedItem.Item.ID)
+	if !exists {
+		return
 	}
 	
 	// 减少物品数量（创造模式下不减少物品数量）
-	if ms.inventorySystem.GameMode == 0 { // 生存模式才减少物品
+	if ms.inventorySystem.GameMode == graphicsSystem.GameModeSurvival { // 生存模式才减少物品
 		if !ms.inventory.RemoveItem(selectedItem.Item.ID, 1) {
 			// 移除物品失败
 			return
 		}
 	}
 	
-	ms.blocks = append(ms.blocks, newBlock)
+	ms.editor.Set("place", cell, def.ID)
+	
+	// 会下落的方块放在空中时立即下落
+	ms.checkFalling(cell)
+}
+
+// Block implements editor.World: it returns the ID of the block at a cell, "" if there is none
+func (ms *MainScene) Block(x, y int) string {
+	if block := ms.blockAt(grid.Cell{X: x, Y: y}); block != nil {
+		return block.Name
+	}
+	return ""
+}
+
+// SetBlock implements editor.World: it puts the block id at a cell, replacing the block
+// there, or removes the block if id is "". It is the only place that changes ms.blocks;
+// changes that should be undoable go through ms.editor instead.
+func (ms *MainScene) SetBlock(x, y int, id string) {
+	cell := grid.Cell{X: x, Y: y}
+	
+	// 方块实体属于原来的方块，粘贴时在方块之后重新设置
+	delete(ms.blockEntities, cell)
+	if i, exists := ms.blockIndex[cell]; exists {
+		// 用最后一个方块填补空位，保持索引连续
+		last := len(ms.blocks) - 1
+		if i != last {
+			ms.blocks[i] = ms.blocks[last]
+			ms.blockIndex[blockCellOf(ms.blocks[i])] = i
+		}
+		ms.blocks[last] = nil
+		ms.blocks = ms.blocks[:last]
+		delete(ms.blockIndex, cell)
+		ms.tiles.Remove(x, y)
+		ms.light.Remove(x, y)
+	}
+	if id == "" {
+		ms.fluids.Changed(x, y)
+		ms.ticks.Changed(x, y)
+		
+		// 上方会下落的方块失去支撑，不论方块是挖掉、剪切、消失还是自己落下
+		ms.checkFalling(grid.Cell{X: x, Y: y - 1})
+		return
+	}
+	
+	// 按方块定义创建方块，没有定义的方块使用灰色
+	blockColor := color.RGBA{128, 128, 128, 255}
+	if def, exists := ms.blockDefs.Lookup(id); exists {
+		id, blockColor = def.ID, def.Color
+	}
+	block := entities.NewSmallBlockWithColor(float64(x)*GridSize, float64(y)*GridSize, blockColor)
+	block.SetName(id)
+	ms.blockIndex[cell] = len(ms.blocks)
+	ms.blocks = append(ms.blocks, block)
+	ms.updateTile(block)
+	ms.light.Set(x, y, ms.lightBlock(id))
+	ms.fluids.Changed(x, y)
+	ms.ticks.Changed(x, y)
+	if ms.isFluid(block) {
+		// 流体托不住上方的方块
+		ms.checkFalling(grid.Cell{X: x, Y: y - 1})
+	}
+}
+
+// BlockEntity implements editor.EntityWorld: it returns the block entity data of a cell,
+// nil if the cell has none
+func (ms *MainScene) BlockEntity(x, y int) map[string]string {
+	return ms.blockEntities[grid.Cell{X: x, Y: y}]
+}
+
+// SetBlockEntity implements editor.EntityWorld: it sets the block entity data of the
+// block at a cell, or removes it if data is empty. Cells without a block have no data.
+func (ms *MainScene) SetBlockEntity(x, y int, data map[string]string) {
+	cell := grid.Cell{X: x, Y: y}
+	if len(data) == 0 || ms.blockAt(cell) == nil {
+		delete(ms.blockEntities, cell)
+		return
+	}
+	ms.blockEntities[cell] = data
+}
+
+// blockAt returns the block at a cell, nil if there is none
+func (ms *MainScene) blockAt(cell grid.Cell) *entities.SmallBlock {
+	if i, exists := ms.blockIndex[cell]; exists {
+		return ms.blocks[i]
+	}
+	return nil
+}
+
+// updateTile puts a block's tile into the render grid, drawn as its definition says
+func (ms *MainScene) updateTile(block *entities.SmallBlock) {
+	cellX, cellY := blockCell(block)
+	tile := graphics.Tile{Name: block.Name, Color: block.GetColor()}
+	if def, exists := ms.blockDefs.Lookup(block.Name); exists {
+		tile = graphics.Tile{Name: def.Sprite, Color: def.Color}
+		if def.Tinted {
+			// 草和树叶按所在的生物群系着色
+			tile.Tint = ms.biomeTint(cellX)
+		}
+	}
+	if _, exists := ms.blockSprites[tile.Name]; !exists && ms.blockSprites != nil && tile.Name != "" {
+		// 找不到对应名称的精灵时使用默认精灵，没有精灵的方块使用纯色
+		tile.Name = blocks.DefaultSprite
+	}
+	if ms.isFluid(block) {
+		// 流动的流体越浅越透明
+		tile.Color = ms.fluidColor(cellX, cellY, tile.Color)
+	}
+	ms.tiles.Set(cellX, cellY, tile)
+}
+
+// itemForBlock returns the item a block drops when it is broken
+func (ms *MainScene) itemForBlock(block *entities.SmallBlock) *components.Item {
+	return ms.itemFor(block.Name, block.GetColor())
+}
+
+// itemFor returns the item a block named name drops, colored c if it has no definition
+func (ms *MainScene) itemFor(name string, c color.RGBA) *components.Item {
+	if def, exists := ms.blockDefs.Lookup(name); exists {
+		return &components.Item{ID: def.ID, Name: def.Name, Count: 1, MaxStack: 64, Color: def.Color}
+	}
+	// 没有定义的方块掉落同名物品
+	return &components.Item{ID: name, Name: name, Count: 1, MaxStack: 64, Color: c}
+}
+
+// blockSprite returns the sprite of a block or item ID as its definition says, the
+// default sprite if the sprite is missing and nil if the block is drawn as a color
+func (ms *MainScene) blockSprite(id string) *ebiten.Image {
+	return graphicsSystem.BlockSprite(ms.blockDefs, ms.blockSprites, id)
+}
+
+// blockCell returns the grid cell of a block
+func blockCell(block *entities.SmallBlock) (int, int) {
+	return int(math.Floor(block.Position.X / GridSize)), int(math.Floor(block.Position.Y / GridSize))
+}
+
+// blockCellOf returns the grid cell of a block as a cell
+func blockCellOf(block *entities.SmallBlock) grid.Cell {
+	x, y := blockCell(block)
+	return grid.Cell{X: x, Y: y}
+}
+
+// worldCell returns the grid cell containing a world position
+func worldCell(x, y float64) grid.Cell {
+	return grid.Cell{X: int(math.Floor(x / GridSize)), Y: int(math.Floor(y / GridSize))}
 }
 
 // checkGroundCollision checks if the player has hit the ground
@@ -572,11 +814,12 @@ func (ms *MainScene) checkGroundCollision() {
 
 // resolveCollisions checks and resolves collisions between the player and blocks
 func (ms *MainScene) resolveCollisions() {
-	// Check collision with all blocks including ground surface blocks
-	for _, block := range ms.blocks {
-		if ms.player.Box.Intersects(&block.Box) {
+	// 只检查玩家碰撞箱附近格子里的方块，推开玩家后可能碰到相邻格子的方块
+	for _, holder := range ms.solidBlocksNear(ms.player.Box, GridSize) {
+		block := holder.GetBox()
+		if ms.player.Box.Intersects(block) {
 			// Calculate intersection depth
-			xDepth, yDepth := ms.player.Box.GetIntersectionDepth(&block.Box)
+			xDepth, yDepth := ms.player.Box.GetIntersectionDepth(block)
 			
 			// Determine the minimum translation vector
 			if math.Abs(xDepth) < math.Abs(yDepth) {
@@ -606,17 +849,15 @@ func (ms *MainScene) resolveCollisions() {
 
 // Draw renders the scene
 func (ms *MainScene) Draw(screen *ebiten.Image) {
-	// 获取鼠标位置并应用摄像机偏移
-	mouseX, mouseY := ebiten.CursorPosition()
-	mouseXFloat := float64(mouseX) + ms.cameraX
-	mouseYFloat := float64(mouseY) + ms.cameraY
+	// 获取光标位置并转换为世界坐标
+	mouseXFloat, mouseYFloat := ms.camera.ScreenToWorld(input.Cursor())
 	
 	// 计算鼠标所在的网格位置
 	mouseGridX := math.Floor(mouseXFloat/GridSize) * GridSize
 	mouseGridY := math.Floor(mouseYFloat/GridSize) * GridSize
 	
-	// 绘制背景
-	ebitenutil.DrawRect(screen, 0, 0, 800, 600, color.RGBA{135, 206, 235, 255}) // Sky blue background
+	// 绘制随时间变化的天空、太阳和月亮
+	ms.drawSky(screen)
 	
 	// 绘制坐标系网格（如果启用）
 	if ms.showGrid {
@@ -624,192 +865,96 @@ func (ms *MainScene) Draw(screen *ebiten.Image) {
 	}
 	
 	// Draw the player
-	if ms.playerSprite != nil {
-		// 使用精灵渲染玩家
-		opts := &ebiten.DrawImageOptions{}
-		opts.GeoM.Translate(ms.player.Position.X-ms.cameraX, ms.player.Position.Y-ms.cameraY)
-		screen.DrawImage(ms.playerSprite, opts)
+	if frame := ms.player.Animation.Frame(); frame != nil {
+		// 使用当前动画帧渲染玩家
+		ms.drawPlayerFrame(screen, frame)
 	} else {
 		// 回退到纯色矩形渲染
 		playerColor := ms.player.GetColor()
 		ms.drawBoxWithBorder(screen, ms.player.Position.X, ms.player.Position.Y, ms.player.Box.Width, ms.player.Box.Height, playerColor, color.RGBA{0, 0, 0, 255})
 	}
 
-	// Draw blocks
-	for _, block := range ms.blocks {
-		if ms.blockSprites != nil {
-			// 使用精灵渲染方块
-			var blockSprite *ebiten.Image
-			
-			// 根据方块名称选择对应的精灵
-			if sprite, exists := ms.blockSprites[block.Name]; exists {
-				blockSprite = sprite
-			} else {
-				// 如果找不到对应名称的精灵，使用默认精灵
-				blockSprite = ms.blockSprites["SmallBlock"]
-			}
-			
-			if blockSprite != nil {
-				opts := &ebiten.DrawImageOptions{}
-				opts.GeoM.Translate(block.Position.X-ms.cameraX, block.Position.Y-ms.cameraY)
-				screen.DrawImage(blockSprite, opts)
-			} else {
-				// 回退到纯色渲染
-				blockColor := block.GetColor()
-				
-				// 检查鼠标是否悬停在方块上
-				if block.IsMouseOver(mouseXFloat, mouseYFloat) {
-					// 如果鼠标悬停，绘制高亮边框
-					ms.drawBoxWithHighlight(screen, block.Position.X, block.Position.Y, block.Box.Width, block.Box.Height, blockColor)
-				} else {
-					// 否则绘制普通边框
-					ms.drawBoxWithBorder(screen, block.Position.X, block.Position.Y, block.Box.Width, block.Box.Height, blockColor, color.RGBA{0, 0, 0, 255})
-				}
-			}
-		} else {
-			// 回退到纯色矩形渲染
-			blockColor := block.GetColor()
-			
-			// 检查鼠标是否悬停在方块上
-			if block.IsMouseOver(mouseXFloat, mouseYFloat) {
-				// 如果鼠标悬停，绘制高亮边框
-				ms.drawBoxWithHighlight(screen, block.Position.X, block.Position.Y, block.Box.Width, block.Box.Height, blockColor)
-			} else {
-				// 否则绘制普通边框
-				ms.drawBoxWithBorder(screen, block.Position.X, block.Position.Y, block.Box.Width, block.Box.Height, blockColor, color.RGBA{0, 0, 0, 255})
-			}
-		}
+	// Draw blocks：只绘制视野内的区块，区块图像有变化时才重新渲染
+	ms.tileRenderer.Draw(screen, ms.camera)
+	
+	// 没有精灵时高亮鼠标悬停的方块
+	if tile, exists := ms.tiles.At(int(mouseGridX/GridSize), int(mouseGridY/GridSize)); exists && ms.blockSprites == nil {
+		ms.drawBoxWithHighlight(screen, mouseGridX, mouseGridY, GridSize, GridSize, tile.Color)
 	}
 	
 	// 绘制鼠标跟随方块（如果启用）
 	if ms.showDraggedBlock {
-		if ms.blockSprites != nil {
-			// 使用精灵渲染鼠标跟随方块
-			var blockSprite *ebiten.Image
-			
-			// 根据方块类型选择对应的精灵
-			switch ms.draggedBlockType {
-			case "red_block":
-				blockSprite = ms.blockSprites["red_block"]
-			case "blue_block":
-				blockSprite = ms.blockSprites["blue_block"]
-			case "green_block":
-				blockSprite = ms.blockSprites["green_block"]
-			case "dirt":
-				blockSprite = ms.blockSprites["dirt"]
-			case "wood":
-				blockSprite = ms.blockSprites["wood"]
-			case "stone":
-				blockSprite = ms.blockSprites["stone"]
-			default:
-				blockSprite = ms.blockSprites["small_block"]
-			}
-			
-			if blockSprite != nil {
-				opts := &ebiten.DrawImageOptions{}
-				opts.GeoM.Translate(mouseGridX-ms.cameraX, mouseGridY-ms.cameraY)
-				screen.DrawImage(blockSprite, opts)
-			} else {
-				// 回退到纯色矩形渲染
-				ms.drawBoxWithBorder(screen, mouseGridX, mouseGridY, GridSize, GridSize, ms.draggedBlockColor, color.RGBA{255, 255, 255, 255})
-			}
+		// 按方块定义选择精灵渲染鼠标跟随方块
+		if blockSprite := ms.blockSprite(ms.draggedBlockType); blockSprite != nil {
+			ms.drawWorldImage(screen, blockSprite, mouseGridX, mouseGridY, 1, 1)
 		} else {
 			// 回退到纯色矩形渲染
 			ms.drawBoxWithBorder(screen, mouseGridX, mouseGridY, GridSize, GridSize, ms.draggedBlockColor, color.RGBA{255, 255, 255, 255})
 		}
 	}
 	
-	// 绘制鼠标所在的网格位置指示器（半透明红色方框）
-	ms.drawBoxWithBorder(screen, mouseGridX, mouseGridY, GridSize, GridSize, color.RGBA{0, 0, 0, 0}, color.RGBA{255, 0, 0, 100})
+	// 更新HUD上的帧率和坐标信息
+	ms.hud.update(ms, mouseXFloat, mouseYFloat)
 	
-	// 绘制帧率和坐标信息
-	ms.drawDebugInfo(screen, mouseXFloat, mouseYFloat)
+	// 绘制下落中的方块
+	ms.drawFallingBlocks(screen)
 	
 	// Draw item drops
+	view := ms.camera.ViewRect()
 	for _, itemDrop := range ms.itemDrops {
-		// Apply camera offset
-		x := itemDrop.Position.X - ms.cameraX
-		y := itemDrop.Position.Y - ms.cameraY
-		
 		// Get current size (considering shrink effect)
 		width, height := itemDrop.GetCurrentSize()
 		
 		// Center the item based on its current size
-		x += (entities.ItemDropSize - width) / 2
-		y += (entities.ItemDropSize - height) / 2
+		x := itemDrop.Position.X + (entities.ItemDropSize - width) / 2
+		y := itemDrop.Position.Y + (entities.ItemDropSize - height) / 2
 		
 		// Only draw if on screen
-		if x >= -entities.ItemDropSize && x <= 800+entities.ItemDropSize && y >= -entities.ItemDropSize && y <= 600+entities.ItemDropSize {
-			if ms.blockSprites != nil {
-				// Use sprite for item drop based on item type
-				var blockSprite *ebiten.Image
-				
-				// 根据物品ID选择对应的精灵
-				switch itemDrop.GetItem().ID {
-				case "red_block":
-					blockSprite = ms.blockSprites["RedBlock"]
-				case "blue_block":
-					blockSprite = ms.blockSprites["BlueBlock"]
-				case "green_block":
-					blockSprite = ms.blockSprites["GreenBlock"]
-				case "dirt":
-					blockSprite = ms.blockSprites["dirt"]
-				case "wood":
-					blockSprite = ms.blockSprites["wood"]
-				default:
-					blockSprite = ms.blockSprites["SmallBlock"]
-				}
-				
-				if blockSprite != nil {
-					// Create a scaled version of the sprite
-					opts := &ebiten.DrawImageOptions{}
-					
-					// Calculate scale factors
-					scaleX := width / 32.0
-					scaleY := height / 32.0
-					
-					// Apply scaling
-					opts.GeoM.Scale(scaleX, scaleY)
-					opts.GeoM.Translate(x, y)
-					screen.DrawImage(blockSprite, opts)
-				} else {
-					// Fallback to colored rectangle
-					ebitenutil.DrawRect(screen, x, y, width, height, itemDrop.GetItem().Color)
-				}
+		if x >= view.X-entities.ItemDropSize && x <= view.X+view.W+entities.ItemDropSize && y >= view.Y-entities.ItemDropSize && y <= view.Y+view.H+entities.ItemDropSize {
+			// Use the sprite of the item's block definition
+			if blockSprite := ms.blockSprite(itemDrop.GetItem().ID); blockSprite != nil {
+				// Draw the sprite scaled to the current size
+				ms.drawWorldImage(screen, blockSprite, x, y, width/32.0, height/32.0)
 			} else {
 				// Fallback to colored rectangle
-				ebitenutil.DrawRect(screen, x, y, width, height, itemDrop.GetItem().Color)
+				ms.drawBoxWithBorder(screen, x, y, width, height, itemDrop.GetItem().Color, color.RGBA{0, 0, 0, 0})
 			}
 			
 			// Only draw border if item is not too small
 			if width > 4 && height > 4 {
 				// Draw a subtle border
-				ebitenutil.DrawRect(screen, x, y, width, 1, color.RGBA{0, 0, 0, 255}) // Top
-				ebitenutil.DrawRect(screen, x, y, 1, height, color.RGBA{0, 0, 0, 255}) // Left
-				ebitenutil.DrawRect(screen, x+width-1, y, 1, height, color.RGBA{0, 0, 0, 255}) // Right
-				ebitenutil.DrawRect(screen, x, y+height-1, width, 1, color.RGBA{0, 0, 0, 255}) // Bottom
+				ms.drawBoxWithBorder(screen, x, y, width, height, color.RGBA{0, 0, 0, 0}, color.RGBA{0, 0, 0, 255})
 			}
 		}
 	}
 	
+	// 按光照压暗方块、玩家和掉落物
+	ms.drawLight(screen)
+	
+	// 绘制鼠标所在的网格位置指示器（半透明红色方框）
+	ms.drawBoxWithBorder(screen, mouseGridX, mouseGridY, GridSize, GridSize, color.RGBA{0, 0, 0, 0}, color.RGBA{255, 0, 0, 100})
+	
+	// 编辑器模式下绘制选区和工具预览
+	if ms.editing {
+		ms.drawEditor(screen, worldCell(mouseXFloat, mouseYFloat))
+	}
+	
 	// 绘制物品栏
 	ms.inventorySystem.Draw(screen, ms.inventory)
 	
-	// Draw player health bar
-	ms.drawHealthBar(screen)
-	
-	// Draw FPS counter
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("FPS: %.2f", ms.fps), 10, 10)
+	// Draw FPS counter, player health bar and debug info
+	ms.hud.draw(screen)
 	
 }
 
 // drawCoordinateSystem 绘制坐标系网格
 func (ms *MainScene) drawCoordinateSystem(screen *ebiten.Image) {
-	// 获取屏幕边界（考虑摄像机偏移）
-	screenMinX := ms.cameraX
-	screenMaxX := ms.cameraX + 800  // 屏幕宽度
-	screenMinY := ms.cameraY
-	screenMaxY := ms.cameraY + 600  // 屏幕高度
+	// 获取屏幕内可见的世界范围（考虑摄像机位置和缩放）
+	view := ms.camera.ViewRect()
+	screenMinX := view.X
+	screenMaxX := view.X + view.W
+	screenMinY := view.Y
+	screenMaxY := view.Y + view.H
 	
 	// 计算需要绘制的网格线范围
 	startX := math.Floor(screenMinX/GridSize) * GridSize
@@ -819,8 +964,8 @@ func (ms *MainScene) drawCoordinateSystem(screen *ebiten.Image) {
 	
 	// 绘制垂直网格线
 	for x := startX; x <= endX; x += GridSize {
-		// 应用摄像机偏移
-		screenX := x - ms.cameraX
+		// 转换为屏幕坐标
+		screenX, _ := ms.camera.WorldToScreen(x, 0)
 		
 		// 绘制半透明的网格线
 		ebitenutil.DrawRect(screen, screenX, 0, 1, 600, color.RGBA{200, 200, 200, 50})
@@ -828,14 +973,14 @@ func (ms *MainScene) drawCoordinateSystem(screen *ebiten.Image) {
 		// 每128像素绘制坐标标签（避免过于密集）
 		if math.Mod(x, GridSize*4) == 0 {
 			// 绘制X坐标标签
-			ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%.0f", x), int(screenX)+2, 2)
+			fonts.Draw(screen, fmt.Sprintf("%.0f", x), screenX+2, 2, gridLabelStyle)
 		}
 	}
 	
 	// 绘制水平网格线
 	for y := startY; y <= endY; y += GridSize {
-		// 应用摄像机偏移
-		screenY := y - ms.cameraY
+		// 转换为屏幕坐标
+		_, screenY := ms.camera.WorldToScreen(0, y)
 		
 		// 绘制半透明的网格线
 		ebitenutil.DrawRect(screen, 0, screenY, 800, 1, color.RGBA{200, 200, 200, 50})
@@ -843,51 +988,39 @@ func (ms *MainScene) drawCoordinateSystem(screen *ebiten.Image) {
 		// 每128像素绘制坐标标签（避免过于密集）
 		if math.Mod(y, GridSize*4) == 0 {
 			// 绘制Y坐标标签
-			ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%.0f", y), 2, int(screenY)+2)
+			fonts.Draw(screen, fmt.Sprintf("%.0f", y), 2, screenY+2, gridLabelStyle)
 		}
 	}
 }
 
-// drawDebugInfo 绘制调试信息（帧率和坐标）
-func (ms *MainScene) drawDebugInfo(screen *ebiten.Image, mouseX, mouseY float64) {
-	// 绘制玩家坐标信息
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Player: (%.0f, %.0f)", 
-		ms.player.Position.X, ms.player.Position.Y), 10, 30)
-	
-	// 绘制鼠标坐标信息
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Mouse: (%.0f, %.0f)", 
-		mouseX, mouseY), 10, 50)
-	
-	// 绘制网格状态信息
-	gridStatus := "ON"
-	if !ms.showGrid {
-		gridStatus = "OFF"
-	}
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Grid: %s (Press F3 to toggle)", gridStatus), 10, 70)
-	
-	// 绘制当前选中的物品信息
-	if len(ms.itemTypes) > 0 {
-		// 显示所有物品类型，当前选中的加粗显示
-		itemsText := "Items: "
-		for i, itemType := range ms.itemTypes {
-			if i == ms.currentItemIndex {
-				itemsText += fmt.Sprintf("[%s] ", itemType) // 用方括号标记当前选中
-			} else {
-				itemsText += fmt.Sprintf("%s ", itemType)
-			}
-		}
-		ebitenutil.DebugPrintAt(screen, itemsText, 10, 90)
-		
-		// 显示切换提示
-		ebitenutil.DebugPrintAt(screen, "Use mouse wheel or 1-4 keys to switch items", 10, 110)
+// drawWorldImage draws img with its top left corner at a world position, scaled by scaleX and scaleY
+func (ms *MainScene) drawWorldImage(screen, img *ebiten.Image, x, y, scaleX, scaleY float64) {
+	opts := &ebiten.DrawImageOptions{}
+	opts.GeoM.Scale(scaleX, scaleY)
+	opts.GeoM.Translate(x, y)
+	opts.GeoM.Concat(ms.camera.GeoM())
+	screen.DrawImage(img, opts)
+}
+
+// drawPlayerFrame draws an animation frame of the player, standing on the bottom center of its box
+// and mirrored when the player faces left
+func (ms *MainScene) drawPlayerFrame(screen, frame *ebiten.Image) {
+	w, h := float64(frame.Bounds().Dx()), float64(frame.Bounds().Dy())
+	x := ms.player.Position.X + (ms.player.Box.Width-w)/2
+	y := ms.player.Position.Y + ms.player.Box.Height - h
+	if ms.player.Animation.FlipX {
+		ms.drawWorldImage(screen, frame, x+w, y, -1, 1)
+	} else {
+		ms.drawWorldImage(screen, frame, x, y, 1, 1)
 	}
 }
 
 // 添加绘制带高亮边框矩形的辅助方法
 func (ms *MainScene) drawBoxWithHighlight(screen *ebiten.Image, x, y, width, height float64, fillColor color.Color) {
-	// 应用摄像机偏移
-	x -= ms.cameraX
-	y -= ms.cameraY
+	// 转换为屏幕坐标，边框宽度不随缩放变化
+	x, y = ms.camera.WorldToScreen(x, y)
+	width *= ms.camera.Zoom()
+	height *= ms.camera.Zoom()
 	
 	// 绘制填充矩形
 	ebitenutil.DrawRect(screen, x, y, width, height, fillColor)
@@ -916,9 +1049,10 @@ func (ms *MainScene) drawBoxWithHighlight(screen *ebiten.Image, x, y, width, hei
 
 // 添加绘制带边框矩形的辅助方法
 func (ms *MainScene) drawBoxWithBorder(screen *ebiten.Image, x, y, width, height float64, fillColor, borderColor color.Color) {
-	// 应用摄像机偏移
-	x -= ms.cameraX
-	y -= ms.cameraY
+	// 转换为屏幕坐标，边框宽度不随缩放变化
+	x, y = ms.camera.WorldToScreen(x, y)
+	width *= ms.camera.Zoom()
+	height *= ms.camera.Zoom()
 
 	// 绘制填充矩形
 	ebitenutil.DrawRect(screen, x, y, width, height, fillColor)
@@ -953,218 +1087,52 @@ func (ms *MainScene) updateDraggedBlock(mouseX, mouseY float64) {
 
 // pickBlockAt 在指定位置拾取方块
 func (ms *MainScene) pickBlockAt(x, y float64) {
-	// 计算方块应该所在的网格位置（强制对齐到GridSize像素网格）
-	gridX := math.Floor(x/GridSize) * GridSize
-	gridY := math.Floor(y/GridSize) * GridSize
-	
 	// 查找该位置的方块
-	for _, block := range ms.blocks {
-		if block.Position.X == gridX && block.Position.Y == gridY {
-			// 根据方块颜色确定方块类型
-			blockColor := block.GetColor()
-			
-			// 查找匹配的物品类型
-			var targetItemID string
-			switch blockColor {
-			case color.RGBA{200, 50, 50, 255}:
-				targetItemID = "red_block"
-			case color.RGBA{50, 50, 200, 255}:
-				targetItemID = "blue_block"
-			case color.RGBA{50, 200, 50, 255}:
-				targetItemID = "green_block"
-			default:
-				targetItemID = "small_block"
-			}
-			
-			// 查找匹配的物品槽位并选中它
-			for i, slot := range ms.inventory.Slots {
-				if slot.Item != nil && slot.Item.ID == targetItemID {
-					ms.inventory.SelectSlot(i)
-					return
-				}
-			}
-			return
-		}
+	block := ms.blockAt(worldCell(x, y))
+	if block == nil {
+		return
 	}
-}
-
-// breakBlock breaks a block at the given position and creates an item drop
-func (ms *MainScene) breakBlock(x, y float64, blockType string) {
-	// Remove the block from the blocks slice
-	for i, block := range ms.blocks {
-		if block.Position.X == x && block.Position.Y == y {
-			// Remove the block
-			ms.blocks = append(ms.blocks[:i], ms.blocks[i+1:]...)
-			
-			// Create an item based on the block type
-			var item components.Item
-			switch blockType {
-			case "RedBlock":
-				item = components.Item{
-					ID:       "red_block",
-					Name:     "Red Block",
-					Count:    1,
-					MaxStack: 64,
-					Color:    color.RGBA{255, 0, 0, 255},
-				}
-			case "BlueBlock":
-				item = components.Item{
-					ID:       "blue_block",
-					Name:     "Blue Block",
-					Count:    1,
-					MaxStack: 64,
-					Color:    color.RGBA{0, 0, 255, 255},
-				}
-			case "GreenBlock":
-				item = components.Item{
-					ID:       "green_block",
-					Name:     "Green Block",
-					Count:    1,
-					MaxStack: 64,
-					Color:    color.RGBA{0, 255, 0, 255},
-				}
-			case "dirt":
-				item = components.Item{
-					ID:       "dirt",
-					Name:     "Dirt Block",
-					Count:    1,
-					MaxStack: 64,
-					Color:    color.RGBA{139, 69, 19, 255},
-				}
-			case "wood":
-				item = components.Item{
-					ID:       "wood",
-					Name:     "Wood Block",
-					Count:    1,
-					MaxStack: 64,
-					Color:    color.RGBA{160, 120, 40, 255},
-				}
-			default:
-				item = components.Item{
-					ID:       "small_block",
-					Name:     "Small Block",
-					Count:    1,
-					MaxStack: 64,
-					Color:    color.RGBA{128, 128, 128, 255},
-				}
-			}
-			
-			// Create an item drop at the block's position with random initial velocity
-			itemDrop := entities.NewItemDrop(x, y, &item)
-			
-			// Add some random initial velocity
-			itemDrop.Velocity.X = (0.5 - rand.Float64()) * 100 // Random X velocity
-			itemDrop.Velocity.Y = -100 - rand.Float64()*50     // Upward velocity with some randomness
-			
-			// Add the item drop to the scene
-			ms.itemDrops = append(ms.itemDrops, itemDrop)
-			
-			break
+	
+	// 按方块定义确定对应的物品
+	targetItemID := ms.itemForBlock(block).ID
+	
+	// 查找匹配的物品槽位并选中它
+	for i, slot := range ms.inventory.Slots {
+		if slot.Item != nil && slot.Item.ID == targetItemID {
+			ms.inventory.SelectSlot(i)
+			return
 		}
 	}
 }
 
 // initializeInventory adds some initial items to the inventory
 func (ms *MainScene) initializeInventory() {
-	// 添加一些示例物品到物品栏
-	items := []components.Item{
-		{
-			ID:       "stone",
-			Name:     "Stone",
-			Count:    64,
-			MaxStack: 64,
-			Color:    color.RGBA{128, 128, 128, 255},
-		},
-		{
-			ID:       "dirt",
-			Name:     "Dirt",
-			Count:    32,
-			MaxStack: 64,
-			Color:    color.RGBA{100, 50, 0, 255},
-		},
-		{
-			ID:       "wood",
-			Name:     "Wood",
-			Count:    16,
-			MaxStack: 64,
-			Color:    color.RGBA{100, 70, 30, 255},
-		},
-		{
-			ID:       "small_block",
-			Name:     "Small Block",
-			Count:    10,
-			MaxStack: 64,
-			Color:    color.RGBA{200, 200, 50, 255},
-		},
-		{
-			ID:       "red_block",
-			Name:     "Red Block",
-			Count:    10,
-			MaxStack: 64,
-			Color:    color.RGBA{200, 50, 50, 255},
-		},
-		{
-			ID:       "blue_block",
-			Name:     "Blue Block",
-			Count:    10,
-			MaxStack: 64,
-			Color:    color.RGBA{50, 50, 200, 255},
-		},
-		{
-			ID:       "green_block",
-			Name:     "Green Block",
-			Count:    10,
-			MaxStack: 64,
-			Color:    color.RGBA{50, 200, 50, 255},
-		},
+	// 添加一些示例物品到物品栏，名称和颜色来自方块定义
+	items := []struct {
+		id    string
+		count int
+	}{
+		{"stone", 64},
+		{"dirt", 32},
+		{"wood", 16},
+		{"small_block", 10},
+		{"red_block", 10},
+		{"blue_block", 10},
+		{"green_block", 10},
+		{"torch", 16},
+		{"grass", 32},
+		{"sapling", 8},
+		{"wheat", 16},
+		{"sand", 32},
+		{"gravel", 32},
+		{"water", 16},
+		{"lava", 16},
 	}
 	
 	// 添加物品到物品栏
-	for _, item := range items {
-		// 创建物品副本以避免引用问题
-		itemCopy := item
-		ms.inventory.AddItem(itemCopy)
+	for _, entry := range items {
+		item := ms.itemFor(entry.id, color.RGBA{128, 128, 128, 255})
+		item.Count = entry.count
+		ms.inventory.AddItem(*item)
 	}
 }
-
-// drawHealthBar draws the player's health bar on the screen
-func (ms *MainScene) drawHealthBar(screen *ebiten.Image) {
-	// Health bar position and size
-	barX := 10
-	barY := 30
-	barWidth := 200
-	barHeight := 20
-	
-	// Calculate health percentage
-	healthPercentage := ms.player.Health.GetHealthPercentage()
-	
-	// Draw background (red)
-	ebitenutil.DrawRect(screen, float64(barX), float64(barY), float64(barWidth), float64(barHeight), color.RGBA{100, 0, 0, 200})
-	
-	// Draw health (green to yellow based on health percentage)
-	healthWidth := int(float64(barWidth) * healthPercentage / 100)
-	var healthColor color.RGBA
-	if healthPercentage > 50 {
-		// Green to yellow transition (high health)
-		greenValue := uint8(255)
-		redValue := uint8(255 * (100 - healthPercentage) / 50)
-		healthColor = color.RGBA{redValue, greenValue, 0, 255}
-	} else {
-		// Yellow to red transition (low health)
-		redValue := uint8(255)
-		greenValue := uint8(255 * healthPercentage / 50)
-		healthColor = color.RGBA{redValue, greenValue, 0, 255}
-	}
-	
-	ebitenutil.DrawRect(screen, float64(barX), float64(barY), float64(healthWidth), float64(barHeight), healthColor)
-	
-	// Draw border
-	ebitenutil.DrawRect(screen, float64(barX), float64(barY), float64(barWidth), 1, color.RGBA{255, 255, 255, 255}) // Top
-	ebitenutil.DrawRect(screen, float64(barX), float64(barY), 1, float64(barHeight), color.RGBA{255, 255, 255, 255}) // Left
-	ebitenutil.DrawRect(screen, float64(barX+barWidth-1), float64(barY), 1, float64(barHeight), color.RGBA{255, 255, 255, 255}) // Right
-	ebitenutil.DrawRect(screen, float64(barX), float64(barY+barHeight-1), float64(barWidth), 1, color.RGBA{255, 255, 255, 255}) // Bottom
-	
-	// Draw health text
-	healthText := fmt.Sprintf("Health: %d/%d (%.0f%%)", ms.player.Health.Current, ms.player.Health.Max, healthPercentage)
-	ebitenutil.DebugPrintAt(screen, healthText, barX, barY+barHeight+5)
-}
@@ -1,7 +1,13 @@
 package scenes
 
 import (
+	"math"
 	"testing"
+
+	"github.com/wubinrui111/2d-game/internal/editor"
+	"github.com/wubinrui111/2d-game/internal/grid"
+	"github.com/wubinrui111/2d-game/internal/tiled"
+	"github.com/wubinrui111/2d-game/internal/worldgen"
 )
 
 func TestMainSceneCreation(t *testing.T) {
@@ -58,13 +64,9 @@ func TestMainSceneCreation(t *testing.T) {
 		t.Error("Expected input manager to be created")
 	}
 	
-	// Check that camera is initialized
-	if scene.cameraX != 320-400 { // player starts at x=320, screen width is 800
-		t.Errorf("Expected cameraX to be initialized, got %f", scene.cameraX)
-	}
-	
-	if scene.cameraY != 160-300 { // player starts at y=160, screen height is 600
-		t.Errorf("Expected cameraY to be initialized, got %f", scene.cameraY)
+	// Check that camera is centered on the player (player starts at (320, 160) and is 32x32)
+	if scene.camera.X != 336 || scene.camera.Y != 176 {
+		t.Errorf("Expected camera to be centered on the player, got (%f, %f)", scene.camera.X, scene.camera.Y)
 	}
 	
 	// 检查物品系统是否初始化
@@ -81,10 +83,6 @@ func TestMainSceneUpdate(t *testing.T) {
 	// Test updating the main scene
 	scene := NewMainScene()
 	
-	// Store initial camera positions
-	initialCameraX := scene.cameraX
-	initialCameraY := scene.cameraY
-	
 	// Update the scene
 	err := scene.Update()
 	
@@ -97,18 +95,13 @@ func TestMainSceneUpdate(t *testing.T) {
 	// depends on many factors including input, gravity, and collisions.
 	// The important thing is that the Update method runs without error.
 	
-	// Check that camera has been updated (it should move toward the target)
-	// Since the camera uses smooth following, it might not have reached the target yet
-	// but it should have moved from its initial position
-	targetX := scene.player.Position.X - 400
-	targetY := scene.player.Position.Y - 300
-	
-	// Either the camera moved or it was already at the target
-	cameraMoved := (scene.cameraX != initialCameraX) || (scene.cameraY != initialCameraY)
-	cameraAtTarget := (scene.cameraX == targetX) && (scene.cameraY == targetY)
-	
-	if !cameraMoved && !cameraAtTarget {
-		t.Error("Expected camera to either move or be at target position")
+	// Check that the camera keeps the player near the center of the screen.
+	// The camera only moves once the player leaves the dead-zone, so it may not have moved yet.
+	centerX, centerY := scene.playerCenter()
+	maxOffsetX := scene.camera.DeadZoneWidth/2 + scene.camera.MaxLookAheadX
+	maxOffsetY := scene.camera.DeadZoneHeight/2 + scene.camera.MaxLookAheadY
+	if math.Abs(scene.camera.X-centerX) > maxOffsetX || math.Abs(scene.camera.Y-centerY) > maxOffsetY {
+		t.Errorf("Expected camera near the player, camera at (%f, %f), player at (%f, %f)", scene.camera.X, scene.camera.Y, centerX, centerY)
 	}
 }
 
@@ -168,6 +161,17 @@ func TestPlaceAndRemoveBlocks(t *testing.T) {
 		t.Errorf("Expected block count to return to previous value, got %d", len(scene.blocks))
 	}
 	
+	// 渲染网格与方块列表保持一致
+	if scene.tiles.Len() != len(scene.blocks) {
+		t.Errorf("Expected %d tiles to render, got %d", len(scene.blocks), scene.tiles.Len())
+	}
+	if _, exists := scene.tiles.At(3, 3); !exists {
+		t.Error("Expected the placed block at (96, 96) to be in the render grid")
+	}
+	if _, exists := scene.tiles.At(3, 18); exists {
+		t.Error("Expected the removed block at (96, 576) to leave the render grid")
+	}
+	
 	// 检查所有方块是否仍然对齐到网格
 	for _, block := range scene.blocks {
 		if block.Position.X != float64(int(block.Position.X)/32*32) || 
@@ -204,4 +208,197 @@ func TestItemSwitching(t *testing.T) {
 	if scene.currentItemIndex != 0 {
 		t.Errorf("Expected item index to wrap around to 0, got %d", scene.currentItemIndex)
 	}
-}
\ No newline at end of file
+}
+
+func TestTerrainGeneratedAroundSpawn(t *testing.T) {
+	scene := NewMainSceneWithSeed(1)
+	if scene.generator == nil {
+		t.Fatal("Expected the start level to turn on terrain generation")
+	}
+	if err := scene.Update(); err != nil {
+		t.Fatalf("Expected no error from Update, got %v", err)
+	}
+
+	// 出生点两侧的区块列都已生成，地面方块在生成器给出的位置
+	centerX, _ := scene.playerCenter()
+	cx := int(math.Floor(centerX / GridSize / worldgen.ChunkSize))
+	for x := cx - generateRadius; x <= cx+generateRadius; x++ {
+		column, exists := scene.columns[x]
+		if !exists {
+			t.Errorf("Expected chunk column %d near the spawn to be generated", x)
+			continue
+		}
+		for i := 0; i < worldgen.ChunkSize; i++ {
+			wx := x*worldgen.ChunkSize + i
+			y, _ := scene.generator.Surface(wx)
+			if scene.blockAt(grid.Cell{X: wx, Y: y}) == nil || scene.blockAt(grid.Cell{X: wx, Y: worldgen.Bottom}) == nil {
+				t.Errorf("Expected terrain in column %d of chunk column %d", wx, column.X)
+			}
+		}
+	}
+	if _, exists := scene.columns[cx+generateRadius+1]; exists {
+		t.Error("Expected chunk columns far from the player not to be generated yet")
+	}
+}
+
+func TestSandFallsIntoWaterSource(t *testing.T) {
+	scene := NewMainSceneWithSeed(1)
+
+	// 关卡外的一个水坑：石头地面上的水源，水向两侧流开
+	for x := -103; x <= -97; x++ {
+		scene.SetBlock(x, 10, "stone")
+	}
+	scene.SetBlock(-100, 9, "water")
+	for i := 0; i < 200; i++ {
+		scene.fluids.Update()
+	}
+	if scene.Block(-99, 9) != "water" {
+		t.Fatal("Expected the water to flow next to its source")
+	}
+
+	scene.SetBlock(-100, 5, "sand")
+	scene.checkFalling(grid.Cell{X: -100, Y: 5})
+	drops := len(scene.itemDrops)
+	for i := 0; i < 600 && len(scene.fallingBlocks) > 0; i++ {
+		scene.updateFallingBlocks()
+	}
+	if scene.Block(-100, 9) != "sand" || len(scene.itemDrops) != drops {
+		t.Fatalf("Expected the sand to replace the water source, got %q and %d new item drops", scene.Block(-100, 9), len(scene.itemDrops)-drops)
+	}
+	if _, exists := scene.fluids.Fluid(-100, 9); exists {
+		t.Error("Expected the water source to be gone")
+	}
+
+	// 没有水源后流出的水退去
+	for i := 0; i < 200; i++ {
+		scene.fluids.Update()
+	}
+	if scene.Block(-99, 9) != "" || scene.Block(-101, 9) != "" {
+		t.Errorf("Expected the flowing water to dry up, got %q and %q", scene.Block(-101, 9), scene.Block(-99, 9))
+	}
+}
+
+func TestSandFallsWhenItsSupportGoes(t *testing.T) {
+	scene := NewMainSceneWithSeed(1)
+
+	// 剪切掉支撑的方块后，上面堆着的沙子一起下落
+	scene.SetBlock(-100, 10, "stone")
+	scene.SetBlock(-100, 9, "sand")
+	scene.SetBlock(-100, 8, "sand")
+	scene.editor.Cut(editor.Rect{X: -100, Y: 10, W: 1, H: 1})
+	if scene.Block(-100, 9) != "" || scene.Block(-100, 8) != "" || len(scene.fallingBlocks) != 2 {
+		t.Fatalf("Expected both sand blocks to fall, got %q, %q and %d falling blocks", scene.Block(-100, 8), scene.Block(-100, 9), len(scene.fallingBlocks))
+	}
+
+	// 换成流体也托不住沙子
+	scene.SetBlock(-90, 10, "stone")
+	scene.SetBlock(-90, 9, "sand")
+	scene.SetBlock(-90, 10, "water")
+	if scene.Block(-90, 9) != "" || len(scene.fallingBlocks) != 3 {
+		t.Errorf("Expected the sand on the water to fall, got %q", scene.Block(-90, 9))
+	}
+}
+
+func TestBlockEntitiesCopyAndPaste(t *testing.T) {
+	scene := NewMainSceneWithSeed(1)
+
+	scene.SetBlock(-50, 0, "wood")
+	scene.SetBlockEntity(-50, 0, map[string]string{"items": "3"})
+	scene.SetBlockEntity(-50, 1, map[string]string{"items": "1"})
+	if scene.BlockEntity(-50, 1) != nil {
+		t.Error("Expected no block entity data in a cell without a block")
+	}
+
+	// 复制和粘贴带上方块实体数据
+	scene.editor.Copy(editor.Rect{X: -50, Y: 0, W: 1, H: 1})
+	scene.editor.Paste(grid.Cell{X: -40, Y: 0})
+	if data := scene.BlockEntity(-40, 0); scene.Block(-40, 0) != "wood" || data["items"] != "3" {
+		t.Errorf("Expected the pasted block to keep its data, got %q with %v", scene.Block(-40, 0), data)
+	}
+
+	// 方块被替换或移除时数据一起消失
+	scene.SetBlock(-40, 0, "stone")
+	scene.SetBlock(-50, 0, "")
+	if scene.BlockEntity(-40, 0) != nil || scene.BlockEntity(-50, 0) != nil {
+		t.Error("Expected the data to go with its block")
+	}
+}
+
+func TestBlockEntitiesExportAndLoad(t *testing.T) {
+	scene := NewMainSceneWithSeed(1)
+	scene.SetBlock(-50, 0, "wood")
+	scene.SetBlockEntity(-50, 0, map[string]string{"items": "3", "name": "chest"})
+
+	// 导出为 Tiled 地图再导入，数据作为方块实体对象保存
+	data, err := tiled.EncodeTMX(tiled.Export(scene.Level(), int(GridSize), int(GridSize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := tiled.DecodeTMX(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	level, err := tiled.Import(m, scene.blockDefs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewMainSceneWithSeed(1)
+	for _, b := range level.Blocks {
+		loaded.SetBlock(b.X, b.Y, b.ID)
+	}
+	for _, e := range level.Entities {
+		if e.Class == blockEntityClass {
+			if err := loaded.addEntity(e); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if data := loaded.BlockEntity(-50, 0); data["items"] != "3" || data["name"] != "chest" {
+		t.Errorf("Expected the block entity data to survive the export, got %v", data)
+	}
+}
+
+func TestLevelSavesFluidSources(t *testing.T) {
+	scene := NewMainSceneWithSeed(1)
+	for x := -103; x <= -97; x++ {
+		scene.SetBlock(x, 10, "stone")
+	}
+	scene.SetBlock(-100, 9, "water")
+	for i := 0; i < 200; i++ {
+		scene.fluids.Update()
+	}
+	if scene.Block(-99, 9) != "water" {
+		t.Fatal("Expected the water to flow next to its source")
+	}
+
+	// 流出的水不保存，否则载入后变成水源
+	blocks := make(map[grid.Cell]string)
+	for _, b := range scene.Level().Blocks {
+		blocks[grid.Cell{X: b.X, Y: b.Y}] = b.ID
+	}
+	if blocks[grid.Cell{X: -100, Y: 9}] != "water" {
+		t.Error("Expected the water source to be saved")
+	}
+	if id, exists := blocks[grid.Cell{X: -99, Y: 9}]; exists {
+		t.Errorf("Expected the flowing water not to be saved, got %q", id)
+	}
+}
+
+func TestSolidBlocksNear(t *testing.T) {
+	scene := NewMainSceneWithSeed(1)
+	scene.SetBlock(-100, 0, "stone")
+	scene.SetBlock(-99, 0, "water")
+	scene.SetBlock(-90, 0, "stone")
+
+	// 只有碰撞箱附近的固体方块，流体和远处的方块不算
+	box := scene.blockAt(grid.Cell{X: -100, Y: 0}).Box
+	box.X += GridSize / 2
+	near := scene.solidBlocksNear(box, 0)
+	if len(near) != 1 || near[0].GetBox().X != -100*GridSize {
+		t.Errorf("Expected only the stone next to the box, got %d blocks", len(near))
+	}
+	if far := scene.solidBlocksNear(box, 10*GridSize); len(far) != 2 {
+		t.Errorf("Expected both stones within the margin, got %d blocks", len(far))
+	}
+}
@@ -0,0 +1,79 @@
+package scenes
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/wubinrui111/2d-game/internal/fonts"
+	"github.com/wubinrui111/2d-game/internal/input"
+	"github.com/wubinrui111/2d-game/internal/ui"
+)
+
+const (
+	// Screen size used to center menus
+	screenWidth  = 800
+	screenHeight = 600
+
+	// Size of menu buttons
+	menuButtonWidth  = 220
+	menuButtonHeight = 36
+)
+
+var (
+	// menuTitleStyle is used for the title above a menu
+	menuTitleStyle = fonts.Style{Size: 28, Color: color.White, OutlineColor: color.Black, OutlineWidth: 2, Align: fonts.AlignCenter}
+
+	// menuButtonStyle is used for menu button captions
+	menuButtonStyle = fonts.Style{Size: 16, Color: color.White}
+)
+
+// menu is a vertical list of buttons below a title, centered on the screen
+type menu struct {
+	ctx   *ui.Context
+	root  *ui.Panel
+	title *ui.Label
+}
+
+// newMenu creates an empty menu with the given title
+func newMenu(title string) *menu {
+	m := &menu{
+		ctx:   ui.NewContext(),
+		title: ui.NewLabel(title, menuTitleStyle),
+	}
+	m.root = ui.NewPanel(ui.Vertical)
+	m.root.Padding = 20
+	m.root.Spacing = 12
+	m.root.Align = ui.CrossCenter
+	m.root.PassThrough = true
+	m.root.Add(m.title)
+	return m
+}
+
+// addButton appends a button that calls onClick
+func (m *menu) addButton(text string, onClick func()) *ui.Button {
+	button := ui.NewButton(text, onClick)
+	button.Style = menuButtonStyle
+	button.Width = menuButtonWidth
+	button.Height = menuButtonHeight
+	m.root.Add(button)
+	return button
+}
+
+// layout centers the menu on the screen
+func (m *menu) layout() {
+	w, h := m.root.PreferredSize()
+	m.root.SetBounds(ui.Rect{X: (screenWidth - w) / 2, Y: (screenHeight - h) / 2, W: w, H: h})
+}
+
+// update handles input for the menu
+func (m *menu) update() {
+	m.layout()
+	m.ctx.Update(m.root, input.UIInput())
+}
+
+// draw renders the menu
+func (m *menu) draw(screen *ebiten.Image) {
+	m.layout()
+	ui.Layout(m.root)
+	ui.Draw(screen, m.root)
+}
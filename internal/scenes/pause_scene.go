@@ -0,0 +1,80 @@
+package scenes
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/wubinrui111/2d-game/internal/i18n"
+	"github.com/wubinrui111/2d-game/internal/input"
+)
+
+// PauseScene is the Esc menu drawn over a frozen world
+type PauseScene struct {
+	nav  Navigator
+	menu *menu
+}
+
+// NewPauseScene creates the pause menu
+func NewPauseScene() *PauseScene {
+	ps := &PauseScene{}
+	ps.build()
+	return ps
+}
+
+// build creates the menu; it is rebuilt when the scene resumes in case the language changed
+func (ps *PauseScene) build() {
+	ps.menu = newMenu(i18n.T("pause.title"))
+	ps.menu.addButton(i18n.T("pause.resume"), ps.resume)
+	ps.menu.addButton(i18n.T("menu.settings"), ps.openSettings)
+	ps.menu.addButton(i18n.T("pause.quit_to_title"), ps.quitToTitle)
+}
+
+// OnResume rebuilds the menu after returning from the settings
+func (ps *PauseScene) OnResume() {
+	ps.build()
+}
+
+// SetNavigator implements NavigatorSetter
+func (ps *PauseScene) SetNavigator(nav Navigator) {
+	ps.nav = nav
+}
+
+// IsOverlay keeps the world visible below the menu
+func (ps *PauseScene) IsOverlay() bool {
+	return true
+}
+
+func (ps *PauseScene) resume() {
+	if ps.nav != nil {
+		ps.nav.Pop()
+	}
+}
+
+func (ps *PauseScene) openSettings() {
+	if ps.nav != nil {
+		ps.nav.Push(NewSettingsScene())
+	}
+}
+
+func (ps *PauseScene) quitToTitle() {
+	if ps.nav != nil {
+		ps.nav.Reset(NewTitleScene())
+	}
+}
+
+// Update handles menu input; Esc resumes the game
+func (ps *PauseScene) Update() error {
+	if input.IsActionJustPressed(input.ActionPause) {
+		ps.resume()
+		return nil
+	}
+	ps.menu.update()
+	return nil
+}
+
+// Draw dims the world and renders the menu
+func (ps *PauseScene) Draw(screen *ebiten.Image) {
+	vector.DrawFilledRect(screen, 0, 0, screenWidth, screenHeight, color.RGBA{0, 0, 0, 150}, false)
+	ps.menu.draw(screen)
+}
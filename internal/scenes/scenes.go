@@ -9,3 +9,52 @@ type Scene interface {
 	Update() error
 	Draw(*ebiten.Image)
 }
+
+// Navigator changes the active scene. It is implemented by managers.SceneManager;
+// changes requested during Update take effect once the current frame's update is done.
+type Navigator interface {
+	// Push shows a scene on top of the current one and pauses it
+	Push(scene Scene)
+	// Pop closes the top scene and resumes the one below
+	Pop()
+	// Replace swaps the top scene for another with a fade transition
+	Replace(scene Scene)
+	// Reset closes every scene and shows the given one with a fade transition
+	Reset(scene Scene)
+	// Quit ends the game
+	Quit()
+}
+
+// 以下为可选的生命周期接口，场景按需实现
+
+// Enterer is implemented by scenes that need to set up when they become part of the stack
+type Enterer interface {
+	OnEnter()
+}
+
+// Exiter is implemented by scenes that need to clean up when they leave the stack
+type Exiter interface {
+	OnExit()
+}
+
+// Pauser is implemented by scenes that want to know when another scene is pushed on top of them
+type Pauser interface {
+	OnPause()
+}
+
+// Resumer is implemented by scenes that want to know when they are on top again
+type Resumer interface {
+	OnResume()
+}
+
+// NavigatorSetter is implemented by scenes that change scenes themselves.
+// The scene manager calls SetNavigator before OnEnter.
+type NavigatorSetter interface {
+	SetNavigator(nav Navigator)
+}
+
+// Overlay is implemented by scenes that only cover part of the screen.
+// The scenes below an overlay are still drawn, but only the top scene is updated.
+type Overlay interface {
+	IsOverlay() bool
+}
@@ -0,0 +1,359 @@
+package scenes
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/wubinrui111/2d-game/internal/fonts"
+	"github.com/wubinrui111/2d-game/internal/i18n"
+	"github.com/wubinrui111/2d-game/internal/input"
+	"github.com/wubinrui111/2d-game/internal/settings"
+	"github.com/wubinrui111/2d-game/internal/ui"
+)
+
+const (
+	// Size of the widgets in a settings row
+	settingsLabelWidth  = 200
+	settingsValueWidth  = 180
+	settingsSmallButton = 36
+	settingsRowHeight   = 30
+
+	// Steps used by the -/+ buttons
+	volumeStep   = 0.1
+	guiScaleStep = 0.25
+)
+
+var (
+	// settingsLabelStyle is used for the names of the settings
+	settingsLabelStyle = fonts.Style{Size: 16, Color: color.White}
+
+	// settingsHeadingStyle is used for section headings
+	settingsHeadingStyle = fonts.Style{Size: 18, Color: color.RGBA{255, 220, 120, 255}}
+)
+
+// ApplySettings applies preferences to the running game: window, vsync, language, text scale and key bindings
+func ApplySettings(s settings.Settings) error {
+	ebiten.SetWindowSize(s.WindowWidth, s.WindowHeight)
+	ebiten.SetFullscreen(s.Fullscreen)
+	ebiten.SetVsyncEnabled(s.VSync)
+	fonts.Default().SetScale(s.GUIScale)
+
+	var errs []error
+	if err := i18n.SetLanguage(s.Language); err != nil {
+		errs = append(errs, err)
+	}
+	if err := input.SetBindings(s.Bindings); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// SettingsScene lets the player change their preferences. Changes are applied
+// immediately and saved to the preferences file when the scene is closed.
+type SettingsScene struct {
+	nav    Navigator
+	store  *settings.Store
+	ctx    *ui.Context
+	root   *ui.Panel
+	scroll *ui.ScrollView
+
+	// bindingAction is the action waiting for a key press, or ""
+	bindingAction string
+
+	// dirty is set when the menu texts need rebuilding (e.g. after a language change)
+	dirty bool
+}
+
+// NewSettingsScene creates the settings menu for the game's preferences
+func NewSettingsScene() *SettingsScene {
+	ss := &SettingsScene{
+		store: settings.Default(),
+		ctx:   ui.NewContext(),
+	}
+	ss.build()
+	return ss
+}
+
+// SetNavigator implements NavigatorSetter
+func (ss *SettingsScene) SetNavigator(nav Navigator) {
+	ss.nav = nav
+}
+
+// IsOverlay keeps the previous scene visible behind the menu
+func (ss *SettingsScene) IsOverlay() bool {
+	return true
+}
+
+// OnExit saves the preferences
+func (ss *SettingsScene) OnExit() {
+	if err := ss.store.Save(); err != nil {
+		fmt.Printf("Failed to save settings: %v\n", err)
+	}
+}
+
+// change applies an edit to a copy of the current preferences and uses it if it is valid
+func (ss *SettingsScene) change(edit func(s *settings.Settings)) {
+	s := ss.store.Current()
+	edit(&s)
+	if err := ss.store.Set(s); err != nil {
+		fmt.Printf("Invalid settings: %v\n", err)
+		return
+	}
+	if err := ApplySettings(s); err != nil {
+		fmt.Printf("Failed to apply settings: %v\n", err)
+	}
+	ss.dirty = true
+}
+
+// resetToDefaults restores and applies the default preferences
+func (ss *SettingsScene) resetToDefaults() {
+	ss.store.Reset()
+	if err := ApplySettings(ss.store.Current()); err != nil {
+		fmt.Printf("Failed to apply settings: %v\n", err)
+	}
+	ss.bindingAction = ""
+	ss.dirty = true
+}
+
+func (ss *SettingsScene) back() {
+	if ss.nav != nil {
+		ss.nav.Pop()
+	}
+}
+
+// build creates the widgets for the current preferences
+func (ss *SettingsScene) build() {
+	s := ss.store.Current()
+
+	rows := ui.NewPanel(ui.Vertical)
+	rows.Spacing = 6
+	rows.PassThrough = true
+
+	// 窗口大小在预设之间循环切换
+	rows.Add(settingsRow(i18n.T("settings.window_size"),
+		settingsButton(fmt.Sprintf("%d x %d", s.WindowWidth, s.WindowHeight), settingsValueWidth, func() {
+			ss.change(func(s *settings.Settings) {
+				s.WindowWidth, s.WindowHeight = nextWindowSize(s.WindowWidth, s.WindowHeight)
+			})
+		})))
+	rows.Add(settingsRow(i18n.T("settings.fullscreen"),
+		settingsButton(onOff(s.Fullscreen), settingsValueWidth, func() {
+			ss.change(func(s *settings.Settings) { s.Fullscreen = !s.Fullscreen })
+		})))
+	rows.Add(settingsRow(i18n.T("settings.vsync"),
+		settingsButton(onOff(s.VSync), settingsValueWidth, func() {
+			ss.change(func(s *settings.Settings) { s.VSync = !s.VSync })
+		})))
+	rows.Add(settingsRow(i18n.T("settings.volume"), stepper(fmt.Sprintf("%.0f%%", s.Volume*100),
+		func() { ss.change(func(s *settings.Settings) { s.Volume = clampStep(s.Volume, -volumeStep, 0, 1) }) },
+		func() { ss.change(func(s *settings.Settings) { s.Volume = clampStep(s.Volume, volumeStep, 0, 1) }) })...))
+	rows.Add(settingsRow(i18n.T("settings.language"),
+		settingsButton(i18n.T("language."+s.Language), settingsValueWidth, func() {
+			ss.change(func(s *settings.Settings) { s.Language = nextLanguage(s.Language) })
+		})))
+	rows.Add(settingsRow(i18n.T("settings.gui_scale"), stepper(fmt.Sprintf("%.2gx", s.GUIScale),
+		func() {
+			ss.change(func(s *settings.Settings) {
+				s.GUIScale = clampStep(s.GUIScale, -guiScaleStep, settings.MinGUIScale, settings.MaxGUIScale)
+			})
+		},
+		func() {
+			ss.change(func(s *settings.Settings) {
+				s.GUIScale = clampStep(s.GUIScale, guiScaleStep, settings.MinGUIScale, settings.MaxGUIScale)
+			})
+		})...))
+
+	// 按键绑定：点击后按下新的按键、鼠标键或手柄键
+	rows.Add(ui.NewLabel(i18n.T("settings.controls"), settingsHeadingStyle))
+	for _, action := range settings.Actions() {
+		text := strings.Join(s.Bindings[action], ", ")
+		if text == "" {
+			text = "-"
+		}
+		if ss.bindingAction == action {
+			text = i18n.T("settings.press_key")
+		}
+		rows.Add(settingsRow(i18n.T("action."+action), settingsButton(text, settingsValueWidth, func() {
+			ss.bindingAction = action
+			ss.dirty = true
+		})))
+	}
+
+	// 重建界面时保留滚动位置
+	scroll := ui.NewScrollView(rows, settingsLabelWidth+settingsValueWidth+40, 400)
+	if ss.scroll != nil {
+		scroll.ScrollY = ss.scroll.ScrollY
+	}
+	ss.scroll = scroll
+
+	buttons := ui.NewPanel(ui.Horizontal)
+	buttons.Spacing = 12
+	buttons.PassThrough = true
+	buttons.Add(
+		settingsButton(i18n.T("settings.reset"), menuButtonWidth, ss.resetToDefaults),
+		settingsButton(i18n.T("settings.back"), menuButtonWidth, ss.back),
+	)
+
+	ss.root = ui.NewPanel(ui.Vertical)
+	ss.root.Padding = 20
+	ss.root.Spacing = 12
+	ss.root.Align = ui.CrossCenter
+	ss.root.Background = color.RGBA{30, 30, 45, 230}
+	ss.root.Border = color.RGBA{200, 200, 220, 255}
+	ss.root.Add(ui.NewLabel(i18n.T("settings.title"), menuTitleStyle), scroll, buttons)
+	ss.dirty = false
+}
+
+// settingsRow creates a row with a name on the left and controls on the right
+func settingsRow(name string, controls ...ui.Widget) *ui.Panel {
+	label := ui.NewPanel(ui.Horizontal)
+	label.Width = settingsLabelWidth
+	label.Height = settingsRowHeight
+	label.Align = ui.CrossCenter
+	label.PassThrough = true
+	label.Add(ui.NewLabel(name, settingsLabelStyle))
+
+	row := ui.NewPanel(ui.Horizontal)
+	row.Spacing = 4
+	row.Align = ui.CrossCenter
+	row.PassThrough = true
+	row.Add(label)
+	row.Add(controls...)
+	return row
+}
+
+// settingsButton creates a button of the given width
+func settingsButton(text string, width float64, onClick func()) *ui.Button {
+	button := ui.NewButton(text, onClick)
+	button.Style = menuButtonStyle
+	button.Width = width
+	button.Height = settingsRowHeight
+	return button
+}
+
+// stepper creates "-", value and "+" controls
+func stepper(value string, decrease, increase func()) []ui.Widget {
+	valueLabel := ui.NewPanel(ui.Horizontal)
+	valueLabel.Width = settingsValueWidth - 2*(settingsSmallButton+4)
+	valueLabel.Height = settingsRowHeight
+	valueLabel.Align = ui.CrossCenter
+	valueLabel.PassThrough = true
+	valueLabel.Add(ui.NewLabel(value, settingsLabelStyle))
+
+	return []ui.Widget{
+		settingsButton("-", settingsSmallButton, decrease),
+		valueLabel,
+		settingsButton("+", settingsSmallButton, increase),
+	}
+}
+
+// onOff returns the translated text for a boolean setting
+func onOff(on bool) string {
+	if on {
+		return i18n.T("debug.on")
+	}
+	return i18n.T("debug.off")
+}
+
+// clampStep adds step to value and keeps the result in [min, max]
+func clampStep(value, step, minValue, maxValue float64) float64 {
+	return max(minValue, min(maxValue, value+step))
+}
+
+// nextWindowSize returns the window size preset after the given size
+func nextWindowSize(width, height int) (int, int) {
+	for i, size := range settings.WindowSizes {
+		if size[0] == width && size[1] == height {
+			next := settings.WindowSizes[(i+1)%len(settings.WindowSizes)]
+			return next[0], next[1]
+		}
+	}
+	return settings.WindowSizes[0][0], settings.WindowSizes[0][1]
+}
+
+// nextLanguage returns the loaded language after the given one
+func nextLanguage(language string) string {
+	languages := i18n.Default().Languages()
+	if len(languages) == 0 {
+		return language
+	}
+	for i, l := range languages {
+		if l == language {
+			return languages[(i+1)%len(languages)]
+		}
+	}
+	return languages[0]
+}
+
+// captureBinding binds the first input pressed this frame to the action waiting for one,
+// replacing the action's bindings of the same device. Escape cancels.
+// An input taken from another action is removed from it.
+func (ss *SettingsScene) captureBinding() {
+	name, ok := input.CaptureBinding()
+	if !ok {
+		return
+	}
+
+	action := ss.bindingAction
+	ss.bindingAction = ""
+	ss.dirty = true
+	if name == ebiten.KeyEscape.String() {
+		return
+	}
+
+	captured, err := input.ParseBinding(name)
+	if err != nil {
+		return
+	}
+	ss.change(func(s *settings.Settings) {
+		for other, names := range s.Bindings {
+			kept := make([]string, 0, len(names))
+			for _, n := range names {
+				binding, err := input.ParseBinding(n)
+				sameDevice := other == action && err == nil && binding.Device() == captured.Device()
+				if n != name && !sameDevice {
+					kept = append(kept, n)
+				}
+			}
+			s.Bindings[other] = kept
+		}
+		s.Bindings[action] = append(s.Bindings[action], name)
+	})
+}
+
+// Update handles menu input and key capture
+func (ss *SettingsScene) Update() error {
+	if ss.bindingAction != "" {
+		ss.captureBinding()
+	} else if input.UIInput().Escape {
+		ss.back()
+		return nil
+	}
+
+	ss.layout()
+	ss.ctx.Update(ss.root, input.UIInput())
+
+	// 按钮回调可能修改了设置，下一帧前重建界面
+	if ss.dirty {
+		ss.build()
+	}
+	return nil
+}
+
+// layout centers the menu on the screen
+func (ss *SettingsScene) layout() {
+	w, h := ss.root.PreferredSize()
+	ss.root.SetBounds(ui.Rect{X: (screenWidth - w) / 2, Y: (screenHeight - h) / 2, W: w, H: h})
+}
+
+// Draw dims the scene below and renders the menu
+func (ss *SettingsScene) Draw(screen *ebiten.Image) {
+	vector.DrawFilledRect(screen, 0, 0, screenWidth, screenHeight, color.RGBA{0, 0, 0, 150}, false)
+	ss.layout()
+	ui.Layout(ss.root)
+	ui.Draw(screen, ss.root)
+}
@@ -0,0 +1,53 @@
+package scenes
+
+import (
+	"fmt"
+
+	"github.com/wubinrui111/2d-game/internal/grid"
+	"github.com/wubinrui111/2d-game/internal/ticks"
+)
+
+// randomTickRadius is how many chunks around the player get random ticks
+const randomTickRadius = 4
+
+// RandomTickSpeed is how many random ticks each chunk gets per game tick; the engine
+// sets it from the game configuration
+var RandomTickSpeed = ticks.DefaultRandomSpeed
+
+// updateBlockTicks runs the scheduled block ticks and the random ticks of the chunks
+// around the player
+func (ms *MainScene) updateBlockTicks() {
+	cell := worldCell(ms.playerCenter())
+	ms.ticks.RandomSpeed = RandomTickSpeed
+	ms.ticks.Update(ticks.ChunksAround(cell.X, cell.Y, randomTickRadius))
+}
+
+// LightLevel implements ticks.World: it returns the light level of a cell
+func (ms *MainScene) LightLevel(x, y int) int {
+	return ms.light.Level(x, y)
+}
+
+// PlaceStructure implements ticks.World: it grows the schematic with the asset key out
+// of the block at a cell, such as a tree out of a sapling. The structure only grows
+// where its blocks go into empty cells or flowing fluid.
+func (ms *MainScene) PlaceStructure(key string, x, y int) bool {
+	region, err := loadSchematic(key)
+	if err != nil {
+		fmt.Printf("Failed to grow structure: %v\n", err)
+		return false
+	}
+	at := grid.Cell{X: x, Y: y}
+	edits := region.Edits(at, false)
+	for _, edit := range edits {
+		if edit.Cell != at && ms.blockAt(edit.Cell) != nil && !ms.flowingFluid(edit.Cell) {
+			return false
+		}
+	}
+
+	// 和关卡的方块一样不记入撤销历史
+	ms.SetBlock(x, y, "")
+	for _, edit := range edits {
+		ms.SetBlock(edit.X, edit.Y, edit.ID)
+	}
+	return true
+}
@@ -0,0 +1,151 @@
+package scenes
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/wubinrui111/2d-game/internal/grid"
+	"github.com/wubinrui111/2d-game/internal/i18n"
+	"github.com/wubinrui111/2d-game/internal/input"
+	"github.com/wubinrui111/2d-game/internal/worldtime"
+)
+
+const (
+	// horizon is how far down the screen the sun and moon set, as a fraction of its height
+	horizon = 0.8
+
+	// celestialSize is the size the sun and moon are drawn at, in screen pixels
+	celestialSize = 64.0
+)
+
+var (
+	// 没有精灵时太阳和月亮的颜色
+	sunColor  = color.RGBA{255, 236, 140, 255}
+	moonColor = color.RGBA{228, 232, 240, 255}
+
+	// skipTimes are the times of day the skip action jumps between
+	skipTimes = []float64{worldtime.Midnight, worldtime.Sunrise, worldtime.Noon, worldtime.Sunset}
+)
+
+// updateTime advances the world clock, applies the time commands and passes the
+// ambient light to the lighting engine
+func (ms *MainScene) updateTime() {
+	if input.IsActionJustPressed(input.ActionFreezeTime) {
+		ms.clock.Frozen = !ms.clock.Frozen
+	}
+	if input.IsActionJustPressed(input.ActionSkipTime) {
+		ms.skipTime()
+	}
+	if input.IsActionJustPressed(input.ActionSetTime) {
+		ms.openTimePrompt()
+	}
+	ms.clock.Advance()
+	ms.light.SetAmbient(ms.clock.Ambient())
+}
+
+// hostileMobsCanSpawn reports whether the rules let a hostile mob spawn at a cell: it
+// must be empty and dark enough for the time of day
+func (ms *MainScene) hostileMobsCanSpawn(cell grid.Cell) bool {
+	if ms.Block(cell.X, cell.Y) != "" {
+		return false
+	}
+	return ms.clock.HostileMobsCanSpawn(ms.light.Sky(cell.X, cell.Y), ms.light.BlockLight(cell.X, cell.Y))
+}
+
+// skipTime moves the clock to the next of skipTimes
+func (ms *MainScene) skipTime() {
+	now := ms.clock.TimeOfDay()
+	for _, t := range skipTimes {
+		if t > now {
+			ms.clock.SkipTo(t)
+			return
+		}
+	}
+	ms.clock.SkipTo(skipTimes[0])
+}
+
+// openTimePrompt shows the field to type a time command into, with keyboard focus
+func (ms *MainScene) openTimePrompt() {
+	h := ms.hud
+	h.timeInput.Text = ""
+	h.timeInput.Hidden = false
+	h.timeHint.Text = i18n.T("debug.set_time")
+	h.timeHint.Hidden = false
+	h.ctx.Focus(h.timeInput)
+}
+
+// closeTimePrompt hides the time field
+func (ms *MainScene) closeTimePrompt() {
+	h := ms.hud
+	h.timeInput.Hidden = true
+	h.timeHint.Hidden = true
+	h.ctx.Focus(nil)
+}
+
+// updateTimePrompt edits the time command while its field is open and reports whether
+// it is. Enter sets the clock with worldtime.Clock.Set; Esc closes the field.
+func (ms *MainScene) updateTimePrompt() bool {
+	h := ms.hud
+	if h.timeInput.Hidden {
+		return false
+	}
+	in := input.UIInput()
+	switch {
+	case in.Escape:
+		ms.closeTimePrompt()
+	case in.Enter:
+		if err := ms.clock.Set(h.timeInput.Text); err != nil {
+			h.timeHint.Text = i18n.T("debug.set_time_invalid", h.timeInput.Text)
+			return true
+		}
+		ms.closeTimePrompt()
+	default:
+		// 点击其他地方也不会失去焦点，输入框打开时按键只用于输入
+		h.ctx.Focus(h.timeInput)
+		h.ctx.Update(h.root, in)
+	}
+	return true
+}
+
+// drawSky draws the sky gradient of the time of day, with the sun and the moon
+func (ms *MainScene) drawSky(screen *ebiten.Image) {
+	width, height := float64(screen.Bounds().Dx()), float64(screen.Bounds().Dy())
+
+	// 两个像素的图像线性插值拉伸成从顶部到地平线的渐变
+	top, bottom := ms.clock.SkyColors()
+	if ms.skyImage == nil {
+		ms.skyImage = ebiten.NewImage(1, 2)
+	}
+	ms.skyImage.WritePixels([]byte{top.R, top.G, top.B, 255, bottom.R, bottom.G, bottom.B, 255})
+	opts := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear}
+	opts.GeoM.Scale(width, height/2)
+	screen.DrawImage(ms.skyImage, opts)
+
+	ms.drawCelestial(screen, ms.clock.Sun(), ms.sunSprite, sunColor)
+	ms.drawCelestial(screen, ms.clock.Moon(), ms.moonSprite, moonColor)
+}
+
+// drawCelestial draws the sun or the moon on its arc across the screen. progress is
+// 0 when it rises on the left and 1 when it sets on the right.
+func (ms *MainScene) drawCelestial(screen *ebiten.Image, progress float64, sprite *ebiten.Image, fallback color.RGBA) {
+	if progress < -0.1 || progress > 1.1 {
+		return
+	}
+	width, height := float64(screen.Bounds().Dx()), float64(screen.Bounds().Dy())
+	horizonY := height * horizon
+	x := progress * width
+	y := horizonY - math.Sin(progress*math.Pi)*(horizonY-celestialSize)
+
+	if sprite == nil {
+		vector.DrawFilledCircle(screen, float32(x), float32(y), celestialSize/3, fallback, true)
+		return
+	}
+	w, h := sprite.Bounds().Dx(), sprite.Bounds().Dy()
+	opts := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear}
+	opts.GeoM.Translate(-float64(w)/2, -float64(h)/2)
+	opts.GeoM.Scale(celestialSize/float64(w), celestialSize/float64(h))
+	opts.GeoM.Translate(x, y)
+	screen.DrawImage(sprite, opts)
+}
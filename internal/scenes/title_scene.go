@@ -0,0 +1,87 @@
+package scenes
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/wubinrui111/2d-game/internal/i18n"
+)
+
+// TitleScene is the main menu shown when the game starts
+type TitleScene struct {
+	nav  Navigator
+	menu *menu
+}
+
+// NewTitleScene creates the title screen
+func NewTitleScene() *TitleScene {
+	ts := &TitleScene{}
+	ts.build()
+	return ts
+}
+
+// build creates the menu; it is rebuilt when the scene resumes in case the language changed
+func (ts *TitleScene) build() {
+	ts.menu = newMenu(i18n.T("menu.title"))
+	ts.menu.addButton(i18n.T("menu.new_world"), ts.newWorld)
+
+	// 载入导出的关卡，还没有导出过时按钮禁用
+	_, err := os.Stat(LevelExportPath)
+	ts.menu.addButton(i18n.T("menu.load_world"), ts.loadWorld).Disabled = err != nil
+
+	ts.menu.addButton(i18n.T("menu.settings"), ts.openSettings)
+	ts.menu.addButton(i18n.T("menu.quit"), ts.quit)
+}
+
+// OnResume rebuilds the menu after returning from the settings
+func (ts *TitleScene) OnResume() {
+	ts.build()
+}
+
+// SetNavigator implements NavigatorSetter
+func (ts *TitleScene) SetNavigator(nav Navigator) {
+	ts.nav = nav
+}
+
+func (ts *TitleScene) newWorld() {
+	if ts.nav != nil {
+		ts.nav.Replace(NewMainScene())
+	}
+}
+
+func (ts *TitleScene) loadWorld() {
+	scene, err := LoadMainScene(LevelExportPath)
+	if err != nil {
+		fmt.Printf("Failed to load world: %v\n", err)
+		return
+	}
+	if ts.nav != nil {
+		ts.nav.Replace(scene)
+	}
+}
+
+func (ts *TitleScene) openSettings() {
+	if ts.nav != nil {
+		ts.nav.Push(NewSettingsScene())
+	}
+}
+
+func (ts *TitleScene) quit() {
+	if ts.nav != nil {
+		ts.nav.Quit()
+	}
+}
+
+// Update handles menu input
+func (ts *TitleScene) Update() error {
+	ts.menu.update()
+	return nil
+}
+
+// Draw renders the title screen
+func (ts *TitleScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{40, 60, 90, 255})
+	ts.menu.draw(screen)
+}
@@ -0,0 +1,115 @@
+package scenes
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+	"math/rand"
+
+	"github.com/wubinrui111/2d-game/internal/components"
+)
+
+// WorldHash returns a hash of the simulated world: the player, blocks, item drops,
+// falling blocks, fluids, block ticks, world time, random numbers drawn, inventory,
+// camera and game mode. Two runs with the same seed and input must end
+// with the same hash, which is what replay tests check.
+func (ms *MainScene) WorldHash() uint64 {
+	h := fnv.New64a()
+
+	p := ms.player
+	hashFloats(h, p.Position.X, p.Position.Y, p.Velocity.X, p.Velocity.Y)
+	hashInts(h, p.Health.Current, p.Health.Max, boolInt(p.OnGround))
+
+	hashInts(h, len(ms.blocks))
+	for _, block := range ms.blocks {
+		h.Write([]byte(block.Name))
+		hashFloats(h, block.Position.X, block.Position.Y)
+	}
+
+	hashInts(h, len(ms.itemDrops))
+	for _, drop := range ms.itemDrops {
+		h.Write([]byte(drop.GetItem().ID))
+		hashFloats(h, drop.Position.X, drop.Position.Y, drop.Velocity.X, drop.Velocity.Y, drop.Life)
+	}
+
+	hashInts(h, len(ms.fallingBlocks))
+	for _, fb := range ms.fallingBlocks {
+		h.Write([]byte(fb.BlockID))
+		hashFloats(h, fb.Position.X, fb.Position.Y, fb.Velocity.X, fb.Velocity.Y, fb.Life)
+		hashInts(h, boolInt(fb.Landed))
+	}
+
+	// 流体、计划刻和随机刻决定以后的方块变化，随机刻取决于已经取了多少个随机数
+	ms.fluids.Hash(h)
+	ms.ticks.Hash(h)
+	hashInts(h, ms.rngSource.draws)
+	hashInts(h, int(ms.clock.Ticks), int(ms.clock.TicksPerDay), boolInt(ms.clock.Frozen))
+
+	hashInts(h, ms.inventory.SelectedSlot, ms.inventorySystem.GameMode, ms.currentItemIndex)
+	for _, slot := range ms.inventory.Slots {
+		hashStack(h, slot)
+	}
+	if attached := ms.inventorySystem.MouseAttachedItem; attached != nil {
+		hashStack(h, *attached)
+	}
+
+	hashFloats(h, ms.camera.X, ms.camera.Y, ms.camera.Zoom())
+	return h.Sum64()
+}
+
+// hashStack adds an item stack to h
+func hashStack(h hash.Hash64, stack components.ItemStack) {
+	if stack.Item != nil {
+		h.Write([]byte(stack.Item.ID))
+	}
+	hashInts(h, stack.Count)
+}
+
+// hashFloats adds the exact bits of values to h
+func hashFloats(h hash.Hash64, values ...float64) {
+	var buf [8]byte
+	for _, v := range values {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+		h.Write(buf[:])
+	}
+}
+
+// hashInts adds values to h
+func hashInts(h hash.Hash64, values ...int) {
+	var buf [8]byte
+	for _, v := range values {
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		h.Write(buf[:])
+	}
+}
+
+// boolInt converts a flag to 0 or 1 for hashing
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// countingSource is a random source that counts the numbers drawn from it. A source
+// with a known seed is in the same state after the same number of draws.
+type countingSource struct {
+	rand.Source64
+	draws int
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return s.Source64.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.draws++
+	return s.Source64.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.draws = 0
+	s.Source64.Seed(seed)
+}
@@ -0,0 +1,151 @@
+package scenes
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wubinrui111/2d-game/internal/grid"
+	"github.com/wubinrui111/2d-game/internal/tiled"
+	"github.com/wubinrui111/2d-game/internal/worldgen"
+)
+
+const (
+	// generateProperty is the map property that turns on terrain generation around
+	// the level: set to true, chunk columns are generated as the player comes near
+	generateProperty = "generate"
+
+	// seedProperty is the map property holding the seed of the generated terrain;
+	// without it the world's seed is used
+	seedProperty = "seed"
+
+	// biomesProperty is the map property holding the biomes of the generated chunk
+	// columns, which are not generated again when the level is loaded
+	biomesProperty = "biomes"
+
+	// generateRadius is how many chunk columns on each side of the player are generated
+	generateRadius = 3
+)
+
+// generatedStructures are the schematics the terrain generator places on the surface,
+// in order: earlier structures win where two would overlap
+var generatedStructures = []struct {
+	key      string
+	surfaces []string
+	chance   float64
+	spacing  int
+}{
+	{"schematics/hut.json", []string{"grass"}, 0.3, 96},
+}
+
+// loadGenerator starts the terrain generator if the level turns it on, with the chunk
+// columns the level already holds
+func (ms *MainScene) loadGenerator(props tiled.Properties) error {
+	if generate, _ := props.Get(generateProperty); generate != "true" {
+		return nil
+	}
+	seed := ms.seed
+	if value, exists := props.Get(seedProperty); exists {
+		var err error
+		if seed, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("property %s is not an integer: %q", seedProperty, value)
+		}
+	}
+	ms.generator = worldgen.NewGenerator(seed)
+	ms.columns = make(map[int]*worldgen.ChunkColumn)
+	for _, s := range generatedStructures {
+		region, err := loadSchematic(s.key)
+		if err != nil {
+			fmt.Printf("Failed to load generated structure: %v\n", err)
+			continue
+		}
+		ms.generator.Structures = append(ms.generator.Structures, worldgen.Structure{Region: region, Surfaces: s.surfaces, Chance: s.chance, Spacing: s.spacing})
+	}
+
+	biomes, _ := props.Get(biomesProperty)
+	for _, text := range strings.Fields(biomes) {
+		column := &worldgen.ChunkColumn{}
+		if err := column.UnmarshalText([]byte(text)); err != nil {
+			return err
+		}
+		ms.columns[column.X] = column
+	}
+	return nil
+}
+
+// updateGeneration generates the chunk columns near the player that are not
+// generated yet
+func (ms *MainScene) updateGeneration() {
+	if ms.generator == nil {
+		return
+	}
+	centerX, _ := ms.playerCenter()
+	cx := int(math.Floor(centerX / GridSize / worldgen.ChunkSize))
+	for x := cx - generateRadius; x <= cx+generateRadius; x++ {
+		if _, generated := ms.columns[x]; !generated {
+			ms.generateColumn(x)
+		}
+	}
+}
+
+// generateColumn generates a chunk column. Generated blocks go only into empty cells,
+// so the level's own blocks stay, and are not part of the undo history.
+func (ms *MainScene) generateColumn(cx int) {
+	// 先记录群系，放置方块时按群系着色
+	column := ms.generator.Column(cx)
+	ms.columns[cx] = column
+	placed := make(map[grid.Cell]bool)
+	ms.generator.Fill(column, func(x, y int, id string) {
+		if ms.blockAt(grid.Cell{X: x, Y: y}) == nil {
+			ms.SetBlock(x, y, id)
+			placed[grid.Cell{X: x, Y: y}] = true
+		}
+	})
+
+	// 生成的结构带上方块实体数据
+	for _, site := range ms.generator.Sites(cx*worldgen.ChunkSize, (cx+1)*worldgen.ChunkSize) {
+		for _, edit := range site.Structure.Region.Edits(grid.Cell{X: site.X, Y: site.Y}, false) {
+			if edit.Entity != nil && placed[edit.Cell] {
+				ms.SetBlockEntity(edit.X, edit.Y, edit.Entity)
+			}
+		}
+	}
+}
+
+// biomeTint returns the tint of the biome of a column, zero where nothing is generated
+func (ms *MainScene) biomeTint(x int) color.RGBA {
+	cx := int(math.Floor(float64(x) / worldgen.ChunkSize))
+	if column, exists := ms.columns[cx]; exists {
+		return column.Biome(x).Tint
+	}
+	return color.RGBA{}
+}
+
+// saveGenerator writes the generator's seed and the biomes of the generated chunk
+// columns to the level's properties
+func (ms *MainScene) saveGenerator(props *tiled.Properties) {
+	if ms.generator == nil {
+		return
+	}
+	props.Set(generateProperty, "bool", "true")
+	props.Set(seedProperty, "int", strconv.FormatInt(ms.generator.Seed, 10))
+
+	keys := make([]int, 0, len(ms.columns))
+	for cx := range ms.columns {
+		keys = append(keys, cx)
+	}
+	sort.Ints(keys)
+	texts := make([]string, 0, len(keys))
+	for _, cx := range keys {
+		text, err := ms.columns[cx].MarshalText()
+		if err != nil {
+			fmt.Printf("Failed to save biomes: %v\n", err)
+			continue
+		}
+		texts = append(texts, string(text))
+	}
+	props.Set(biomesProperty, "string", strings.Join(texts, " "))
+}
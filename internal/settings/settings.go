@@ -0,0 +1,255 @@
+// internal/settings/settings.go
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FileName is the name of the user preferences file
+	FileName = "settings.yaml"
+
+	// appDir is the directory created below the user's config directory
+	appDir = "2d-game"
+
+	// Limits for numeric settings
+	MinWindowWidth  = 640
+	MinWindowHeight = 480
+	MaxWindowWidth  = 7680
+	MaxWindowHeight = 4320
+	MinGUIScale     = 0.5
+	MaxGUIScale     = 2.0
+)
+
+// WindowSizes are the window sizes offered in the settings menu
+var WindowSizes = [][2]int{
+	{800, 600},
+	{1024, 768},
+	{1280, 960},
+	{1600, 1200},
+}
+
+// Settings are the user's preferences. They are stored apart from config/config.yaml,
+// which holds the game's defaults, so they survive updates of the game files.
+type Settings struct {
+	WindowWidth  int  `yaml:"window_width"`
+	WindowHeight int  `yaml:"window_height"`
+	Fullscreen   bool `yaml:"fullscreen"`
+	VSync        bool `yaml:"vsync"`
+
+	// Volume is the master volume from 0 to 1.
+	// 目前还没有音频系统，先保存起来供以后使用
+	Volume float64 `yaml:"volume"`
+
+	// Language is a locale such as "zh-CN"
+	Language string `yaml:"language"`
+
+	// GUIScale scales interface text
+	GUIScale float64 `yaml:"gui_scale"`
+
+	// Bindings maps action names to the inputs bound to them. An input is a key name
+	// (see ebiten.Key.String), "Mouse:<button>", "Mouse:WheelUp"/"Mouse:WheelDown",
+	// "Pad:<button>" or "Pad:<stick axis><sign>", see input.ParseBinding.
+	Bindings map[string][]string `yaml:"bindings"`
+}
+
+// DefaultBindings returns the default input bindings.
+// The action names match the input.Action constants.
+func DefaultBindings() map[string][]string {
+	bindings := map[string][]string{
+		"move_left":      {"ArrowLeft", "A", "Pad:Left", "Pad:LeftStickX-"},
+		"move_right":     {"ArrowRight", "D", "Pad:Right", "Pad:LeftStickX+"},
+		"move_down":      {"S", "Pad:Down", "Pad:LeftStickY+"},
+		"jump":           {"Space", "W", "Pad:A"},
+		"attack":         {"Mouse:Left", "Pad:RT"},
+		"use_item":       {"Mouse:Right", "Pad:LT"},
+		"pick_block":     {"Mouse:Middle", "Pad:Y"},
+		"inventory":      {"E", "Pad:X"},
+		"game_mode":      {"G"},
+		"toggle_grid":    {"F3"},
+		"export_level":   {"F6"},
+		"editor":         {"F4"},
+		"editor_tool":    {"T"},
+		"fill":           {"F"},
+		"replace":        {"R"},
+		"delete":         {"Delete"},
+		"copy":           {"C"},
+		"cut":            {"X"},
+		"paste":          {"V"},
+		"undo":           {"Z"},
+		"redo":           {"Y"},
+		"save_schematic": {"F7"},
+		"next_schematic": {"N"},
+		"rotate":         {"Q"},
+		"mirror":         {"M"},
+		"freeze_time":    {"F9"},
+		"skip_time":      {"F10"},
+		"set_time":       {"F8"},
+		"pause":          {"Escape", "Pad:Start"},
+		"next_slot":      {"Mouse:WheelDown", "Pad:RB"},
+		"prev_slot":      {"Mouse:WheelUp", "Pad:LB"},
+		"zoom":           {"ControlLeft", "ControlRight", "Pad:Back"},
+	}
+	for i := 1; i <= 9; i++ {
+		bindings[fmt.Sprintf("hotbar_%d", i)] = []string{fmt.Sprintf("Digit%d", i)}
+	}
+	return bindings
+}
+
+// Actions returns the names of all bindable actions in sorted order
+func Actions() []string {
+	return sortedActions(DefaultBindings())
+}
+
+// Defaults returns the default preferences for the given window size and language
+func Defaults(width, height int, language string) Settings {
+	return Settings{
+		WindowWidth:  width,
+		WindowHeight: height,
+		Fullscreen:   false,
+		VSync:        true,
+		Volume:       1.0,
+		Language:     language,
+		GUIScale:     1.0,
+		Bindings:     DefaultBindings(),
+	}
+}
+
+// DefaultPath returns the preferences file in the user's config directory,
+// or in the working directory if there is none
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return FileName
+	}
+	return filepath.Join(dir, appDir, FileName)
+}
+
+// Validate checks that every value is in range
+func (s Settings) Validate() error {
+	var errs []error
+	if s.WindowWidth < MinWindowWidth || s.WindowWidth > MaxWindowWidth {
+		errs = append(errs, fmt.Errorf("window width %d out of range [%d, %d]", s.WindowWidth, MinWindowWidth, MaxWindowWidth))
+	}
+	if s.WindowHeight < MinWindowHeight || s.WindowHeight > MaxWindowHeight {
+		errs = append(errs, fmt.Errorf("window height %d out of range [%d, %d]", s.WindowHeight, MinWindowHeight, MaxWindowHeight))
+	}
+	if s.Volume < 0 || s.Volume > 1 {
+		errs = append(errs, fmt.Errorf("volume %v out of range [0, 1]", s.Volume))
+	}
+	if s.GUIScale < MinGUIScale || s.GUIScale > MaxGUIScale {
+		errs = append(errs, fmt.Errorf("gui scale %v out of range [%v, %v]", s.GUIScale, MinGUIScale, MaxGUIScale))
+	}
+	if s.Language == "" {
+		errs = append(errs, errors.New("language is empty"))
+	}
+	if err := validateBindings(s.Bindings); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("settings: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// validateBindings rejects unknown actions, empty input names and inputs bound to several actions
+func validateBindings(bindings map[string][]string) error {
+	known := DefaultBindings()
+	usedBy := make(map[string]string)
+
+	var errs []error
+	for _, action := range sortedActions(bindings) {
+		if _, exists := known[action]; !exists {
+			errs = append(errs, fmt.Errorf("unknown action %q", action))
+			continue
+		}
+		for _, name := range bindings[action] {
+			if name == "" {
+				errs = append(errs, fmt.Errorf("empty input name for action %q", action))
+				continue
+			}
+			if other, exists := usedBy[name]; exists && other != action {
+				errs = append(errs, fmt.Errorf("input %q is bound to both %q and %q", name, other, action))
+				continue
+			}
+			usedBy[name] = action
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sortedActions returns the actions of a binding map in sorted order so errors are stable
+func sortedActions(bindings map[string][]string) []string {
+	actions := make([]string, 0, len(bindings))
+	for action := range bindings {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// Clone returns a copy that does not share the bindings
+func (s Settings) Clone() Settings {
+	clone := s
+	clone.Bindings = make(map[string][]string, len(s.Bindings))
+	for action, names := range s.Bindings {
+		clone.Bindings[action] = append([]string(nil), names...)
+	}
+	return clone
+}
+
+// Load reads preferences from path on top of defaults.
+// A missing file is not an error; an invalid file returns the defaults and an error.
+func Load(path string, defaults Settings) (Settings, error) {
+	s := defaults.Clone()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+
+	// 文件中没有的按键绑定保留默认值
+	loaded := s.Clone()
+	if err := yaml.Unmarshal(data, &loaded); err != nil {
+		return s, fmt.Errorf("settings: failed to parse %s: %w", path, err)
+	}
+	if loaded.Bindings == nil {
+		loaded.Bindings = make(map[string][]string)
+	}
+	for action, names := range s.Bindings {
+		if _, exists := loaded.Bindings[action]; !exists {
+			loaded.Bindings[action] = names
+		}
+	}
+
+	if err := loaded.Validate(); err != nil {
+		return s, err
+	}
+	return loaded, nil
+}
+
+// Save validates the preferences and writes them to path, creating its directory
+func (s Settings) Save(path string) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
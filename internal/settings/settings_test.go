@@ -0,0 +1,174 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDefaultIsValid(t *testing.T) {
+	s := Defaults(800, 600, "zh-CN")
+	if err := s.Validate(); err != nil {
+		t.Errorf("Expected default settings to be valid, got %v", err)
+	}
+}
+
+func TestValidateRejectsOutOfRangeValues(t *testing.T) {
+	s := Defaults(800, 600, "zh-CN")
+	s.WindowWidth = 100
+	s.Volume = 1.5
+	s.GUIScale = 5
+
+	err := s.Validate()
+	if err == nil {
+		t.Fatal("Expected validation to fail")
+	}
+
+	for _, expected := range []string{"window width", "volume", "gui scale"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error to mention %q, got %v", expected, err)
+		}
+	}
+}
+
+func TestValidateRejectsConflictingBindings(t *testing.T) {
+	s := Defaults(800, 600, "zh-CN")
+	s.Bindings["jump"] = []string{"E"}
+
+	if err := s.Validate(); err == nil || !strings.Contains(err.Error(), `"E"`) {
+		t.Errorf("Expected error for input bound to two actions, got %v", err)
+	}
+
+	s = Defaults(800, 600, "zh-CN")
+	s.Bindings["fly"] = []string{"F"}
+	if err := s.Validate(); err == nil || !strings.Contains(err.Error(), "unknown action") {
+		t.Errorf("Expected error for unknown action, got %v", err)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", FileName)
+
+	s := Defaults(800, 600, "zh-CN")
+	s.WindowWidth, s.WindowHeight = 1280, 960
+	s.Fullscreen = true
+	s.Volume = 0.25
+	s.Language = "en-US"
+	s.Bindings["jump"] = []string{"Space"}
+
+	if err := s.Save(path); err != nil {
+		t.Fatalf("Failed to save settings: %v", err)
+	}
+
+	loaded, err := Load(path, Defaults(800, 600, "zh-CN"))
+	if err != nil {
+		t.Fatalf("Failed to load settings: %v", err)
+	}
+
+	if !reflect.DeepEqual(loaded, s) {
+		t.Errorf("Expected loaded settings %+v, got %+v", s, loaded)
+	}
+}
+
+func TestLoadMissingFileReturnsDefaults(t *testing.T) {
+	defaults := Defaults(1024, 768, "zh-CN")
+
+	s, err := Load(filepath.Join(t.TempDir(), FileName), defaults)
+	if err != nil {
+		t.Fatalf("Expected no error for missing file, got %v", err)
+	}
+	if !reflect.DeepEqual(s, defaults) {
+		t.Errorf("Expected defaults, got %+v", s)
+	}
+}
+
+func TestLoadKeepsDefaultsForMissingFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	data := "volume: 0.5\nbindings:\n  jump: [Space]\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load(path, Defaults(800, 600, "zh-CN"))
+	if err != nil {
+		t.Fatalf("Failed to load settings: %v", err)
+	}
+
+	if s.Volume != 0.5 || s.WindowWidth != 800 || s.Language != "zh-CN" {
+		t.Errorf("Expected volume from file and other values from defaults, got %+v", s)
+	}
+	if !reflect.DeepEqual(s.Bindings["jump"], []string{"Space"}) {
+		t.Errorf("Expected jump bound to Space, got %v", s.Bindings["jump"])
+	}
+	if !reflect.DeepEqual(s.Bindings["inventory"], DefaultBindings()["inventory"]) {
+		t.Errorf("Expected default inventory bindings to be kept, got %v", s.Bindings["inventory"])
+	}
+}
+
+func TestLoadInvalidFileReturnsDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte("gui_scale: 10\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	defaults := Defaults(800, 600, "zh-CN")
+	s, err := Load(path, defaults)
+	if err == nil {
+		t.Error("Expected an error for an invalid file")
+	}
+	if !reflect.DeepEqual(s, defaults) {
+		t.Errorf("Expected defaults for an invalid file, got %+v", s)
+	}
+}
+
+func TestCloneDoesNotShareBindings(t *testing.T) {
+	s := Defaults(800, 600, "zh-CN")
+	clone := s.Clone()
+	clone.Bindings["jump"][0] = "X"
+
+	if s.Bindings["jump"][0] != "Space" {
+		t.Error("Expected clone to have its own bindings")
+	}
+}
+
+func TestStoreSetRejectsInvalidSettings(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), FileName), Defaults(800, 600, "zh-CN"))
+
+	invalid := store.Current()
+	invalid.Volume = -1
+	if err := store.Set(invalid); err == nil {
+		t.Error("Expected invalid settings to be rejected")
+	}
+	if store.Current().Volume != 1 {
+		t.Errorf("Expected volume to stay at the default, got %v", store.Current().Volume)
+	}
+}
+
+func TestStoreSaveLoadAndReset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	store := NewStore(path, Defaults(800, 600, "zh-CN"))
+
+	s := store.Current()
+	s.VSync = false
+	if err := store.Set(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+
+	other := NewStore(path, Defaults(800, 600, "zh-CN"))
+	if err := other.Load(); err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if other.Current().VSync {
+		t.Error("Expected vsync to be off after loading")
+	}
+
+	other.Reset()
+	if !other.Current().VSync {
+		t.Error("Expected reset to restore the defaults")
+	}
+}
@@ -0,0 +1,59 @@
+package settings
+
+// Store holds the preferences in use by the running game and the file they are saved to
+type Store struct {
+	// Path is the preferences file
+	Path string
+
+	// Defaults are used for values missing from the file and by Reset
+	Defaults Settings
+
+	current Settings
+}
+
+// NewStore creates a store that starts with the defaults
+func NewStore(path string, defaults Settings) *Store {
+	return &Store{
+		Path:     path,
+		Defaults: defaults.Clone(),
+		current:  defaults.Clone(),
+	}
+}
+
+var defaultStore = NewStore(DefaultPath(), Defaults(800, 600, "en-US"))
+
+// Default returns the store used by the game
+func Default() *Store {
+	return defaultStore
+}
+
+// Load reads the preferences file. On error the defaults are used.
+func (st *Store) Load() error {
+	s, err := Load(st.Path, st.Defaults)
+	st.current = s
+	return err
+}
+
+// Current returns a copy of the preferences in use
+func (st *Store) Current() Settings {
+	return st.current.Clone()
+}
+
+// Set replaces the preferences in use if they are valid
+func (st *Store) Set(s Settings) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	st.current = s.Clone()
+	return nil
+}
+
+// Reset restores the defaults
+func (st *Store) Reset() {
+	st.current = st.Defaults.Clone()
+}
+
+// Save writes the preferences in use to the preferences file
+func (st *Store) Save() error {
+	return st.current.Save(st.Path)
+}
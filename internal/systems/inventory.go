@@ -3,12 +3,15 @@ package graphics
 import (
 	"fmt"
 	"image/color"
-	"sort"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/wubinrui111/2d-game/internal/blocks"
 	"github.com/wubinrui111/2d-game/internal/components"
+	"github.com/wubinrui111/2d-game/internal/fonts"
+	"github.com/wubinrui111/2d-game/internal/i18n"
+	"github.com/wubinrui111/2d-game/internal/input"
+	"github.com/wubinrui111/2d-game/internal/ui"
 )
 
 const (
@@ -27,6 +30,38 @@ const (
 	HotbarHeight = SlotSize
 )
 
+// Game modes
+const (
+	GameModeSurvival  = 0
+	GameModeCreative  = 1
+	GameModeSpectator = 2 // Flies through blocks and cannot change the world
+
+	gameModeCount = 3
+)
+
+var (
+	// countTextStyle draws stack counts in the bottom right corner of a slot
+	countTextStyle = fonts.Style{
+		Size:         12,
+		Color:        color.White,
+		OutlineColor: color.Black,
+		OutlineWidth: 1,
+		Align:        fonts.AlignRight,
+	}
+
+	// slotNumberStyle draws the hotbar key hint in the top left corner of a slot
+	slotNumberStyle = fonts.Style{Size: 10, Color: color.RGBA{220, 220, 220, 255}}
+
+	// titleTextStyle draws the inventory title centered at the top of the screen
+	titleTextStyle = fonts.Style{Size: 18, Align: fonts.AlignCenter}
+
+	// labelTextStyle draws creative item names below their slots
+	labelTextStyle = fonts.Style{Size: 10}
+
+	// hintTextStyle draws key hints at the bottom of the inventory screen
+	hintTextStyle = fonts.Style{}
+)
+
 // InventorySystem handles the rendering and interaction with the player's inventory
 type InventorySystem struct {
 	// Visible indicates whether the full inventory is visible (not just the hotbar)
@@ -38,15 +73,32 @@ type InventorySystem struct {
 	// MouseAttachedItem stores the item attached to the mouse
 	MouseAttachedItem *components.ItemStack
 	
-	// GameMode indicates the current game mode (0 = survival, 1 = creative)
+	// GameMode indicates the current game mode (GameModeSurvival, GameModeCreative or GameModeSpectator)
 	GameMode int
 	
 	// BlockSprites stores references to block sprites for rendering
 	BlockSprites map[string]*ebiten.Image
 	
+	// Blocks holds the block definitions the creative items and their sprites come from
+	Blocks *blocks.Registry
+	
 	// Cache for creative items
 	creativeItemsCache []components.Item
 	cacheDirty         bool
+	
+	// inventory is the inventory being shown, set at the start of Update and Draw
+	inventory *components.Inventory
+	
+	// UI widgets
+	ui            *ui.Context
+	root          *ui.Panel
+	hotbarGrid    *ui.SlotGrid
+	overlay       *ui.Panel
+	titleLabel    *ui.Label
+	inventoryView *ui.ScrollView
+	inventoryGrid *ui.SlotGrid
+	closeHint     *ui.Label
+	modeHint      *ui.Label
 }
 
 // SetBlockSprites sets the block sprites for the inventory system
@@ -55,43 +107,169 @@ func (is *InventorySystem) SetBlockSprites(sprites map[string]*ebiten.Image) {
 	is.cacheDirty = true
 }
 
+// SetBlockDefs sets the block definitions the creative items are made from
+func (is *InventorySystem) SetBlockDefs(defs *blocks.Registry) {
+	is.Blocks = defs
+	is.cacheDirty = true
+}
+
+// BlockSprite returns the sprite of a block or item ID as its definition in defs says,
+// the default sprite if the sprite is missing and nil if the block is drawn as a color
+func BlockSprite(defs *blocks.Registry, sprites map[string]*ebiten.Image, id string) *ebiten.Image {
+	if sprites == nil {
+		return nil
+	}
+	name := id
+	if defs != nil {
+		if def, exists := defs.Lookup(id); exists {
+			name = def.Sprite
+		}
+	}
+	if name == "" {
+		return nil
+	}
+	if sprite, exists := sprites[name]; exists {
+		return sprite
+	}
+	return sprites[blocks.DefaultSprite]
+}
+
 // NewInventorySystem creates a new inventory system
 func NewInventorySystem() *InventorySystem {
-	return &InventorySystem{
+	is := &InventorySystem{
 		Visible:           false,
 		MouseAttachedSlot: -1, // -1 indicates no item is attached to mouse
 		MouseAttachedItem: nil,
 		GameMode:          0, // 0 = survival mode by default
+		ui:                ui.NewContext(),
+	}
+	is.buildUI()
+	return is
+}
+
+// buildUI creates the hotbar and full inventory widgets
+func (is *InventorySystem) buildUI() {
+	// Hotbar at the bottom of the screen
+	is.hotbarGrid = ui.NewSlotGrid(9, SlotSize, SlotMargin, func() int {
+		if is.inventory == nil {
+			return 0
+		}
+		return min(is.inventory.HotbarSize, len(is.inventory.Slots))
+	})
+	is.hotbarGrid.DrawSlot = is.drawHotbarSlot
+	ui.PlaceAt(is.hotbarGrid, HotbarX, HotbarY)
+	
+	// Full inventory: player slots followed by creative items in creative mode
+	is.inventoryGrid = ui.NewSlotGrid(9, SlotSize, SlotMargin, is.totalSlots)
+	is.inventoryGrid.DrawSlot = is.drawInventorySlot
+	is.inventoryView = ui.NewScrollView(is.inventoryGrid, 9*(SlotSize+SlotMargin)+8, 470)
+	is.inventoryView.SetBounds(ui.Rect{X: InventoryX, Y: 60, W: is.inventoryView.Width, H: is.inventoryView.Height})
+	
+	is.titleLabel = ui.NewLabel("", titleTextStyle)
+	is.titleLabel.SetBounds(ui.Rect{X: 0, Y: 20, W: 800, H: 20})
+	is.modeHint = ui.NewLabel("", hintTextStyle)
+	ui.PlaceAt(is.modeHint, 10, 550)
+	is.closeHint = ui.NewLabel("", hintTextStyle)
+	ui.PlaceAt(is.closeHint, 10, 570)
+	
+	is.overlay = ui.NewPanel(ui.Absolute)
+	is.overlay.Background = color.RGBA{0, 0, 0, 150}
+	is.overlay.SetBounds(ui.Rect{X: 0, Y: 0, W: 800, H: 600})
+	is.overlay.Add(is.titleLabel, is.inventoryView, is.modeHint, is.closeHint)
+	
+	is.root = ui.NewPanel(ui.Absolute)
+	is.root.PassThrough = true
+	is.root.SetBounds(ui.Rect{X: 0, Y: 0, W: 800, H: 600})
+	is.root.Add(is.hotbarGrid, is.overlay)
+	is.syncUI()
+}
+
+// syncUI updates widget visibility and texts from the system state
+func (is *InventorySystem) syncUI() {
+	is.hotbarGrid.Hidden = is.Visible
+	is.overlay.Hidden = !is.Visible
+	
+	modeKey := input.BindingName(input.ActionToggleGameMode)
+	switch is.GameMode {
+	case GameModeCreative:
+		is.titleLabel.Text = i18n.T("inventory.title.creative")
+		is.modeHint.Text = i18n.T("inventory.hint.to_spectator", modeKey)
+	case GameModeSpectator:
+		is.titleLabel.Text = i18n.T("inventory.title.spectator")
+		is.modeHint.Text = i18n.T("inventory.hint.to_survival", modeKey)
+	default:
+		is.titleLabel.Text = i18n.T("inventory.title.survival")
+		is.modeHint.Text = i18n.T("inventory.hint.to_creative", modeKey)
+	}
+	is.closeHint.Text = i18n.T("inventory.hint.close", input.BindingName(input.ActionOpenInventory))
+}
+
+// totalSlots returns the number of slots in the full inventory grid
+func (is *InventorySystem) totalSlots() int {
+	if is.inventory == nil {
+		return 0
+	}
+	total := len(is.inventory.Slots)
+	if is.GameMode == GameModeCreative {
+		// Only include creative items in creative mode
+		total += len(is.creativeItems())
+	}
+	return total
+}
+
+// slotStack returns the item stack shown in full inventory slot i and whether it is a creative item
+func (is *InventorySystem) slotStack(i int) (*components.ItemStack, bool) {
+	if i < len(is.inventory.Slots) {
+		return &is.inventory.Slots[i], false
 	}
+	
+	creativeItems := is.creativeItems()
+	creativeItemIndex := i - len(is.inventory.Slots)
+	return &components.ItemStack{
+		Item:  &creativeItems[creativeItemIndex],
+		Count: creativeItems[creativeItemIndex].MaxStack,
+	}, true
+}
+
+// WantsMouse reports whether the cursor is over the inventory UI, so the world should ignore clicks
+func (is *InventorySystem) WantsMouse() bool {
+	return is.ui.WantsMouse() || is.MouseAttachedItem != nil
 }
 
 // Update handles input for the inventory system
 func (is *InventorySystem) Update(inventory *components.Inventory) {
-	// Toggle full inventory visibility with 'E' key
-	if inpututil.IsKeyJustPressed(ebiten.KeyE) {
+	is.inventory = inventory
+	
+	// Toggle full inventory visibility with the inventory key ('E' by default)
+	if input.IsActionJustPressed(input.ActionOpenInventory) {
 		is.Visible = !is.Visible
 	}
 	
-	// Toggle game mode with 'G' key (creative/survival)
-	if inpututil.IsKeyJustPressed(ebiten.KeyG) {
-		is.GameMode = 1 - is.GameMode // Toggle between 0 and 1
+	// Cycle game mode with the game mode key ('G' by default, survival/creative/spectator)
+	if input.IsActionJustPressed(input.ActionToggleGameMode) {
+		is.GameMode = (is.GameMode + 1) % gameModeCount
 	}
 
-	// Handle hotbar slot selection with number keys
-	for i := 1; i <= inventory.HotbarSize; i++ {
-		key := ebiten.Key(int(ebiten.Key1) + i - 1)
-		if inpututil.IsKeyJustPressed(key) {
-			inventory.SelectSlot(i - 1)
+	// Handle hotbar slot selection with the hotbar actions (number keys by default)
+	for i := 0; i < inventory.HotbarSize; i++ {
+		if input.IsActionJustPressed(input.HotbarAction(i)) {
+			inventory.SelectSlot(i)
 			break
 		}
 	}
 
-	// Handle mouse wheel for slot selection (reversed direction)
-	_, wheelY := ebiten.Wheel()
-	if wheelY > 0 {
-		inventory.SelectPreviousSlot()
-	} else if wheelY < 0 {
-		inventory.SelectNextSlot()
+	is.syncUI()
+	is.ui.Update(is.root, input.UIInput())
+
+	// Handle slot selection (mouse wheel and shoulder buttons by default),
+	// unless the wheel is scrolling the full inventory or zooming the camera
+	scrolling := is.Visible && is.inventoryView.Bounds().Contains(is.ui.Input.CursorX, is.ui.Input.CursorY)
+	if !scrolling && !input.IsActionPressed(input.ActionZoom) {
+		if input.IsActionJustPressed(input.ActionPrevSlot) {
+			inventory.SelectPreviousSlot()
+		} else if input.IsActionJustPressed(input.ActionNextSlot) {
+			inventory.SelectNextSlot()
+		}
 	}
 	
 	// Handle mouse attachment for inventory slots
@@ -100,338 +278,132 @@ func (is *InventorySystem) Update(inventory *components.Inventory) {
 
 // Draw renders the inventory based on its visibility state
 func (is *InventorySystem) Draw(screen *ebiten.Image, inventory *components.Inventory) {
-	if is.Visible {
-		// Draw full inventory
-		is.drawFullInventory(screen, inventory)
-	} else {
-		// Draw hotbar only
-		is.drawHotbar(screen, inventory)
-	}
+	is.inventory = inventory
+	is.syncUI()
+	ui.Layout(is.root)
+	ui.Draw(screen, is.root)
+	
+	// Draw attached item on top of everything
+	is.drawAttachedItem(screen)
 }
 
-// drawHotbar renders only the hotbar (first N slots) at the bottom of the screen
-func (is *InventorySystem) drawHotbar(screen *ebiten.Image, inventory *components.Inventory) {
-	// Draw slots first
-	for i := 0; i < inventory.HotbarSize && i < len(inventory.Slots); i++ {
-		// Skip drawing the slot that has an attached item
-		if is.MouseAttachedSlot == i {
-			continue
-		}
-		
-		slot := inventory.Slots[i]
-		x := float64(HotbarX + i*(SlotSize+SlotMargin))
-		y := float64(HotbarY)
-
-		// Draw slot background
-		slotColor := color.RGBA{100, 100, 100, 200}
-		if i == inventory.SelectedSlot {
-			slotColor = color.RGBA{150, 150, 150, 255} // Highlight selected slot
-		}
-		ebitenutil.DrawRect(screen, x, y, SlotSize, SlotSize, slotColor)
-
-		// Draw item if present
-		if slot.Item != nil && slot.Count > 0 {
-			// Use sprite if available, otherwise fallback to colored rectangle
-			if is.BlockSprites != nil {
-				// Try to find a matching sprite for this item
-				var sprite *ebiten.Image
-				if s, exists := is.BlockSprites[slot.Item.ID]; exists {
-					sprite = s
-				} else if s, exists := is.BlockSprites[slot.Item.Name]; exists {
-					sprite = s
-				}
-				
-				if sprite != nil {
-					// Draw the sprite
-					opts := &ebiten.DrawImageOptions{}
-					opts.GeoM.Translate(x+2, y+2)
-					screen.DrawImage(sprite, opts)
-				} else {
-					// Fallback to colored rectangle
-					ebitenutil.DrawRect(screen, x+2, y+2, SlotSize-4, SlotSize-4, slot.Item.Color)
-				}
-			} else {
-				// Fallback to colored rectangle
-				ebitenutil.DrawRect(screen, x+2, y+2, SlotSize-4, SlotSize-4, slot.Item.Color)
-			}
-
-			// Draw item count
-			countText := fmt.Sprintf("%d", slot.Count)
-			textWidth := len(countText) * 6 // Approximate character width
-			ebitenutil.DebugPrintAt(screen, countText, int(x)+SlotSize-textWidth-2, int(y)+SlotSize-12)
-		}
-
-		// Draw slot number
-		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%d", (i+1)%10), int(x+2), int(y+2))
+// drawHotbarSlot renders one hotbar slot
+func (is *InventorySystem) drawHotbarSlot(screen *ebiten.Image, i int, r ui.Rect, hovered bool) {
+	// Skip drawing the slot that has an attached item
+	if is.MouseAttachedSlot == i {
+		return
 	}
 	
-	// Draw attached item if there is one
-	if is.MouseAttachedItem != nil {
-		// Get mouse position
-		mouseX, mouseY := ebiten.CursorPosition()
-		
-		// Draw item at mouse position
-		x := float64(mouseX)
-		y := float64(mouseY)
-		
-		// Draw semi-transparent background
-		ebitenutil.DrawRect(screen, x, y, SlotSize, SlotSize, color.RGBA{100, 100, 100, 150})
-		
-		// Draw item color
-		ebitenutil.DrawRect(screen, x+2, y+2, SlotSize-4, SlotSize-4, is.MouseAttachedItem.Item.Color)
-		
-		// Draw item count
-		countText := fmt.Sprintf("%d", is.MouseAttachedItem.Count)
-		textWidth := len(countText) * 6 // Approximate character width
-		ebitenutil.DebugPrintAt(screen, countText, int(x)+SlotSize-textWidth-2, int(y)+SlotSize-12)
+	// Draw slot background
+	slotColor := color.RGBA{100, 100, 100, 200}
+	if i == is.inventory.SelectedSlot {
+		slotColor = color.RGBA{150, 150, 150, 255} // Highlight selected slot
 	}
-}
+	is.drawSlot(screen, &is.inventory.Slots[i], r, slotColor)
 
-// drawFullInventory renders the full inventory grid
-func (is *InventorySystem) drawFullInventory(screen *ebiten.Image, inventory *components.Inventory) {
-	// Draw semi-transparent background
-	ebitenutil.DrawRect(screen, 0, 0, 800, 600, color.RGBA{0, 0, 0, 150})
+	// Draw slot number
+	fonts.Draw(screen, fmt.Sprintf("%d", (i+1)%10), r.X+2, r.Y+1, slotNumberStyle)
+}
 
-	// Draw title
-	if is.GameMode == 0 {
-		ebitenutil.DebugPrintAt(screen, "Inventory (Survival Mode)", 300, 20)
-	} else {
-		ebitenutil.DebugPrintAt(screen, "Inventory (Creative Mode)", 300, 20)
+// drawInventorySlot renders one slot of the full inventory grid
+func (is *InventorySystem) drawInventorySlot(screen *ebiten.Image, i int, r ui.Rect, hovered bool) {
+	slot, isCreativeItem := is.slotStack(i)
+	
+	// Skip drawing the slot that has an attached item (only for player slots)
+	if !isCreativeItem && is.MouseAttachedSlot == i {
+		return
 	}
 
-	// Define creative items from block sprites
-	creativeItems := is.generateCreativeItemsFromSprites()
+	// Draw slot background
+	slotColor := color.RGBA{100, 100, 100, 200}
+	if !isCreativeItem && i == is.inventory.SelectedSlot {
+		slotColor = color.RGBA{150, 150, 150, 255} // Highlight selected slot
+	} else if isCreativeItem {
+		slotColor = color.RGBA{80, 80, 120, 200} // Different color for creative items
+	}
+	is.drawSlot(screen, slot, r, slotColor)
 
-	// Calculate total slots needed (player slots + creative items in creative mode)
-	totalSlots := len(inventory.Slots)
-	if is.GameMode == 1 {
-		// Only include creative items in creative mode
-		totalSlots += len(creativeItems)
+	// For creative items, also draw a label
+	if isCreativeItem {
+		name := fonts.Truncate(ItemDisplayName(slot.Item), SlotSize+SlotMargin, labelTextStyle)
+		fonts.Draw(screen, name, r.X, r.Y+SlotSize+1, labelTextStyle)
 	}
-	
-	cols := 9
-	
-	// Draw all slots including creative items (if in creative mode)
-	for i := 0; i < totalSlots; i++ {
-		var slot *components.ItemStack
-		var isCreativeItem bool
-		var creativeItemIndex int
-		
-		// Determine if this is a player inventory slot or a creative item slot
-		if i < len(inventory.Slots) {
-			// Player inventory slot
-			slot = &inventory.Slots[i]
-		} else {
-			// Creative item slot (only in creative mode)
-			isCreativeItem = true
-			creativeItemIndex = i - len(inventory.Slots)
-			slot = &components.ItemStack{
-				Item:  &creativeItems[creativeItemIndex],
-				Count: creativeItems[creativeItemIndex].MaxStack,
-			}
-		}
-		
-		// Skip drawing the slot that has an attached item (only for player slots)
-		if !isCreativeItem && is.MouseAttachedSlot == i {
-			continue
-		}
-		
-		row := i / cols
-		col := i % cols
-
-		x := float64(InventoryX + col*(SlotSize+SlotMargin))
-		y := float64(60 + row*(SlotSize+SlotMargin))
-
-		// Draw slot background
-		slotColor := color.RGBA{100, 100, 100, 200}
-		if !isCreativeItem && i == inventory.SelectedSlot {
-			slotColor = color.RGBA{150, 150, 150, 255} // Highlight selected slot
-		} else if isCreativeItem {
-			slotColor = color.RGBA{80, 80, 120, 200} // Different color for creative items
-		}
-		ebitenutil.DrawRect(screen, x, y, SlotSize, SlotSize, slotColor)
-
-		// Draw item if present
-		if slot.Item != nil && slot.Count > 0 {
-			// Use sprite if available, otherwise fallback to colored rectangle
-			if is.BlockSprites != nil {
-				// Try to find a matching sprite for this item
-				var sprite *ebiten.Image
-				if s, exists := is.BlockSprites[slot.Item.ID]; exists {
-					sprite = s
-				} else if s, exists := is.BlockSprites[slot.Item.Name]; exists {
-					sprite = s
-				}
-				
-				if sprite != nil {
-					// Draw the sprite
-					opts := &ebiten.DrawImageOptions{}
-					opts.GeoM.Translate(x+2, y+2)
-					screen.DrawImage(sprite, opts)
-				} else {
-					// Fallback to colored rectangle
-					ebitenutil.DrawRect(screen, x+2, y+2, SlotSize-4, SlotSize-4, slot.Item.Color)
-				}
-			} else {
-				// Fallback to colored rectangle
-				ebitenutil.DrawRect(screen, x+2, y+2, SlotSize-4, SlotSize-4, slot.Item.Color)
-			}
+}
 
-			// Draw item count
-			countText := fmt.Sprintf("%d", slot.Count)
-			textWidth := len(countText) * 6 // Approximate character width
-			ebitenutil.DebugPrintAt(screen, countText, int(x)+SlotSize-textWidth-2, int(y)+SlotSize-12)
-			
-			// For creative items, also draw a label
-			if isCreativeItem {
-				name := slot.Item.Name
-				if len(name) > 10 {
-					name = name[:10] + "..."
-				}
-				ebitenutil.DebugPrintAt(screen, name, int(x), int(y+SlotSize+2))
-			}
-		}
+// drawSlot renders a slot background with its item and count
+func (is *InventorySystem) drawSlot(screen *ebiten.Image, slot *components.ItemStack, r ui.Rect, slotColor color.RGBA) {
+	ui.FillRect(screen, r, slotColor)
+	if slot.Item != nil && slot.Count > 0 {
+		is.drawItem(screen, slot.Item, r.X, r.Y)
+		drawItemCount(screen, slot.Count, r.X, r.Y)
 	}
+}
 
-	// Draw instructions
-	ebitenutil.DebugPrintAt(screen, "Press 'E' to close inventory", 10, 570)
-	if is.GameMode == 0 {
-		ebitenutil.DebugPrintAt(screen, "Press 'G' to switch to Creative Mode", 10, 550)
+// drawItem draws an item's sprite inside the slot at (x, y), or its color if there is no sprite
+func (is *InventorySystem) drawItem(screen *ebiten.Image, item *components.Item, x, y float64) {
+	// Use sprite if available, otherwise fallback to colored rectangle
+	if sprite := BlockSprite(is.Blocks, is.BlockSprites, item.ID); sprite != nil {
+		// Draw the sprite
+		opts := &ebiten.DrawImageOptions{}
+		opts.GeoM.Translate(x+2, y+2)
+		screen.DrawImage(sprite, opts)
 	} else {
-		ebitenutil.DebugPrintAt(screen, "Press 'G' to switch to Survival Mode", 10, 550)
+		// Fallback to colored rectangle
+		ebitenutil.DrawRect(screen, x+2, y+2, SlotSize-4, SlotSize-4, item.Color)
+	}
+}
+
+// drawAttachedItem draws the item attached to the mouse at the cursor position
+func (is *InventorySystem) drawAttachedItem(screen *ebiten.Image) {
+	if is.MouseAttachedItem == nil || is.MouseAttachedItem.Item == nil {
+		return
 	}
 	
-	// Draw attached item if there is one
-	if is.MouseAttachedItem != nil {
-		// Get mouse position
-		mouseX, mouseY := ebiten.CursorPosition()
-		
-		// Draw item at mouse position
-		x := float64(mouseX)
-		y := float64(mouseY)
-		
-		// Draw semi-transparent background
-		ebitenutil.DrawRect(screen, x, y, SlotSize, SlotSize, color.RGBA{100, 100, 100, 150})
-		
-		// Use sprite if available, otherwise fallback to colored rectangle
-		if is.BlockSprites != nil && is.MouseAttachedItem.Item != nil {
-			// Try to find a matching sprite for this item
-			var sprite *ebiten.Image
-			if s, exists := is.BlockSprites[is.MouseAttachedItem.Item.ID]; exists {
-				sprite = s
-			} else if s, exists := is.BlockSprites[is.MouseAttachedItem.Item.Name]; exists {
-				sprite = s
-			}
-			
-			if sprite != nil {
-				// Draw the sprite
-				opts := &ebiten.DrawImageOptions{}
-				opts.GeoM.Translate(x+2, y+2)
-				screen.DrawImage(sprite, opts)
-			} else {
-				// Fallback to colored rectangle
-				ebitenutil.DrawRect(screen, x+2, y+2, SlotSize-4, SlotSize-4, is.MouseAttachedItem.Item.Color)
-			}
-		} else if is.MouseAttachedItem.Item != nil {
-			// Fallback to colored rectangle
-			ebitenutil.DrawRect(screen, x+2, y+2, SlotSize-4, SlotSize-4, is.MouseAttachedItem.Item.Color)
-		}
-		
-		// Draw item count
-		countText := fmt.Sprintf("%d", is.MouseAttachedItem.Count)
-		textWidth := len(countText) * 6 // Approximate character width
-		ebitenutil.DebugPrintAt(screen, countText, int(x)+SlotSize-textWidth-2, int(y)+SlotSize-12)
+	// Get cursor position
+	x, y := input.Cursor()
+	
+	// Draw semi-transparent background
+	ebitenutil.DrawRect(screen, x, y, SlotSize, SlotSize, color.RGBA{100, 100, 100, 150})
+	is.drawItem(screen, is.MouseAttachedItem.Item, x, y)
+	drawItemCount(screen, is.MouseAttachedItem.Count, x, y)
+}
+
+// inventorySlotAt returns the full inventory slot under the cursor, or -1.
+// Slots scrolled out of the view cannot be hit.
+func (is *InventorySystem) inventorySlotAt(mouseX, mouseY float64) int {
+	if !is.inventoryView.Bounds().Contains(mouseX, mouseY) {
+		return -1
 	}
+	return is.inventoryGrid.SlotAt(mouseX, mouseY)
 }
 
 // handleMouseAttachment handles the mouse attachment logic for inventory slots
 func (is *InventorySystem) handleMouseAttachment(inventory *components.Inventory) {
-	// Get mouse position
-	mouseX, mouseY := ebiten.CursorPosition()
+	in := is.ui.Input
 	
 	// Check if we are attaching an item to the mouse
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+	if in.JustPressed[ui.MouseLeft] {
 		if is.MouseAttachedSlot == -1 { // Only attach if nothing is already attached
 			if is.Visible {
-				// Define creative items from block sprites
-				creativeItems := is.generateCreativeItemsFromSprites()
-				
-				// Calculate total slots (only include creative items in creative mode)
-				totalSlots := len(inventory.Slots)
-				if is.GameMode == 1 {
-					totalSlots += len(creativeItems)
-				}
-				
-				cols := 9
-				
-				// Check all slots including creative items (if in creative mode)
-				for i := 0; i < totalSlots; i++ {
-					var slot *components.ItemStack
-					var isCreativeItem bool
-					
-					// Determine if this is a player inventory slot or a creative item slot
-					if i < len(inventory.Slots) {
-						// Player inventory slot
-						slot = &inventory.Slots[i]
-					} else {
-						// Creative item slot (only in creative mode)
-						isCreativeItem = true
-						creativeItemIndex := i - len(inventory.Slots)
-						slot = &components.ItemStack{
-							Item:  &creativeItems[creativeItemIndex],
-							Count: creativeItems[creativeItemIndex].MaxStack,
-						}
-					}
-					
-					row := i / cols
-					col := i % cols
-
-					x := float64(InventoryX + col*(SlotSize+SlotMargin))
-					y := float64(60 + row*(SlotSize+SlotMargin))
-					
-					// Check if mouse is within slot bounds
-					if float64(mouseX) >= x && float64(mouseX) <= x+SlotSize && float64(mouseY) >= y && float64(mouseY) <= y+SlotSize {
-						// Only attach if slot has an item
-						if slot.Item != nil && slot.Count > 0 {
-							if isCreativeItem {
-								// For creative items, attach a copy with max stack count
-								is.MouseAttachedItem = &components.ItemStack{
-									Item:  slot.Item,
-									Count: slot.Count,
-								}
-								// Use special slot value to indicate this is a creative item
-								is.MouseAttachedSlot = -2
-							} else {
-								// For player inventory slots
-								is.MouseAttachedSlot = i
-								// Create a copy of the item stack to attach to mouse
-								is.MouseAttachedItem = &components.ItemStack{
-									Item:  slot.Item,
-									Count: slot.Count,
-								}
-							}
-							return
-						}
-					}
-				}
+				is.checkFullInventorySlotClick(inventory, in.CursorX, in.CursorY)
 			} else {
 				// Check hotbar slots
-				is.checkHotbarSlotClick(inventory, float64(mouseX), float64(mouseY))
+				is.checkHotbarSlotClick(inventory, in.CursorX, in.CursorY)
 			}
 		} else {
 			// Place item in clicked slot
 			if is.Visible {
 				// Handle placement in full inventory
-				is.handleFullInventoryPlacement(inventory, float64(mouseX), float64(mouseY))
+				is.handleFullInventoryPlacement(inventory, in.CursorX, in.CursorY)
 			} else {
 				// Handle placement in hotbar
-				is.handleHotbarPlacement(inventory, float64(mouseX), float64(mouseY))
+				is.handleHotbarPlacement(inventory, in.CursorX, in.CursorY)
 			}
 		}
 	}
 	
 	// Handle right-click to detach creative items
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) && is.MouseAttachedSlot == -2 {
+	if in.JustPressed[ui.MouseRight] && is.MouseAttachedSlot == -2 {
 		is.MouseAttachedSlot = -1
 		is.MouseAttachedItem = nil
 	}
@@ -439,336 +411,162 @@ func (is *InventorySystem) handleMouseAttachment(inventory *components.Inventory
 
 // checkHotbarSlotClick checks if a hotbar slot was clicked for attachment
 func (is *InventorySystem) checkHotbarSlotClick(inventory *components.Inventory, mouseX, mouseY float64) {
-	for i := 0; i < inventory.HotbarSize && i < len(inventory.Slots); i++ {
-		slot := inventory.Slots[i]
-		x := float64(HotbarX + i*(SlotSize+SlotMargin))
-		y := float64(HotbarY)
-		
-		// Check if mouse is within slot bounds
-		if mouseX >= x && mouseX <= x+SlotSize && mouseY >= y && mouseY <= y+SlotSize {
-			// Only attach if slot has an item
-			if slot.Item != nil && slot.Count > 0 {
-				is.MouseAttachedSlot = i
-				// Create a copy of the item stack to attach to mouse
-				is.MouseAttachedItem = &components.ItemStack{
-					Item:  slot.Item,
-					Count: slot.Count,
-				}
-				return
-			}
+	i := is.hotbarGrid.SlotAt(mouseX, mouseY)
+	if i < 0 {
+		return
+	}
+	
+	// Only attach if slot has an item
+	slot := inventory.Slots[i]
+	if slot.Item != nil && slot.Count > 0 {
+		is.MouseAttachedSlot = i
+		// Create a copy of the item stack to attach to mouse
+		is.MouseAttachedItem = &components.ItemStack{
+			Item:  slot.Item,
+			Count: slot.Count,
 		}
 	}
 }
 
 // checkFullInventorySlotClick checks if a full inventory slot was clicked for attachment
 func (is *InventorySystem) checkFullInventorySlotClick(inventory *components.Inventory, mouseX, mouseY float64) {
-	cols := 9
-	for i, slot := range inventory.Slots {
-		row := i / cols
-		col := i % cols
-		
-		x := float64(InventoryX + col*(SlotSize+SlotMargin))
-		y := float64(60 + row*(SlotSize+SlotMargin))
-		
-		// Check if mouse is within slot bounds
-		if mouseX >= x && mouseX <= x+SlotSize && mouseY >= y && mouseY <= y+SlotSize {
-			// Only attach if slot has an item
-			if slot.Item != nil && slot.Count > 0 {
-				is.MouseAttachedSlot = i
-				// Create a copy of the item stack to attach to mouse
-				is.MouseAttachedItem = &components.ItemStack{
-					Item:  slot.Item,
-					Count: slot.Count,
-				}
-				return
-			}
-		}
+	i := is.inventorySlotAt(mouseX, mouseY)
+	if i < 0 {
+		return
 	}
-}
-
-// handleHotbarPlacement handles placing an attached item in the hotbar
-func (is *InventorySystem) handleHotbarPlacement(inventory *components.Inventory, mouseX, mouseY float64) {
-	// Find which slot we're placing onto
-	for i := 0; i < inventory.HotbarSize && i < len(inventory.Slots); i++ {
-		x := float64(HotbarX + i*(SlotSize+SlotMargin))
-		y := float64(HotbarY)
-		
-		// Check if mouse is within slot bounds
-		if mouseX >= x && mouseX <= x+SlotSize && mouseY >= y && mouseY <= y+SlotSize {
-			// Place item in slot
-			if is.MouseAttachedItem != nil {
-				// If it's the same slot, just detach the item
-				if i == is.MouseAttachedSlot {
-					// But don't detach creative items
-					if is.MouseAttachedSlot != -2 {
-						is.MouseAttachedSlot = -1
-						is.MouseAttachedItem = nil
-					}
-					return
-				}
-				
-				// For creative mode items, just place them in the slot (don't swap)
-				if is.MouseAttachedSlot == -2 { // -2 indicates creative item
-					targetSlot := &inventory.Slots[i]
-					// Make sure the target slot is properly initialized
-					if targetSlot == nil {
-						inventory.Slots[i] = components.ItemStack{}
-						targetSlot = &inventory.Slots[i]
-					}
-					targetSlot.Item = is.MouseAttachedItem.Item
-					targetSlot.Count = is.MouseAttachedItem.Count
-					
-					// Keep the item attached to mouse for multiple placements
-					// is.MouseAttachedItem = nil
-					return
-				}
-				
-				// Handle regular item placement/swapping
-				if is.MouseAttachedSlot >= 0 && is.MouseAttachedSlot < len(inventory.Slots) {
-					// Swap items between slots
-					attachedSlot := &inventory.Slots[is.MouseAttachedSlot]
-					targetSlot := &inventory.Slots[i]
-					
-					// Store the target slot's item
-					var targetItem *components.ItemStack
-					if targetSlot.Item != nil && targetSlot.Count > 0 {
-						targetItem = &components.ItemStack{
-							Item:  targetSlot.Item,
-							Count: targetSlot.Count,
-						}
-					}
-					
-					// Place attached item in target slot
-					targetSlot.Item = is.MouseAttachedItem.Item
-					targetSlot.Count = is.MouseAttachedItem.Count
-					
-					// Place target item in attached slot (or clear if target was empty)
-					if targetItem != nil {
-						attachedSlot.Item = targetItem.Item
-						attachedSlot.Count = targetItem.Count
-					} else {
-						attachedSlot.Item = nil
-						attachedSlot.Count = 0
-					}
-				} else {
-					// Just place the item in the slot (for items attached from outside the inventory)
-					targetSlot := &inventory.Slots[i]
-					targetSlot.Item = is.MouseAttachedItem.Item
-					targetSlot.Count = is.MouseAttachedItem.Count
-				}
-				
-				// Detach item from mouse
-				is.MouseAttachedSlot = -1
-				is.MouseAttachedItem = nil
-				return
-			}
-		}
+	
+	// Only attach if slot has an item
+	slot, isCreativeItem := is.slotStack(i)
+	if slot.Item == nil || slot.Count <= 0 {
+		return
 	}
 	
-	// If clicked outside a slot, drop the item (detach it) - but not for creative items
-	if is.MouseAttachedSlot != -2 {
-		is.MouseAttachedSlot = -1
-		is.MouseAttachedItem = nil
+	if isCreativeItem {
+		// For creative items, attach a copy with max stack count
+		is.MouseAttachedItem = &components.ItemStack{
+			Item:  slot.Item,
+			Count: slot.Count,
+		}
+		// Use special slot value to indicate this is a creative item
+		is.MouseAttachedSlot = -2
+	} else {
+		// For player inventory slots
+		is.MouseAttachedSlot = i
+		// Create a copy of the item stack to attach to mouse
+		is.MouseAttachedItem = &components.ItemStack{
+			Item:  slot.Item,
+			Count: slot.Count,
+		}
 	}
 }
 
-// handleFullInventoryPlacement handles placing an attached item in the full inventory
-func (is *InventorySystem) handleFullInventoryPlacement(inventory *components.Inventory, mouseX, mouseY float64) {
-	// Define creative items from block sprites
-	creativeItems := is.generateCreativeItemsFromSprites()
+// placeAttachedItem puts the attached item into target slot i, swapping with whatever was there
+func (is *InventorySystem) placeAttachedItem(inventory *components.Inventory, i int) {
+	targetSlot := &inventory.Slots[i]
 	
-	// Calculate total slots (only include creative items in creative mode)
-	totalSlots := len(inventory.Slots)
-	if is.GameMode == 1 {
-		totalSlots += len(creativeItems)
+	// For creative mode items, just place them in the slot (don't swap)
+	// and keep the item attached to mouse for multiple placements
+	if is.MouseAttachedSlot == -2 { // -2 indicates creative item
+		targetSlot.Item = is.MouseAttachedItem.Item
+		targetSlot.Count = is.MouseAttachedItem.Count
+		return
 	}
 	
-	cols := 9
-	
-	// Find which slot we're placing onto
-	for i := 0; i < totalSlots; i++ {
-		var isCreativeItem bool
+	// Handle regular item placement/swapping
+	if is.MouseAttachedSlot >= 0 && is.MouseAttachedSlot < len(inventory.Slots) {
+		// Swap items between slots
+		attachedSlot := &inventory.Slots[is.MouseAttachedSlot]
 		
-		// Determine if this is a player inventory slot or a creative item slot
-		if i >= len(inventory.Slots) {
-			// Creative item slot (only in creative mode)
-			isCreativeItem = true
+		// Store the target slot's item
+		var targetItem *components.ItemStack
+		if targetSlot.Item != nil && targetSlot.Count > 0 {
+			targetItem = &components.ItemStack{
+				Item:  targetSlot.Item,
+				Count: targetSlot.Count,
+			}
 		}
 		
-		row := i / cols
-		col := i % cols
-
-		x := float64(InventoryX + col*(SlotSize+SlotMargin))
-		y := float64(60 + row*(SlotSize+SlotMargin))
+		// Place attached item in target slot
+		targetSlot.Item = is.MouseAttachedItem.Item
+		targetSlot.Count = is.MouseAttachedItem.Count
 		
-		// Check if mouse is within slot bounds
-		if float64(mouseX) >= x && float64(mouseX) <= x+SlotSize && float64(mouseY) >= y && float64(mouseY) <= y+SlotSize {
-			// Place item in slot
-			if is.MouseAttachedItem != nil {
-				// If it's the same slot, just detach the item
-				if i == is.MouseAttachedSlot {
-					// But don't detach creative items
-					if is.MouseAttachedSlot != -2 {
-						is.MouseAttachedSlot = -1
-						is.MouseAttachedItem = nil
-					}
-					return
-				}
-				
-				// For creative mode items, just place them in the slot (don't swap)
-				if is.MouseAttachedSlot == -2 { // -2 indicates creative item
-					if !isCreativeItem {
-						// Can only place creative items in player inventory slots
-						targetSlot := &inventory.Slots[i]
-						// Make sure the target slot is properly initialized
-						if targetSlot == nil {
-							inventory.Slots[i] = components.ItemStack{}
-							targetSlot = &inventory.Slots[i]
-						}
-						targetSlot.Item = is.MouseAttachedItem.Item
-						targetSlot.Count = is.MouseAttachedItem.Count
-						
-						// Keep the item attached to mouse for multiple placements
-						// is.MouseAttachedItem = nil
-						return
-					}
-				}
-				
-				// Handle regular item placement/swapping
-				if is.MouseAttachedSlot >= 0 && is.MouseAttachedSlot < len(inventory.Slots) {
-					// Can only swap items in player inventory slots
-					if !isCreativeItem {
-						// Swap items between slots
-						attachedSlot := &inventory.Slots[is.MouseAttachedSlot]
-						targetSlot := &inventory.Slots[i]
-						
-						// Store the target slot's item
-						var targetItem *components.ItemStack
-						if targetSlot.Item != nil && targetSlot.Count > 0 {
-							targetItem = &components.ItemStack{
-								Item:  targetSlot.Item,
-								Count: targetSlot.Count,
-							}
-						}
-						
-						// Place attached item in target slot
-						targetSlot.Item = is.MouseAttachedItem.Item
-						targetSlot.Count = is.MouseAttachedItem.Count
-						
-						// Place target item in attached slot (or clear if target was empty)
-						if targetItem != nil {
-							attachedSlot.Item = targetItem.Item
-							attachedSlot.Count = targetItem.Count
-						} else {
-							attachedSlot.Item = nil
-							attachedSlot.Count = 0
-						}
-					}
-				} else if is.MouseAttachedSlot < 0 {
-					// Just place the item in the slot (for items attached from outside the inventory)
-					if !isCreativeItem {
-						targetSlot := &inventory.Slots[i]
-						targetSlot.Item = is.MouseAttachedItem.Item
-						targetSlot.Count = is.MouseAttachedItem.Count
-					}
-				}
-				
-				// Detach item from mouse (but not for creative items)
-				if is.MouseAttachedSlot != -2 {
-					is.MouseAttachedSlot = -1
-					is.MouseAttachedItem = nil
-				}
-				return
-			}
+		// Place target item in attached slot (or clear if target was empty)
+		if targetItem != nil {
+			attachedSlot.Item = targetItem.Item
+			attachedSlot.Count = targetItem.Count
+		} else {
+			attachedSlot.Item = nil
+			attachedSlot.Count = 0
 		}
+	} else {
+		// Just place the item in the slot (for items attached from outside the inventory)
+		targetSlot.Item = is.MouseAttachedItem.Item
+		targetSlot.Count = is.MouseAttachedItem.Count
 	}
 	
-	// If clicked outside a slot, drop the item (detach it) - but not for creative items
-	if is.MouseAttachedSlot != -2 {
-		is.MouseAttachedSlot = -1
-		is.MouseAttachedItem = nil
-	}
+	// Detach item from mouse
+	is.MouseAttachedSlot = -1
+	is.MouseAttachedItem = nil
 }
 
-// handleCreativeItemClick checks if a creative item was clicked and attaches it to the mouse
-func (is *InventorySystem) handleCreativeItemClick(mouseX, mouseY float64, creativeItems []components.Item) {
-	// Only handle clicks in creative mode
-	if is.GameMode != 1 {
+// handleSlotPlacement places the attached item into slot i, or drops it when i is -1
+func (is *InventorySystem) handleSlotPlacement(inventory *components.Inventory, i int, isCreativeItem bool) {
+	if is.MouseAttachedItem == nil {
 		return
 	}
 	
-	// Check if a creative item was clicked
-	cols := 9
-	startX := 10
-	startY := 420
-	
-	for i, item := range creativeItems {
-		col := i % cols
-		row := i / cols
-		
-		x := float64(startX + col*(SlotSize+SlotMargin))
-		y := float64(startY + row*(SlotSize+SlotMargin))
-		
-		// Check if mouse is within item bounds
-		if mouseX >= x && mouseX <= x+SlotSize && mouseY >= y && mouseY <= y+SlotSize {
-			// Attach a copy of this item to the mouse with max stack count
-			is.MouseAttachedItem = &components.ItemStack{
-				Item:  &item,
-				Count: item.MaxStack,
-			}
-			// Use special slot value to indicate this is a creative item
-			is.MouseAttachedSlot = -2
-			return
-		}
+	switch {
+	case i == is.MouseAttachedSlot:
+		// If it's the same slot, just detach the item
+		is.MouseAttachedSlot = -1
+		is.MouseAttachedItem = nil
+	case i >= 0 && !isCreativeItem:
+		is.placeAttachedItem(inventory, i)
+	case i < 0 && is.MouseAttachedSlot != -2:
+		// If clicked outside a slot, drop the item (detach it) - but not for creative items
+		is.MouseAttachedSlot = -1
+		is.MouseAttachedItem = nil
 	}
 }
 
-// generateCreativeItemsFromSprites generates creative items from available block sprites
-func (is *InventorySystem) generateCreativeItemsFromSprites() []components.Item {
+// handleHotbarPlacement handles placing an attached item in the hotbar
+func (is *InventorySystem) handleHotbarPlacement(inventory *components.Inventory, mouseX, mouseY float64) {
+	is.handleSlotPlacement(inventory, is.hotbarGrid.SlotAt(mouseX, mouseY), false)
+}
+
+// handleFullInventoryPlacement handles placing an attached item in the full inventory
+func (is *InventorySystem) handleFullInventoryPlacement(inventory *components.Inventory, mouseX, mouseY float64) {
+	i := is.inventorySlotAt(mouseX, mouseY)
+	
+	// Items can only be placed in player inventory slots, not on creative items
+	isCreativeItem := i >= len(inventory.Slots)
+	is.handleSlotPlacement(inventory, i, isCreativeItem)
+}
+
+// creativeItems returns an item for every block definition, in the order of the definitions
+func (is *InventorySystem) creativeItems() []components.Item {
 	// Return cached items if available and not dirty
 	if !is.cacheDirty && is.creativeItemsCache != nil {
 		return is.creativeItemsCache
 	}
 	
-	// If no block sprites are available, return empty list
-	if is.BlockSprites == nil {
+	// If no block definitions are available, return empty list
+	if is.Blocks == nil {
 		return []components.Item{}
 	}
 	
-	// Create a map to avoid duplicates
-	itemsMap := make(map[string]components.Item)
-	
-	// Create items from block sprites
-	for name, sprite := range is.BlockSprites {
-		// Skip player sprite
-		if name == "Player" {
-			continue
-		}
-		
-		// Create item
-		item := components.Item{
-			ID:       name,
-			Name:     formatItemName(name),
+	items := make([]components.Item, 0, len(is.Blocks.IDs()))
+	for _, id := range is.Blocks.IDs() {
+		def, _ := is.Blocks.Get(id)
+		items = append(items, components.Item{
+			ID:       def.ID,
+			Name:     def.Name,
 			Count:    1,
 			MaxStack: 64,
-			Color:    getColorFromSprite(sprite),
-		}
-		
-		// Use ID as key to avoid duplicates
-		itemsMap[item.ID] = item
+			Color:    def.Color,
+		})
 	}
 	
-	// Convert map to slice
-	items := make([]components.Item, 0, len(itemsMap))
-	for _, item := range itemsMap {
-		items = append(items, item)
-	}
-	
-	// Sort items by ID to ensure consistent order
-	sort.Slice(items, func(i, j int) bool {
-		return items[i].ID < items[j].ID
-	})
-	
 	// Cache the items
 	is.creativeItemsCache = items
 	is.cacheDirty = false
@@ -776,27 +574,18 @@ func (is *InventorySystem) generateCreativeItemsFromSprites() []components.Item
 	return items
 }
 
-// formatItemName formats an item name from its ID
-func formatItemName(id string) string {
-	// Simple formatting - replace underscores with spaces and capitalize first letter
-	name := ""
-	for i, r := range id {
-		if i == 0 {
-			// Capitalize first letter
-			if r >= 'a' && r <= 'z' {
-				r = r - 'a' + 'A'
-			}
-		} else if r == '_' {
-			r = ' '
-		}
-		name += string(r)
-	}
-	return name
+// drawItemCount draws a stack count right-aligned in the bottom corner of the slot at (x, y)
+func drawItemCount(screen *ebiten.Image, count int, x, y float64) {
+	countText := fmt.Sprintf("%d", count)
+	_, textHeight := fonts.Measure(countText, countTextStyle)
+	fonts.Draw(screen, countText, x+SlotSize-2, y+SlotSize-textHeight, countTextStyle)
 }
 
-// getColorFromSprite extracts a representative color from a sprite
-func getColorFromSprite(sprite *ebiten.Image) color.RGBA {
-	// For now, return a default color
-	// In a more advanced implementation, we could sample the sprite to get an average color
-	return color.RGBA{128, 128, 128, 255}
+// ItemDisplayName returns the localized name of an item, falling back to its Name field
+func ItemDisplayName(item *components.Item) string {
+	key := "item." + item.ID
+	if i18n.Has(key) {
+		return i18n.T(key)
+	}
+	return item.Name
 }
@@ -0,0 +1,207 @@
+// Package ticks changes blocks over time. Random ticks pick cells of the chunks near
+// the player at a fixed rate; scheduled ticks run at a target tick, after a block or
+// one next to it changed. Blocks react to both as the tick behaviors of their
+// definitions say: grass spreads onto dirt, crops and saplings grow, leaves decay.
+package ticks
+
+import (
+	"io"
+	"math/rand"
+
+	"github.com/wubinrui111/2d-game/internal/blocks"
+	"github.com/wubinrui111/2d-game/internal/grid"
+)
+
+// ChunkSize is the width and height of a chunk in cells, the same as a render chunk
+const ChunkSize = 16
+
+// DefaultRandomSpeed is how many random ticks each chunk gets per game tick
+const DefaultRandomSpeed = 3
+
+// World is the world whose blocks tick
+type World interface {
+	Block(x, y int) string
+	SetBlock(x, y int, id string)
+
+	// LightLevel returns the light level of a cell, 0 to lighting.MaxLevel
+	LightLevel(x, y int) int
+
+	// PlaceStructure places the schematic with the asset key at a cell, replacing the
+	// block there; it returns false and places nothing if the structure does not fit
+	PlaceStructure(key string, x, y int) bool
+}
+
+// Chunk is a ChunkSize x ChunkSize square of cells
+type Chunk struct {
+	X, Y int
+}
+
+// ChunkOf returns the chunk containing a cell
+func ChunkOf(x, y int) Chunk {
+	return Chunk{grid.FloorDiv(x, ChunkSize), grid.FloorDiv(y, ChunkSize)}
+}
+
+// ChunksAround returns the chunks at most radius chunks from the chunk containing a
+// cell, row by row
+func ChunksAround(x, y, radius int) []Chunk {
+	center := ChunkOf(x, y)
+	chunks := make([]Chunk, 0, (2*radius+1)*(2*radius+1))
+	for cy := center.Y - radius; cy <= center.Y+radius; cy++ {
+		for cx := center.X - radius; cx <= center.X+radius; cx++ {
+			chunks = append(chunks, Chunk{cx, cy})
+		}
+	}
+	return chunks
+}
+
+// Sim runs the block ticks of a world
+type Sim struct {
+	World  World
+	Blocks *blocks.Registry // Definitions with the tick behaviors
+
+	// RandomSpeed is how many random ticks each chunk gets per game tick
+	RandomSpeed int
+
+	rng       *rand.Rand
+	scheduled *grid.Scheduler // Cells with a scheduled tick
+}
+
+// New creates the block ticks of world with the definitions of registry. rng picks
+// the cells of random ticks and decides their chances, so the same seed gives the
+// same changes.
+func New(world World, registry *blocks.Registry, rng *rand.Rand) *Sim {
+	return &Sim{
+		World:       world,
+		Blocks:      registry,
+		RandomSpeed: DefaultRandomSpeed,
+		rng:         rng,
+		scheduled:   grid.NewScheduler(),
+	}
+}
+
+// Tick returns the number of the current tick
+func (s *Sim) Tick() int64 {
+	return s.scheduled.Tick()
+}
+
+// Pending returns how many cells have a scheduled tick
+func (s *Sim) Pending() int {
+	return s.scheduled.Pending()
+}
+
+// Schedule makes a cell tick after delay ticks, unless it ticks earlier already
+func (s *Sim) Schedule(x, y, delay int) {
+	s.scheduled.Schedule(grid.Cell{X: x, Y: y}, delay)
+}
+
+// ScheduleAt makes a cell tick at a target tick, unless it ticks earlier already.
+// Targets that have passed tick on the next update.
+func (s *Sim) ScheduleAt(x, y int, tick int64) {
+	s.scheduled.ScheduleAt(grid.Cell{X: x, Y: y}, tick)
+}
+
+// Changed tells the ticks that the block at a cell changed: the cell and the cells
+// next to it whose blocks have a delay get a scheduled tick
+func (s *Sim) Changed(x, y int) {
+	c := grid.Cell{X: x, Y: y}
+	neighbors := c.Neighbors()
+	for _, n := range append(neighbors[:], c) {
+		if b := s.behavior(s.World.Block(n.X, n.Y)); b != nil && b.Delay > 0 {
+			s.Schedule(n.X, n.Y, b.Delay)
+		}
+	}
+}
+
+// Update advances one tick: it runs the scheduled ticks due at it, then RandomSpeed
+// random ticks in each of chunks
+func (s *Sim) Update(chunks []Chunk) {
+	for _, c := range s.scheduled.Advance() {
+		s.apply(c, false)
+	}
+
+	for _, chunk := range chunks {
+		for i := 0; i < s.RandomSpeed; i++ {
+			c := grid.Cell{X: chunk.X*ChunkSize + s.rng.Intn(ChunkSize), Y: chunk.Y*ChunkSize + s.rng.Intn(ChunkSize)}
+			s.apply(c, true)
+		}
+	}
+}
+
+// apply changes the block at a cell as its behavior says. A random tick changes it
+// only with the behavior's chance.
+func (s *Sim) apply(c grid.Cell, random bool) {
+	id := s.World.Block(c.X, c.Y)
+	b := s.behavior(id)
+	if b == nil || random && (b.Chance == 0 || s.rng.Float64() >= b.Chance) {
+		return
+	}
+
+	// 被不透明方块盖住，例如草方块变回泥土
+	if b.Covered != "" && s.covered(c) {
+		s.World.SetBlock(c.X, c.Y, b.Covered)
+		return
+	}
+	// 附近没有需要的方块时消失，例如树叶离开原木
+	if b.DecayWithout != "" && !s.near(c, b.DecayWithout, b.DecayRange) {
+		s.World.SetBlock(c.X, c.Y, "")
+		return
+	}
+	if s.World.LightLevel(c.X, c.Y-1) < b.MinLight {
+		return
+	}
+
+	switch {
+	case b.Spread != "":
+		// 蔓延到周围随机一格上方有光照的方块
+		target := grid.Cell{X: c.X + s.rng.Intn(3) - 1, Y: c.Y + s.rng.Intn(3) - 1}
+		if target != c && s.World.Block(target.X, target.Y) == b.Spread && !s.covered(target) &&
+			s.World.LightLevel(target.X, target.Y-1) >= b.MinLight {
+			s.World.SetBlock(target.X, target.Y, id)
+		}
+	case b.Structure != "":
+		s.World.PlaceStructure(b.Structure, c.X, c.Y)
+	case b.Grow != "":
+		s.World.SetBlock(c.X, c.Y, b.Grow)
+	}
+}
+
+// behavior returns the tick behavior of a block, nil if it has none
+func (s *Sim) behavior(id string) *blocks.Behavior {
+	if id == "" || s.Blocks == nil {
+		return nil
+	}
+	if def, exists := s.Blocks.Lookup(id); exists {
+		return def.Ticks
+	}
+	return nil
+}
+
+// covered reports whether the block above a cell keeps the light off it: any block
+// that is not transparent
+func (s *Sim) covered(c grid.Cell) bool {
+	above := s.World.Block(c.X, c.Y-1)
+	if above == "" {
+		return false
+	}
+	def, exists := s.Blocks.Lookup(above)
+	return !exists || !def.Transparent
+}
+
+// near reports whether a block id is at most r cells from a cell in both directions
+func (s *Sim) near(c grid.Cell, id string, r int) bool {
+	for y := c.Y - r; y <= c.Y+r; y++ {
+		for x := c.X - r; x <= c.X+r; x++ {
+			if s.World.Block(x, y) == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Hash writes the state of the scheduled ticks to w in a fixed order: the tick and
+// the cells waiting for theirs, so two runs can be compared. The random ticks depend
+// on the random source, which the caller compares.
+func (s *Sim) Hash(w io.Writer) {
+	s.scheduled.Hash(w)
+}
@@ -0,0 +1,221 @@
+package ticks
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"testing"
+
+	"github.com/wubinrui111/2d-game/internal/blocks"
+	"github.com/wubinrui111/2d-game/internal/grid"
+	"github.com/wubinrui111/2d-game/internal/grid/gridtest"
+)
+
+// testBlocks are the definitions of the tests, like the game's
+const testBlocks = `{"blocks": [
+	{"id": "stone", "color": "#808080"},
+	{"id": "dirt", "color": "#643200"},
+	{"id": "glass", "color": "#ffffff40", "transparent": true},
+	{"id": "grass", "color": "#4ca03c", "ticks": {"chance": 1, "min_light": 9, "spread": "dirt", "covered": "dirt"}},
+	{"id": "wheat", "color": "#80a030", "ticks": {"chance": 1, "min_light": 9, "grow": "wheat_ripe"}},
+	{"id": "wheat_ripe", "color": "#d0b040"},
+	{"id": "sapling", "color": "#30a030", "ticks": {"chance": 1, "min_light": 9, "structure": "tree"}},
+	{"id": "wood", "color": "#64461e"},
+	{"id": "leaves", "color": "#308020", "transparent": true, "ticks": {"chance": 0.5, "delay": 10, "decay_without": "wood", "decay_range": 2}}
+]}`
+
+// gridWorld is a test world, lit everywhere except the dark cells
+type gridWorld struct {
+	*gridtest.World
+	dark       map[grid.Cell]bool
+	structures []grid.Cell
+	sim        *Sim
+}
+
+func newGridWorld(t *testing.T) *gridWorld {
+	t.Helper()
+	registry, err := blocks.Parse([]byte(testBlocks))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &gridWorld{World: gridtest.New(), dark: make(map[grid.Cell]bool)}
+	w.sim = New(w, registry, rand.New(rand.NewSource(1)))
+	w.Changed = w.sim.Changed
+	return w
+}
+
+func (w *gridWorld) LightLevel(x, y int) int {
+	if w.dark[grid.Cell{X: x, Y: y}] {
+		return 0
+	}
+	return 15
+}
+
+// PlaceStructure grows a one cell wide tree: a log replacing the sapling
+func (w *gridWorld) PlaceStructure(key string, x, y int) bool {
+	if key != "tree" {
+		return false
+	}
+	w.structures = append(w.structures, grid.Cell{X: x, Y: y})
+	w.SetBlock(x, y, "wood")
+	return true
+}
+
+// tickAll gives every cell of a w x h area at the origin a random tick n times
+func (w *gridWorld) tickAll(width, height, n int) {
+	for i := 0; i < n; i++ {
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				w.sim.apply(grid.Cell{X: x, Y: y}, true)
+			}
+		}
+	}
+}
+
+func TestGrassSpreadsOntoLitDirt(t *testing.T) {
+	w := newGridWorld(t)
+	for x := 0; x < 5; x++ {
+		w.SetBlock(x, 1, "dirt")
+	}
+	w.SetBlock(0, 1, "grass")
+	w.SetBlock(3, 0, "stone")            // 盖住的泥土
+	w.dark[grid.Cell{X: 4, Y: 0}] = true // 上方黑暗的泥土
+
+	w.tickAll(5, 2, 50)
+	want := []string{"grass", "grass", "grass", "dirt", "dirt"}
+	for x, id := range want {
+		if got := w.Block(x, 1); got != id {
+			t.Errorf("Expected %s at x=%d, got %s", id, x, got)
+		}
+	}
+
+	// 被不透明方块盖住的草变回泥土，透明方块不影响
+	w.SetBlock(1, 0, "stone")
+	w.SetBlock(2, 0, "glass")
+	w.tickAll(5, 2, 1)
+	if w.Block(1, 1) != "dirt" || w.Block(2, 1) != "grass" {
+		t.Errorf("Expected covered grass to turn into dirt, got %s and %s", w.Block(1, 1), w.Block(2, 1))
+	}
+}
+
+func TestGrowth(t *testing.T) {
+	w := newGridWorld(t)
+	w.SetBlock(0, 0, "wheat")
+	w.SetBlock(1, 0, "wheat")
+	w.dark[grid.Cell{X: 1, Y: -1}] = true
+	w.SetBlock(2, 0, "sapling")
+
+	w.tickAll(3, 1, 1)
+	if w.Block(0, 0) != "wheat_ripe" {
+		t.Errorf("Expected the wheat to grow, got %s", w.Block(0, 0))
+	}
+	if w.Block(1, 0) != "wheat" {
+		t.Errorf("Expected wheat in the dark not to grow, got %s", w.Block(1, 0))
+	}
+	if len(w.structures) != 1 || w.structures[0] != (grid.Cell{X: 2, Y: 0}) || w.Block(2, 0) != "wood" {
+		t.Errorf("Expected the sapling to grow into a tree, got %v", w.structures)
+	}
+}
+
+func TestLeavesDecayWithoutLog(t *testing.T) {
+	w := newGridWorld(t)
+	w.SetBlock(0, 0, "wood")
+	for x := 1; x <= 4; x++ {
+		w.SetBlock(x, 0, "leaves")
+	}
+	for i := 0; i < 20; i++ {
+		w.sim.Update(nil)
+	}
+	if w.Block(1, 0) != "leaves" || w.Block(2, 0) != "leaves" {
+		t.Errorf("Expected leaves next to the log to stay, got %s %s", w.Block(1, 0), w.Block(2, 0))
+	}
+	if w.Block(3, 0) != "" || w.Block(4, 0) != "" {
+		t.Errorf("Expected leaves away from the log to decay, got %s %s", w.Block(3, 0), w.Block(4, 0))
+	}
+
+	// 移除原木后，剩下的树叶按计划依次消失
+	w.SetBlock(0, 0, "")
+	if w.sim.Pending() != 1 {
+		t.Fatalf("Expected a scheduled tick for the leaves next to the log, got %d", w.sim.Pending())
+	}
+	for i := 0; i < 9; i++ {
+		w.sim.Update(nil)
+	}
+	if w.Block(1, 0) != "leaves" {
+		t.Fatal("Expected the leaves to wait for their delay")
+	}
+	for i := 0; i < 20 && w.sim.Pending() > 0; i++ {
+		w.sim.Update(nil)
+	}
+	if w.Block(1, 0) != "" || w.Block(2, 0) != "" {
+		t.Errorf("Expected all leaves to decay, got %s %s", w.Block(1, 0), w.Block(2, 0))
+	}
+}
+
+func TestRandomTicksPerChunk(t *testing.T) {
+	w := newGridWorld(t)
+	for y := 0; y < ChunkSize; y++ {
+		for x := 0; x < ChunkSize; x++ {
+			w.Cells[grid.Cell{X: x, Y: y}] = "wheat"
+		}
+	}
+	w.sim.RandomSpeed = 5
+	w.sim.Update([]Chunk{{0, 0}})
+	grown := 0
+	for _, id := range w.Cells {
+		if id == "wheat_ripe" {
+			grown++
+		}
+	}
+	if grown == 0 || grown > 5 {
+		t.Errorf("Expected 1 to 5 random ticks in the chunk, got %d", grown)
+	}
+
+	// 其他区块的随机刻不影响这个区块
+	before := len(w.Cells)
+	w.sim.Update([]Chunk{{1, 0}, {-1, -1}})
+	if len(w.Cells) != before {
+		t.Error("Expected empty chunks not to change")
+	}
+}
+
+func TestChunks(t *testing.T) {
+	if c := ChunkOf(-1, 16); c != (Chunk{-1, 1}) {
+		t.Errorf("Expected chunk (-1, 1), got %v", c)
+	}
+	chunks := ChunksAround(0, 0, 1)
+	if len(chunks) != 9 || chunks[0] != (Chunk{-1, -1}) || chunks[8] != (Chunk{1, 1}) {
+		t.Errorf("Unexpected chunks around the origin %v", chunks)
+	}
+}
+
+func TestHash(t *testing.T) {
+	hash := func(w *gridWorld) uint64 {
+		h := fnv.New64a()
+		w.sim.Hash(h)
+		return h.Sum64()
+	}
+	a, b := newGridWorld(t), newGridWorld(t)
+	a.sim.Schedule(0, 0, 3)
+	a.sim.Schedule(1, 0, 3)
+	b.sim.Schedule(1, 0, 3)
+	b.sim.Schedule(0, 0, 3)
+	if hash(a) == hash(b) {
+		t.Error("Expected the order of ticks due together to change the hash")
+	}
+
+	// 改到更早时刻的计划只算一次
+	b = newGridWorld(t)
+	b.sim.Schedule(0, 0, 8)
+	b.sim.Schedule(0, 0, 5)
+	b.sim.Schedule(1, 0, 3)
+	a = newGridWorld(t)
+	a.sim.Schedule(1, 0, 3)
+	a.sim.Schedule(0, 0, 5)
+	if hash(a) != hash(b) {
+		t.Error("Expected the same scheduled ticks to have the same hash")
+	}
+	a.sim.Update(nil)
+	if hash(a) == hash(b) {
+		t.Error("Expected a tick to change the hash")
+	}
+}
@@ -0,0 +1,138 @@
+package tiled
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxTiles is the most tiles a tile layer or chunk may hold, checked before the
+// tiles are allocated so a corrupt or hostile map cannot take all the memory
+const maxTiles = 1 << 24
+
+// tileCount checks the size of a tile layer or chunk and returns how many tiles it holds
+func tileCount(width, height int) (int, error) {
+	if width <= 0 || height <= 0 {
+		return 0, fmt.Errorf("tiled: invalid layer size %dx%d", width, height)
+	}
+	if width > maxTiles/height {
+		return 0, fmt.Errorf("tiled: layer of %dx%d tiles is larger than %d tiles", width, height, maxTiles)
+	}
+	return width * height, nil
+}
+
+// decodeCSV parses the CSV encoding of tile layer data
+func decodeCSV(text string, n int) ([]uint32, error) {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r' || r == ' ' || r == '\t'
+	})
+	if len(fields) != n {
+		return nil, fmt.Errorf("tiled: layer data has %d tiles, expected %d", len(fields), n)
+	}
+	gids := make([]uint32, n)
+	for i, field := range fields {
+		gid, err := strconv.ParseUint(field, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("tiled: invalid tile %q in layer data", field)
+		}
+		gids[i] = uint32(gid)
+	}
+	return gids, nil
+}
+
+// decodeBase64 parses the base64 encoding of tile layer data: little-endian GIDs,
+// optionally compressed with zlib or gzip
+func decodeBase64(text, compression string, n int) ([]uint32, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+	if err != nil {
+		return nil, fmt.Errorf("tiled: invalid base64 layer data: %w", err)
+	}
+
+	var r io.Reader
+	switch compression {
+	case "":
+		r = bytes.NewReader(data)
+	case "zlib":
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	case "gzip":
+		gr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close()
+		r = gr
+	default:
+		return nil, fmt.Errorf("tiled: layer compression %q is not supported", compression)
+	}
+
+	gids := make([]uint32, n)
+	if err := binary.Read(r, binary.LittleEndian, gids); err != nil {
+		return nil, fmt.Errorf("tiled: layer data is shorter than %d tiles", n)
+	}
+	return gids, nil
+}
+
+// encodeCSV writes tile layer data as CSV, one row per line
+func encodeCSV(gids []uint32, width int) string {
+	var sb strings.Builder
+	sb.WriteByte('\n')
+	for i, gid := range gids {
+		sb.WriteString(strconv.FormatUint(uint64(gid), 10))
+		if i < len(gids)-1 {
+			sb.WriteByte(',')
+		}
+		if (i+1)%width == 0 {
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+// chunk is a part of a tile layer of an infinite map
+type chunk struct {
+	x, y, width, height int
+	data                []uint32
+}
+
+// mergeChunks copies the chunks of an infinite map's layer into one rectangle covering
+// all of them. The rectangle is limited to maxTiles like a single layer.
+func mergeChunks(layer *Layer, chunks []chunk) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+	minX, minY := chunks[0].x, chunks[0].y
+	maxX, maxY := minX+chunks[0].width, minY+chunks[0].height
+	for _, c := range chunks {
+		// 坐标限制在图块数量上限以内，计算范围时不会溢出
+		if c.x < -maxTiles || c.x > maxTiles || c.y < -maxTiles || c.y > maxTiles {
+			return fmt.Errorf("tiled: chunk %d,%d is too far from the origin", c.x, c.y)
+		}
+		minX, minY = min(minX, c.x), min(minY, c.y)
+		maxX, maxY = max(maxX, c.x+c.width), max(maxY, c.y+c.height)
+	}
+
+	n, err := tileCount(maxX-minX, maxY-minY)
+	if err != nil {
+		return err
+	}
+	layer.X, layer.Y, layer.Width, layer.Height = minX, minY, maxX-minX, maxY-minY
+	layer.Data = make([]uint32, n)
+	for _, c := range chunks {
+		for row := 0; row < c.height; row++ {
+			start := (c.y-minY+row)*layer.Width + c.x - minX
+			copy(layer.Data[start:start+c.width], c.data[row*c.width:(row+1)*c.width])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package tiled
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/wubinrui111/2d-game/internal/blocks"
+)
+
+// Names the game gives meaning to in Tiled maps
+const (
+	// BlockProperty is the tileset tile property naming the block the tile places,
+	// an ID or alias in the block registry
+	BlockProperty = "block"
+
+	// OriginXProperty and OriginYProperty are map properties: the world cell of the
+	// map's top-left tile, 0 when not set
+	OriginXProperty = "originX"
+	OriginYProperty = "originY"
+
+	// SpawnClass is the class (type) of the object marking the player's spawn point
+	SpawnClass = "spawn"
+
+	// exportTileset is the name of the tileset written by Export
+	exportTileset = "blocks"
+)
+
+// Block is a block of a level at a world cell
+type Block struct {
+	X, Y int
+	ID   string
+}
+
+// Entity is an object of a level, in world cells
+type Entity struct {
+	Name          string
+	Class         string
+	X, Y          float64
+	Width, Height float64
+	Point         bool
+	Properties    Properties
+}
+
+// Level is a world layout: blocks, the player's spawn point and other entities
+type Level struct {
+	Blocks     []Block // Sorted by row, then column
+	Spawn      *Entity // nil if the map has no spawn point
+	Entities   []*Entity
+	Properties Properties // Map properties, without the origin
+}
+
+// Import converts a map to a level. Tiles of visible tile layers become blocks through
+// the BlockProperty of their tileset tile; later layers cover earlier ones. Objects of
+// visible object layers become entities, except the SpawnClass object. Block IDs are
+// checked against defs, and aliases replaced by IDs, unless defs is nil.
+func Import(m *Map, defs *blocks.Registry) (*Level, error) {
+	originX, err := m.Properties.Int(OriginXProperty, 0)
+	if err != nil {
+		return nil, err
+	}
+	originY, err := m.Properties.Int(OriginYProperty, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	level := &Level{}
+	for _, p := range m.Properties {
+		if p.Name != OriginXProperty && p.Name != OriginYProperty {
+			level.Properties = append(level.Properties, p)
+		}
+	}
+
+	cells := make(map[[2]int]string)
+	ids := make(map[uint32]string)
+	for _, layer := range m.Layers {
+		if !layer.Visible {
+			continue
+		}
+		switch layer.Type {
+		case TileLayer:
+			for i, gid := range layer.Data {
+				gid &= gidMask
+				if gid == 0 {
+					continue
+				}
+				id, exists := ids[gid]
+				if !exists {
+					if id, err = blockID(m, gid, defs); err != nil {
+						return nil, fmt.Errorf("tiled: layer %q: %w", layer.Name, err)
+					}
+					ids[gid] = id
+				}
+				x := originX + layer.X + i%layer.Width
+				y := originY + layer.Y + i/layer.Width
+				cells[[2]int{x, y}] = id
+			}
+		case ObjectLayer:
+			for _, obj := range layer.Objects {
+				entity := &Entity{
+					Name:       obj.Name,
+					Class:      obj.Class,
+					X:          float64(originX) + obj.X/float64(m.TileWidth),
+					Y:          float64(originY) + obj.Y/float64(m.TileHeight),
+					Width:      obj.Width / float64(m.TileWidth),
+					Height:     obj.Height / float64(m.TileHeight),
+					Point:      obj.Point,
+					Properties: obj.Properties,
+				}
+				if obj.Class != SpawnClass {
+					level.Entities = append(level.Entities, entity)
+					continue
+				}
+				if level.Spawn != nil {
+					return nil, fmt.Errorf("tiled: map has more than one %s object", SpawnClass)
+				}
+				level.Spawn = entity
+			}
+		}
+	}
+
+	for cell, id := range cells {
+		level.Blocks = append(level.Blocks, Block{X: cell[0], Y: cell[1], ID: id})
+	}
+	sortBlocks(level.Blocks)
+	return level, nil
+}
+
+// blockID returns the block a tile places
+func blockID(m *Map, gid uint32, defs *blocks.Registry) (string, error) {
+	ts, local, exists := m.TileAt(gid)
+	if !exists {
+		return "", fmt.Errorf("tile %d is in no tileset", gid)
+	}
+	var id string
+	if tile := ts.Tile(local); tile != nil {
+		id, _ = tile.Properties.Get(BlockProperty)
+	}
+	if id == "" {
+		return "", fmt.Errorf("tile %d of tileset %q has no %q property", local, ts.Name, BlockProperty)
+	}
+	if defs == nil {
+		return id, nil
+	}
+	def, exists := defs.Lookup(id)
+	if !exists {
+		return "", fmt.Errorf("tile %d of tileset %q names unknown block %q", local, ts.Name, id)
+	}
+	return def.ID, nil
+}
+
+// sortBlocks sorts blocks by row, then column
+func sortBlocks(list []Block) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Y != list[j].Y {
+			return list[i].Y < list[j].Y
+		}
+		return list[i].X < list[j].X
+	})
+}
+
+// Export converts a level to a fixed-size map with tiles of tileWidth x tileHeight pixels.
+// The map covers the level's blocks and entities; its origin is stored in map properties.
+// Blocks use a tileset without images, one tile per block ID with a BlockProperty, so
+// the map can be edited in Tiled and imported again.
+func Export(level *Level, tileWidth, tileHeight int) *Map {
+	minX, minY, maxX, maxY := level.bounds()
+
+	m := &Map{
+		Orientation: "orthogonal",
+		Width:       maxX - minX,
+		Height:      maxY - minY,
+		TileWidth:   tileWidth,
+		TileHeight:  tileHeight,
+		Properties:  append(Properties(nil), level.Properties...),
+	}
+	m.Properties.Set(OriginXProperty, "int", strconv.Itoa(minX))
+	m.Properties.Set(OriginYProperty, "int", strconv.Itoa(minY))
+
+	// 每种方块一个图块，按 ID 排序
+	var ids []string
+	gids := make(map[string]uint32)
+	for _, block := range level.Blocks {
+		if _, exists := gids[block.ID]; !exists {
+			gids[block.ID] = 0
+			ids = append(ids, block.ID)
+		}
+	}
+	sort.Strings(ids)
+	tileset := &Tileset{FirstGID: 1, Name: exportTileset, TileWidth: tileWidth, TileHeight: tileHeight, TileCount: len(ids)}
+	for i, id := range ids {
+		gids[id] = uint32(tileset.FirstGID + i)
+		tileset.Tiles = append(tileset.Tiles, &Tile{ID: i, Properties: Properties{{Name: BlockProperty, Type: "string", Value: id}}})
+	}
+	m.Tilesets = []*Tileset{tileset}
+
+	tiles := &Layer{Name: "blocks", Type: TileLayer, Visible: true, Width: m.Width, Height: m.Height}
+	tiles.Data = make([]uint32, m.Width*m.Height)
+	for _, block := range level.Blocks {
+		tiles.Data[(block.Y-minY)*m.Width+block.X-minX] = gids[block.ID]
+	}
+
+	objects := &Layer{Name: "objects", Type: ObjectLayer, Visible: true}
+	entities := level.Entities
+	if level.Spawn != nil {
+		entities = append([]*Entity{level.Spawn}, entities...)
+	}
+	for i, e := range entities {
+		objects.Objects = append(objects.Objects, &Object{
+			ID:         i + 1,
+			Name:       e.Name,
+			Class:      e.Class,
+			X:          (e.X - float64(minX)) * float64(tileWidth),
+			Y:          (e.Y - float64(minY)) * float64(tileHeight),
+			Width:      e.Width * float64(tileWidth),
+			Height:     e.Height * float64(tileHeight),
+			Point:      e.Point,
+			Properties: e.Properties,
+		})
+	}
+	m.Layers = []*Layer{tiles, objects}
+	return m
+}
+
+// bounds returns the cells covered by the level's blocks and entities, at least one cell
+func (l *Level) bounds() (minX, minY, maxX, maxY int) {
+	minX, minY = math.MaxInt, math.MaxInt
+	maxX, maxY = math.MinInt, math.MinInt
+	include := func(x0, y0, x1, y1 int) {
+		minX, minY = min(minX, x0), min(minY, y0)
+		maxX, maxY = max(maxX, x1), max(maxY, y1)
+	}
+	for _, block := range l.Blocks {
+		include(block.X, block.Y, block.X+1, block.Y+1)
+	}
+	entities := l.Entities
+	if l.Spawn != nil {
+		entities = append([]*Entity{l.Spawn}, entities...)
+	}
+	for _, e := range entities {
+		x, y := int(math.Floor(e.X)), int(math.Floor(e.Y))
+		include(x, y, max(x+1, int(math.Ceil(e.X+e.Width))), max(y+1, int(math.Ceil(e.Y+e.Height))))
+	}
+	if minX > maxX {
+		return 0, 0, 1, 1
+	}
+	return minX, minY, maxX, maxY
+}
@@ -0,0 +1,184 @@
+package tiled
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/wubinrui111/2d-game/internal/blocks"
+)
+
+// testRegistry returns block definitions for stone and dirt, with the alias Dirt
+func testRegistry(t *testing.T) *blocks.Registry {
+	t.Helper()
+	defs, err := blocks.Parse([]byte(`{"blocks": [
+		{"id": "stone", "name": "Stone", "color": "#808080"},
+		{"id": "dirt", "name": "Dirt", "color": "#643200", "aliases": ["Dirt"]}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return defs
+}
+
+func TestImport(t *testing.T) {
+	data := `<map orientation="orthogonal" width="3" height="2" tilewidth="16" tileheight="16">
+	 <properties>
+	  <property name="originX" type="int" value="-10"/>
+	  <property name="originY" type="int" value="4"/>
+	  <property name="title" value="cave"/>
+	 </properties>
+	 <tileset firstgid="1" name="ground" tilewidth="16" tileheight="16" tilecount="2" columns="2">
+	  <tile id="0"><properties><property name="block" value="stone"/></properties></tile>
+	  <tile id="1"><properties><property name="block" value="Dirt"/></properties></tile>
+	 </tileset>
+	 <layer name="base" width="3" height="2"><data encoding="csv">1,1,0,0,0,2147483649</data></layer>
+	 <layer name="top" width="3" height="2"><data encoding="csv">0,2,0,0,0,0</data></layer>
+	 <layer name="off" width="3" height="2" visible="0"><data encoding="csv">2,2,2,2,2,2</data></layer>
+	 <objectgroup name="objects">
+	  <object id="1" type="spawn" x="8" y="16"><point/></object>
+	  <object id="2" name="drop" type="item" x="32" y="0" width="16" height="8">
+	   <properties><property name="item" value="dirt"/></properties>
+	  </object>
+	 </objectgroup>
+	</map>`
+	m, err := DecodeTMX([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	level, err := Import(m, testRegistry(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 上层覆盖下层，别名换成 ID，翻转的图块也算
+	want := []Block{{X: -10, Y: 4, ID: "stone"}, {X: -9, Y: 4, ID: "dirt"}, {X: -8, Y: 5, ID: "stone"}}
+	if !reflect.DeepEqual(level.Blocks, want) {
+		t.Errorf("Expected blocks %v, got %v", want, level.Blocks)
+	}
+	if level.Spawn == nil || level.Spawn.X != -9.5 || level.Spawn.Y != 5 || !level.Spawn.Point {
+		t.Errorf("Unexpected spawn %+v", level.Spawn)
+	}
+	if len(level.Entities) != 1 {
+		t.Fatalf("Expected 1 entity, got %d", len(level.Entities))
+	}
+	if drop := level.Entities[0]; drop.Class != "item" || drop.X != -8 || drop.Y != 4 || drop.Width != 1 || drop.Height != 0.5 {
+		t.Errorf("Unexpected entity %+v", drop)
+	} else if item, _ := drop.Properties.Get("item"); item != "dirt" {
+		t.Errorf("Expected the entity's properties, got %+v", drop.Properties)
+	}
+	if want := (Properties{{Name: "title", Type: "string", Value: "cave"}}); !reflect.DeepEqual(level.Properties, want) {
+		t.Errorf("Expected the origin to be removed from the properties, got %+v", level.Properties)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	header := `<map orientation="orthogonal" width="1" height="1" tilewidth="8" tileheight="8">
+	 <tileset firstgid="1" name="t" tilewidth="8" tileheight="8" tilecount="2" columns="2">
+	  <tile id="0"><properties><property name="block" value="lava"/></properties></tile>
+	 </tileset>`
+	tests := map[string]string{
+		"unknown block": header + `<layer name="l" width="1" height="1"><data encoding="csv">1</data></layer></map>`,
+		"no property":   header + `<layer name="l" width="1" height="1"><data encoding="csv">2</data></layer></map>`,
+		"no tileset":    `<map width="1" height="1" tilewidth="8" tileheight="8"><layer name="l" width="1" height="1"><data encoding="csv">1</data></layer></map>`,
+		"two spawns":    header + `<objectgroup name="o"><object id="1" type="spawn" x="0" y="0"/><object id="2" type="spawn" x="0" y="0"/></objectgroup></map>`,
+		"bad origin":    `<map width="1" height="1" tilewidth="8" tileheight="8"><properties><property name="originX" value="left"/></properties></map>`,
+	}
+	for name, data := range tests {
+		m, err := DecodeTMX([]byte(data))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if _, err := Import(m, testRegistry(t)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// testLevel returns a level using every feature Export writes
+func testLevel() *Level {
+	return &Level{
+		Blocks: []Block{
+			{X: -3, Y: -2, ID: "stone"},
+			{X: 4, Y: -2, ID: "dirt"},
+			{X: 0, Y: 7, ID: "stone"},
+		},
+		Spawn: &Entity{Name: "player", Class: SpawnClass, X: 1.5, Y: 2, Point: true},
+		Entities: []*Entity{
+			{Name: "drop", Class: "item", X: 6, Y: 9.25, Width: 1, Height: 0.5, Properties: Properties{
+				{Name: "item", Type: "string", Value: "dirt"},
+				{Name: "count", Type: "int", Value: "3"},
+			}},
+		},
+		Properties: Properties{{Name: "title", Type: "string", Value: "test"}, {Name: "dark", Type: "bool", Value: "false"}},
+	}
+}
+
+func TestLevelRoundTrip(t *testing.T) {
+	for _, name := range []string{"level.tmx", "level.tmj"} {
+		level := testLevel()
+		m := Export(level, 32, 32)
+		if m.Width != 10 || m.Height != 12 {
+			t.Errorf("%s: expected the map to cover 10x12 cells, got %dx%d", name, m.Width, m.Height)
+		}
+
+		data, err := Encode(name, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := Load(name, readFiles(map[string]string{name: string(data)}))
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", name, err, data)
+		}
+		imported, err := Import(decoded, testRegistry(t))
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", name, err, data)
+		}
+		if !reflect.DeepEqual(imported, level) {
+			t.Errorf("%s: round trip changed the level:\n%s", name, data)
+		}
+	}
+}
+
+func TestExportEmptyLevel(t *testing.T) {
+	m := Export(&Level{}, 16, 16)
+	if m.Width != 1 || m.Height != 1 || len(m.Layers[0].Data) != 1 {
+		t.Errorf("Expected a 1x1 map, got %dx%d", m.Width, m.Height)
+	}
+	level, err := Import(m, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(level.Blocks) != 0 || level.Spawn != nil || len(level.Entities) != 0 {
+		t.Errorf("Expected an empty level, got %+v", level)
+	}
+}
+
+func TestDefaultLevel(t *testing.T) {
+	dir := filepath.Join("..", "..", "assets")
+	read := func(name string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	}
+	data, err := read(blocks.DefaultKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defs, err := blocks.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := Load(path.Join("levels", "start.tmx"), read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	level, err := Import(m, defs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(level.Blocks) == 0 || level.Spawn == nil {
+		t.Errorf("Expected blocks and a spawn point, got %d blocks and spawn %v", len(level.Blocks), level.Spawn)
+	}
+}
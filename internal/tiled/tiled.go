@@ -0,0 +1,251 @@
+// Package tiled reads and writes maps made with the Tiled map editor (https://www.mapeditor.org),
+// in both its XML (.tmx) and JSON (.tmj) formats, and converts them to and from game levels.
+// Only orthogonal maps are supported; image layers are ignored and layer groups are flattened.
+package tiled
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+)
+
+// Global tile IDs (GIDs) carry flip flags in their highest bits
+const (
+	FlippedHorizontally uint32 = 0x80000000
+	FlippedVertically   uint32 = 0x40000000
+	FlippedDiagonally   uint32 = 0x20000000
+	RotatedHexagonal120 uint32 = 0x10000000
+
+	// gidMask clears the flip flags
+	gidMask = ^(FlippedHorizontally | FlippedVertically | FlippedDiagonally | RotatedHexagonal120)
+)
+
+// Layer types, as Tiled names them in .tmj files
+const (
+	TileLayer   = "tilelayer"
+	ObjectLayer = "objectgroup"
+)
+
+// Map is a Tiled map. Tile layers of infinite maps are stored like those of fixed-size
+// maps, covering the bounding box of their chunks.
+type Map struct {
+	Orientation           string
+	Width, Height         int // In tiles
+	TileWidth, TileHeight int // In pixels
+	Infinite              bool
+	Properties            Properties
+	Tilesets              []*Tileset
+	Layers                []*Layer // Bottom first; layers of groups are listed in place of the group
+}
+
+// Tileset maps a range of GIDs, starting at FirstGID, to tiles
+type Tileset struct {
+	FirstGID int
+
+	// Source is the file of an external tileset, relative to the map. The other fields
+	// are loaded from it.
+	Source string
+
+	Name                  string
+	TileWidth, TileHeight int
+	TileCount, Columns    int
+	Image                 string // Empty for tilesets made of single images
+	ImageWidth            int
+	ImageHeight           int
+	Properties            Properties
+	Tiles                 []*Tile // Tiles with a class or properties
+}
+
+// Tile holds the class and properties of a tile in a tileset
+type Tile struct {
+	ID         int // Local to the tileset
+	Class      string
+	Properties Properties
+}
+
+// Layer is a tile layer or an object layer
+type Layer struct {
+	Name       string
+	Type       string // TileLayer or ObjectLayer
+	Visible    bool
+	Properties Properties
+
+	// Tile layers: the area Data covers, in tiles. X and Y are not 0 only for infinite
+	// maps, whose chunks may lie anywhere.
+	X, Y, Width, Height int
+	Data                []uint32 // GIDs row by row, 0 for no tile
+
+	// Object layers
+	Objects []*Object
+}
+
+// Object is a shape or point placed on an object layer, in pixels
+type Object struct {
+	ID            int
+	Name          string
+	Class         string // Called "type" in most Tiled versions
+	X, Y          float64
+	Width, Height float64
+	Point         bool
+	Properties    Properties
+}
+
+// Property is a custom property. Values are kept as text: "true", "12", "#ff00ff00".
+type Property struct {
+	Name  string
+	Type  string // "string", "int", "float", "bool", "color", "file", "object" or "class"
+	Value string
+}
+
+// Properties is a list of custom properties
+type Properties []Property
+
+// Get returns the value of the property named name
+func (ps Properties) Get(name string) (string, bool) {
+	for _, p := range ps {
+		if p.Name == name {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
+// Int returns the value of the property named name as an integer, or def if there is none
+func (ps Properties) Int(name string, def int) (int, error) {
+	value, exists := ps.Get(name)
+	if !exists {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("tiled: property %s is not an integer: %q", name, value)
+	}
+	return n, nil
+}
+
+// Set adds a property or replaces the property with the same name
+func (ps *Properties) Set(name, kind, value string) {
+	for i := range *ps {
+		if (*ps)[i].Name == name {
+			(*ps)[i] = Property{Name: name, Type: kind, Value: value}
+			return
+		}
+	}
+	*ps = append(*ps, Property{Name: name, Type: kind, Value: value})
+}
+
+// TileAt returns the tileset and local tile ID of a GID, ignoring its flip flags
+func (m *Map) TileAt(gid uint32) (*Tileset, int, bool) {
+	gid &= gidMask
+	if gid == 0 {
+		return nil, 0, false
+	}
+	var found *Tileset
+	for _, ts := range m.Tilesets {
+		if ts.FirstGID <= int(gid) && (found == nil || ts.FirstGID > found.FirstGID) {
+			found = ts
+		}
+	}
+	if found == nil {
+		return nil, 0, false
+	}
+	return found, int(gid) - found.FirstGID, true
+}
+
+// Tile returns the tile with the local ID id, nil if it has no class or properties
+func (ts *Tileset) Tile(id int) *Tile {
+	for _, tile := range ts.Tiles {
+		if tile.ID == id {
+			return tile
+		}
+	}
+	return nil
+}
+
+// ReadFunc reads a file by its slash-separated path, such as assets.Manager.Data
+type ReadFunc func(name string) ([]byte, error)
+
+// Load reads a .tmx or .tmj map and its external tilesets with read
+func Load(name string, read ReadFunc) (*Map, error) {
+	data, err := read(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var m *Map
+	switch path.Ext(name) {
+	case ".tmx":
+		m, err = DecodeTMX(data)
+	case ".tmj", ".json":
+		m, err = DecodeTMJ(data)
+	default:
+		return nil, fmt.Errorf("tiled: %s is not a .tmx or .tmj map", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	// 外部图块集的路径相对于地图文件
+	for _, ts := range m.Tilesets {
+		if ts.Source == "" {
+			continue
+		}
+		if err := loadTileset(ts, path.Join(path.Dir(name), ts.Source), read); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Encode writes a map in the format of name's extension, .tmx or .tmj
+func Encode(name string, m *Map) ([]byte, error) {
+	switch path.Ext(name) {
+	case ".tmx":
+		return EncodeTMX(m)
+	case ".tmj", ".json":
+		return EncodeTMJ(m)
+	default:
+		return nil, fmt.Errorf("tiled: %s is not a .tmx or .tmj map", name)
+	}
+}
+
+// loadTileset fills an external tileset from its .tsx or .tsj file
+func loadTileset(ts *Tileset, name string, read ReadFunc) error {
+	data, err := read(name)
+	if err != nil {
+		return err
+	}
+	var loaded *Tileset
+	switch path.Ext(name) {
+	case ".tsx":
+		loaded, err = DecodeTSX(data)
+	case ".tsj", ".json":
+		loaded, err = DecodeTSJ(data)
+	default:
+		return fmt.Errorf("tiled: %s is not a .tsx or .tsj tileset", name)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	firstGID, source := ts.FirstGID, ts.Source
+	*ts = *loaded
+	ts.FirstGID, ts.Source = firstGID, source
+	return nil
+}
+
+// checkMap validates what the decoders cannot express in their types
+func checkMap(m *Map) error {
+	if m.Orientation != "" && m.Orientation != "orthogonal" {
+		return fmt.Errorf("tiled: %s maps are not supported", m.Orientation)
+	}
+	if m.TileWidth <= 0 || m.TileHeight <= 0 {
+		return fmt.Errorf("tiled: map needs a positive tile size")
+	}
+	for _, layer := range m.Layers {
+		if layer.Type == TileLayer && len(layer.Data) != layer.Width*layer.Height {
+			return fmt.Errorf("tiled: layer %q has %d tiles, expected %dx%d", layer.Name, len(layer.Data), layer.Width, layer.Height)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,474 @@
+package tiled
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// tmjMap is the root object of a .tmj file
+type tmjMap struct {
+	Type         string        `json:"type"`
+	Version      string        `json:"version"`
+	Orientation  string        `json:"orientation"`
+	RenderOrder  string        `json:"renderorder,omitempty"`
+	Width        int           `json:"width"`
+	Height       int           `json:"height"`
+	TileWidth    int           `json:"tilewidth"`
+	TileHeight   int           `json:"tileheight"`
+	Infinite     bool          `json:"infinite"`
+	NextLayerID  int           `json:"nextlayerid,omitempty"`
+	NextObjectID int           `json:"nextobjectid,omitempty"`
+	Properties   []tmjProperty `json:"properties,omitempty"`
+	Tilesets     []tmjTileset  `json:"tilesets"`
+	Layers       []tmjLayer    `json:"layers"`
+}
+
+type tmjProperty struct {
+	Name         string          `json:"name"`
+	Type         string          `json:"type,omitempty"`
+	PropertyType string          `json:"propertytype,omitempty"`
+	Value        json.RawMessage `json:"value"`
+}
+
+type tmjTileset struct {
+	Type        string        `json:"type,omitempty"` // "tileset" in .tsj files
+	FirstGID    int           `json:"firstgid,omitempty"`
+	Source      string        `json:"source,omitempty"`
+	Name        string        `json:"name,omitempty"`
+	TileWidth   int           `json:"tilewidth,omitempty"`
+	TileHeight  int           `json:"tileheight,omitempty"`
+	TileCount   int           `json:"tilecount,omitempty"`
+	Columns     *int          `json:"columns,omitempty"`
+	Image       string        `json:"image,omitempty"`
+	ImageWidth  int           `json:"imagewidth,omitempty"`
+	ImageHeight int           `json:"imageheight,omitempty"`
+	Properties  []tmjProperty `json:"properties,omitempty"`
+	Tiles       []tmjTile     `json:"tiles,omitempty"`
+}
+
+type tmjTile struct {
+	ID         int           `json:"id"`
+	Type       string        `json:"type,omitempty"`
+	Class      string        `json:"class,omitempty"` // Tiled 1.9
+	Properties []tmjProperty `json:"properties,omitempty"`
+}
+
+type tmjLayer struct {
+	ID          int             `json:"id,omitempty"`
+	Name        string          `json:"name"`
+	Type        string          `json:"type"`
+	Visible     *bool           `json:"visible,omitempty"`
+	Opacity     float64         `json:"opacity"`
+	X           int             `json:"x"`
+	Y           int             `json:"y"`
+	Width       int             `json:"width,omitempty"`
+	Height      int             `json:"height,omitempty"`
+	StartX      int             `json:"startx,omitempty"`
+	StartY      int             `json:"starty,omitempty"`
+	Encoding    string          `json:"encoding,omitempty"`
+	Compression string          `json:"compression,omitempty"`
+	Data        json.RawMessage `json:"data,omitempty"` // Array of GIDs or base64 text
+	Chunks      []tmjChunk      `json:"chunks,omitempty"`
+	DrawOrder   string          `json:"draworder,omitempty"`
+	Objects     []tmjObject     `json:"objects,omitempty"`
+	Layers      []tmjLayer      `json:"layers,omitempty"` // Children of a group
+	Properties  []tmjProperty   `json:"properties,omitempty"`
+}
+
+type tmjChunk struct {
+	X      int             `json:"x"`
+	Y      int             `json:"y"`
+	Width  int             `json:"width"`
+	Height int             `json:"height"`
+	Data   json.RawMessage `json:"data"`
+}
+
+type tmjObject struct {
+	ID         int           `json:"id"`
+	Name       string        `json:"name"`
+	Type       string        `json:"type"`
+	Class      string        `json:"class,omitempty"` // Tiled 1.9
+	GID        uint32        `json:"gid,omitempty"`
+	X          float64       `json:"x"`
+	Y          float64       `json:"y"`
+	Width      float64       `json:"width"`
+	Height     float64       `json:"height"`
+	Rotation   float64       `json:"rotation"`
+	Visible    bool          `json:"visible"`
+	Point      bool          `json:"point,omitempty"`
+	Properties []tmjProperty `json:"properties,omitempty"`
+}
+
+// DecodeTMJ parses a map in Tiled's JSON format
+func DecodeTMJ(data []byte) (*Map, error) {
+	var raw tmjMap
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if raw.Type != "" && raw.Type != "map" {
+		return nil, fmt.Errorf("tiled: expected a map, got %q", raw.Type)
+	}
+
+	m := &Map{
+		Orientation: raw.Orientation,
+		Width:       raw.Width,
+		Height:      raw.Height,
+		TileWidth:   raw.TileWidth,
+		TileHeight:  raw.TileHeight,
+		Infinite:    raw.Infinite,
+	}
+	var err error
+	if m.Properties, err = decodeTMJProperties(raw.Properties); err != nil {
+		return nil, err
+	}
+	for _, ts := range raw.Tilesets {
+		tileset, err := ts.decode()
+		if err != nil {
+			return nil, err
+		}
+		m.Tilesets = append(m.Tilesets, tileset)
+	}
+	if err := decodeTMJLayers(m, raw.Layers, true); err != nil {
+		return nil, err
+	}
+	if err := checkMap(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DecodeTSJ parses an external tileset in Tiled's JSON format
+func DecodeTSJ(data []byte) (*Tileset, error) {
+	var raw tmjTileset
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return raw.decode()
+}
+
+// decodeTMJLayers appends the layers to m, flattening groups. Layers in a hidden
+// group are hidden.
+func decodeTMJLayers(m *Map, layers []tmjLayer, visible bool) error {
+	for _, raw := range layers {
+		props, err := decodeTMJProperties(raw.Properties)
+		if err != nil {
+			return fmt.Errorf("tiled: layer %q: %w", raw.Name, err)
+		}
+		layer := &Layer{
+			Name:       raw.Name,
+			Type:       raw.Type,
+			Visible:    visible && (raw.Visible == nil || *raw.Visible),
+			Properties: props,
+		}
+		switch raw.Type {
+		case TileLayer:
+			if err := decodeTMJData(m, layer, raw); err != nil {
+				return fmt.Errorf("tiled: layer %q: %w", raw.Name, err)
+			}
+		case ObjectLayer:
+			for _, obj := range raw.Objects {
+				o, err := obj.decode()
+				if err != nil {
+					return fmt.Errorf("tiled: layer %q: %w", raw.Name, err)
+				}
+				layer.Objects = append(layer.Objects, o)
+			}
+		case "group":
+			if err := decodeTMJLayers(m, raw.Layers, layer.Visible); err != nil {
+				return err
+			}
+			continue
+		default:
+			// 图像层等不支持的图层
+			continue
+		}
+		m.Layers = append(m.Layers, layer)
+	}
+	return nil
+}
+
+// decodeTMJData reads the tiles of a tile layer, from chunks for infinite maps
+func decodeTMJData(m *Map, layer *Layer, raw tmjLayer) error {
+	if !m.Infinite {
+		layer.Width, layer.Height = raw.Width, raw.Height
+		n, err := tileCount(raw.Width, raw.Height)
+		if err != nil {
+			return err
+		}
+		gids, err := decodeTMJGIDs(raw.Data, raw.Encoding, raw.Compression, n)
+		layer.Data = gids
+		return err
+	}
+
+	chunks := make([]chunk, len(raw.Chunks))
+	for i, c := range raw.Chunks {
+		n, err := tileCount(c.Width, c.Height)
+		if err != nil {
+			return fmt.Errorf("chunk %d,%d: %w", c.X, c.Y, err)
+		}
+		gids, err := decodeTMJGIDs(c.Data, raw.Encoding, raw.Compression, n)
+		if err != nil {
+			return fmt.Errorf("chunk %d,%d: %w", c.X, c.Y, err)
+		}
+		chunks[i] = chunk{x: c.X, y: c.Y, width: c.Width, height: c.Height, data: gids}
+	}
+	return mergeChunks(layer, chunks)
+}
+
+// decodeTMJGIDs decodes n tiles given as a JSON array or base64 text
+func decodeTMJGIDs(data json.RawMessage, encoding, compression string, n int) ([]uint32, error) {
+	if encoding == "base64" {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return nil, err
+		}
+		return decodeBase64(text, compression, n)
+	}
+
+	var gids []uint32
+	if err := json.Unmarshal(data, &gids); err != nil {
+		return nil, err
+	}
+	if len(gids) != n {
+		return nil, fmt.Errorf("tiled: layer data has %d tiles, expected %d", len(gids), n)
+	}
+	return gids, nil
+}
+
+// decodeTMJProperties converts JSON property values to text
+func decodeTMJProperties(raw []tmjProperty) (Properties, error) {
+	var props Properties
+	for _, p := range raw {
+		kind := p.Type
+		if kind == "" {
+			kind = "string"
+		}
+		value := string(p.Value)
+		var text string
+		if err := json.Unmarshal(p.Value, &text); err == nil {
+			value = text
+		} else if !json.Valid(p.Value) {
+			return nil, fmt.Errorf("tiled: property %s has an invalid value", p.Name)
+		}
+		props = append(props, Property{Name: p.Name, Type: kind, Value: value})
+	}
+	return props, nil
+}
+
+func (ts tmjTileset) decode() (*Tileset, error) {
+	props, err := decodeTMJProperties(ts.Properties)
+	if err != nil {
+		return nil, err
+	}
+	tileset := &Tileset{
+		FirstGID:    ts.FirstGID,
+		Source:      ts.Source,
+		Name:        ts.Name,
+		TileWidth:   ts.TileWidth,
+		TileHeight:  ts.TileHeight,
+		TileCount:   ts.TileCount,
+		Image:       ts.Image,
+		ImageWidth:  ts.ImageWidth,
+		ImageHeight: ts.ImageHeight,
+		Properties:  props,
+	}
+	if ts.Columns != nil {
+		tileset.Columns = *ts.Columns
+	}
+	for _, tile := range ts.Tiles {
+		props, err := decodeTMJProperties(tile.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("tiled: tile %d of %s: %w", tile.ID, ts.Name, err)
+		}
+		class := tile.Type
+		if class == "" {
+			class = tile.Class
+		}
+		tileset.Tiles = append(tileset.Tiles, &Tile{ID: tile.ID, Class: class, Properties: props})
+	}
+	return tileset, nil
+}
+
+func (obj tmjObject) decode() (*Object, error) {
+	props, err := decodeTMJProperties(obj.Properties)
+	if err != nil {
+		return nil, err
+	}
+	class := obj.Type
+	if class == "" {
+		class = obj.Class
+	}
+	o := &Object{
+		ID:         obj.ID,
+		Name:       obj.Name,
+		Class:      class,
+		X:          obj.X,
+		Y:          obj.Y,
+		Width:      obj.Width,
+		Height:     obj.Height,
+		Point:      obj.Point,
+		Properties: props,
+	}
+	if obj.GID != 0 {
+		// 图块对象以底边定位，统一换成顶边
+		o.Y -= o.Height
+	}
+	return o, nil
+}
+
+// EncodeTMJ writes a map in Tiled's JSON format. Tile layers are written as arrays;
+// those of infinite maps as a single chunk.
+func EncodeTMJ(m *Map) ([]byte, error) {
+	raw := tmjMap{
+		Type:        "map",
+		Version:     "1.10",
+		Orientation: orientation(m),
+		RenderOrder: "right-down",
+		Width:       m.Width,
+		Height:      m.Height,
+		TileWidth:   m.TileWidth,
+		TileHeight:  m.TileHeight,
+		Infinite:    m.Infinite,
+		Properties:  encodeTMJProperties(m.Properties),
+		Tilesets:    []tmjTileset{},
+		Layers:      []tmjLayer{},
+	}
+	for _, ts := range m.Tilesets {
+		raw.Tilesets = append(raw.Tilesets, encodeTMJTileset(ts, true))
+	}
+
+	nextObjectID := 1
+	for i, layer := range m.Layers {
+		visible := layer.Visible
+		el := tmjLayer{
+			ID:         i + 1,
+			Name:       layer.Name,
+			Type:       layer.Type,
+			Visible:    &visible,
+			Opacity:    1,
+			Properties: encodeTMJProperties(layer.Properties),
+		}
+		switch layer.Type {
+		case TileLayer:
+			data, err := json.Marshal(nonNil(layer.Data))
+			if err != nil {
+				return nil, err
+			}
+			if m.Infinite {
+				el.Width, el.Height = layer.Width, layer.Height
+				el.StartX, el.StartY = layer.X, layer.Y
+				el.Chunks = []tmjChunk{{X: layer.X, Y: layer.Y, Width: layer.Width, Height: layer.Height, Data: data}}
+			} else {
+				el.Width, el.Height = layer.Width, layer.Height
+				el.Data = data
+			}
+		case ObjectLayer:
+			el.DrawOrder = "topdown"
+			for _, obj := range layer.Objects {
+				el.Objects = append(el.Objects, tmjObject{
+					ID:         obj.ID,
+					Name:       obj.Name,
+					Type:       obj.Class,
+					X:          obj.X,
+					Y:          obj.Y,
+					Width:      obj.Width,
+					Height:     obj.Height,
+					Visible:    true,
+					Point:      obj.Point,
+					Properties: encodeTMJProperties(obj.Properties),
+				})
+				nextObjectID = max(nextObjectID, obj.ID+1)
+			}
+		default:
+			return nil, fmt.Errorf("tiled: layer %q has unknown type %q", layer.Name, layer.Type)
+		}
+		raw.Layers = append(raw.Layers, el)
+	}
+	raw.NextLayerID = len(m.Layers) + 1
+	raw.NextObjectID = nextObjectID
+	return marshalTMJ(raw)
+}
+
+// EncodeTSJ writes a tileset in Tiled's JSON format, for use as an external tileset
+func EncodeTSJ(ts *Tileset) ([]byte, error) {
+	raw := encodeTMJTileset(ts, false)
+	raw.Type = "tileset"
+	return marshalTMJ(raw)
+}
+
+// marshalTMJ writes indented JSON without escaping <, > and &, as Tiled does
+func marshalTMJ(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", " ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeTMJTileset converts a tileset; inMap writes its first GID and, for external
+// tilesets, only the reference
+func encodeTMJTileset(ts *Tileset, inMap bool) tmjTileset {
+	if inMap && ts.Source != "" {
+		return tmjTileset{FirstGID: ts.FirstGID, Source: ts.Source}
+	}
+	columns := ts.Columns
+	raw := tmjTileset{
+		Name:        ts.Name,
+		TileWidth:   ts.TileWidth,
+		TileHeight:  ts.TileHeight,
+		TileCount:   ts.TileCount,
+		Columns:     &columns,
+		Image:       ts.Image,
+		ImageWidth:  ts.ImageWidth,
+		ImageHeight: ts.ImageHeight,
+		Properties:  encodeTMJProperties(ts.Properties),
+	}
+	if inMap {
+		raw.FirstGID = ts.FirstGID
+	}
+	for _, tile := range ts.Tiles {
+		raw.Tiles = append(raw.Tiles, tmjTile{ID: tile.ID, Type: tile.Class, Properties: encodeTMJProperties(tile.Properties)})
+	}
+	return raw
+}
+
+// encodeTMJProperties writes property values as JSON of their type
+func encodeTMJProperties(props Properties) []tmjProperty {
+	var raw []tmjProperty
+	for _, p := range props {
+		value, _ := json.Marshal(p.Value)
+		switch p.Type {
+		case "int", "object":
+			if _, err := strconv.ParseInt(p.Value, 10, 64); err == nil {
+				value = json.RawMessage(p.Value)
+			}
+		case "float":
+			if _, err := strconv.ParseFloat(p.Value, 64); err == nil && json.Valid([]byte(p.Value)) {
+				value = json.RawMessage(p.Value)
+			}
+		case "bool":
+			if b, err := strconv.ParseBool(p.Value); err == nil {
+				value = json.RawMessage(strconv.FormatBool(b))
+			}
+		case "class":
+			if json.Valid([]byte(p.Value)) {
+				value = json.RawMessage(p.Value)
+			}
+		}
+		raw = append(raw, tmjProperty{Name: p.Name, Type: p.Type, Value: value})
+	}
+	return raw
+}
+
+// nonNil returns an empty slice instead of nil, so it is written as []
+func nonNil(gids []uint32) []uint32 {
+	if gids == nil {
+		return []uint32{}
+	}
+	return gids
+}
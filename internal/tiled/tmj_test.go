@@ -0,0 +1,183 @@
+package tiled
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+const tmjSample = `{
+ "type": "map", "version": "1.10", "orientation": "orthogonal",
+ "width": 2, "height": 2, "tilewidth": 32, "tileheight": 32, "infinite": false,
+ "properties": [
+  {"name": "title", "type": "string", "value": "tower"},
+  {"name": "floors", "type": "int", "value": 3},
+  {"name": "dark", "type": "bool", "value": true},
+  {"name": "tint", "type": "color", "value": "#ff102030"}
+ ],
+ "tilesets": [
+  {"firstgid": 1, "name": "inline", "tilewidth": 32, "tileheight": 32, "tilecount": 2, "columns": 2,
+   "image": "tiles.png", "imagewidth": 64, "imageheight": 32,
+   "tiles": [{"id": 1, "class": "solid", "properties": [{"name": "block", "type": "string", "value": "stone"}]}]},
+  {"firstgid": 3, "source": "more.tsj"}
+ ],
+ "layers": [
+  {"id": 1, "name": "ground", "type": "tilelayer", "width": 2, "height": 2, "x": 0, "y": 0,
+   "opacity": 1, "visible": true, "data": [2, 0, 3, 1073741826]},
+  {"id": 2, "name": "group", "type": "group", "visible": false, "layers": [
+   {"id": 3, "name": "decor", "type": "tilelayer", "width": 2, "height": 2, "visible": true, "data": [0, 0, 0, 2]}
+  ]},
+  {"id": 4, "name": "objects", "type": "objectgroup", "objects": [
+   {"id": 1, "name": "", "type": "spawn", "x": 16, "y": 48, "width": 0, "height": 0, "point": true, "visible": true},
+   {"id": 2, "name": "crate", "type": "item", "gid": 2, "x": 32, "y": 64, "width": 32, "height": 32, "visible": true,
+    "properties": [{"name": "count", "type": "int", "value": 5}]}
+  ]},
+  {"id": 5, "name": "sky", "type": "imagelayer", "image": "sky.png"}
+ ]
+}`
+
+const tsjSample = `{"type": "tileset", "name": "more", "tilewidth": 32, "tileheight": 32, "tilecount": 1, "columns": 0,
+ "tiles": [{"id": 0, "type": "soft", "properties": [{"name": "block", "value": "dirt"}]}]}`
+
+func TestLoadTMJ(t *testing.T) {
+	m, err := Load("tower.tmj", readFiles(map[string]string{"tower.tmj": tmjSample, "more.tsj": tsjSample}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantProps := Properties{
+		{Name: "title", Type: "string", Value: "tower"},
+		{Name: "floors", Type: "int", Value: "3"},
+		{Name: "dark", Type: "bool", Value: "true"},
+		{Name: "tint", Type: "color", Value: "#ff102030"},
+	}
+	if !reflect.DeepEqual(m.Properties, wantProps) {
+		t.Errorf("Expected properties %+v, got %+v", wantProps, m.Properties)
+	}
+	if m.Tilesets[0].Tile(1).Class != "solid" || m.Tilesets[1].Name != "more" || m.Tilesets[1].Tile(0).Class != "soft" {
+		t.Errorf("Unexpected tilesets %+v %+v", m.Tilesets[0], m.Tilesets[1])
+	}
+
+	if len(m.Layers) != 3 {
+		t.Fatalf("Expected 3 layers, got %d", len(m.Layers))
+	}
+	if want := []uint32{2, 0, 3, 2 | FlippedVertically}; !reflect.DeepEqual(m.Layers[0].Data, want) {
+		t.Errorf("Expected %v, got %v", want, m.Layers[0].Data)
+	}
+	if m.Layers[1].Visible {
+		t.Error("Expected layers of a hidden group to be hidden")
+	}
+
+	objects := m.Layers[2].Objects
+	if !objects[0].Point || objects[0].Class != SpawnClass {
+		t.Errorf("Unexpected spawn %+v", objects[0])
+	}
+	// 图块对象以底边定位
+	if crate := objects[1]; crate.Y != 32 || crate.Class != "item" {
+		t.Errorf("Expected the tile object's top edge at 32, got %+v", crate)
+	}
+}
+
+func TestDecodeTMJEncodings(t *testing.T) {
+	gids := []uint32{4, 0, 0, 1}
+	for _, compression := range []string{"", "zlib", "gzip"} {
+		data := fmt.Sprintf(`{"orientation": "orthogonal", "width": 2, "height": 2, "tilewidth": 8, "tileheight": 8,
+			"layers": [{"name": "l", "type": "tilelayer", "width": 2, "height": 2, "encoding": "base64", "compression": %q, "data": %q}]}`,
+			compression, base64GIDs(t, gids, compression))
+		m, err := DecodeTMJ([]byte(data))
+		if err != nil {
+			t.Fatalf("Compression %q: %v", compression, err)
+		}
+		if !reflect.DeepEqual(m.Layers[0].Data, gids) {
+			t.Errorf("Compression %q: expected %v, got %v", compression, gids, m.Layers[0].Data)
+		}
+	}
+
+	infinite := `{"orientation": "orthogonal", "width": 2, "height": 2, "tilewidth": 8, "tileheight": 8, "infinite": true,
+		"layers": [{"name": "l", "type": "tilelayer", "chunks": [
+			{"x": 16, "y": 0, "width": 1, "height": 1, "data": [9]},
+			{"x": 15, "y": 1, "width": 1, "height": 1, "data": [8]}]}]}`
+	m, err := DecodeTMJ([]byte(infinite))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layer := m.Layers[0]; layer.X != 15 || layer.Y != 0 || !reflect.DeepEqual(layer.Data, []uint32{0, 9, 8, 0}) {
+		t.Errorf("Unexpected infinite layer %+v", layer)
+	}
+}
+
+func TestDecodeTMJErrors(t *testing.T) {
+	tests := map[string]string{
+		"invalid json": `{`,
+		"tileset":      `{"type": "tileset"}`,
+		"short data":   `{"width": 2, "height": 1, "tilewidth": 8, "tileheight": 8, "layers": [{"name": "l", "type": "tilelayer", "width": 2, "height": 1, "data": [1]}]}`,
+		"hexagonal":    `{"orientation": "hexagonal", "tilewidth": 8, "tileheight": 8}`,
+	}
+	for name, data := range tests {
+		if _, err := DecodeTMJ([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestDecodeTMJRejectsBadSizes(t *testing.T) {
+	layer := func(width, height int) string {
+		return fmt.Sprintf(`{"width": 1, "height": 1, "tilewidth": 8, "tileheight": 8, "layers": [
+			{"name": "l", "type": "tilelayer", "width": %d, "height": %d, "encoding": "base64", "data": "AAAAAA=="}]}`, width, height)
+	}
+	chunks := func(chunks string) string {
+		return `{"width": 1, "height": 1, "tilewidth": 8, "tileheight": 8, "infinite": true, "layers": [
+			{"name": "l", "type": "tilelayer", "encoding": "base64", "chunks": [` + chunks + `]}]}`
+	}
+	tests := map[string]string{
+		"negative width": layer(-1, 1),
+		"zero height":    layer(1, 0),
+		"too many tiles": layer(1<<16, 1<<16),
+		"negative chunk": chunks(`{"x": 0, "y": 0, "width": -4, "height": 1, "data": "AAAAAA=="}`),
+		"huge chunk":     chunks(`{"x": 0, "y": 0, "width": 65536, "height": 65536, "data": "AAAAAA=="}`),
+		"far apart":      chunks(`{"x": -5000, "y": 0, "width": 1, "height": 1, "data": "AAAAAA=="}, {"x": 5000, "y": 5000, "width": 1, "height": 1, "data": "AAAAAA=="}`),
+	}
+	for name, data := range tests {
+		if _, err := DecodeTMJ([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestEncodeTMJRoundTrip(t *testing.T) {
+	files := map[string]string{"tower.tmj": tmjSample, "more.tsj": tsjSample}
+	m, err := Load("tower.tmj", readFiles(files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := EncodeTMJ(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tsj, err := EncodeTSJ(m.Tilesets[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := Load("tower.tmj", readFiles(map[string]string{"tower.tmj": string(data), "more.tsj": string(tsj)}))
+	if err != nil {
+		t.Fatalf("%v\n%s", err, data)
+	}
+	if !reflect.DeepEqual(again, m) {
+		t.Errorf("Round trip changed the map:\n%s", data)
+	}
+
+	// 两种格式之间互相转换也不丢失内容
+	tmx, err := EncodeTMX(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromTMX, err := DecodeTMX(tmx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromTMX.Tilesets[1] = m.Tilesets[1] // 外部图块集没有重新读取
+	if !reflect.DeepEqual(fromTMX, m) {
+		t.Errorf("Converting to TMX changed the map:\n%s", tmx)
+	}
+}
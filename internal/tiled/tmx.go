@@ -0,0 +1,440 @@
+package tiled
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// tmxMap is the root element of a .tmx file
+type tmxMap struct {
+	XMLName      xml.Name       `xml:"map"`
+	Version      string         `xml:"version,attr"`
+	Orientation  string         `xml:"orientation,attr"`
+	RenderOrder  string         `xml:"renderorder,attr,omitempty"`
+	Width        int            `xml:"width,attr"`
+	Height       int            `xml:"height,attr"`
+	TileWidth    int            `xml:"tilewidth,attr"`
+	TileHeight   int            `xml:"tileheight,attr"`
+	Infinite     int            `xml:"infinite,attr"`
+	NextLayerID  int            `xml:"nextlayerid,attr,omitempty"`
+	NextObjectID int            `xml:"nextobjectid,attr,omitempty"`
+	Properties   *tmxProperties `xml:"properties"`
+	Tilesets     []tmxTileset   `xml:"tileset"`
+	Layers       []tmxLayer     `xml:",any"` // layer, objectgroup, group and others, in order
+}
+
+type tmxProperties struct {
+	Properties []tmxProperty `xml:"property"`
+}
+
+type tmxProperty struct {
+	Name         string `xml:"name,attr"`
+	Type         string `xml:"type,attr,omitempty"`
+	PropertyType string `xml:"propertytype,attr,omitempty"`
+	Value        string `xml:"value,attr"`
+	Text         string `xml:",chardata"` // Multi-line strings are written as text
+}
+
+type tmxTileset struct {
+	XMLName    xml.Name       `xml:"tileset"`
+	FirstGID   int            `xml:"firstgid,attr,omitempty"`
+	Source     string         `xml:"source,attr,omitempty"`
+	Name       string         `xml:"name,attr,omitempty"`
+	TileWidth  int            `xml:"tilewidth,attr,omitempty"`
+	TileHeight int            `xml:"tileheight,attr,omitempty"`
+	TileCount  int            `xml:"tilecount,attr,omitempty"`
+	Columns    *int           `xml:"columns,attr"`
+	Properties *tmxProperties `xml:"properties"`
+	Image      *tmxImage      `xml:"image"`
+	Tiles      []tmxTile      `xml:"tile"`
+}
+
+type tmxImage struct {
+	Source string `xml:"source,attr"`
+	Width  int    `xml:"width,attr,omitempty"`
+	Height int    `xml:"height,attr,omitempty"`
+}
+
+type tmxTile struct {
+	ID         int            `xml:"id,attr"`
+	Type       string         `xml:"type,attr,omitempty"`
+	Class      string         `xml:"class,attr,omitempty"` // Tiled 1.9
+	Properties *tmxProperties `xml:"properties"`
+}
+
+// tmxLayer is any layer element; which fields are used depends on its name
+type tmxLayer struct {
+	XMLName    xml.Name
+	ID         int            `xml:"id,attr,omitempty"`
+	Name       string         `xml:"name,attr"`
+	Width      int            `xml:"width,attr,omitempty"`
+	Height     int            `xml:"height,attr,omitempty"`
+	Visible    string         `xml:"visible,attr,omitempty"` // "0" when hidden
+	Properties *tmxProperties `xml:"properties"`
+	Data       *tmxData       `xml:"data"`
+	Objects    []tmxObject    `xml:"object"`
+	Layers     []tmxLayer     `xml:",any"` // Children of a group
+}
+
+type tmxData struct {
+	Encoding    string        `xml:"encoding,attr,omitempty"`
+	Compression string        `xml:"compression,attr,omitempty"`
+	Text        string        `xml:",chardata"`
+	Tiles       []tmxDataTile `xml:"tile"`
+	Chunks      []tmxChunk    `xml:"chunk"`
+}
+
+type tmxDataTile struct {
+	GID uint32 `xml:"gid,attr"`
+}
+
+type tmxChunk struct {
+	X      int           `xml:"x,attr"`
+	Y      int           `xml:"y,attr"`
+	Width  int           `xml:"width,attr"`
+	Height int           `xml:"height,attr"`
+	Text   string        `xml:",chardata"`
+	Tiles  []tmxDataTile `xml:"tile"`
+}
+
+type tmxObject struct {
+	ID         int            `xml:"id,attr"`
+	Name       string         `xml:"name,attr,omitempty"`
+	Type       string         `xml:"type,attr,omitempty"`
+	Class      string         `xml:"class,attr,omitempty"` // Tiled 1.9
+	GID        uint32         `xml:"gid,attr,omitempty"`
+	X          float64        `xml:"x,attr"`
+	Y          float64        `xml:"y,attr"`
+	Width      float64        `xml:"width,attr,omitempty"`
+	Height     float64        `xml:"height,attr,omitempty"`
+	Properties *tmxProperties `xml:"properties"`
+	Point      *struct{}      `xml:"point"`
+}
+
+// DecodeTMX parses a map in Tiled's XML format
+func DecodeTMX(data []byte) (*Map, error) {
+	var raw tmxMap
+	if err := xml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	m := &Map{
+		Orientation: raw.Orientation,
+		Width:       raw.Width,
+		Height:      raw.Height,
+		TileWidth:   raw.TileWidth,
+		TileHeight:  raw.TileHeight,
+		Infinite:    raw.Infinite != 0,
+		Properties:  raw.Properties.decode(),
+	}
+	for _, ts := range raw.Tilesets {
+		m.Tilesets = append(m.Tilesets, ts.decode())
+	}
+	if err := decodeTMXLayers(m, raw.Layers, true); err != nil {
+		return nil, err
+	}
+	if err := checkMap(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DecodeTSX parses an external tileset in Tiled's XML format
+func DecodeTSX(data []byte) (*Tileset, error) {
+	var raw tmxTileset
+	if err := xml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return raw.decode(), nil
+}
+
+// decodeTMXLayers appends the layers to m, flattening groups. Layers in a hidden
+// group are hidden.
+func decodeTMXLayers(m *Map, layers []tmxLayer, visible bool) error {
+	for _, raw := range layers {
+		layer := &Layer{
+			Name:       raw.Name,
+			Visible:    visible && raw.Visible != "0",
+			Properties: raw.Properties.decode(),
+		}
+		switch raw.XMLName.Local {
+		case "layer":
+			layer.Type = TileLayer
+			layer.Width, layer.Height = raw.Width, raw.Height
+			if err := decodeTMXData(m, layer, raw.Data); err != nil {
+				return fmt.Errorf("tiled: layer %q: %w", raw.Name, err)
+			}
+		case "objectgroup":
+			layer.Type = ObjectLayer
+			for _, obj := range raw.Objects {
+				layer.Objects = append(layer.Objects, obj.decode())
+			}
+		case "group":
+			if err := decodeTMXLayers(m, raw.Layers, layer.Visible); err != nil {
+				return err
+			}
+			continue
+		default:
+			// 图像层等不支持的元素
+			continue
+		}
+		m.Layers = append(m.Layers, layer)
+	}
+	return nil
+}
+
+// decodeTMXData reads the tiles of a tile layer, from chunks for infinite maps
+func decodeTMXData(m *Map, layer *Layer, data *tmxData) error {
+	if data == nil {
+		return fmt.Errorf("no data")
+	}
+	if !m.Infinite {
+		n, err := tileCount(layer.Width, layer.Height)
+		if err != nil {
+			return err
+		}
+		gids, err := decodeTMXGIDs(data, data.Text, data.Tiles, n)
+		layer.Data = gids
+		return err
+	}
+
+	chunks := make([]chunk, len(data.Chunks))
+	for i, c := range data.Chunks {
+		n, err := tileCount(c.Width, c.Height)
+		if err != nil {
+			return fmt.Errorf("chunk %d,%d: %w", c.X, c.Y, err)
+		}
+		gids, err := decodeTMXGIDs(data, c.Text, c.Tiles, n)
+		if err != nil {
+			return fmt.Errorf("chunk %d,%d: %w", c.X, c.Y, err)
+		}
+		chunks[i] = chunk{x: c.X, y: c.Y, width: c.Width, height: c.Height, data: gids}
+	}
+	layer.Width, layer.Height = 0, 0
+	return mergeChunks(layer, chunks)
+}
+
+// decodeTMXGIDs decodes n tiles in the encoding of data
+func decodeTMXGIDs(data *tmxData, text string, tiles []tmxDataTile, n int) ([]uint32, error) {
+	switch data.Encoding {
+	case "csv":
+		return decodeCSV(text, n)
+	case "base64":
+		return decodeBase64(text, data.Compression, n)
+	case "":
+		// 旧版本的 <tile gid="..."> 元素
+		if len(tiles) != n {
+			return nil, fmt.Errorf("tiled: layer data has %d tiles, expected %d", len(tiles), n)
+		}
+		gids := make([]uint32, n)
+		for i, tile := range tiles {
+			gids[i] = tile.GID
+		}
+		return gids, nil
+	default:
+		return nil, fmt.Errorf("tiled: layer encoding %q is not supported", data.Encoding)
+	}
+}
+
+func (ps *tmxProperties) decode() Properties {
+	if ps == nil {
+		return nil
+	}
+	var props Properties
+	for _, p := range ps.Properties {
+		kind := p.Type
+		if kind == "" {
+			kind = "string"
+		}
+		value := p.Value
+		if value == "" && strings.TrimSpace(p.Text) != "" {
+			value = p.Text
+		}
+		props = append(props, Property{Name: p.Name, Type: kind, Value: value})
+	}
+	return props
+}
+
+func (ts tmxTileset) decode() *Tileset {
+	tileset := &Tileset{
+		FirstGID:   ts.FirstGID,
+		Source:     ts.Source,
+		Name:       ts.Name,
+		TileWidth:  ts.TileWidth,
+		TileHeight: ts.TileHeight,
+		TileCount:  ts.TileCount,
+		Properties: ts.Properties.decode(),
+	}
+	if ts.Columns != nil {
+		tileset.Columns = *ts.Columns
+	}
+	if ts.Image != nil {
+		tileset.Image = ts.Image.Source
+		tileset.ImageWidth, tileset.ImageHeight = ts.Image.Width, ts.Image.Height
+	}
+	for _, tile := range ts.Tiles {
+		class := tile.Type
+		if class == "" {
+			class = tile.Class
+		}
+		tileset.Tiles = append(tileset.Tiles, &Tile{ID: tile.ID, Class: class, Properties: tile.Properties.decode()})
+	}
+	return tileset
+}
+
+func (obj tmxObject) decode() *Object {
+	class := obj.Type
+	if class == "" {
+		class = obj.Class
+	}
+	o := &Object{
+		ID:         obj.ID,
+		Name:       obj.Name,
+		Class:      class,
+		X:          obj.X,
+		Y:          obj.Y,
+		Width:      obj.Width,
+		Height:     obj.Height,
+		Point:      obj.Point != nil,
+		Properties: obj.Properties.decode(),
+	}
+	if obj.GID != 0 {
+		// 图块对象以底边定位，统一换成顶边
+		o.Y -= o.Height
+	}
+	return o
+}
+
+// EncodeTMX writes a map in Tiled's XML format. Tile layers are CSV encoded; those of
+// infinite maps are written as a single chunk.
+func EncodeTMX(m *Map) ([]byte, error) {
+	raw := tmxMap{
+		Version:     "1.10",
+		Orientation: orientation(m),
+		RenderOrder: "right-down",
+		Width:       m.Width,
+		Height:      m.Height,
+		TileWidth:   m.TileWidth,
+		TileHeight:  m.TileHeight,
+		Properties:  encodeTMXProperties(m.Properties),
+	}
+	if m.Infinite {
+		raw.Infinite = 1
+	}
+	for _, ts := range m.Tilesets {
+		raw.Tilesets = append(raw.Tilesets, encodeTMXTileset(ts, true))
+	}
+
+	nextObjectID := 1
+	for i, layer := range m.Layers {
+		el := tmxLayer{ID: i + 1, Name: layer.Name, Properties: encodeTMXProperties(layer.Properties)}
+		if !layer.Visible {
+			el.Visible = "0"
+		}
+		switch layer.Type {
+		case TileLayer:
+			el.XMLName.Local = "layer"
+			el.Width, el.Height = layer.Width, layer.Height
+			el.Data = &tmxData{Encoding: "csv"}
+			if m.Infinite {
+				el.Data.Chunks = []tmxChunk{{X: layer.X, Y: layer.Y, Width: layer.Width, Height: layer.Height, Text: encodeCSV(layer.Data, layer.Width)}}
+			} else {
+				el.Data.Text = encodeCSV(layer.Data, layer.Width)
+			}
+		case ObjectLayer:
+			el.XMLName.Local = "objectgroup"
+			for _, obj := range layer.Objects {
+				el.Objects = append(el.Objects, encodeTMXObject(obj))
+				nextObjectID = max(nextObjectID, obj.ID+1)
+			}
+		default:
+			return nil, fmt.Errorf("tiled: layer %q has unknown type %q", layer.Name, layer.Type)
+		}
+		raw.Layers = append(raw.Layers, el)
+	}
+	raw.NextLayerID = len(m.Layers) + 1
+	raw.NextObjectID = nextObjectID
+
+	data, err := xml.MarshalIndent(raw, "", " ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// EncodeTSX writes a tileset in Tiled's XML format, for use as an external tileset
+func EncodeTSX(ts *Tileset) ([]byte, error) {
+	data, err := xml.MarshalIndent(encodeTMXTileset(ts, false), "", " ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// encodeTMXTileset converts a tileset; inMap writes its first GID and, for external
+// tilesets, only the reference
+func encodeTMXTileset(ts *Tileset, inMap bool) tmxTileset {
+	if inMap && ts.Source != "" {
+		return tmxTileset{FirstGID: ts.FirstGID, Source: ts.Source}
+	}
+	columns := ts.Columns
+	raw := tmxTileset{
+		Name:       ts.Name,
+		TileWidth:  ts.TileWidth,
+		TileHeight: ts.TileHeight,
+		TileCount:  ts.TileCount,
+		Columns:    &columns,
+		Properties: encodeTMXProperties(ts.Properties),
+	}
+	if inMap {
+		raw.FirstGID = ts.FirstGID
+	}
+	if ts.Image != "" {
+		raw.Image = &tmxImage{Source: ts.Image, Width: ts.ImageWidth, Height: ts.ImageHeight}
+	}
+	for _, tile := range ts.Tiles {
+		raw.Tiles = append(raw.Tiles, tmxTile{ID: tile.ID, Type: tile.Class, Properties: encodeTMXProperties(tile.Properties)})
+	}
+	return raw
+}
+
+func encodeTMXObject(obj *Object) tmxObject {
+	raw := tmxObject{
+		ID:         obj.ID,
+		Name:       obj.Name,
+		Type:       obj.Class,
+		X:          obj.X,
+		Y:          obj.Y,
+		Width:      obj.Width,
+		Height:     obj.Height,
+		Properties: encodeTMXProperties(obj.Properties),
+	}
+	if obj.Point {
+		raw.Point = &struct{}{}
+	}
+	return raw
+}
+
+func encodeTMXProperties(props Properties) *tmxProperties {
+	if len(props) == 0 {
+		return nil
+	}
+	raw := &tmxProperties{}
+	for _, p := range props {
+		kind := p.Type
+		if kind == "string" {
+			kind = ""
+		}
+		raw.Properties = append(raw.Properties, tmxProperty{Name: p.Name, Type: kind, Value: p.Value})
+	}
+	return raw
+}
+
+// orientation returns the orientation to write, orthogonal when not set
+func orientation(m *Map) string {
+	if m.Orientation == "" {
+		return "orthogonal"
+	}
+	return m.Orientation
+}
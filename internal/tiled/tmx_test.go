@@ -0,0 +1,275 @@
+package tiled
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// readFiles returns a ReadFunc serving the given files
+func readFiles(files map[string]string) ReadFunc {
+	return func(name string) ([]byte, error) {
+		data, exists := files[name]
+		if !exists {
+			return nil, os.ErrNotExist
+		}
+		return []byte(data), nil
+	}
+}
+
+// base64GIDs encodes GIDs as Tiled's base64 layer data, compressed with compression
+func base64GIDs(t *testing.T, gids []uint32, compression string) string {
+	t.Helper()
+	var raw bytes.Buffer
+	binary.Write(&raw, binary.LittleEndian, gids)
+
+	var out bytes.Buffer
+	switch compression {
+	case "":
+		out = raw
+	case "zlib":
+		w := zlib.NewWriter(&out)
+		w.Write(raw.Bytes())
+		w.Close()
+	case "gzip":
+		w := gzip.NewWriter(&out)
+		w.Write(raw.Bytes())
+		w.Close()
+	}
+	return base64.StdEncoding.EncodeToString(out.Bytes())
+}
+
+const tmxSample = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" tiledversion="1.10.2" orientation="orthogonal" renderorder="right-down" width="3" height="2" tilewidth="16" tileheight="16" infinite="0" nextlayerid="5" nextobjectid="3">
+ <editorsettings><export target="x.tmj" format="json"/></editorsettings>
+ <properties>
+  <property name="title" value="cave"/>
+  <property name="gravity" type="float" value="9.5"/>
+  <property name="notes">line one
+line two</property>
+ </properties>
+ <tileset firstgid="1" name="ground" tilewidth="16" tileheight="16" tilecount="4" columns="2">
+  <image source="ground.png" width="32" height="32"/>
+  <tile id="0" type="solid"><properties><property name="block" value="stone"/></properties></tile>
+  <tile id="2"><properties><property name="block" value="dirt"/></properties></tile>
+ </tileset>
+ <tileset firstgid="5" source="extra.tsx"/>
+ <layer id="1" name="back" width="3" height="2">
+  <data encoding="csv">
+1,0,3,
+0,2147483653,0
+</data>
+ </layer>
+ <group id="2" name="hidden" visible="0">
+  <layer id="3" name="inner" width="3" height="2">
+   <data><tile gid="1"/><tile gid="1"/><tile gid="1"/><tile gid="1"/><tile gid="1"/><tile gid="1"/></data>
+  </layer>
+ </group>
+ <imagelayer id="5" name="sky"><image source="sky.png"/></imagelayer>
+ <objectgroup id="4" name="things">
+  <object id="1" name="start" type="spawn" x="8" y="24"><point/></object>
+  <object id="2" class="chest" x="16" y="0" width="16" height="16">
+   <properties><property name="loot" type="int" value="3"/></properties>
+  </object>
+ </objectgroup>
+</map>
+`
+
+const tsxSample = `<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" name="extra" tilewidth="16" tileheight="16" tilecount="2" columns="0">
+ <tile id="0"><properties><property name="block" value="wood"/></properties></tile>
+</tileset>
+`
+
+func TestLoadTMX(t *testing.T) {
+	m, err := Load("maps/cave.tmx", readFiles(map[string]string{"maps/cave.tmx": tmxSample, "maps/extra.tsx": tsxSample}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Width != 3 || m.Height != 2 || m.TileWidth != 16 || m.Infinite {
+		t.Errorf("Unexpected map header %+v", m)
+	}
+	wantProps := Properties{
+		{Name: "title", Type: "string", Value: "cave"},
+		{Name: "gravity", Type: "float", Value: "9.5"},
+		{Name: "notes", Type: "string", Value: "line one\nline two"},
+	}
+	if !reflect.DeepEqual(m.Properties, wantProps) {
+		t.Errorf("Expected properties %+v, got %+v", wantProps, m.Properties)
+	}
+
+	if len(m.Tilesets) != 2 {
+		t.Fatalf("Expected 2 tilesets, got %d", len(m.Tilesets))
+	}
+	ground, extra := m.Tilesets[0], m.Tilesets[1]
+	if ground.Image != "ground.png" || ground.Columns != 2 || ground.Tile(0).Class != "solid" {
+		t.Errorf("Unexpected tileset %+v", ground)
+	}
+	if extra.FirstGID != 5 || extra.Source != "extra.tsx" || extra.Name != "extra" || extra.Tile(0) == nil {
+		t.Errorf("Expected the external tileset to be loaded, got %+v", extra)
+	}
+
+	// 图层组被展开，图像层被忽略
+	if len(m.Layers) != 3 {
+		t.Fatalf("Expected 3 layers, got %d", len(m.Layers))
+	}
+	back, inner, things := m.Layers[0], m.Layers[1], m.Layers[2]
+	if want := []uint32{1, 0, 3, 0, 5 | FlippedHorizontally, 0}; !reflect.DeepEqual(back.Data, want) {
+		t.Errorf("Expected CSV data %v, got %v", want, back.Data)
+	}
+	if inner.Visible || len(inner.Data) != 6 || inner.Data[5] != 1 {
+		t.Errorf("Expected a hidden layer from the XML tiles, got %+v", inner)
+	}
+	if things.Type != ObjectLayer || len(things.Objects) != 2 {
+		t.Fatalf("Unexpected object layer %+v", things)
+	}
+	if spawn := things.Objects[0]; spawn.Class != SpawnClass || !spawn.Point || spawn.X != 8 || spawn.Y != 24 {
+		t.Errorf("Unexpected spawn object %+v", spawn)
+	}
+	if chest := things.Objects[1]; chest.Class != "chest" || chest.Width != 16 {
+		t.Errorf("Expected the 1.9 class attribute to be read, got %+v", chest)
+	} else if loot, _ := chest.Properties.Int("loot", 0); loot != 3 {
+		t.Errorf("Expected loot 3, got %d", loot)
+	}
+
+	if ts, local, ok := m.TileAt(6 | FlippedVertically); !ok || ts != extra || local != 1 {
+		t.Errorf("Expected GID 6 to be tile 1 of the external tileset, got %v %d", ts, local)
+	}
+}
+
+func TestDecodeTMXEncodings(t *testing.T) {
+	gids := []uint32{1, 0, 2, 2, 0, 1}
+	for _, compression := range []string{"", "zlib", "gzip"} {
+		attr := ""
+		if compression != "" {
+			attr = fmt.Sprintf(` compression="%s"`, compression)
+		}
+		data := fmt.Sprintf(`<map orientation="orthogonal" width="3" height="2" tilewidth="8" tileheight="8">
+			<layer name="l" width="3" height="2"><data encoding="base64"%s>
+			%s
+			</data></layer></map>`, attr, base64GIDs(t, gids, compression))
+		m, err := DecodeTMX([]byte(data))
+		if err != nil {
+			t.Fatalf("Compression %q: %v", compression, err)
+		}
+		if !reflect.DeepEqual(m.Layers[0].Data, gids) {
+			t.Errorf("Compression %q: expected %v, got %v", compression, gids, m.Layers[0].Data)
+		}
+	}
+}
+
+func TestDecodeTMXInfinite(t *testing.T) {
+	data := `<map orientation="orthogonal" width="4" height="4" tilewidth="8" tileheight="8" infinite="1">
+		<layer name="l" width="4" height="4"><data encoding="csv">
+		<chunk x="-2" y="0" width="2" height="1">1,2</chunk>
+		<chunk x="0" y="-1" width="1" height="2">3,
+4</chunk>
+		</data></layer></map>`
+	m, err := DecodeTMX([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer := m.Layers[0]
+	if layer.X != -2 || layer.Y != -1 || layer.Width != 3 || layer.Height != 2 {
+		t.Fatalf("Expected the chunks' bounding box, got %d,%d %dx%d", layer.X, layer.Y, layer.Width, layer.Height)
+	}
+	if want := []uint32{0, 0, 3, 1, 2, 4}; !reflect.DeepEqual(layer.Data, want) {
+		t.Errorf("Expected %v, got %v", want, layer.Data)
+	}
+}
+
+func TestDecodeTMXErrors(t *testing.T) {
+	tests := map[string]string{
+		"invalid xml":      `<map`,
+		"isometric":        `<map orientation="isometric" width="1" height="1" tilewidth="8" tileheight="8"/>`,
+		"no tile size":     `<map orientation="orthogonal" width="1" height="1"/>`,
+		"short csv":        `<map width="2" height="1" tilewidth="8" tileheight="8"><layer name="l" width="2" height="1"><data encoding="csv">1</data></layer></map>`,
+		"bad csv":          `<map width="1" height="1" tilewidth="8" tileheight="8"><layer name="l" width="1" height="1"><data encoding="csv">x</data></layer></map>`,
+		"zstd":             `<map width="1" height="1" tilewidth="8" tileheight="8"><layer name="l" width="1" height="1"><data encoding="base64" compression="zstd">AAAAAA==</data></layer></map>`,
+		"missing data":     `<map width="1" height="1" tilewidth="8" tileheight="8"><layer name="l" width="1" height="1"/></map>`,
+		"unknown encoding": `<map width="1" height="1" tilewidth="8" tileheight="8"><layer name="l" width="1" height="1"><data encoding="hex">00</data></layer></map>`,
+	}
+	for name, data := range tests {
+		if _, err := DecodeTMX([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	if _, err := Load("a.tmx", readFiles(map[string]string{"a.tmx": tmxSample})); err == nil {
+		t.Error("Expected an error for a missing external tileset")
+	}
+}
+
+func TestDecodeTMXRejectsBadSizes(t *testing.T) {
+	layer := func(width, height int) string {
+		return fmt.Sprintf(`<map width="1" height="1" tilewidth="8" tileheight="8">
+			<layer name="l" width="%d" height="%d"><data encoding="base64">AAAAAA==</data></layer></map>`, width, height)
+	}
+	chunks := func(chunks ...string) string {
+		return `<map width="1" height="1" tilewidth="8" tileheight="8" infinite="1">
+			<layer name="l" width="1" height="1"><data encoding="base64">` + strings.Join(chunks, "") + `</data></layer></map>`
+	}
+	tests := map[string]string{
+		"negative width":  layer(-1, 1),
+		"zero height":     layer(1, 0),
+		"too many tiles":  layer(1<<16, 1<<16),
+		"negative chunk":  chunks(`<chunk x="0" y="0" width="-4" height="1">AAAAAA==</chunk>`),
+		"huge chunk":      chunks(`<chunk x="0" y="0" width="65536" height="65536">AAAAAA==</chunk>`),
+		"far apart":       chunks(`<chunk x="-5000" y="0" width="1" height="1">AAAAAA==</chunk>`, `<chunk x="5000" y="5000" width="1" height="1">AAAAAA==</chunk>`),
+		"far from origin": chunks(`<chunk x="9223372036854775807" y="0" width="1" height="1">AAAAAA==</chunk>`),
+	}
+	for name, data := range tests {
+		if _, err := DecodeTMX([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestEncodeTMXRoundTrip(t *testing.T) {
+	m, err := Load("maps/cave.tmx", readFiles(map[string]string{"maps/cave.tmx": tmxSample, "maps/extra.tsx": tsxSample}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := EncodeTMX(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tsx, err := EncodeTSX(m.Tilesets[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := Load("maps/cave.tmx", readFiles(map[string]string{"maps/cave.tmx": string(data), "maps/extra.tsx": string(tsx)}))
+	if err != nil {
+		t.Fatalf("%v\n%s", err, data)
+	}
+	if !reflect.DeepEqual(again, m) {
+		t.Errorf("Round trip changed the map:\n%s", data)
+	}
+}
+
+func TestEncodeTMXInfiniteRoundTrip(t *testing.T) {
+	m := &Map{
+		Orientation: "orthogonal", Width: 2, Height: 2, TileWidth: 8, TileHeight: 8, Infinite: true,
+		Layers: []*Layer{{Name: "l", Type: TileLayer, Visible: true, X: -3, Y: 5, Width: 2, Height: 1, Data: []uint32{7, 0}}},
+	}
+	data, err := EncodeTMX(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := DecodeTMX(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, m) {
+		t.Errorf("Round trip changed the map:\n%s", data)
+	}
+}
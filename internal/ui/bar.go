@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ProgressBar shows a value between 0 and 1 as a partially filled rectangle
+type ProgressBar struct {
+	Base
+
+	// Value is the filled fraction, clamped to [0, 1] when drawn
+	Value float64
+
+	// Background is drawn behind the fill
+	Background color.Color
+
+	// Border is drawn around the bar when not nil
+	Border color.Color
+
+	// FillColor returns the fill color for the current value
+	FillColor func(value float64) color.Color
+
+	// Width and Height are the preferred size
+	Width, Height float64
+}
+
+// NewProgressBar creates a bar of the given size
+func NewProgressBar(width, height float64) *ProgressBar {
+	return &ProgressBar{
+		Base:       Base{PassThrough: true},
+		Width:      width,
+		Height:     height,
+		Background: color.RGBA{100, 0, 0, 200},
+		FillColor: func(float64) color.Color {
+			return color.RGBA{0, 200, 0, 255}
+		},
+	}
+}
+
+// PreferredSize returns the configured size
+func (b *ProgressBar) PreferredSize() (float64, float64) {
+	return b.Width, b.Height
+}
+
+// Draw renders the bar
+func (b *ProgressBar) Draw(dst *ebiten.Image) {
+	value := b.Value
+	if value < 0 {
+		value = 0
+	} else if value > 1 {
+		value = 1
+	}
+
+	FillRect(dst, b.bounds, b.Background)
+	if b.FillColor != nil && value > 0 {
+		fill := b.bounds
+		fill.W = float64(int(fill.W * value))
+		FillRect(dst, fill, b.FillColor(value))
+	}
+	StrokeRect(dst, b.bounds, 1, b.Border)
+}
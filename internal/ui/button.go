@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/wubinrui111/2d-game/internal/fonts"
+)
+
+var (
+	buttonColor         = color.RGBA{70, 70, 90, 230}
+	buttonHoverColor    = color.RGBA{95, 95, 125, 240}
+	buttonPressedColor  = color.RGBA{50, 50, 70, 255}
+	buttonDisabledColor = color.RGBA{60, 60, 60, 180}
+	buttonBorderColor   = color.RGBA{200, 200, 220, 255}
+	buttonFocusColor    = color.RGBA{255, 255, 0, 255}
+)
+
+// Button is a clickable rectangle with a centered caption
+type Button struct {
+	Base
+
+	// Text is the caption
+	Text string
+
+	// Style is used for the caption; Align is ignored
+	Style fonts.Style
+
+	// Disabled buttons are drawn greyed out and ignore clicks
+	Disabled bool
+
+	// OnClick is called when the button is clicked
+	OnClick func()
+
+	// Width and Height are the preferred size; 0 sizes the button to its caption
+	Width, Height float64
+
+	hovered bool
+	pressed bool
+	focused bool
+}
+
+// NewButton creates a button with a caption and click handler
+func NewButton(text string, onClick func()) *Button {
+	return &Button{
+		Text:    text,
+		OnClick: onClick,
+	}
+}
+
+// PreferredSize returns the configured size or the caption size plus padding
+func (b *Button) PreferredSize() (float64, float64) {
+	w, h := fonts.Measure(b.Text, b.Style)
+	w, h = w+24, h+12
+	if b.Width > 0 {
+		w = b.Width
+	}
+	if b.Height > 0 {
+		h = b.Height
+	}
+	return w, h
+}
+
+// Update tracks hover/press state and fires OnClick
+func (b *Button) Update(ctx *Context) {
+	b.hovered = ctx.IsHovered(b)
+	b.pressed = ctx.IsPressed(b)
+	b.focused = ctx.IsFocused(b)
+
+	if b.Disabled {
+		return
+	}
+
+	if ctx.JustPressed(b, MouseLeft) {
+		ctx.Focus(b)
+	}
+
+	// 获得焦点时回车也可以触发
+	activated := ctx.Clicked(b) || (b.focused && ctx.Input.Enter)
+	if activated && b.OnClick != nil {
+		b.OnClick()
+	}
+}
+
+// Draw renders the button background, border and caption
+func (b *Button) Draw(dst *ebiten.Image) {
+	background := buttonColor
+	switch {
+	case b.Disabled:
+		background = buttonDisabledColor
+	case b.pressed:
+		background = buttonPressedColor
+	case b.hovered:
+		background = buttonHoverColor
+	}
+	FillRect(dst, b.bounds, background)
+
+	border := color.Color(buttonBorderColor)
+	if b.focused && !b.Disabled {
+		border = buttonFocusColor
+	}
+	StrokeRect(dst, b.bounds, 1, border)
+
+	style := b.Style
+	style.Align = fonts.AlignCenter
+	if b.Disabled {
+		style.Color = color.RGBA{150, 150, 150, 255}
+	}
+	_, textHeight := fonts.Measure(b.Text, style)
+	fonts.Draw(dst, b.Text, b.bounds.X+b.bounds.W/2, b.bounds.Y+(b.bounds.H-textHeight)/2, style)
+}
@@ -0,0 +1,182 @@
+package ui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Context tracks hover, focus and press state across frames and dispatches input to a widget tree
+type Context struct {
+	// Input is the input of the current frame
+	Input Input
+
+	hovered Widget
+	focused Widget
+	pressed Widget
+}
+
+// NewContext creates an empty UI context
+func NewContext() *Context {
+	return &Context{}
+}
+
+// Update lays out the tree, resolves which widget is under the cursor and updates all visible widgets
+func (c *Context) Update(root Widget, in Input) {
+	c.Input = in
+
+	layoutTree(root)
+	c.hovered = hitTest(root, in.CursorX, in.CursorY)
+
+	if in.JustPressed[MouseLeft] {
+		c.pressed = c.hovered
+		// 点击空白处取消焦点
+		if c.focused != nil && c.focused != c.hovered {
+			c.focused = nil
+		}
+	}
+
+	updateTree(root, c)
+
+	if !in.Pressed[MouseLeft] && !in.JustPressed[MouseLeft] {
+		c.pressed = nil
+	}
+}
+
+// Layout positions a widget tree without updating it, for trees that are only drawn (like the HUD)
+func Layout(root Widget) {
+	layoutTree(root)
+}
+
+// Draw renders the widget tree
+func Draw(dst *ebiten.Image, root Widget) {
+	drawTree(dst, root)
+}
+
+// Hovered returns the topmost widget under the cursor, or nil
+func (c *Context) Hovered() Widget {
+	return c.hovered
+}
+
+// IsHovered reports whether w is the topmost widget under the cursor
+func (c *Context) IsHovered(w Widget) bool {
+	return c.hovered != nil && c.hovered == w
+}
+
+// IsPressed reports whether the left button went down on w and is still held
+func (c *Context) IsPressed(w Widget) bool {
+	return c.pressed != nil && c.pressed == w && c.Input.Pressed[MouseLeft]
+}
+
+// JustPressed reports whether button went down over w this frame
+func (c *Context) JustPressed(w Widget, button MouseButton) bool {
+	return c.Input.JustPressed[button] && c.IsHovered(w)
+}
+
+// Clicked reports whether a left click that started on w was released on w this frame
+func (c *Context) Clicked(w Widget) bool {
+	return c.Input.JustReleased[MouseLeft] && c.pressed == w && c.IsHovered(w)
+}
+
+// Focus gives keyboard focus to w
+func (c *Context) Focus(w Widget) {
+	c.focused = w
+}
+
+// IsFocused reports whether w has keyboard focus
+func (c *Context) IsFocused(w Widget) bool {
+	return c.focused != nil && c.focused == w
+}
+
+// Focused returns the widget with keyboard focus, or nil
+func (c *Context) Focused() Widget {
+	return c.focused
+}
+
+// WantsMouse reports whether the cursor is over a widget, so the game should ignore mouse clicks
+func (c *Context) WantsMouse() bool {
+	return c.hovered != nil
+}
+
+// WantsKeyboard reports whether a widget has keyboard focus, so the game should ignore typing
+func (c *Context) WantsKeyboard() bool {
+	return c.focused != nil
+}
+
+// layoutTree lets every container position its children, parents first
+func layoutTree(w Widget) {
+	if !w.Visible() {
+		return
+	}
+	if layouter, ok := w.(Layouter); ok {
+		layouter.Layout()
+	}
+	if container, ok := w.(Container); ok {
+		for _, child := range container.Children() {
+			layoutTree(child)
+		}
+	}
+}
+
+// hitTest returns the topmost visible widget containing the point.
+// Children are drawn after their parent, so they are tested first and in reverse order.
+func hitTest(w Widget, x, y float64) Widget {
+	if !w.Visible() {
+		return nil
+	}
+
+	bounds := w.Bounds()
+	clipped := false
+	if clipper, ok := w.(Clipper); ok && clipper.ClipChildren() {
+		clipped = true
+	}
+
+	if container, ok := w.(Container); ok && (!clipped || bounds.Contains(x, y)) {
+		children := container.Children()
+		for i := len(children) - 1; i >= 0; i-- {
+			if hit := hitTest(children[i], x, y); hit != nil {
+				return hit
+			}
+		}
+	}
+
+	if pass, ok := w.(interface{ passThrough() bool }); ok && pass.passThrough() {
+		return nil
+	}
+	if bounds.Contains(x, y) {
+		return w
+	}
+	return nil
+}
+
+// updateTree updates a widget and then its children
+func updateTree(w Widget, c *Context) {
+	if !w.Visible() {
+		return
+	}
+	w.Update(c)
+	if container, ok := w.(Container); ok {
+		for _, child := range container.Children() {
+			updateTree(child, c)
+		}
+	}
+}
+
+// drawTree draws a widget and then its children, clipping them when the widget asks for it
+func drawTree(dst *ebiten.Image, w Widget) {
+	if !w.Visible() {
+		return
+	}
+	w.Draw(dst)
+
+	container, ok := w.(Container)
+	if !ok {
+		return
+	}
+
+	target := dst
+	if clipper, ok := w.(Clipper); ok && clipper.ClipChildren() {
+		target = dst.SubImage(w.Bounds().image()).(*ebiten.Image)
+	}
+	for _, child := range container.Children() {
+		drawTree(target, child)
+	}
+}
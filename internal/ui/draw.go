@@ -0,0 +1,27 @@
+package ui
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// FillRect fills a rectangle with a solid color
+func FillRect(dst *ebiten.Image, r Rect, c color.Color) {
+	if c == nil {
+		return
+	}
+	ebitenutil.DrawRect(dst, r.X, r.Y, r.W, r.H, c)
+}
+
+// StrokeRect draws a rectangle outline of the given thickness inside r
+func StrokeRect(dst *ebiten.Image, r Rect, thickness float64, c color.Color) {
+	if c == nil || thickness <= 0 {
+		return
+	}
+	ebitenutil.DrawRect(dst, r.X, r.Y, r.W, thickness, c)               // Top
+	ebitenutil.DrawRect(dst, r.X, r.Y+r.H-thickness, r.W, thickness, c) // Bottom
+	ebitenutil.DrawRect(dst, r.X, r.Y, thickness, r.H, c)               // Left
+	ebitenutil.DrawRect(dst, r.X+r.W-thickness, r.Y, thickness, r.H, c) // Right
+}
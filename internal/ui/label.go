@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/wubinrui111/2d-game/internal/fonts"
+)
+
+// Label displays a piece of text
+type Label struct {
+	Base
+
+	// Text is the string to display; it may be changed every frame
+	Text string
+
+	// Style controls font, color, outline and wrapping
+	Style fonts.Style
+}
+
+// NewLabel creates a label that does not block the mouse
+func NewLabel(text string, style fonts.Style) *Label {
+	return &Label{
+		Base:  Base{PassThrough: true},
+		Text:  text,
+		Style: style,
+	}
+}
+
+// PreferredSize returns the measured size of the text
+func (l *Label) PreferredSize() (float64, float64) {
+	return fonts.Measure(l.Text, l.Style)
+}
+
+// Draw renders the text aligned inside the label's bounds
+func (l *Label) Draw(dst *ebiten.Image) {
+	x := l.bounds.X
+	switch l.Style.Align {
+	case fonts.AlignCenter:
+		x += l.bounds.W / 2
+	case fonts.AlignRight:
+		x += l.bounds.W
+	}
+	fonts.Draw(dst, l.Text, x, l.bounds.Y, l.Style)
+}
@@ -0,0 +1,182 @@
+package ui
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Direction is the axis along which a Panel arranges its children
+type Direction int
+
+const (
+	// Vertical stacks children from top to bottom
+	Vertical Direction = iota
+	// Horizontal stacks children from left to right
+	Horizontal
+	// Absolute leaves children where their owner placed them
+	Absolute
+)
+
+// CrossAlign is the alignment of children across the layout direction
+type CrossAlign int
+
+const (
+	CrossStart CrossAlign = iota
+	CrossCenter
+	CrossEnd
+	CrossStretch
+)
+
+// Panel is a container with an optional background and border that lays out its children
+type Panel struct {
+	Base
+
+	// Direction selects how children are arranged
+	Direction Direction
+
+	// Padding is the space between the panel's edge and its children
+	Padding float64
+
+	// Spacing is the gap between consecutive children
+	Spacing float64
+
+	// Align positions children across the layout direction
+	Align CrossAlign
+
+	// Background and Border are drawn behind the children when not nil
+	Background color.Color
+	Border     color.Color
+
+	// Width and Height fix the preferred size; 0 fits the children
+	Width, Height float64
+
+	children []Widget
+}
+
+// NewPanel creates a panel that arranges children along the given direction
+func NewPanel(direction Direction) *Panel {
+	return &Panel{Direction: direction}
+}
+
+// Add appends children to the panel and returns it for chaining
+func (p *Panel) Add(children ...Widget) *Panel {
+	p.children = append(p.children, children...)
+	return p
+}
+
+// Clear removes all children
+func (p *Panel) Clear() {
+	p.children = nil
+}
+
+// Children returns the panel's children
+func (p *Panel) Children() []Widget {
+	return p.children
+}
+
+// childSize returns the preferred size of a child, or its current size if it has none
+func childSize(w Widget) (float64, float64) {
+	if sizer, ok := w.(Sizer); ok {
+		return sizer.PreferredSize()
+	}
+	b := w.Bounds()
+	return b.W, b.H
+}
+
+// PreferredSize fits the visible children plus padding unless a fixed size is set
+func (p *Panel) PreferredSize() (float64, float64) {
+	var w, h float64
+	count := 0
+	for _, child := range p.children {
+		if !child.Visible() {
+			continue
+		}
+		cw, ch := childSize(child)
+		switch p.Direction {
+		case Vertical:
+			h += ch
+			if cw > w {
+				w = cw
+			}
+		case Horizontal:
+			w += cw
+			if ch > h {
+				h = ch
+			}
+		}
+		count++
+	}
+	if count > 1 {
+		gaps := p.Spacing * float64(count-1)
+		if p.Direction == Vertical {
+			h += gaps
+		} else if p.Direction == Horizontal {
+			w += gaps
+		}
+	}
+	w += 2 * p.Padding
+	h += 2 * p.Padding
+
+	if p.Width > 0 {
+		w = p.Width
+	}
+	if p.Height > 0 {
+		h = p.Height
+	}
+	return w, h
+}
+
+// Layout positions the children inside the panel's bounds
+func (p *Panel) Layout() {
+	if p.Direction == Absolute {
+		return
+	}
+
+	inner := p.bounds.Inset(p.Padding)
+	cursor := 0.0
+	for _, child := range p.children {
+		if !child.Visible() {
+			continue
+		}
+		cw, ch := childSize(child)
+
+		var r Rect
+		if p.Direction == Vertical {
+			r = Rect{X: inner.X, Y: inner.Y + cursor, W: cw, H: ch}
+			r.X, r.W = crossPlace(p.Align, inner.X, inner.W, cw)
+			cursor += ch + p.Spacing
+		} else {
+			r = Rect{X: inner.X + cursor, Y: inner.Y, W: cw, H: ch}
+			r.Y, r.H = crossPlace(p.Align, inner.Y, inner.H, ch)
+			cursor += cw + p.Spacing
+		}
+		child.SetBounds(r)
+	}
+}
+
+// crossPlace returns the position and size of a child across the layout direction
+func crossPlace(align CrossAlign, start, available, size float64) (float64, float64) {
+	switch align {
+	case CrossCenter:
+		return start + (available-size)/2, size
+	case CrossEnd:
+		return start + available - size, size
+	case CrossStretch:
+		return start, available
+	default:
+		return start, size
+	}
+}
+
+// Draw renders the background and border
+func (p *Panel) Draw(dst *ebiten.Image) {
+	FillRect(dst, p.bounds, p.Background)
+	StrokeRect(dst, p.bounds, 1, p.Border)
+}
+
+// PlaceAt sizes w to its preferred size and puts its top left corner at (x, y)
+func PlaceAt(w Widget, x, y float64) {
+	width, height := childSize(w)
+	w.SetBounds(Rect{X: x, Y: y, W: width, H: height})
+}
@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// scrollbarWidth is the width reserved for the scrollbar on the right edge
+const scrollbarWidth = 6.0
+
+// ScrollView shows a vertically scrollable window onto a taller content widget
+type ScrollView struct {
+	Base
+
+	// Content is the scrolled widget
+	Content Widget
+
+	// ScrollY is the distance the content is scrolled up, in pixels
+	ScrollY float64
+
+	// ScrollSpeed is the number of pixels scrolled per wheel step
+	ScrollSpeed float64
+
+	// Width and Height are the preferred size of the viewport
+	Width, Height float64
+
+	// Background is drawn behind the content when not nil
+	Background color.Color
+}
+
+// NewScrollView creates a viewport of the given size around content
+func NewScrollView(content Widget, width, height float64) *ScrollView {
+	return &ScrollView{
+		Content:     content,
+		ScrollSpeed: 24,
+		Width:       width,
+		Height:      height,
+	}
+}
+
+// Children returns the content widget
+func (s *ScrollView) Children() []Widget {
+	if s.Content == nil {
+		return nil
+	}
+	return []Widget{s.Content}
+}
+
+// ClipChildren keeps the content inside the viewport
+func (s *ScrollView) ClipChildren() bool {
+	return true
+}
+
+// PreferredSize returns the viewport size
+func (s *ScrollView) PreferredSize() (float64, float64) {
+	return s.Width, s.Height
+}
+
+// maxScroll returns how far the content can be scrolled
+func (s *ScrollView) maxScroll() float64 {
+	if s.Content == nil {
+		return 0
+	}
+	_, h := childSize(s.Content)
+	return math.Max(0, h-s.bounds.H)
+}
+
+// Layout places the content shifted by the scroll offset
+func (s *ScrollView) Layout() {
+	if s.Content == nil {
+		return
+	}
+	s.ScrollY = math.Max(0, math.Min(s.ScrollY, s.maxScroll()))
+
+	w, h := childSize(s.Content)
+	if s.maxScroll() > 0 {
+		w = math.Min(w, s.bounds.W-scrollbarWidth)
+	}
+	s.Content.SetBounds(Rect{X: s.bounds.X, Y: s.bounds.Y - s.ScrollY, W: w, H: h})
+}
+
+// Update scrolls with the mouse wheel while the cursor is over the viewport
+func (s *ScrollView) Update(ctx *Context) {
+	if ctx.Input.WheelY == 0 || !s.bounds.Contains(ctx.Input.CursorX, ctx.Input.CursorY) {
+		return
+	}
+	s.ScrollY -= ctx.Input.WheelY * s.ScrollSpeed
+	s.ScrollY = math.Max(0, math.Min(s.ScrollY, s.maxScroll()))
+}
+
+// Draw renders the background and the scrollbar
+func (s *ScrollView) Draw(dst *ebiten.Image) {
+	FillRect(dst, s.bounds, s.Background)
+
+	limit := s.maxScroll()
+	if limit <= 0 {
+		return
+	}
+	track := Rect{X: s.bounds.X + s.bounds.W - scrollbarWidth, Y: s.bounds.Y, W: scrollbarWidth, H: s.bounds.H}
+	FillRect(dst, track, color.RGBA{40, 40, 40, 160})
+
+	visible := s.bounds.H / (s.bounds.H + limit)
+	thumb := track
+	thumb.H = math.Max(12, track.H*visible)
+	thumb.Y += (track.H - thumb.H) * s.ScrollY / limit
+	FillRect(dst, thumb, color.RGBA{200, 200, 200, 220})
+}
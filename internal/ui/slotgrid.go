@@ -0,0 +1,132 @@
+package ui
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// SlotGrid is a grid of equally sized square slots, such as an inventory.
+// It owns the slot geometry so drawing and hit testing always agree.
+type SlotGrid struct {
+	Base
+
+	// Columns is the number of slots per row
+	Columns int
+
+	// SlotSize is the side length of a slot and Margin the gap between slots
+	SlotSize, Margin float64
+
+	// Count returns the current number of slots
+	Count func() int
+
+	// DrawSlot renders the slot at index inside r
+	DrawSlot func(dst *ebiten.Image, index int, r Rect, hovered bool)
+
+	// OnSlotPressed is called when a mouse button goes down over a slot
+	OnSlotPressed func(index int, button MouseButton)
+
+	hoveredSlot int
+}
+
+// NewSlotGrid creates a grid with the given column count and slot geometry
+func NewSlotGrid(columns int, slotSize, margin float64, count func() int) *SlotGrid {
+	return &SlotGrid{
+		Columns:     columns,
+		SlotSize:    slotSize,
+		Margin:      margin,
+		Count:       count,
+		hoveredSlot: -1,
+	}
+}
+
+// slotCount returns the number of slots, guarding against a missing Count
+func (g *SlotGrid) slotCount() int {
+	if g.Count == nil {
+		return 0
+	}
+	return g.Count()
+}
+
+// Rows returns the number of rows needed for the current slot count
+func (g *SlotGrid) Rows() int {
+	if g.Columns <= 0 {
+		return 0
+	}
+	return (g.slotCount() + g.Columns - 1) / g.Columns
+}
+
+// PreferredSize returns the size of the full grid
+func (g *SlotGrid) PreferredSize() (float64, float64) {
+	cols := g.Columns
+	if n := g.slotCount(); n < cols {
+		cols = n
+	}
+	stride := g.SlotSize + g.Margin
+	return math.Max(0, float64(cols)*stride-g.Margin), math.Max(0, float64(g.Rows())*stride-g.Margin)
+}
+
+// SlotRect returns the screen rectangle of the slot at index
+func (g *SlotGrid) SlotRect(index int) Rect {
+	stride := g.SlotSize + g.Margin
+	col := index % g.Columns
+	row := index / g.Columns
+	return Rect{
+		X: g.bounds.X + float64(col)*stride,
+		Y: g.bounds.Y + float64(row)*stride,
+		W: g.SlotSize,
+		H: g.SlotSize,
+	}
+}
+
+// SlotAt returns the index of the slot containing the point, or -1 for gaps and outside points
+func (g *SlotGrid) SlotAt(x, y float64) int {
+	if g.Columns <= 0 {
+		return -1
+	}
+	stride := g.SlotSize + g.Margin
+	col := int(math.Floor((x - g.bounds.X) / stride))
+	row := int(math.Floor((y - g.bounds.Y) / stride))
+	if col < 0 || col >= g.Columns || row < 0 {
+		return -1
+	}
+
+	index := row*g.Columns + col
+	if index >= g.slotCount() || !g.SlotRect(index).Contains(x, y) {
+		return -1
+	}
+	return index
+}
+
+// HoveredSlot returns the slot under the cursor during the last update, or -1
+func (g *SlotGrid) HoveredSlot() int {
+	return g.hoveredSlot
+}
+
+// Update tracks the hovered slot and reports presses
+func (g *SlotGrid) Update(ctx *Context) {
+	g.hoveredSlot = -1
+	if !ctx.IsHovered(g) {
+		return
+	}
+	g.hoveredSlot = g.SlotAt(ctx.Input.CursorX, ctx.Input.CursorY)
+
+	if g.OnSlotPressed == nil || g.hoveredSlot < 0 {
+		return
+	}
+	for _, button := range []MouseButton{MouseLeft, MouseRight, MouseMiddle} {
+		if ctx.Input.JustPressed[button] {
+			g.OnSlotPressed(g.hoveredSlot, button)
+		}
+	}
+}
+
+// Draw renders every slot through DrawSlot
+func (g *SlotGrid) Draw(dst *ebiten.Image) {
+	if g.DrawSlot == nil {
+		return
+	}
+	for i, n := 0, g.slotCount(); i < n; i++ {
+		g.DrawSlot(dst, i, g.SlotRect(i), i == g.hoveredSlot)
+	}
+}
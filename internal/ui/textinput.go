@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"image/color"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/wubinrui111/2d-game/internal/fonts"
+)
+
+// TextInput is a single-line editable text field
+type TextInput struct {
+	Base
+
+	// Text is the current content
+	Text string
+
+	// Placeholder is shown in grey while Text is empty
+	Placeholder string
+
+	// MaxLength limits the number of runes, 0 for no limit
+	MaxLength int
+
+	// Style is used for the text
+	Style fonts.Style
+
+	// Width is the preferred width of the field
+	Width float64
+
+	// OnChange is called after every edit and OnSubmit when Enter is pressed
+	OnChange func(text string)
+	OnSubmit func(text string)
+
+	focused bool
+	ticks   int
+}
+
+// NewTextInput creates an empty text field of the given width
+func NewTextInput(width float64) *TextInput {
+	return &TextInput{Width: width}
+}
+
+// PreferredSize returns the configured width and one line of text plus padding
+func (t *TextInput) PreferredSize() (float64, float64) {
+	_, h := fonts.Measure("Ag", t.Style)
+	return t.Width, h + 8
+}
+
+// Update takes focus on click and edits the text while focused
+func (t *TextInput) Update(ctx *Context) {
+	if ctx.JustPressed(t, MouseLeft) {
+		ctx.Focus(t)
+	}
+	t.focused = ctx.IsFocused(t)
+	t.ticks++
+	if !t.focused {
+		return
+	}
+
+	changed := false
+	for _, r := range ctx.Input.Chars {
+		if !unicode.IsPrint(r) {
+			continue
+		}
+		if t.MaxLength > 0 && utf8.RuneCountInString(t.Text) >= t.MaxLength {
+			break
+		}
+		t.Text += string(r)
+		changed = true
+	}
+
+	if ctx.Input.Backspace && t.Text != "" {
+		_, size := utf8.DecodeLastRuneInString(t.Text)
+		t.Text = t.Text[:len(t.Text)-size]
+		changed = true
+	}
+
+	if changed && t.OnChange != nil {
+		t.OnChange(t.Text)
+	}
+
+	if ctx.Input.Enter && t.OnSubmit != nil {
+		t.OnSubmit(t.Text)
+	}
+	if ctx.Input.Escape {
+		ctx.Focus(nil)
+		t.focused = false
+	}
+}
+
+// Draw renders the field, its text and a blinking caret while focused
+func (t *TextInput) Draw(dst *ebiten.Image) {
+	FillRect(dst, t.bounds, color.RGBA{20, 20, 30, 220})
+	border := color.RGBA{150, 150, 170, 255}
+	if t.focused {
+		border = color.RGBA{255, 255, 0, 255}
+	}
+	StrokeRect(dst, t.bounds, 1, border)
+
+	style := t.Style
+	style.Align = fonts.AlignLeft
+	style.WrapWidth = 0
+	content := t.Text
+	if content == "" && !t.focused {
+		content = t.Placeholder
+		style.Color = color.RGBA{140, 140, 140, 255}
+	}
+
+	// 文本过长时只显示末尾部分
+	maxWidth := t.bounds.W - 10
+	for content != "" && fonts.Advance(content, style) > maxWidth {
+		_, size := utf8.DecodeRuneInString(content)
+		content = content[size:]
+	}
+
+	_, textHeight := fonts.Measure("Ag", style)
+	y := t.bounds.Y + (t.bounds.H-textHeight)/2
+	fonts.Draw(dst, content, t.bounds.X+5, y, style)
+
+	if t.focused && (t.ticks/30)%2 == 0 {
+		caretX := t.bounds.X + 5 + fonts.Advance(content, style)
+		FillRect(dst, Rect{X: caretX, Y: y, W: 1, H: textHeight}, color.White)
+	}
+}
@@ -0,0 +1,211 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/wubinrui111/2d-game/internal/fonts"
+)
+
+func TestSlotGridSlotAt(t *testing.T) {
+	grid := NewSlotGrid(3, 32, 4, func() int { return 5 })
+	grid.SetBounds(Rect{X: 10, Y: 20, W: 104, H: 68})
+
+	tests := []struct {
+		x, y     float64
+		expected int
+	}{
+		{10, 20, 0},   // top left corner of the first slot
+		{50, 30, 1},   // second slot
+		{60, 60, 4},   // second row
+		{44, 30, -1},  // gap between slots
+		{5, 30, -1},   // left of the grid
+		{130, 30, -1}, // right of the last column
+		{90, 90, -1},  // below the last row
+		{30, 60, 3},   // first slot in the second row
+	}
+
+	for _, test := range tests {
+		if got := grid.SlotAt(test.x, test.y); got != test.expected {
+			t.Errorf("SlotAt(%v, %v): expected %d, got %d", test.x, test.y, test.expected, got)
+		}
+	}
+
+	// Slot 5 would be in the second row but does not exist
+	if got := grid.SlotAt(90+36, 60); got != -1 {
+		t.Errorf("Expected no slot past the slot count, got %d", got)
+	}
+}
+
+func TestSlotGridSlotRect(t *testing.T) {
+	grid := NewSlotGrid(9, 32, 4, func() int { return 36 })
+	grid.SetBounds(Rect{X: 10, Y: 60})
+
+	r := grid.SlotRect(10)
+	expected := Rect{X: 46, Y: 96, W: 32, H: 32}
+	if r != expected {
+		t.Errorf("Expected slot 10 at %v, got %v", expected, r)
+	}
+
+	if grid.Rows() != 4 {
+		t.Errorf("Expected 4 rows, got %d", grid.Rows())
+	}
+
+	w, h := grid.PreferredSize()
+	if w != 9*36-4 || h != 4*36-4 {
+		t.Errorf("Expected preferred size %vx%v, got %vx%v", 9*36-4, 4*36-4, w, h)
+	}
+}
+
+func TestPanelVerticalLayout(t *testing.T) {
+	first := NewProgressBar(100, 20)
+	second := NewProgressBar(50, 10)
+	hidden := NewProgressBar(80, 80)
+	hidden.Hidden = true
+
+	panel := NewPanel(Vertical)
+	panel.Padding = 10
+	panel.Spacing = 5
+	panel.Add(first, hidden, second)
+
+	w, h := panel.PreferredSize()
+	if w != 120 || h != 55 {
+		t.Errorf("Expected preferred size 120x55, got %vx%v", w, h)
+	}
+
+	panel.SetBounds(Rect{X: 0, Y: 0, W: w, H: h})
+	Layout(panel)
+
+	if first.Bounds() != (Rect{X: 10, Y: 10, W: 100, H: 20}) {
+		t.Errorf("Unexpected bounds for first child: %v", first.Bounds())
+	}
+	if second.Bounds() != (Rect{X: 10, Y: 35, W: 50, H: 10}) {
+		t.Errorf("Unexpected bounds for second child: %v", second.Bounds())
+	}
+}
+
+func TestPanelCrossAlign(t *testing.T) {
+	child := NewProgressBar(40, 10)
+
+	panel := NewPanel(Horizontal)
+	panel.Align = CrossCenter
+	panel.Add(child)
+	panel.SetBounds(Rect{X: 0, Y: 0, W: 100, H: 50})
+	Layout(panel)
+
+	if child.Bounds() != (Rect{X: 0, Y: 20, W: 40, H: 10}) {
+		t.Errorf("Expected child centered vertically, got %v", child.Bounds())
+	}
+}
+
+// press returns input with the left button going down at (x, y)
+func press(x, y float64) Input {
+	in := Input{CursorX: x, CursorY: y}
+	in.Pressed[MouseLeft] = true
+	in.JustPressed[MouseLeft] = true
+	return in
+}
+
+// release returns input with the left button going up at (x, y)
+func release(x, y float64) Input {
+	in := Input{CursorX: x, CursorY: y}
+	in.JustReleased[MouseLeft] = true
+	return in
+}
+
+func TestButtonClick(t *testing.T) {
+	clicks := 0
+	button := NewButton("OK", func() { clicks++ })
+	button.Width, button.Height = 80, 30
+
+	root := NewPanel(Vertical)
+	root.Add(button)
+	root.SetBounds(Rect{X: 0, Y: 0, W: 200, H: 100})
+
+	ctx := NewContext()
+	ctx.Update(root, press(10, 10))
+	if clicks != 0 {
+		t.Fatalf("Expected no click on press, got %d", clicks)
+	}
+	ctx.Update(root, release(10, 10))
+	if clicks != 1 {
+		t.Errorf("Expected one click after release, got %d", clicks)
+	}
+
+	// Releasing outside the button cancels the click
+	ctx.Update(root, press(10, 10))
+	ctx.Update(root, release(150, 80))
+	if clicks != 1 {
+		t.Errorf("Expected click to be cancelled when released outside, got %d clicks", clicks)
+	}
+}
+
+func TestContextWantsMouse(t *testing.T) {
+	label := NewLabel("hint", fonts.Style{})
+	label.SetBounds(Rect{X: 0, Y: 0, W: 100, H: 20})
+
+	slots := NewSlotGrid(2, 32, 4, func() int { return 2 })
+	PlaceAt(slots, 0, 50)
+
+	root := NewPanel(Absolute)
+	root.PassThrough = true
+	root.Add(label, slots)
+	root.SetBounds(Rect{X: 0, Y: 0, W: 800, H: 600})
+
+	ctx := NewContext()
+	ctx.Update(root, Input{CursorX: 10, CursorY: 10})
+	if ctx.WantsMouse() {
+		t.Error("Expected labels and pass-through panels not to capture the mouse")
+	}
+
+	ctx.Update(root, Input{CursorX: 10, CursorY: 60})
+	if !ctx.WantsMouse() || !ctx.IsHovered(slots) {
+		t.Error("Expected the slot grid to capture the mouse")
+	}
+	if slots.HoveredSlot() != 0 {
+		t.Errorf("Expected slot 0 to be hovered, got %d", slots.HoveredSlot())
+	}
+}
+
+func TestTextInputFocusAndTyping(t *testing.T) {
+	submitted := ""
+	input := NewTextInput(100)
+	input.MaxLength = 4
+	input.OnSubmit = func(text string) { submitted = text }
+	PlaceAt(input, 0, 0)
+
+	root := NewPanel(Absolute)
+	root.Add(input)
+	root.SetBounds(Rect{X: 0, Y: 0, W: 800, H: 600})
+
+	ctx := NewContext()
+	ctx.Update(root, Input{Chars: []rune("x")})
+	if input.Text != "" {
+		t.Fatalf("Expected unfocused input to ignore typing, got %q", input.Text)
+	}
+
+	ctx.Update(root, press(5, 5))
+	if !ctx.IsFocused(input) {
+		t.Fatal("Expected input to be focused after click")
+	}
+
+	ctx.Update(root, Input{Chars: []rune("世界ab!")})
+	if input.Text != "世界ab" {
+		t.Errorf("Expected text limited to 4 characters, got %q", input.Text)
+	}
+
+	ctx.Update(root, Input{Backspace: true})
+	if input.Text != "世界a" {
+		t.Errorf("Expected backspace to remove one character, got %q", input.Text)
+	}
+
+	ctx.Update(root, Input{Enter: true})
+	if submitted != "世界a" {
+		t.Errorf("Expected Enter to submit the text, got %q", submitted)
+	}
+
+	// Clicking elsewhere removes focus
+	ctx.Update(root, press(500, 500))
+	if ctx.WantsKeyboard() {
+		t.Error("Expected focus to be cleared by clicking outside")
+	}
+}
@@ -0,0 +1,164 @@
+// internal/ui/widget.go
+package ui
+
+import (
+	"image"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Rect is an axis-aligned rectangle in screen coordinates
+type Rect struct {
+	X, Y, W, H float64
+}
+
+// Contains reports whether the point lies inside the rectangle (edges included)
+func (r Rect) Contains(x, y float64) bool {
+	return x >= r.X && x <= r.X+r.W && y >= r.Y && y <= r.Y+r.H
+}
+
+// Inset returns the rectangle shrunk by d on every side
+func (r Rect) Inset(d float64) Rect {
+	return Rect{X: r.X + d, Y: r.Y + d, W: math.Max(0, r.W-2*d), H: math.Max(0, r.H-2*d)}
+}
+
+// image converts the rectangle to integer pixel bounds
+func (r Rect) image() image.Rectangle {
+	return image.Rect(int(math.Floor(r.X)), int(math.Floor(r.Y)), int(math.Ceil(r.X+r.W)), int(math.Ceil(r.Y+r.H)))
+}
+
+// Widget is the interface implemented by every UI element
+type Widget interface {
+	// Bounds returns the area the widget occupies on screen
+	Bounds() Rect
+	// SetBounds places the widget; containers call it during layout
+	SetBounds(r Rect)
+	// Visible reports whether the widget is drawn and receives input
+	Visible() bool
+	// Update handles input for the widget
+	Update(ctx *Context)
+	// Draw renders the widget
+	Draw(dst *ebiten.Image)
+}
+
+// Container is a widget with children
+type Container interface {
+	Widget
+	Children() []Widget
+}
+
+// Sizer is implemented by widgets that know how large they want to be
+type Sizer interface {
+	PreferredSize() (width, height float64)
+}
+
+// Layouter is implemented by containers that position their children
+type Layouter interface {
+	Layout()
+}
+
+// Clipper is implemented by containers whose children are only visible inside their bounds
+type Clipper interface {
+	ClipChildren() bool
+}
+
+// Base implements the bookkeeping shared by all widgets; embed it in concrete widgets
+type Base struct {
+	bounds Rect
+
+	// Hidden hides the widget and stops it from receiving input
+	Hidden bool
+
+	// PassThrough lets the mouse reach widgets (or the game) behind this one
+	PassThrough bool
+}
+
+// Bounds returns the widget's area
+func (b *Base) Bounds() Rect {
+	return b.bounds
+}
+
+// SetBounds sets the widget's area
+func (b *Base) SetBounds(r Rect) {
+	b.bounds = r
+}
+
+// Visible reports whether the widget is shown
+func (b *Base) Visible() bool {
+	return !b.Hidden
+}
+
+// Update does nothing by default
+func (b *Base) Update(ctx *Context) {}
+
+// Draw does nothing by default
+func (b *Base) Draw(dst *ebiten.Image) {}
+
+// passThrough reports whether hit testing should skip the widget itself
+func (b *Base) passThrough() bool {
+	return b.PassThrough
+}
+
+// MouseButton identifies a mouse button in Input
+type MouseButton int
+
+const (
+	MouseLeft MouseButton = iota
+	MouseRight
+	MouseMiddle
+	mouseButtonCount
+)
+
+// Input is the input state for one frame.
+// It is a plain struct so tests and replays can drive the UI without a window.
+type Input struct {
+	CursorX, CursorY float64
+
+	// Pressed, JustPressed and JustReleased are indexed by MouseButton
+	Pressed      [mouseButtonCount]bool
+	JustPressed  [mouseButtonCount]bool
+	JustReleased [mouseButtonCount]bool
+
+	// WheelY is the vertical scroll amount this frame
+	WheelY float64
+
+	// Chars are the characters typed this frame
+	Chars []rune
+
+	// Editing keys, true when pressed (or repeated) this frame
+	Backspace, Enter, Escape, Tab bool
+}
+
+// keyRepeated reports whether a held key should fire this frame, with a short repeat delay
+func keyRepeated(key ebiten.Key) bool {
+	d := inpututil.KeyPressDuration(key)
+	return d == 1 || (d >= 30 && d%4 == 0)
+}
+
+// PollInput reads the current frame's input from Ebiten
+func PollInput() Input {
+	x, y := ebiten.CursorPosition()
+	_, wheelY := ebiten.Wheel()
+
+	in := Input{
+		CursorX:   float64(x),
+		CursorY:   float64(y),
+		WheelY:    wheelY,
+		Chars:     ebiten.AppendInputChars(nil),
+		Backspace: keyRepeated(ebiten.KeyBackspace),
+		Enter:     inpututil.IsKeyJustPressed(ebiten.KeyEnter),
+		Escape:    inpututil.IsKeyJustPressed(ebiten.KeyEscape),
+		Tab:       inpututil.IsKeyJustPressed(ebiten.KeyTab),
+	}
+
+	buttons := [mouseButtonCount]ebiten.MouseButton{ebiten.MouseButtonLeft, ebiten.MouseButtonRight, ebiten.MouseButtonMiddle}
+	for i, button := range buttons {
+		in.Pressed[i] = ebiten.IsMouseButtonPressed(button)
+		in.JustPressed[i] = inpututil.IsMouseButtonJustPressed(button)
+		in.JustReleased[i] = inpututil.IsMouseButtonJustReleased(button)
+	}
+
+	return in
+}
@@ -0,0 +1,130 @@
+package worldgen
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// Biome is a kind of land: its blocks, how hilly it is, how much grows on it and the
+// tint of its grass and leaves
+type Biome struct {
+	ID      string
+	Surface string // Top block of the terrain
+	Filler  string // Blocks under the surface, FillerDepth deep
+
+	// Variance is how many cells the surface rises and falls
+	Variance float64
+
+	// Vegetation multiplies the chances of trees, tall grass and flowers
+	Vegetation float64
+
+	// Tint multiplies the colors of tinted blocks, such as grass and leaves
+	Tint color.RGBA
+}
+
+var (
+	// Plains are gentle grass hills with a few trees
+	Plains = &Biome{ID: "plains", Surface: "grass", Filler: "dirt", Variance: 3, Vegetation: 1, Tint: color.RGBA{255, 255, 255, 255}}
+
+	// Desert is flat sand with nothing growing on it
+	Desert = &Biome{ID: "desert", Surface: "sand", Filler: "sand", Variance: 2, Vegetation: 0, Tint: color.RGBA{230, 215, 140, 255}}
+
+	// Snow is high, steep land covered in snow with a few trees
+	Snow = &Biome{ID: "snow", Surface: "snow", Filler: "dirt", Variance: 7, Vegetation: 1, Tint: color.RGBA{190, 220, 215, 255}}
+
+	// Forest is grass land thick with trees
+	Forest = &Biome{ID: "forest", Surface: "grass", Filler: "dirt", Variance: 4, Vegetation: 2.5, Tint: color.RGBA{170, 215, 150, 255}}
+
+	// Biomes are all biomes, by which BiomeByID finds them
+	Biomes = []*Biome{Plains, Desert, Snow, Forest}
+)
+
+// ChooseBiome returns the biome of a climate, both values from 0 to 1: snow where it
+// is cold, desert where it is hot and dry, forest where it is wet and plains elsewhere
+func ChooseBiome(temperature, humidity float64) *Biome {
+	switch {
+	case temperature < 0.3:
+		return Snow
+	case temperature > 0.65 && humidity < 0.45:
+		return Desert
+	case humidity > 0.55:
+		return Forest
+	default:
+		return Plains
+	}
+}
+
+// BiomeByID returns the biome with an ID, nil if there is none
+func BiomeByID(id string) *Biome {
+	for _, b := range Biomes {
+		if b.ID == id {
+			return b
+		}
+	}
+	return nil
+}
+
+// ChunkColumn is a generated column of chunks: ChunkSize columns of cells from the
+// sky down to the bottom of the terrain, with the biome of each column
+type ChunkColumn struct {
+	X      int // In chunks
+	Biomes [ChunkSize]*Biome
+}
+
+// Biome returns the biome of a world column in the chunk column
+func (c *ChunkColumn) Biome(x int) *Biome {
+	return c.Biomes[x-c.X*ChunkSize]
+}
+
+// MarshalText writes the chunk column's biomes as its X and the runs of biome IDs,
+// such as "-2:plains*10,forest*6"
+func (c *ChunkColumn) MarshalText() ([]byte, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d:", c.X)
+	for i := 0; i < ChunkSize; {
+		n := 1
+		for i+n < ChunkSize && c.Biomes[i+n] == c.Biomes[i] {
+			n++
+		}
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if c.Biomes[i] == nil {
+			return nil, fmt.Errorf("worldgen: chunk column %d has no biome at %d", c.X, i)
+		}
+		fmt.Fprintf(&b, "%s*%d", c.Biomes[i].ID, n)
+		i += n
+	}
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText reads a chunk column's biomes written by MarshalText
+func (c *ChunkColumn) UnmarshalText(text []byte) error {
+	x, runs, found := strings.Cut(string(text), ":")
+	if !found {
+		return fmt.Errorf("worldgen: invalid chunk column %q", text)
+	}
+	var err error
+	if c.X, err = strconv.Atoi(x); err != nil {
+		return fmt.Errorf("worldgen: invalid chunk column %q", text)
+	}
+	i := 0
+	for _, run := range strings.Split(runs, ",") {
+		id, count, _ := strings.Cut(run, "*")
+		n, err := strconv.Atoi(count)
+		biome := BiomeByID(id)
+		if err != nil || n <= 0 || i+n > ChunkSize || biome == nil {
+			return fmt.Errorf("worldgen: invalid biomes %q of chunk column %d", run, c.X)
+		}
+		for ; n > 0; n-- {
+			c.Biomes[i] = biome
+			i++
+		}
+	}
+	if i != ChunkSize {
+		return fmt.Errorf("worldgen: chunk column %d has %d biomes, expected %d", c.X, i, ChunkSize)
+	}
+	return nil
+}
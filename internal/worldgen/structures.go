@@ -0,0 +1,127 @@
+package worldgen
+
+import (
+	"math"
+	"slices"
+
+	"github.com/wubinrui111/2d-game/internal/editor"
+	"github.com/wubinrui111/2d-game/internal/grid"
+)
+
+// structureSalt is the salt of the structure rolls; each structure adds its index
+// times three for its three decisions
+const structureSalt = 100
+
+// Structure is a schematic the generator places standing on the surface, such as a
+// hut. The world is split into sites of Spacing columns; each site holds the
+// structure with a chance, at a column of the site that depends on the seed.
+type Structure struct {
+	Region   *editor.Region
+	Surfaces []string // Surface blocks the structure stands on
+	Chance   float64  // Chance of each site to hold the structure
+	Spacing  int      // Width of a site, at least the width of the region
+}
+
+// Site is where a structure stands: the world cell its region's origin lands on
+type Site struct {
+	Structure *Structure
+	X, Y      int
+}
+
+// Bounds returns the cells the structure covers at the site
+func (s Site) Bounds() editor.Rect {
+	return s.Structure.Region.Bounds(grid.Cell{X: s.X, Y: s.Y})
+}
+
+// Sites returns where the generator's structures stand with a part in the columns
+// from x0 to x1, x1 excluded. A structure stands only where the ground under all its
+// columns is one of its surface blocks at the same height, and not where a structure
+// earlier in Structures could stand.
+func (g *Generator) Sites(x0, x1 int) []Site {
+	var sites []Site
+	for i := range g.Structures {
+		for _, site := range g.candidates(i, x0, x1) {
+			if !g.crowded(i, site) {
+				sites = append(sites, site)
+			}
+		}
+	}
+	return sites
+}
+
+// candidates returns the sites of structure i overlapping the columns from x0 to x1
+// that pass its chance and stand on flat ground of its surfaces
+func (g *Generator) candidates(i, x0, x1 int) []Site {
+	s := &g.Structures[i]
+	spacing := max(s.Spacing, s.Region.W)
+	salt := uint64(structureSalt + 3*i)
+
+	var sites []Site
+	first := int(math.Floor(float64(x0-spacing) / float64(spacing)))
+	last := int(math.Floor(float64(x1) / float64(spacing)))
+	for k := first; k <= last; k++ {
+		if hash(g.Seed, k, salt) >= s.Chance {
+			continue
+		}
+		// 在区域内随机选一列作为结构最左边的一列
+		left := k*spacing + int(hash(g.Seed, k, salt+1)*float64(spacing-s.Region.W+1))
+		if left+s.Region.W <= x0 || left >= x1 {
+			continue
+		}
+		y, ok := g.flatGround(left, s.Region.W, s.Surfaces)
+		if !ok {
+			continue
+		}
+		// 结构的底行立在地面上
+		sites = append(sites, Site{Structure: s, X: left + s.Region.Origin.X, Y: y - s.Region.H + s.Region.Origin.Y})
+	}
+	return sites
+}
+
+// crowded reports whether a site of structure i overlaps a site of an earlier structure
+func (g *Generator) crowded(i int, site Site) bool {
+	bounds := site.Bounds()
+	for j := 0; j < i; j++ {
+		for _, other := range g.candidates(j, bounds.X, bounds.X+bounds.W) {
+			if overlaps(bounds, other.Bounds()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// flatGround returns the height of the surface under the w columns from x if they
+// all have the same height and one of the surface blocks
+func (g *Generator) flatGround(x, w int, surfaces []string) (int, bool) {
+	y, _ := g.Surface(x)
+	for n := x; n < x+w; n++ {
+		top, id := g.Surface(n)
+		if top != y || !slices.Contains(surfaces, id) {
+			return 0, false
+		}
+	}
+	return y, true
+}
+
+// placeStructures calls set for the blocks of the structures that fall in r
+func (g *Generator) placeStructures(r Rect, set func(x, y int, id string)) {
+	for _, site := range g.Sites(r.X, r.X+r.W) {
+		for _, edit := range site.Structure.Region.Edits(grid.Cell{X: site.X, Y: site.Y}, false) {
+			if r.contains(edit.X, edit.Y) {
+				set(edit.X, edit.Y, edit.ID)
+			}
+		}
+	}
+}
+
+// nearStructure reports whether a tree in column x could reach a structure, so no
+// vegetation grows there
+func (g *Generator) nearStructure(x int) bool {
+	return len(g.Sites(x-treeRadius, x+treeRadius+1)) > 0
+}
+
+// overlaps reports whether two rectangles share a column
+func overlaps(a, b editor.Rect) bool {
+	return a.X < b.X+b.W && b.X < a.X+a.W
+}
@@ -0,0 +1,88 @@
+package worldgen
+
+import (
+	"testing"
+
+	"github.com/wubinrui111/2d-game/internal/editor"
+	"github.com/wubinrui111/2d-game/internal/grid"
+)
+
+// testHut is a 3x2 structure standing on grass: a roof over two walls, origin at the
+// bottom center
+func testHut() Structure {
+	return Structure{
+		Region:   &editor.Region{W: 3, H: 2, Origin: grid.Cell{X: 1, Y: 1}, Blocks: []string{"red_block", "red_block", "red_block", "wood", "", "wood"}},
+		Surfaces: []string{"grass"},
+		Chance:   0.5,
+		Spacing:  12,
+	}
+}
+
+func TestStructures(t *testing.T) {
+	g := NewGenerator(4)
+	g.Structures = []Structure{testHut()}
+	sites := g.Sites(-500, 500)
+	if len(sites) < 5 {
+		t.Fatalf("Expected some huts, got %d", len(sites))
+	}
+
+	var columns []int
+	for cx := -40; cx < 40; cx++ {
+		columns = append(columns, cx)
+	}
+	cells := fill(g, columns...)
+	for _, site := range sites {
+		// 立在平坦的草地上，和地面之间没有空隙
+		ground, id := g.Surface(site.X)
+		if id != "grass" || site.Y != ground-1 {
+			t.Errorf("Expected the hut at %d,%d on grass, the surface is %s at %d", site.X, site.Y, id, ground)
+		}
+		b := site.Bounds()
+		for x := b.X; x < b.X+b.W; x++ {
+			if y, _ := g.Surface(x); y != ground {
+				t.Errorf("Expected flat ground under the hut at %d", site.X)
+			}
+		}
+
+		// 方块按结构放置，旁边没有植被
+		if cells[cell{site.X - 1, site.Y}] != "wood" || cells[cell{site.X, site.Y - 1}] != "red_block" {
+			t.Errorf("Expected the hut's blocks at %d,%d", site.X, site.Y)
+		}
+		if id, exists := cells[cell{site.X, site.Y}]; exists {
+			t.Errorf("Expected nothing inside the hut, got %s", id)
+		}
+		for x := b.X - treeRadius; x < b.X+b.W+treeRadius; x++ {
+			if x >= b.X && x < b.X+b.W {
+				continue
+			}
+			y, _ := g.Surface(x)
+			if id := cells[cell{x, y - 1}]; id == TallGrassBlock || id == RedFlowerBlock || id == YellowFlowerBlock || id == LogBlock {
+				t.Errorf("Expected no vegetation next to the hut at %d, got %s", x, id)
+			}
+		}
+	}
+
+	// 区块列按任意顺序生成，结构的方块都一样
+	a := fill(g, -2, -1, 0, 1, 2)
+	b := fill(g, 2, 0, -2, 1, -1)
+	for c, id := range a {
+		if b[c] != id {
+			t.Errorf("Expected %s at %v, got %s", id, c, b[c])
+		}
+	}
+
+	// 后面的结构不和前面的重叠
+	big := testHut()
+	big.Chance = 1
+	g.Structures = append(g.Structures, big)
+	for _, site := range g.Sites(-500, 500) {
+		if site.Structure != &g.Structures[1] {
+			continue
+		}
+		for _, other := range g.Sites(site.Bounds().X, site.Bounds().X+site.Bounds().W) {
+			if other.Structure == &g.Structures[0] {
+				t.Errorf("Expected the second structure to keep away from the first at %d", site.X)
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package worldgen
+
+import (
+	"math"
+)
+
+const (
+	// SurfaceLevel is the y the surface rises and falls around
+	SurfaceLevel = 20
+
+	// FillerDepth is how many cells of a biome's filler block are under its surface
+	FillerDepth = 3
+
+	// Bottom is the y of the lowest row of terrain
+	Bottom = SurfaceLevel + 48
+
+	// StoneBlock is the block under the filler
+	StoneBlock = "stone"
+
+	// climateScale and heightScale are how many columns apart the points of the
+	// climate and height noise are
+	climateScale = 160.0
+	heightScale  = 24.0
+
+	// blendRadius is how many columns the hilliness of biomes blends over, so the
+	// surface has no cliffs where biomes meet
+	blendRadius = 8
+)
+
+// Salts of the noises, so they do not repeat each other
+const (
+	temperatureSalt = 10 + iota
+	humiditySalt
+	heightSalt
+)
+
+// Generator generates the terrain of a world seed, one chunk column at a time
+type Generator struct {
+	Seed       int64
+	Vegetation *Vegetation
+	Structures []Structure // Placed on the surface in this order; none by default
+}
+
+// NewGenerator creates the generator of a world seed, with vegetation as dense as
+// the biomes say and none around structures
+func NewGenerator(seed int64) *Generator {
+	g := &Generator{Seed: seed, Vegetation: NewVegetation(seed)}
+	g.Vegetation.Density = func(x int) float64 {
+		if g.nearStructure(x) {
+			return 0
+		}
+		return g.Biome(x).Vegetation
+	}
+	return g
+}
+
+// Climate returns the temperature and humidity of a column, both from 0 to 1
+func (g *Generator) Climate(x int) (temperature, humidity float64) {
+	return noise(g.Seed, temperatureSalt, x, climateScale), noise(g.Seed, humiditySalt, x, climateScale)
+}
+
+// Biome returns the biome of a column
+func (g *Generator) Biome(x int) *Biome {
+	return ChooseBiome(g.Climate(x))
+}
+
+// Surface implements Terrain: it returns the y and the block of a column's surface
+func (g *Generator) Surface(x int) (int, string) {
+	// 附近各列生物群系的起伏取平均，群系交界处地面平滑过渡
+	var variance float64
+	for n := x - blendRadius; n <= x+blendRadius; n++ {
+		variance += g.Biome(n).Variance
+	}
+	variance /= 2*blendRadius + 1
+
+	height := (2*noise(g.Seed, heightSalt, x, heightScale) - 1) * variance
+	return SurfaceLevel - int(math.Round(height)), g.Biome(x).Surface
+}
+
+// Column returns the chunk column cx with the biomes of its columns
+func (g *Generator) Column(cx int) *ChunkColumn {
+	c := &ChunkColumn{X: cx}
+	for i := range c.Biomes {
+		c.Biomes[i] = g.Biome(cx*ChunkSize + i)
+	}
+	return c
+}
+
+// Fill places the blocks of a chunk column by calling set for each cell that gets
+// one: the surface, filler and stone of the terrain, then the structures, then the
+// vegetation. Cells outside the chunk column are never set.
+func (g *Generator) Fill(c *ChunkColumn, set func(x, y int, id string)) {
+	for i, biome := range c.Biomes {
+		x := c.X*ChunkSize + i
+		surfaceY, surface := g.Surface(x)
+		set(x, surfaceY, surface)
+		for y := surfaceY + 1; y <= Bottom; y++ {
+			id := StoneBlock
+			if y <= surfaceY+FillerDepth {
+				id = biome.Filler
+			}
+			set(x, y, id)
+		}
+	}
+
+	// 植被最高到最高的地面上最高的树冠，包括相邻区块的树伸进来的树冠
+	var variance float64
+	for _, b := range Biomes {
+		variance = math.Max(variance, b.Variance)
+	}
+	sky := SurfaceLevel - int(math.Ceil(variance)) - maxTrunk - 1
+	for _, s := range g.Structures {
+		sky = min(sky, SurfaceLevel-int(math.Ceil(variance))-s.Region.H)
+	}
+	r := Rect{c.X * ChunkSize, sky, ChunkSize, Bottom + 1 - sky}
+	g.placeStructures(r, set)
+	g.Vegetation.DecorateRect(r, g, set)
+}
+
+// noise returns smooth value noise from 0 to 1 along x: random values scale columns
+// apart, blended with a smoothstep
+func noise(seed int64, salt uint64, x int, scale float64) float64 {
+	pos := float64(x) / scale
+	i := math.Floor(pos)
+	t := pos - i
+	t = t * t * (3 - 2*t)
+	a, b := hash(seed, int(i), salt), hash(seed, int(i)+1, salt)
+	return a + (b-a)*t
+}
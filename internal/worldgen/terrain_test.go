@@ -0,0 +1,182 @@
+package worldgen
+
+import (
+	"testing"
+)
+
+func TestChooseBiome(t *testing.T) {
+	tests := []struct {
+		temperature, humidity float64
+		biome                 *Biome
+	}{
+		{0.1, 0.9, Snow},
+		{0.8, 0.2, Desert},
+		{0.8, 0.7, Forest},
+		{0.5, 0.7, Forest},
+		{0.5, 0.5, Plains},
+		{0.7, 0.5, Plains},
+	}
+	for _, test := range tests {
+		if got := ChooseBiome(test.temperature, test.humidity); got != test.biome {
+			t.Errorf("Expected %s at temperature %v and humidity %v, got %s", test.biome.ID, test.temperature, test.humidity, got.ID)
+		}
+	}
+}
+
+func TestBiomesAndSurface(t *testing.T) {
+	g := NewGenerator(5)
+	seen := make(map[*Biome]bool)
+	prev, _ := g.Surface(-5000)
+	for x := -5000; x < 5000; x++ {
+		seen[g.Biome(x)] = true
+		y, id := g.Surface(x)
+		if id != g.Biome(x).Surface {
+			t.Fatalf("Expected the surface of %s at x=%d, got %s", g.Biome(x).ID, x, id)
+		}
+		// 群系交界处也没有陡崖
+		if y-prev > 2 || prev-y > 2 {
+			t.Fatalf("Expected a smooth surface, got %d then %d at x=%d", prev, y, x)
+		}
+		prev = y
+	}
+	for _, b := range Biomes {
+		if !seen[b] {
+			t.Errorf("Expected some %s", b.ID)
+		}
+	}
+
+	again := NewGenerator(5)
+	for x := -100; x < 100; x++ {
+		y1, id1 := g.Surface(x)
+		y2, id2 := again.Surface(x)
+		if y1 != y2 || id1 != id2 {
+			t.Fatalf("Expected the same surface for the same seed at x=%d", x)
+		}
+	}
+}
+
+// fill generates chunk columns into a map in the given order
+func fill(g *Generator, columns ...int) map[cell]string {
+	cells := make(map[cell]string)
+	for _, cx := range columns {
+		g.Fill(g.Column(cx), func(x, y int, id string) {
+			cells[cell{x, y}] = id
+		})
+	}
+	return cells
+}
+
+func TestFill(t *testing.T) {
+	g := NewGenerator(11)
+	cells := fill(g, 0)
+	for c := range cells {
+		if c.x < 0 || c.x >= ChunkSize || c.y > Bottom {
+			t.Fatalf("Expected only cells of chunk column 0, got %v", c)
+		}
+	}
+	for x := 0; x < ChunkSize; x++ {
+		biome := g.Biome(x)
+		y, _ := g.Surface(x)
+		if cells[cell{x, y}] != biome.Surface || cells[cell{x, y + FillerDepth}] != biome.Filler ||
+			cells[cell{x, y + FillerDepth + 1}] != StoneBlock || cells[cell{x, Bottom}] != StoneBlock {
+			t.Errorf("Expected %s over %s over stone at x=%d", biome.Surface, biome.Filler, x)
+		}
+	}
+
+	// 相邻的区块列按任意顺序生成，结果都一样
+	a := fill(g, -3, -2, -1, 0, 1, 2, 3)
+	b := fill(g, 3, 0, -3, 1, -1, 2, -2)
+	if len(a) != len(b) {
+		t.Fatalf("Expected the same cells in any order, got %d and %d", len(a), len(b))
+	}
+	for c, id := range a {
+		if b[c] != id {
+			t.Errorf("Expected %s at %v, got %s", id, c, b[c])
+		}
+	}
+}
+
+func TestBiomeVegetation(t *testing.T) {
+	g := NewGenerator(2)
+	trees := make(map[*Biome]int)
+	columns := make(map[*Biome]int)
+	for x := -3000; x < 3000; x++ {
+		biome := g.Biome(x)
+		columns[biome]++
+		if g.Vegetation.tree(x, Rule{Trees: DefaultRules()[biome.Surface].Trees * biome.Vegetation}) {
+			trees[biome]++
+		}
+	}
+	if trees[Desert] != 0 {
+		t.Errorf("Expected no trees in the desert, got %d", trees[Desert])
+	}
+	perColumn := func(b *Biome) float64 { return float64(trees[b]) / float64(columns[b]) }
+	if columns[Forest] == 0 || columns[Plains] == 0 || perColumn(Forest) <= perColumn(Plains) {
+		t.Errorf("Expected more trees in forests than on plains, got %v and %v", perColumn(Forest), perColumn(Plains))
+	}
+}
+
+func TestChunkColumnText(t *testing.T) {
+	c := &ChunkColumn{X: -2}
+	for i := range c.Biomes {
+		c.Biomes[i] = Plains
+		if i >= 10 {
+			c.Biomes[i] = Forest
+		}
+	}
+	text, err := c.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(text) != "-2:plains*10,forest*6" {
+		t.Errorf("Unexpected text %s", text)
+	}
+
+	var decoded ChunkColumn
+	if err := decoded.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != *c {
+		t.Errorf("Expected %v, got %v", c, decoded)
+	}
+	if decoded.Biome(-32) != Plains || decoded.Biome(-17) != Forest {
+		t.Error("Expected the biomes of world columns")
+	}
+
+	for _, bad := range []string{"", "x:plains*16", "0:plains*15", "0:plains*17", "0:jungle*16", "0:plains", "0:plains*0,forest*16"} {
+		if err := new(ChunkColumn).UnmarshalText([]byte(bad)); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+	if _, err := new(ChunkColumn).MarshalText(); err == nil {
+		t.Error("Expected an error for a column without biomes")
+	}
+}
+
+func TestFillGrowsVegetation(t *testing.T) {
+	g := NewGenerator(4)
+	cells := fill(g, -4, -3, -2, -1, 0, 1, 2, 3)
+
+	// 生成的区块列里的植物和逐个区块放置在这片地形上的植被一样
+	plants := make(map[cell]string)
+	for cx := -4; cx <= 3; cx++ {
+		for cy := -2; cy*ChunkSize <= Bottom; cy++ {
+			g.Vegetation.Decorate(Chunk{cx, cy}, g, func(x, y int, id string) {
+				plants[cell{x, y}] = id
+			})
+		}
+	}
+	if len(plants) == 0 {
+		t.Fatal("Expected some plants on the generated terrain")
+	}
+	for c, id := range plants {
+		if cells[c] != id {
+			t.Errorf("Expected %s at %v in the generated terrain, got %q", id, c, cells[c])
+		}
+	}
+	for c, id := range cells {
+		if y, _ := g.Surface(c.x); c.y < y && plants[c] != id {
+			t.Errorf("Expected only vegetation above the surface, got %s at %v", id, c)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Package worldgen generates the world: terrain shaped by biomes, and vegetation that
+// decorates it. Everything is a function of the seed, so a chunk comes out the same
+// no matter when, or next to which other chunks, it is generated.
+package worldgen
+
+// ChunkSize is the width and height of a chunk in cells, the same as a render chunk
+const ChunkSize = 16
+
+const (
+	// treeSpacing is the fewest columns between two trees
+	treeSpacing = 3
+
+	// treeRadius is how far a tree's canopy reaches from its trunk
+	treeRadius = 2
+
+	// minTrunk and maxTrunk are the heights of tree trunks
+	minTrunk, maxTrunk = 3, 5
+)
+
+// Block IDs of the vegetation
+const (
+	LogBlock          = "wood"
+	LeavesBlock       = "leaves"
+	TallGrassBlock    = "tall_grass"
+	RedFlowerBlock    = "red_flower"
+	YellowFlowerBlock = "yellow_flower"
+)
+
+// Terrain is the generated terrain that vegetation grows on. It must give the same
+// answer for a column every time, including columns of chunks not generated yet.
+type Terrain interface {
+	// Surface returns the y of the top block of column x and the block's ID. Cells
+	// above it are empty; y grows downwards.
+	Surface(x int) (y int, id string)
+}
+
+// Rule is how much vegetation grows on a surface block, as the chance of each column
+type Rule struct {
+	Trees   float64
+	Grass   float64
+	Flowers float64
+}
+
+// DefaultRules are the vegetation of the surface blocks: trees, tall grass and
+// flowers on grass, a little tall grass on dirt, a few trees on snow and nothing on
+// other blocks
+func DefaultRules() map[string]Rule {
+	return map[string]Rule{
+		"grass": {Trees: 0.12, Grass: 0.3, Flowers: 0.08},
+		"dirt":  {Grass: 0.1},
+		"snow":  {Trees: 0.06},
+	}
+}
+
+// Chunk is a ChunkSize x ChunkSize square of cells
+type Chunk struct {
+	X, Y int
+}
+
+// Rect is a rectangle of cells
+type Rect struct {
+	X, Y, W, H int
+}
+
+// contains reports whether a cell is inside r
+func (r Rect) contains(x, y int) bool {
+	return x >= r.X && x < r.X+r.W && y >= r.Y && y < r.Y+r.H
+}
+
+// Bounds returns the cells of a chunk
+func (c Chunk) Bounds() Rect {
+	return Rect{c.X * ChunkSize, c.Y * ChunkSize, ChunkSize, ChunkSize}
+}
+
+// Vegetation places trees, tall grass and flowers on terrain
+type Vegetation struct {
+	Seed  int64
+	Rules map[string]Rule // By surface block ID
+
+	// Density multiplies the chances of the rules in a column, such as by its biome;
+	// nil leaves them as they are
+	Density func(x int) float64
+}
+
+// NewVegetation creates the vegetation of a world seed with the default rules
+func NewVegetation(seed int64) *Vegetation {
+	return &Vegetation{Seed: seed, Rules: DefaultRules()}
+}
+
+// Decorate places the vegetation of a chunk by calling set for each of its cells
+// that gets a plant. Trees growing in the chunks next to it reach into it, so its
+// cells are set as if the whole world was decorated at once.
+func (v *Vegetation) Decorate(chunk Chunk, terrain Terrain, set func(x, y int, id string)) {
+	v.DecorateRect(chunk.Bounds(), terrain, set)
+}
+
+// DecorateRect places the vegetation of the cells in r
+func (v *Vegetation) DecorateRect(r Rect, terrain Terrain, set func(x, y int, id string)) {
+	// 从区域两侧树冠能够伸进来的列开始，只写入区域内的格子
+	for x := r.X - treeRadius; x < r.X+r.W+treeRadius; x++ {
+		surfaceY, id := terrain.Surface(x)
+		rule, exists := v.Rules[id]
+		if !exists {
+			continue
+		}
+		if v.Density != nil {
+			d := v.Density(x)
+			rule = Rule{Trees: rule.Trees * d, Grass: rule.Grass * d, Flowers: rule.Flowers * d}
+		}
+		place := func(px, py int, block string) {
+			// 植物只长在空格子里，不会替换地形
+			if top, _ := terrain.Surface(px); r.contains(px, py) && py < top {
+				set(px, py, block)
+			}
+		}
+
+		if v.tree(x, rule) {
+			v.growTree(x, surfaceY, place)
+			continue
+		}
+		if x < r.X || x >= r.X+r.W {
+			continue
+		}
+		// 没有树的列按概率长草或花
+		switch roll := v.roll(x, 1); {
+		case roll < rule.Flowers:
+			flower := RedFlowerBlock
+			if v.roll(x, 2) < 0.5 {
+				flower = YellowFlowerBlock
+			}
+			place(x, surfaceY-1, flower)
+		case roll < rule.Flowers+rule.Grass:
+			place(x, surfaceY-1, TallGrassBlock)
+		}
+	}
+}
+
+// tree reports whether a tree grows in column x: the column's roll must be below the
+// chance and the lowest of the columns within treeSpacing, so trees never crowd
+func (v *Vegetation) tree(x int, rule Rule) bool {
+	roll := v.roll(x, 0)
+	if roll >= rule.Trees {
+		return false
+	}
+	for n := x - treeSpacing; n <= x+treeSpacing; n++ {
+		if other := v.roll(n, 0); n != x && (other < roll || other == roll && n < x) {
+			return false
+		}
+	}
+	return true
+}
+
+// growTree places a tree standing on the surface at column x: a trunk of logs with a
+// canopy of leaves that is wide at the bottom and narrow at the top
+func (v *Vegetation) growTree(x, surfaceY int, place func(x, y int, id string)) {
+	height := minTrunk + int(v.roll(x, 3)*float64(maxTrunk-minTrunk+1))
+	top := surfaceY - height
+	for y := top; y < surfaceY; y++ {
+		place(x, y, LogBlock)
+	}
+	for dy := -1; dy <= 1; dy++ {
+		radius := treeRadius
+		if dy < 0 {
+			radius--
+		}
+		for dx := -radius; dx <= radius; dx++ {
+			if dx != 0 || dy < 0 {
+				place(x+dx, top+dy, LeavesBlock)
+			}
+		}
+	}
+}
+
+// roll returns a number in [0, 1) that depends only on the seed, a column and which
+// decision it is for
+func (v *Vegetation) roll(x int, decision uint64) float64 {
+	return hash(v.Seed, x, decision)
+}
+
+// hash returns a number in [0, 1) that depends only on a seed, a position and a salt
+func hash(seed int64, x int, salt uint64) float64 {
+	h := mix(uint64(seed) ^ mix(uint64(int64(x))) ^ mix(salt+0x9e3779b97f4a7c15))
+	return float64(h>>11) / (1 << 53)
+}
+
+// mix scrambles the bits of a number (the SplitMix64 finalizer)
+func mix(z uint64) uint64 {
+	z ^= z >> 30
+	z *= 0xbf58476d1ce4e5b9
+	z ^= z >> 27
+	z *= 0x94d049bb133111eb
+	z ^= z >> 31
+	return z
+}
@@ -0,0 +1,143 @@
+package worldgen
+
+import (
+	"testing"
+)
+
+// hills is a terrain of grass hills, with a stretch of sand
+type hills struct{}
+
+func (hills) Surface(x int) (int, string) {
+	y := 26 + (x*x/7)%5
+	if x >= 40 && x < 48 {
+		return y, "sand"
+	}
+	return y, "grass"
+}
+
+// cell is a cell of a decorated map
+type cell struct {
+	x, y int
+}
+
+// decorate decorates the chunks one at a time in the given order
+func decorate(v *Vegetation, chunks []Chunk) map[cell]string {
+	cells := make(map[cell]string)
+	for _, c := range chunks {
+		v.Decorate(c, hills{}, func(x, y int, id string) {
+			cells[cell{x, y}] = id
+		})
+	}
+	return cells
+}
+
+// row returns the chunks of row 1 from x0 to x1
+func row(x0, x1 int) []Chunk {
+	var chunks []Chunk
+	for x := x0; x <= x1; x++ {
+		chunks = append(chunks, Chunk{x, 1})
+	}
+	return chunks
+}
+
+func TestVegetationIsDeterministic(t *testing.T) {
+	a := decorate(NewVegetation(42), row(-4, 4))
+	b := decorate(NewVegetation(42), row(-4, 4))
+	if len(a) == 0 || len(a) != len(b) {
+		t.Fatalf("Expected the same vegetation, got %d and %d plants", len(a), len(b))
+	}
+	for c, id := range a {
+		if b[c] != id {
+			t.Errorf("Expected %s at %v, got %s", id, c, b[c])
+		}
+	}
+
+	other := decorate(NewVegetation(43), row(-4, 4))
+	same := len(other) == len(a)
+	for c, id := range a {
+		same = same && other[c] == id
+	}
+	if same {
+		t.Error("Expected another seed to give other vegetation")
+	}
+}
+
+func TestVegetationAcrossChunks(t *testing.T) {
+	v := NewVegetation(7)
+	whole := make(map[cell]string)
+	v.DecorateRect(Rect{-4 * ChunkSize, ChunkSize, 9 * ChunkSize, ChunkSize}, hills{}, func(x, y int, id string) {
+		whole[cell{x, y}] = id
+	})
+
+	// 按任意顺序逐个生成区块，树冠跨过区块边界的部分也一样
+	chunks := row(-4, 4)
+	reversed := make([]Chunk, len(chunks))
+	for i, c := range chunks {
+		reversed[len(chunks)-1-i] = c
+	}
+	for name, cells := range map[string]map[cell]string{"in order": decorate(v, chunks), "reversed": decorate(v, reversed)} {
+		if len(cells) != len(whole) {
+			t.Errorf("%s: expected %d plants, got %d", name, len(whole), len(cells))
+		}
+		for c, id := range whole {
+			if cells[c] != id {
+				t.Errorf("%s: expected %s at %v, got %s", name, id, c, cells[c])
+			}
+		}
+	}
+
+	// 只生成一个区块时不会写到区块外
+	for c := range decorate(v, []Chunk{{0, 1}}) {
+		if !(Chunk{0, 1}).Bounds().contains(c.x, c.y) {
+			t.Errorf("Expected only cells of the chunk, got %v", c)
+		}
+	}
+}
+
+func TestVegetationPlacement(t *testing.T) {
+	v := NewVegetation(3)
+	cells := decorate(v, row(-8, 8))
+
+	trunks := 0
+	lastTree := -1000
+	for x := -8 * ChunkSize; x < 9*ChunkSize; x++ {
+		top, ground := hills{}.Surface(x)
+		for y := ChunkSize; y < 2*ChunkSize; y++ {
+			if id, exists := cells[cell{x, y}]; exists && y >= top {
+				t.Fatalf("Expected plants only above the surface, got %s at (%d, %d)", id, x, y)
+			}
+		}
+		if ground == "sand" && cells[cell{x, top - 1}] != "" {
+			t.Errorf("Expected nothing to grow on sand at x=%d, got %s", x, cells[cell{x, top - 1}])
+		}
+		if cells[cell{x, top - 1}] != LogBlock {
+			continue
+		}
+		// 树干站在地面上，树与树之间有间隔
+		trunks++
+		if x-lastTree <= treeSpacing {
+			t.Errorf("Expected trees at least %d columns apart, got %d and %d", treeSpacing+1, lastTree, x)
+		}
+		lastTree = x
+		height := 0
+		for cells[cell{x, top - 1 - height}] == LogBlock {
+			height++
+		}
+		if height < minTrunk || height > maxTrunk || cells[cell{x, top - 1 - height}] != LeavesBlock {
+			t.Errorf("Expected a trunk of %d to %d logs under leaves at x=%d, got %d", minTrunk, maxTrunk, x, height)
+		}
+	}
+	if trunks == 0 {
+		t.Error("Expected some trees")
+	}
+
+	counts := make(map[string]int)
+	for _, id := range cells {
+		counts[id]++
+	}
+	for _, id := range []string{LeavesBlock, TallGrassBlock, RedFlowerBlock, YellowFlowerBlock} {
+		if counts[id] == 0 {
+			t.Errorf("Expected some %s", id)
+		}
+	}
+}
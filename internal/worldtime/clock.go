@@ -0,0 +1,211 @@
+// Package worldtime keeps the time of day of the world. The clock counts game ticks;
+// the time of day decides the colour of the sky, where the sun and moon are and how
+// much sunlight reaches the ground.
+package worldtime
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/wubinrui111/2d-game/internal/lighting"
+)
+
+// DefaultTicksPerDay is the length of a day: 20 minutes at 60 ticks per second
+const DefaultTicksPerDay = 72000
+
+// Times of day, as a fraction of the day
+const (
+	Midnight = 0.0
+	Sunrise  = 0.25
+	Morning  = 0.3 // When a new world starts
+	Noon     = 0.5
+	Sunset   = 0.75
+
+	// twilight is how long before and after sunrise and sunset the light changes
+	twilight = 0.04
+)
+
+// TimeNames are the times of day Set accepts by name
+var TimeNames = map[string]float64{
+	"midnight": Midnight,
+	"sunrise":  Sunrise,
+	"morning":  Morning,
+	"noon":     Noon,
+	"sunset":   Sunset,
+}
+
+// NightAmbient is the ambient light level at night, from the moon
+const NightAmbient = 4
+
+// HostileSpawnLight is the highest light level hostile mobs spawn at
+const HostileSpawnLight = 7
+
+var (
+	// 天空顶部和地平线的颜色：白天、夜晚和日出日落时地平线的霞光
+	dayTop       = color.RGBA{135, 206, 235, 255}
+	dayHorizon   = color.RGBA{200, 232, 245, 255}
+	nightTop     = color.RGBA{6, 8, 24, 255}
+	nightHorizon = color.RGBA{24, 32, 64, 255}
+	glowHorizon  = color.RGBA{250, 140, 70, 255}
+)
+
+// Clock is the world's time
+type Clock struct {
+	Ticks       int64 // Ticks since the world began
+	TicksPerDay int64
+	Frozen      bool // Whether Advance keeps the time as it is
+}
+
+// New creates a clock in the morning of the first day
+func New(ticksPerDay int64) *Clock {
+	c := &Clock{TicksPerDay: ticksPerDay}
+	c.SetTimeOfDay(Morning)
+	return c
+}
+
+// Advance moves the clock one tick forward, unless it is frozen
+func (c *Clock) Advance() {
+	if !c.Frozen {
+		c.Ticks++
+	}
+}
+
+// Day returns the number of the current day, from 0
+func (c *Clock) Day() int64 {
+	return floorDiv(c.Ticks, c.TicksPerDay)
+}
+
+// TimeOfDay returns how far the current day is, from 0 (midnight) to 1
+func (c *Clock) TimeOfDay() float64 {
+	return float64(c.Ticks-c.Day()*c.TicksPerDay) / float64(c.TicksPerDay)
+}
+
+// SetTimeOfDay sets the time of the current day, from 0 (midnight) to 1
+func (c *Clock) SetTimeOfDay(t float64) {
+	t -= math.Floor(t)
+	c.Ticks = c.Day()*c.TicksPerDay + int64(t*float64(c.TicksPerDay))
+}
+
+// SkipTo moves the clock forward to the next time the time of day is t
+func (c *Clock) SkipTo(t float64) {
+	t -= math.Floor(t)
+	target := c.Day()*c.TicksPerDay + int64(t*float64(c.TicksPerDay))
+	if target <= c.Ticks {
+		target += c.TicksPerDay
+	}
+	c.Ticks = target
+}
+
+// Set sets the clock from a time command: a name of TimeNames or a time on a 24 hour
+// clock such as "18:30" sets the time of the current day, and a number sets the ticks
+// since the world began
+func (c *Clock) Set(command string) error {
+	command = strings.ToLower(strings.TrimSpace(command))
+	if t, exists := TimeNames[command]; exists {
+		c.SetTimeOfDay(t)
+		return nil
+	}
+	if hour, minute, found := strings.Cut(command, ":"); found {
+		h, errH := strconv.Atoi(hour)
+		m, errM := strconv.Atoi(minute)
+		if errH != nil || errM != nil || h < 0 || h >= 24 || m < 0 || m >= 60 {
+			return fmt.Errorf("worldtime: invalid time %q", command)
+		}
+		c.Ticks = c.Day()*c.TicksPerDay + int64(h*60+m)*c.TicksPerDay/(24*60)
+		return nil
+	}
+	ticks, err := strconv.ParseInt(command, 10, 64)
+	if err != nil || ticks < 0 {
+		return fmt.Errorf("worldtime: invalid time %q", command)
+	}
+	c.Ticks = ticks
+	return nil
+}
+
+// HourMinute returns the time of day on a 24 hour clock
+func (c *Clock) HourMinute() (hour, minute int) {
+	minutes := int(c.TimeOfDay() * 24 * 60)
+	return minutes / 60, minutes % 60
+}
+
+// Daylight returns how much of the day's sunlight there is, from 0 at night to 1
+// by day, changing smoothly around sunrise and sunset
+func (c *Clock) Daylight() float64 {
+	t := c.TimeOfDay()
+	if t < Noon {
+		return smoothstep((t - (Sunrise - twilight)) / (2 * twilight))
+	}
+	return 1 - smoothstep((t-(Sunset-twilight))/(2*twilight))
+}
+
+// Ambient returns the light level of the open sky, for the lighting engine
+func (c *Clock) Ambient() float64 {
+	return NightAmbient + c.Daylight()*(lighting.MaxLevel-NightAmbient)
+}
+
+// HostileMobsCanSpawn reports whether the light lets a hostile mob spawn in an empty
+// cell with the given sunlight and block light. Sunlight is dimmed by the clock's
+// ambient light, so in the open mobs spawn only at night.
+func (c *Clock) HostileMobsCanSpawn(sky, block int) bool {
+	sunlight := max(0, float64(sky)-(lighting.MaxLevel-c.Ambient()))
+	return int(max(sunlight, float64(block))) <= HostileSpawnLight
+}
+
+// Night reports whether the sun is down
+func (c *Clock) Night() bool {
+	t := c.TimeOfDay()
+	return t < Sunrise || t >= Sunset
+}
+
+// SkyColors returns the colour of the sky at its top and at the horizon
+func (c *Clock) SkyColors() (top, horizon color.RGBA) {
+	daylight := c.Daylight()
+	top = lerpColor(nightTop, dayTop, daylight)
+	horizon = lerpColor(nightHorizon, dayHorizon, daylight)
+
+	// 日出日落时地平线泛红，天色变化到一半时最红
+	glow := 1 - math.Abs(2*daylight-1)
+	return top, lerpColor(horizon, glowHorizon, 0.7*glow)
+}
+
+// Sun returns where the sun is on its way across the sky: 0 when it rises, 1 when
+// it sets, from -0.5 to 1.5 counting the time it is below the horizon
+func (c *Clock) Sun() float64 {
+	return (c.TimeOfDay() - Sunrise) / (Sunset - Sunrise)
+}
+
+// Moon returns where the moon is on its way across the sky, like Sun. The moon
+// rises when the sun sets.
+func (c *Clock) Moon() float64 {
+	sun := c.Sun()
+	if sun < 0.5 {
+		return sun + 1
+	}
+	return sun - 1
+}
+
+// smoothstep eases x from 0 to 1, clamped
+func smoothstep(x float64) float64 {
+	x = math.Max(0, math.Min(1, x))
+	return x * x * (3 - 2*x)
+}
+
+// lerpColor mixes a and b, t from 0 (a) to 1 (b)
+func lerpColor(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + (float64(y)-float64(x))*t))
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), mix(a.A, b.A)}
+}
+
+// floorDiv divides rounding down, so negative ticks fall on earlier days
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
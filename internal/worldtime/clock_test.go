@@ -0,0 +1,178 @@
+package worldtime
+
+import (
+	"math"
+	"testing"
+
+	"github.com/wubinrui111/2d-game/internal/lighting"
+)
+
+func TestClockAdvance(t *testing.T) {
+	c := New(100)
+	if c.Day() != 0 || c.TimeOfDay() != Morning {
+		t.Fatalf("Expected a new clock in the morning of day 0, got day %d at %v", c.Day(), c.TimeOfDay())
+	}
+	for i := 0; i < 100; i++ {
+		c.Advance()
+	}
+	if c.Day() != 1 || c.TimeOfDay() != Morning {
+		t.Errorf("Expected the morning of day 1 after a day of ticks, got day %d at %v", c.Day(), c.TimeOfDay())
+	}
+
+	c.Frozen = true
+	c.Advance()
+	if c.Ticks != 130 {
+		t.Errorf("Expected a frozen clock to keep its time, got %d", c.Ticks)
+	}
+}
+
+func TestClockSetTime(t *testing.T) {
+	c := New(DefaultTicksPerDay)
+	c.Ticks = 3*DefaultTicksPerDay + 10
+
+	c.SetTimeOfDay(Noon)
+	if c.Day() != 3 || c.TimeOfDay() != Noon {
+		t.Errorf("Expected noon of the same day, got day %d at %v", c.Day(), c.TimeOfDay())
+	}
+	if h, m := c.HourMinute(); h != 12 || m != 0 {
+		t.Errorf("Expected 12:00, got %02d:%02d", h, m)
+	}
+
+	// 跳到下一次的某个时刻，已经过了就跳到第二天
+	c.SkipTo(Sunset)
+	if c.Day() != 3 || c.TimeOfDay() != Sunset {
+		t.Errorf("Expected sunset of the same day, got day %d at %v", c.Day(), c.TimeOfDay())
+	}
+	c.SkipTo(Noon)
+	if c.Day() != 4 || c.TimeOfDay() != Noon {
+		t.Errorf("Expected noon of the next day, got day %d at %v", c.Day(), c.TimeOfDay())
+	}
+	c.SkipTo(Noon)
+	if c.Day() != 5 {
+		t.Errorf("Expected skipping to the current time to take a day, got day %d", c.Day())
+	}
+
+	c.Ticks = -1
+	if c.Day() != -1 || c.TimeOfDay() >= 1 || c.TimeOfDay() < 0.99 {
+		t.Errorf("Expected negative ticks on the previous day, got day %d at %v", c.Day(), c.TimeOfDay())
+	}
+}
+
+func TestDaylight(t *testing.T) {
+	c := New(1000)
+	tests := []struct {
+		time     float64
+		daylight float64
+		night    bool
+	}{
+		{Midnight, 0, true},
+		{Sunrise, 0.5, false},
+		{Noon, 1, false},
+		{Sunset, 0.5, true},
+		{Sunset + 0.1, 0, true},
+	}
+	for _, test := range tests {
+		c.SetTimeOfDay(test.time)
+		if got := c.Daylight(); math.Abs(got-test.daylight) > 1e-9 {
+			t.Errorf("Expected daylight %v at %v, got %v", test.daylight, test.time, got)
+		}
+		if c.Night() != test.night {
+			t.Errorf("Expected night %v at %v", test.night, test.time)
+		}
+	}
+
+	c.SetTimeOfDay(Noon)
+	if c.Ambient() != lighting.MaxLevel {
+		t.Errorf("Expected full ambient light at noon, got %v", c.Ambient())
+	}
+	c.SetTimeOfDay(Midnight)
+	if c.Ambient() != NightAmbient {
+		t.Errorf("Expected moonlight at midnight, got %v", c.Ambient())
+	}
+}
+
+func TestSkyColors(t *testing.T) {
+	c := New(1000)
+	c.SetTimeOfDay(Noon)
+	if top, horizon := c.SkyColors(); top != dayTop || horizon != dayHorizon {
+		t.Errorf("Expected the day sky at noon, got %v %v", top, horizon)
+	}
+	c.SetTimeOfDay(Midnight)
+	if top, horizon := c.SkyColors(); top != nightTop || horizon != nightHorizon {
+		t.Errorf("Expected the night sky at midnight, got %v %v", top, horizon)
+	}
+	c.SetTimeOfDay(Sunset)
+	if _, horizon := c.SkyColors(); horizon.R <= horizon.B {
+		t.Errorf("Expected a red horizon at sunset, got %v", horizon)
+	}
+}
+
+func TestSunAndMoon(t *testing.T) {
+	c := New(1000)
+	tests := []struct {
+		time      float64
+		sun, moon float64
+	}{
+		{Sunrise, 0, 1},
+		{Noon, 0.5, -0.5},
+		{Sunset, 1, 0},
+		{Midnight, -0.5, 0.5},
+	}
+	for _, test := range tests {
+		c.SetTimeOfDay(test.time)
+		if c.Sun() != test.sun || c.Moon() != test.moon {
+			t.Errorf("Expected the sun at %v and the moon at %v at %v, got %v and %v", test.sun, test.moon, test.time, c.Sun(), c.Moon())
+		}
+	}
+}
+
+func TestClockSetCommand(t *testing.T) {
+	c := New(DefaultTicksPerDay)
+	c.Ticks = 2*DefaultTicksPerDay + 100
+
+	if err := c.Set(" Sunset "); err != nil || c.Day() != 2 || c.TimeOfDay() != Sunset {
+		t.Errorf("Expected sunset of the same day, got day %d at %v (%v)", c.Day(), c.TimeOfDay(), err)
+	}
+	if err := c.Set("18:30"); err != nil || c.Day() != 2 {
+		t.Fatalf("Expected 18:30 of the same day, got day %d (%v)", c.Day(), err)
+	}
+	if h, m := c.HourMinute(); h != 18 || m != 30 {
+		t.Errorf("Expected 18:30, got %02d:%02d", h, m)
+	}
+	if err := c.Set("1000"); err != nil || c.Ticks != 1000 {
+		t.Errorf("Expected 1000 ticks, got %d (%v)", c.Ticks, err)
+	}
+
+	for _, bad := range []string{"", "teatime", "24:00", "12:60", "-5", "1.5"} {
+		if err := c.Set(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+	if c.Ticks != 1000 {
+		t.Errorf("Expected invalid commands to keep the time, got %d", c.Ticks)
+	}
+}
+
+func TestHostileMobsCanSpawn(t *testing.T) {
+	c := New(1000)
+	tests := []struct {
+		time       float64
+		sky, block int
+		spawn      bool
+	}{
+		{Noon, lighting.MaxLevel, 0, false},
+		{Midnight, lighting.MaxLevel, 0, true},
+		{Sunset, lighting.MaxLevel, 0, false},
+		{Sunset + 0.1, lighting.MaxLevel, 0, true},
+		{Midnight, lighting.MaxLevel, 14, false}, // 火把旁边
+		{Noon, 0, 0, true},                       // 洞穴里
+		{Noon, 0, HostileSpawnLight, true},
+		{Noon, 0, HostileSpawnLight + 1, false},
+	}
+	for _, test := range tests {
+		c.SetTimeOfDay(test.time)
+		if got := c.HostileMobsCanSpawn(test.sky, test.block); got != test.spawn {
+			t.Errorf("Expected spawning %v at %v with sky %d and block light %d", test.spawn, test.time, test.sky, test.block)
+		}
+	}
+}